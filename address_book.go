@@ -1,5 +1,236 @@
 package btc_indexer
 
+import (
+	"context"
+	"fmt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/store"
+	"go.uber.org/zap"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
 type AddressWatcher interface {
 	GetAddresses() []string
 }
+
+const (
+	// maxWatchListName limits the length of the name of a watch list, as stored in DB
+	maxWatchListName = 64
+	// maxWatchListAddresses limits the number of addresses added to or removed from a watch list at once
+	maxWatchListAddresses = 1000
+	// WatchListReloadInterval is the interval the edits made through the other instances are picked up at
+	WatchListReloadInterval = 30 * time.Second
+)
+
+// AddressBook is the AddressWatcher of named watch lists persisted by the store Manager.
+// The lists are cached in memory, the edits are written to DB first then to the cache,
+// the running streams picking them up on their next read.
+// The cache is reloaded from DB periodically by Run, to pick up the edits of the other instances,
+// and of concurrent edits written to DB in another order than to the cache.
+type AddressBook struct {
+	manager     store.Manager
+	chainParams *chaincfg.Params
+
+	mu    sync.RWMutex
+	lists map[string]map[string]bool
+	// addresses is the union of the lists, rebuilt on change
+	addresses []string
+	// edits counts the edits of the cache, for a reload not to overwrite the ones made while reading DB
+	edits uint64
+}
+
+// NewAddressBook loads the watch lists from DB.
+func NewAddressBook(ctx context.Context, manager store.Manager, chainParams *chaincfg.Params) (*AddressBook, error) {
+	b := &AddressBook{
+		manager:     manager,
+		chainParams: chainParams,
+		lists:       make(map[string]map[string]bool),
+	}
+	b.rebuild()
+	err := b.Reload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Run reloads the watch lists from DB at every interval, until the context is done.
+func (b *AddressBook) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := b.Reload(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.L().Error("Failed to Reload watch lists", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Reload replaces the cache by the watch lists of DB.
+// The lists are left as they are if edited while being read, the next reload picking up both.
+func (b *AddressBook) Reload(ctx context.Context) error {
+	b.mu.RLock()
+	edits := b.edits
+	b.mu.RUnlock()
+
+	stored, err := b.manager.GetWatchLists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to Get watch lists: %v", err)
+	}
+	lists := make(map[string]map[string]bool, len(stored))
+	for name, addresses := range stored {
+		watched := make(map[string]bool, len(addresses))
+		for _, a := range addresses {
+			watched[a] = true
+		}
+		lists[name] = watched
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.edits != edits || reflect.DeepEqual(lists, b.lists) {
+		return nil
+	}
+	b.lists = lists
+	b.rebuild()
+	return nil
+}
+
+// GetAddresses returns the addresses of all watch lists in ascending order. The slice must not be modified.
+func (b *AddressBook) GetAddresses() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.addresses
+}
+
+// GetLists returns the addresses of all watch lists by name, in ascending order.
+func (b *AddressBook) GetLists() map[string][]string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	lists := make(map[string][]string, len(b.lists))
+	for name := range b.lists {
+		lists[name] = b.list(name)
+	}
+	return lists
+}
+
+// GetList returns the addresses of a watch list in ascending order, none if it doesn't exist.
+func (b *AddressBook) GetList(name string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.list(name)
+}
+
+// Add validates the addresses against the network, then adds them to the watch list,
+// returning the number of ones not in it before.
+func (b *AddressBook) Add(ctx context.Context, name string, addresses []string) (int64, error) {
+	addresses, err := b.validate(name, addresses)
+	if err != nil {
+		return 0, err
+	}
+
+	// Written to DB without the lock, so the readers of the cache don't wait for it
+	added, err := b.manager.AddWatchedAddresses(ctx, name, addresses)
+	if err != nil {
+		return 0, fmt.Errorf("failed to Add addresses to watch list '%s': %v", name, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.edits++
+	watched, ok := b.lists[name]
+	if !ok {
+		watched = make(map[string]bool, len(addresses))
+		b.lists[name] = watched
+	}
+	for _, a := range addresses {
+		watched[a] = true
+	}
+	if added > 0 {
+		b.rebuild()
+	}
+	return added, nil
+}
+
+// Remove removes the addresses from the watch list, returning the number of ones in it before.
+func (b *AddressBook) Remove(ctx context.Context, name string, addresses []string) (int64, error) {
+	addresses, err := b.validate(name, addresses)
+	if err != nil {
+		return 0, err
+	}
+
+	removed, err := b.manager.RemoveWatchedAddresses(ctx, name, addresses)
+	if err != nil {
+		return 0, fmt.Errorf("failed to Remove addresses from watch list '%s': %v", name, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.edits++
+	watched := b.lists[name]
+	for _, a := range addresses {
+		delete(watched, a)
+	}
+	if len(watched) == 0 {
+		delete(b.lists, name)
+	}
+	if removed > 0 {
+		b.rebuild()
+	}
+	return removed, nil
+}
+
+// validate checks the watch list name, then normalizes the addresses, common.ErrInvalidAddress if one is not of the network.
+func (b *AddressBook) validate(name string, addresses []string) ([]string, error) {
+	if len(name) == 0 || len(name) > maxWatchListName || strings.IndexByte(name, 0) >= 0 {
+		return nil, common.ErrInvalidWatchList
+	}
+	if len(addresses) == 0 {
+		return nil, common.ErrNoAddresses
+	}
+	if len(addresses) > maxWatchListAddresses {
+		return nil, common.ErrTooManyAddresses
+	}
+	return common.NormalizeAddresses(addresses, b.chainParams)
+}
+
+func (b *AddressBook) list(name string) []string {
+	watched, ok := b.lists[name]
+	if !ok {
+		return nil
+	}
+	addresses := make([]string, 0, len(watched))
+	for a := range watched {
+		addresses = append(addresses, a)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// rebuild replaces the union of the lists rather than modifying it, as callers may hold the previous one.
+func (b *AddressBook) rebuild() {
+	seen := make(map[string]bool)
+	addresses := make([]string, 0)
+	for _, watched := range b.lists {
+		for a := range watched {
+			if !seen[a] {
+				seen[a] = true
+				addresses = append(addresses, a)
+			}
+		}
+	}
+	sort.Strings(addresses)
+	b.addresses = addresses
+}
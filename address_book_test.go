@@ -0,0 +1,100 @@
+package btc_indexer
+
+import (
+	"context"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/store"
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+const (
+	mainNetAddress = "1BoatSLRHtKNngkdXEeobR76b53LETtpyT"
+	segwitAddress  = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+	testNetAddress = "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn"
+)
+
+func TestAddressBook(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+	manager := store.NewMemoryManager()
+	book, err := NewAddressBook(ctx, manager, &chaincfg.MainNetParams)
+	Expect(err).Should(Succeed())
+	Expect(book.GetAddresses()).Should(BeEmpty())
+
+	// Normalized to the encoding of the network
+	added, err := book.Add(ctx, "hot", []string{"BC1QAR0SRRR7XFKVY5L643LYDNW9RE59GTZZWF5MDQ", mainNetAddress})
+	Expect(err).Should(Succeed())
+	Expect(added).Should(Equal(int64(2)))
+	Expect(book.GetAddresses()).Should(Equal([]string{mainNetAddress, segwitAddress}))
+
+	added, err = book.Add(ctx, "cold", []string{mainNetAddress})
+	Expect(err).Should(Succeed())
+	Expect(added).Should(Equal(int64(1)))
+	Expect(book.GetAddresses()).Should(Equal([]string{mainNetAddress, segwitAddress}))
+
+	// Nothing changed
+	added, err = book.Add(ctx, "hot", []string{mainNetAddress})
+	Expect(err).Should(Succeed())
+	Expect(added).Should(BeZero())
+
+	_, err = book.Add(ctx, "hot", []string{testNetAddress})
+	Expect(err).Should(Equal(common.ErrInvalidAddress))
+	_, err = book.Add(ctx, "hot", []string{"invalid"})
+	Expect(err).Should(Equal(common.ErrInvalidAddress))
+	_, err = book.Add(ctx, "", []string{mainNetAddress})
+	Expect(err).Should(Equal(common.ErrInvalidWatchList))
+	_, err = book.Add(ctx, "hot", nil)
+	Expect(err).Should(Equal(common.ErrNoAddresses))
+	tooMany := make([]string, maxWatchListAddresses+1)
+	for i := range tooMany {
+		tooMany[i] = mainNetAddress
+	}
+	_, err = book.Add(ctx, "hot", tooMany)
+	Expect(err).Should(Equal(common.ErrTooManyAddresses))
+
+	removed, err := book.Remove(ctx, "hot", []string{mainNetAddress})
+	Expect(err).Should(Succeed())
+	Expect(removed).Should(Equal(int64(1)))
+	// Still in the other list
+	Expect(book.GetAddresses()).Should(Equal([]string{mainNetAddress, segwitAddress}))
+	Expect(book.GetList("hot")).Should(Equal([]string{segwitAddress}))
+
+	// Loaded from DB
+	reloaded, err := NewAddressBook(ctx, manager, &chaincfg.MainNetParams)
+	Expect(err).Should(Succeed())
+	Expect(reloaded.GetLists()).Should(Equal(map[string][]string{"hot": {segwitAddress}, "cold": {mainNetAddress}}))
+	Expect(reloaded.GetAddresses()).Should(Equal(book.GetAddresses()))
+
+	removed, err = book.Remove(ctx, "cold", []string{mainNetAddress})
+	Expect(err).Should(Succeed())
+	Expect(removed).Should(Equal(int64(1)))
+	Expect(book.GetList("cold")).Should(BeNil())
+	Expect(book.GetAddresses()).Should(Equal([]string{segwitAddress}))
+}
+
+func TestAddressBook_Reload(t *testing.T) {
+	RegisterTestingT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager := store.NewMemoryManager()
+	book, err := NewAddressBook(ctx, manager, &chaincfg.MainNetParams)
+	Expect(err).Should(Succeed())
+	other, err := NewAddressBook(ctx, manager, &chaincfg.MainNetParams)
+	Expect(err).Should(Succeed())
+
+	go book.Run(ctx, 10*time.Millisecond)
+
+	// Edited through another instance
+	_, err = other.Add(ctx, "hot", []string{mainNetAddress})
+	Expect(err).Should(Succeed())
+	Eventually(func() []string { return book.GetList("hot") }).Should(Equal([]string{mainNetAddress}))
+
+	_, err = other.Remove(ctx, "hot", []string{mainNetAddress})
+	Expect(err).Should(Succeed())
+	Eventually(book.GetAddresses).Should(BeEmpty())
+	Expect(book.Reload(ctx)).Should(Succeed())
+	Expect(book.GetLists()).Should(BeEmpty())
+}
@@ -0,0 +1,228 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/wire"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+)
+
+const (
+	maxBatchSize         = 100
+	maxRawBlockBatchSize = 10
+)
+
+type rpcRequest struct {
+	Jsonrpc string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     uint64            `json:"id"`
+	Result json.RawMessage   `json:"result"`
+	Error  *btcjson.RPCError `json:"error"`
+}
+
+// batchClient sends JSON-RPC batch requests over HTTP POST, which rpcclient doesn't support.
+type batchClient struct {
+	url        string
+	user       string
+	pass       string
+	httpClient *http.Client
+	id         uint64
+}
+
+func newBatchClient(host, user, pass string) *batchClient {
+	return &batchClient{
+		url:        "http://" + host,
+		user:       user,
+		pass:       pass,
+		httpClient: &http.Client{},
+	}
+}
+
+// call sends one request of method per params in a single batch, and returns the results in the same order.
+func (c *batchClient) call(ctx context.Context, method string, params [][]interface{}) ([]json.RawMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, newCallError(err, "call canceled: %v", err)
+	}
+
+	requests := make([]rpcRequest, 0, len(params))
+	indexes := make(map[uint64]int, len(params))
+	for i, p := range params {
+		id := atomic.AddUint64(&c.id, 1)
+		requests = append(requests, rpcRequest{
+			Jsonrpc: "1.0",
+			ID:      id,
+			Method:  method,
+			Params:  p,
+		})
+		indexes[id] = i
+	}
+
+	body, err := json.Marshal(requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Marshal Batch Request '%s': %v", method, err)
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to Create Batch Request '%s': %v", method, err)
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.SetBasicAuth(c.user, c.pass)
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, newCallError(c.cause(ctx, err), "failed to Send Batch Request '%s': %v", method, err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, newCallError(c.cause(ctx, err), "failed to Read Batch Response '%s': %v", method, err)
+	}
+
+	var responses []rpcResponse
+	err = json.Unmarshal(respBody, &responses)
+	if err != nil {
+		// A request failing as a whole is answered by a single JSON-RPC error
+		single := new(rpcResponse)
+		if json.Unmarshal(respBody, single) == nil && single.Error != nil {
+			return nil, newCallError(single.Error, "failed to call '%s': %v", method, single.Error)
+		}
+		if httpResp.StatusCode != http.StatusOK {
+			// Not answered by the Full Node itself, e.g. by a proxy in front of it
+			return nil, newCallError(&httpStatusError{code: httpResp.StatusCode}, "failed to Send Batch Request '%s', status code '%d'", method, httpResp.StatusCode)
+		}
+		return nil, fmt.Errorf("failed to Unmarshal Batch Response '%s', status code '%d': %v", method, httpResp.StatusCode, err)
+	}
+
+	// Responses of a batch may come in any order
+	results := make([]json.RawMessage, len(params))
+	for _, r := range responses {
+		i, ok := indexes[r.ID]
+		if !ok {
+			return nil, fmt.Errorf("unexpected Batch Response '%s', id '%d'", method, r.ID)
+		}
+		if r.Error != nil {
+			return nil, newCallError(r.Error, "failed to call '%s', params %v: %v", method, params[i], r.Error)
+		}
+		results[i] = r.Result
+		delete(indexes, r.ID)
+	}
+	if len(indexes) > 0 {
+		return nil, fmt.Errorf("missing Batch Responses '%s', number '%d'", method, len(indexes))
+	}
+	return results, nil
+}
+
+// callOne sends a single request of method with the params.
+func (c *batchClient) callOne(ctx context.Context, method string, params ...interface{}) (json.RawMessage, error) {
+	results, err := c.call(ctx, method, [][]interface{}{params})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// cause returns the error of the context if done, as the one of the HTTP client only wraps it.
+func (c *batchClient) cause(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func (c *blockchainClient) GetBlockHeadersVerboseByHeightRange(ctx context.Context, from, to int64) ([]*btcjson.GetBlockHeaderVerboseResult, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid Height Range, from '%d' to '%d'", from, to)
+	}
+
+	headers := make([]*btcjson.GetBlockHeaderVerboseResult, 0, to-from+1)
+	for start := from; start <= to; start += maxBatchSize {
+		end := start + maxBatchSize - 1
+		if end > to {
+			end = to
+		}
+
+		hashParams := make([][]interface{}, 0, end-start+1)
+		for height := start; height <= end; height++ {
+			hashParams = append(hashParams, []interface{}{height})
+		}
+		hashResults, err := c.batchClient.call(ctx, "getblockhash", hashParams)
+		if err != nil {
+			return nil, newCallError(err, "failed to Get Block Hashes, Heights from '%d' to '%d': %v", start, end, err)
+		}
+
+		headerParams := make([][]interface{}, 0, len(hashResults))
+		for _, r := range hashResults {
+			var hash string
+			err = json.Unmarshal(r, &hash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to Unmarshal Block Hash: %v", err)
+			}
+			headerParams = append(headerParams, []interface{}{hash, true})
+		}
+		headerResults, err := c.batchClient.call(ctx, "getblockheader", headerParams)
+		if err != nil {
+			return nil, newCallError(err, "failed to Get Block Headers Verbose, Heights from '%d' to '%d': %v", start, end, err)
+		}
+
+		for _, r := range headerResults {
+			header := new(btcjson.GetBlockHeaderVerboseResult)
+			err = json.Unmarshal(r, header)
+			if err != nil {
+				return nil, fmt.Errorf("failed to Unmarshal Block Header Verbose: %v", err)
+			}
+			headers = append(headers, header)
+		}
+	}
+	return headers, nil
+}
+
+func (c *blockchainClient) GetRawBlocks(ctx context.Context, hashes []string) ([]*wire.MsgBlock, error) {
+	blocks := make([]*wire.MsgBlock, 0, len(hashes))
+	for start := 0; start < len(hashes); start += maxRawBlockBatchSize {
+		end := start + maxRawBlockBatchSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+
+		params := make([][]interface{}, 0, end-start)
+		for _, hash := range hashes[start:end] {
+			params = append(params, []interface{}{hash, 0})
+		}
+		results, err := c.batchClient.call(ctx, "getblock", params)
+		if err != nil {
+			return nil, newCallError(err, "failed to Get Blocks, Hashes %v: %v", hashes[start:end], err)
+		}
+
+		for i, r := range results {
+			var blockHex string
+			err = json.Unmarshal(r, &blockHex)
+			if err != nil {
+				return nil, fmt.Errorf("failed to Unmarshal Block, Hash '%s': %v", hashes[start+i], err)
+			}
+			serializedBlock, err := hex.DecodeString(blockHex)
+			if err != nil {
+				return nil, fmt.Errorf("failed to Decode Block, Hash '%s': %v", hashes[start+i], err)
+			}
+			block := new(wire.MsgBlock)
+			err = block.Deserialize(bytes.NewReader(serializedBlock))
+			if err != nil {
+				return nil, fmt.Errorf("failed to Deserialize Block, Hash '%s': %v", hashes[start+i], err)
+			}
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
@@ -0,0 +1,105 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchClient_Call(t *testing.T) {
+	RegisterTestingT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		Expect(user).Should(Equal("user"))
+		Expect(pass).Should(Equal("pass"))
+
+		var requests []rpcRequest
+		Expect(json.NewDecoder(r.Body).Decode(&requests)).Should(Succeed())
+		// Answer in reverse order
+		responses := make([]rpcResponse, 0, len(requests))
+		for i := len(requests) - 1; i >= 0; i-- {
+			height := requests[i].Params[0].(float64)
+			if height < 0 {
+				responses = append(responses, rpcResponse{ID: requests[i].ID, Error: &btcjson.RPCError{Code: btcjson.ErrRPCOutOfRange, Message: "Block height out of range"}})
+				continue
+			}
+			responses = append(responses, rpcResponse{ID: requests[i].ID, Result: json.RawMessage(fmt.Sprintf(`"hash%v"`, height))})
+		}
+		Expect(json.NewEncoder(w).Encode(responses)).Should(Succeed())
+	}))
+	defer server.Close()
+
+	c := newBatchClient(strings.TrimPrefix(server.URL, "http://"), "user", "pass")
+	results, err := c.call(context.Background(), "getblockhash", [][]interface{}{{1}, {2}, {3}})
+	Expect(err).Should(Succeed())
+	Expect(results).Should(Equal([]json.RawMessage{json.RawMessage(`"hash1"`), json.RawMessage(`"hash2"`), json.RawMessage(`"hash3"`)}))
+
+	_, err = c.call(context.Background(), "getblockhash", [][]interface{}{{1}, {-1}})
+	Expect(err).ShouldNot(Succeed())
+	Expect(Cause(err)).Should(BeAssignableToTypeOf(&btcjson.RPCError{}))
+}
+
+func TestBatchClient_Canceled(t *testing.T) {
+	RegisterTestingT(t)
+
+	// The request is canceled on the node too, nothing is left running
+	canceled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The closing of the connection is only seen once the body is read
+		_, _ = ioutil.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+			close(canceled)
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	c := &blockchainClient{batchClient: newBatchClient(strings.TrimPrefix(server.URL, "http://"), "user", "pass")}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err := c.GetBlockHeaderVerboseByHeight(ctx, 13)
+	Expect(Cause(err)).Should(Equal(context.DeadlineExceeded))
+	Eventually(canceled).Should(BeClosed())
+}
+
+func TestBatchClient_HTTPError(t *testing.T) {
+	RegisterTestingT(t)
+
+	var status int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	c := newBatchClient(strings.TrimPrefix(server.URL, "http://"), "user", "pass")
+
+	// Not answered by the Full Node
+	for code, retryable := range map[int]bool{
+		http.StatusBadGateway:         true,
+		http.StatusServiceUnavailable: true,
+		http.StatusTooManyRequests:    true,
+		http.StatusUnauthorized:       false,
+	} {
+		status, body = code, "<html>error</html>"
+		_, err := c.call(context.Background(), "getblockhash", [][]interface{}{{1}})
+		Expect(err).ShouldNot(Succeed())
+		Expect(IsRetryable(err)).Should(Equal(retryable), "status code %d", code)
+	}
+
+	// Rejected by the Full Node
+	status, body = http.StatusInternalServerError, `{"result":null,"error":{"code":-32700,"message":"Parse error"},"id":null}`
+	_, err := c.call(context.Background(), "getblockhash", [][]interface{}{{1}})
+	Expect(Cause(err)).Should(BeAssignableToTypeOf(&btcjson.RPCError{}))
+	Expect(IsRetryable(err)).Should(BeFalse())
+}
@@ -2,101 +2,156 @@ package blockchain
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
-	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/darkknightbk52/btc-indexer/common/log"
-	"sync"
+	"go.uber.org/zap"
+	"strings"
+	"time"
 )
 
+// Client calls the Full Nodes. A call returns once its context is done, with the error of the context as cause.
 type Client interface {
-	GetBlockHeaderVerboseByHeight(height int64) (*btcjson.GetBlockHeaderVerboseResult, error)
-	GetBlockHeaderVerboseByHash(hash string) (*btcjson.GetBlockHeaderVerboseResult, error)
-	GetRawBlock(hash string) (*wire.MsgBlock, error)
+	GetBlockHeaderVerboseByHeight(ctx context.Context, height int64) (*btcjson.GetBlockHeaderVerboseResult, error)
+	GetBlockHeaderVerboseByHash(ctx context.Context, hash string) (*btcjson.GetBlockHeaderVerboseResult, error)
+	GetRawBlock(ctx context.Context, hash string) (*wire.MsgBlock, error)
+	// GetBlockHeadersVerboseByHeightRange returns the headers from height 'from' to 'to' inclusively, in ascending order.
+	GetBlockHeadersVerboseByHeightRange(ctx context.Context, from, to int64) ([]*btcjson.GetBlockHeaderVerboseResult, error)
+	// GetRawBlocks returns the blocks in the same order as the hashes.
+	GetRawBlocks(ctx context.Context, hashes []string) ([]*wire.MsgBlock, error)
 }
 
+// blockchainClient calls a Full Node through the HTTP client of the batches, which cancels a call with its context.
 type blockchainClient struct {
-	rpcClient *rpcclient.Client
+	batchClient *batchClient
 }
 
-func NewBlockchainClient(ctx context.Context, wg *sync.WaitGroup, cfg Config, chainParams chaincfg.Params) (Client, error) {
-	connCfg := &rpcclient.ConnConfig{
-		Host:                 cfg.Host,
-		User:                 cfg.User,
-		Pass:                 cfg.Pass,
-		DisableAutoReconnect: false,
-		DisableTLS:           true,
-		DisableConnectOnNew:  true,
-		HTTPPostMode:         true,
-	}
-	rpcClient, err := rpcclient.New(connCfg, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to Create RPC client to Full Node, config %v: %v", cfg, err)
+// connectTimeout bounds the check of the network of a Full Node on creation of the client.
+const connectTimeout = 10 * time.Second
+
+// NewBlockchainClient checks the Full Nodes of the hosts are on the network, at least one of them has to be reachable.
+// The hosts not reachable yet are kept, the network of their nodes being checked before their first call.
+func NewBlockchainClient(ctx context.Context, cfg Config, chainParams chaincfg.Params) (Client, error) {
+	hosts := cfg.Hosts()
+	nodes := make([]*node, 0, len(hosts))
+	connected := 0
+	var errContents []string
+	for _, host := range hosts {
+		c := newBlockchainClient(host, cfg)
+		checkCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+		err := c.checkNetwork(checkCtx, chainParams)
+		timedOut := ctx.Err() == nil && checkCtx.Err() == context.DeadlineExceeded
+		cancel()
+		if err == nil {
+			log.L().Info("Blockchain Client connected", zap.String("Host", host))
+			nodes = append(nodes, &node{host: host, client: c})
+			connected++
+			continue
+		}
+
+		log.L().Warn("Failed to Connect Full Node", zap.String("Host", host), zap.Error(err))
+		errContents = append(errContents, fmt.Sprintf("host '%s': %v", host, err))
+		// Not reachable yet, skipped by the failover until its network is checked
+		if IsRetryable(err) || timedOut {
+			nodes = append(nodes, &node{host: host, client: c, check: func(ctx context.Context) error {
+				return c.checkNetwork(ctx, chainParams)
+			}})
+		}
 	}
 
-	genesisBlockHash, err := rpcClient.GetBlockHash(0)
-	if err != nil {
-		rpcClient.Disconnect()
-		return nil, fmt.Errorf("failed to Get Genesis (height=0) block: %v", err)
+	if connected == 0 {
+		return nil, fmt.Errorf("failed to Connect any Full Node: %s", strings.Join(errContents, ", "))
+	}
+	if connected < cfg.Quorum {
+		log.L().Warn("Not enough Full Nodes connected for the quorum yet", zap.Int("Connected", connected), zap.Int("Quorum", cfg.Quorum))
 	}
 
-	if *genesisBlockHash != *chainParams.GenesisHash {
-		rpcClient.Disconnect()
-		return nil, fmt.Errorf("not corresponding network, expect: '%v'", chainParams.Net)
-	}
-
-	wg.Add(1)
-	log.L().Info("Blockchain Client connected")
-	go func() {
-		select {
-		case <-ctx.Done():
-			defer wg.Done()
-			rpcClient.Shutdown()
-			rpcClient.WaitForShutdown()
-			log.L().Info("Blockchain Client disconnected")
-		}
-	}()
+	if len(nodes) == 1 {
+		return nodes[0].client, nil
+	}
+	return newMultiNodeClient(nodes, cfg), nil
+}
 
+func newBlockchainClient(host string, cfg Config) *blockchainClient {
 	return &blockchainClient{
-		rpcClient: rpcClient,
-	}, nil
+		batchClient: newBatchClient(host, cfg.User, cfg.Pass),
+	}
 }
 
-func (c *blockchainClient) GetBlockHeaderVerboseByHeight(height int64) (*btcjson.GetBlockHeaderVerboseResult, error) {
-	h, err := c.rpcClient.GetBlockHash(height)
+// checkNetwork checks the Full Node is on the network of chainParams by its genesis block.
+func (c *blockchainClient) checkNetwork(ctx context.Context, chainParams chaincfg.Params) error {
+	genesisBlockHash, err := c.blockHash(ctx, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to Get Block Hash, Height '%d': %v", height, err)
+		return newCallError(err, "failed to Get Genesis (height=0) block: %v", err)
+	}
+	if *genesisBlockHash != *chainParams.GenesisHash {
+		return fmt.Errorf("not corresponding network, expect: '%v'", chainParams.Net)
 	}
-	header, err := c.rpcClient.GetBlockHeaderVerbose(h)
+	return nil
+}
+
+func (c *blockchainClient) blockHash(ctx context.Context, height int64) (*chainhash.Hash, error) {
+	r, err := c.batchClient.callOne(ctx, "getblockhash", height)
 	if err != nil {
-		return nil, fmt.Errorf("failed to Get Block Header Verbose by Hash '%s', %v", h.String(), err)
+		return nil, newCallError(err, "failed to Get Block Hash, Height '%d': %v", height, err)
 	}
-	return header, nil
+	var hash string
+	err = json.Unmarshal(r, &hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Unmarshal Block Hash, Height '%d': %v", height, err)
+	}
+	return chainhash.NewHashFromStr(hash)
 }
 
-func (c *blockchainClient) GetBlockHeaderVerboseByHash(hash string) (*btcjson.GetBlockHeaderVerboseResult, error) {
-	h, err := chainhash.NewHashFromStr(hash)
+func (c *blockchainClient) getBlockHash(ctx context.Context, height int64) (string, error) {
+	h, err := c.blockHash(ctx, height)
 	if err != nil {
-		return nil, fmt.Errorf("failed to Create Hash from String, hash '%s': %v", hash, err)
+		return "", err
 	}
-	header, err := c.rpcClient.GetBlockHeaderVerbose(h)
+	return h.String(), nil
+}
+
+func (c *blockchainClient) getBlockHeaderVerbose(ctx context.Context, h *chainhash.Hash) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	r, err := c.batchClient.callOne(ctx, "getblockheader", h.String(), true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to Get Block Header Verbose by Hash '%s', %v", h.String(), err)
+		return nil, newCallError(err, "failed to Get Block Header Verbose by Hash '%s', %v", h.String(), err)
+	}
+	header := new(btcjson.GetBlockHeaderVerboseResult)
+	err = json.Unmarshal(r, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Unmarshal Block Header Verbose, Hash '%s': %v", h.String(), err)
 	}
 	return header, nil
 }
 
-func (c *blockchainClient) GetRawBlock(hash string) (*wire.MsgBlock, error) {
+func (c *blockchainClient) GetBlockHeaderVerboseByHeight(ctx context.Context, height int64) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	h, err := c.blockHash(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	return c.getBlockHeaderVerbose(ctx, h)
+}
+
+func (c *blockchainClient) GetBlockHeaderVerboseByHash(ctx context.Context, hash string) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	h, err := chainhash.NewHashFromStr(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Create Hash from String, hash '%s': %v", hash, err)
+	}
+	return c.getBlockHeaderVerbose(ctx, h)
+}
+
+func (c *blockchainClient) GetRawBlock(ctx context.Context, hash string) (*wire.MsgBlock, error) {
 	h, err := chainhash.NewHashFromStr(hash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to Create Hash from String, hash '%s': %v", hash, err)
 	}
-	block, err := c.rpcClient.GetBlock(h)
+	blocks, err := c.GetRawBlocks(ctx, []string{h.String()})
 	if err != nil {
-		return nil, fmt.Errorf("failed to Get Block, Hash '%s': %v", h.String(), err)
+		return nil, err
 	}
-	return block, nil
+	return blocks[0], nil
 }
@@ -3,21 +3,28 @@ package blockchain
 import (
 	"context"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/darkknightbk52/btc-indexer/common/log"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
+	"net/http"
+	"net/http/httptest"
 	"os"
-	"sync"
+	"strings"
 	"testing"
 )
 
 var (
+	// client is of the live node of the test config, nil if not reachable
 	client Client
 	cfg    Config
 )
 
+// TestMain connects to the live node if any, the tests needing it are skipped otherwise,
+// so the ones using fakes run without a node, e.g. in CI.
 func TestMain(m *testing.M) {
 	log.Init(false)
 
@@ -41,28 +48,35 @@ func TestMain(m *testing.M) {
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	var wg sync.WaitGroup
 	cfg = Config{
 		Host: testCfg.Host,
 		User: testCfg.User,
 		Pass: testCfg.Pass,
 	}
-	c, err := NewBlockchainClient(ctx, &wg, cfg, chaincfg.TestNet3Params)
+	c, err := NewBlockchainClient(ctx, cfg, chaincfg.TestNet3Params)
 	if err != nil {
-		log.S().Fatal(err)
+		log.L().Warn("Skip the tests of the live node", zap.Error(err))
+	} else {
+		client = c
 	}
-	client = c
 
 	out := m.Run()
 	cancel()
-	wg.Wait()
 	os.Exit(out)
 }
 
+// requireNode skips a test needing the live node if not reachable.
+func requireNode(t *testing.T) {
+	if client == nil {
+		t.Skip("live node not reachable")
+	}
+}
+
 func TestNewBlockchainClient_Failed(t *testing.T) {
+	requireNode(t)
 	RegisterTestingT(t)
 
-	_, err := NewBlockchainClient(context.Background(), &sync.WaitGroup{}, Config{
+	_, err := NewBlockchainClient(context.Background(), Config{
 		Host: "notExisted:18332",
 		User: "user",
 		Pass: "pass",
@@ -70,7 +84,7 @@ func TestNewBlockchainClient_Failed(t *testing.T) {
 	Expect(err).ShouldNot(Succeed())
 	log.S().Info(err)
 
-	_, err = NewBlockchainClient(context.Background(), &sync.WaitGroup{}, Config{
+	_, err = NewBlockchainClient(context.Background(), Config{
 		Host: cfg.Host,
 		User: "userNotExisted",
 		Pass: "pass",
@@ -79,12 +93,12 @@ func TestNewBlockchainClient_Failed(t *testing.T) {
 	log.S().Info(err)
 
 	isFailed := false
-	if _, err = NewBlockchainClient(context.Background(), &sync.WaitGroup{}, Config{
+	if _, err = NewBlockchainClient(context.Background(), Config{
 		Host: cfg.Host,
 		User: cfg.User,
 		Pass: cfg.Pass,
 	}, chaincfg.MainNetParams); err == nil {
-		_, err = NewBlockchainClient(context.Background(), &sync.WaitGroup{}, Config{
+		_, err = NewBlockchainClient(context.Background(), Config{
 			Host: cfg.Host,
 			User: cfg.User,
 			Pass: cfg.Pass,
@@ -99,9 +113,55 @@ func TestNewBlockchainClient_Failed(t *testing.T) {
 	log.S().Info(err)
 }
 
+// genesisServer answers the Full Node calls of the network check with the genesis block of chainParams.
+func genesisServer(chainParams chaincfg.Params) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requests []rpcRequest
+		_ = json.NewDecoder(r.Body).Decode(&requests)
+		responses := make([]rpcResponse, 0, len(requests))
+		for _, req := range requests {
+			responses = append(responses, rpcResponse{ID: req.ID, Result: json.RawMessage(fmt.Sprintf(`"%s"`, chainParams.GenesisHash))})
+		}
+		_ = json.NewEncoder(w).Encode(responses)
+	}))
+}
+
+func TestNewBlockchainClient_NotReachable(t *testing.T) {
+	RegisterTestingT(t)
+
+	up := genesisServer(chaincfg.TestNet3Params)
+	defer up.Close()
+	mainNet := genesisServer(chaincfg.MainNetParams)
+	defer mainNet.Close()
+	down := genesisServer(chaincfg.TestNet3Params)
+	down.Close()
+	host := func(s *httptest.Server) string {
+		return strings.TrimPrefix(s.URL, "http://")
+	}
+
+	// The node not reachable is kept, the one of another network is not
+	c, err := NewBlockchainClient(context.Background(), Config{
+		Host: strings.Join([]string{host(mainNet), host(down), host(up)}, hostSeparator),
+		User: "user",
+		Pass: "pass",
+	}, chaincfg.TestNet3Params)
+	Expect(err).Should(Succeed())
+	nodes := c.(*multiNodeClient).nodes
+	Expect(nodes).Should(HaveLen(2))
+	Expect(nodes[0].host).Should(Equal(host(down)))
+	Expect(nodes[0].check).ShouldNot(BeNil())
+	Expect(nodes[1].host).Should(Equal(host(up)))
+	Expect(nodes[1].check).Should(BeNil())
+
+	// None reachable
+	_, err = NewBlockchainClient(context.Background(), Config{Host: host(down), User: "user", Pass: "pass"}, chaincfg.TestNet3Params)
+	Expect(err).ShouldNot(Succeed())
+}
+
 func TestBlockchainClient_GetBlockHeaderVerboseByHeight(t *testing.T) {
+	requireNode(t)
 	RegisterTestingT(t)
-	header, err := client.GetBlockHeaderVerboseByHeight(13)
+	header, err := client.GetBlockHeaderVerboseByHeight(context.Background(), 13)
 	Expect(err).Should(Succeed())
 	Expect(header.Hash).Should(Equal("0000000092c69507e1628a6a91e4e69ea28fe378a1a6a636b9c3157e84c71b78"))
 	Expect(header.PreviousHash).Should(Equal("000000004705938332863b772ff732d2d5ac8fe60ee824e37813569bda3a1f00"))
@@ -109,8 +169,9 @@ func TestBlockchainClient_GetBlockHeaderVerboseByHeight(t *testing.T) {
 }
 
 func TestBlockchainClient_GetBlockHeaderVerboseByHash(t *testing.T) {
+	requireNode(t)
 	RegisterTestingT(t)
-	header, err := client.GetBlockHeaderVerboseByHash("0000000092c69507e1628a6a91e4e69ea28fe378a1a6a636b9c3157e84c71b78")
+	header, err := client.GetBlockHeaderVerboseByHash(context.Background(), "0000000092c69507e1628a6a91e4e69ea28fe378a1a6a636b9c3157e84c71b78")
 	Expect(err).Should(Succeed())
 	Expect(header.Height).Should(Equal(int32(13)))
 	Expect(header.PreviousHash).Should(Equal("000000004705938332863b772ff732d2d5ac8fe60ee824e37813569bda3a1f00"))
@@ -118,16 +179,18 @@ func TestBlockchainClient_GetBlockHeaderVerboseByHash(t *testing.T) {
 }
 
 func TestBlockchainClient_GetRawBlock(t *testing.T) {
+	requireNode(t)
 	RegisterTestingT(t)
-	block, err := client.GetRawBlock("0000000092c69507e1628a6a91e4e69ea28fe378a1a6a636b9c3157e84c71b78")
+	block, err := client.GetRawBlock(context.Background(), "0000000092c69507e1628a6a91e4e69ea28fe378a1a6a636b9c3157e84c71b78")
 	Expect(err).Should(Succeed())
 	Expect(block.BlockHash().String()).Should(Equal("0000000092c69507e1628a6a91e4e69ea28fe378a1a6a636b9c3157e84c71b78"))
 	log.L().Info("GetRawBlock", zap.Any("block", block))
 }
 
 func Test(t *testing.T) {
+	requireNode(t)
 	RegisterTestingT(t)
-	block, err := client.GetRawBlock("0000000000018278632a43fa935115fd032da5eb190e6a6766fcd859c6c32495")
+	block, err := client.GetRawBlock(context.Background(), "0000000000018278632a43fa935115fd032da5eb190e6a6766fcd859c6c32495")
 	Expect(err).Should(Succeed())
 	for _, tx := range block.Transactions {
 		log.L().Info("Tx", zap.String("Hash", tx.TxHash().String()))
@@ -2,18 +2,57 @@ package blockchain
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"strings"
+	"time"
+)
+
+const (
+	defaultNodeMaxFailures    = 3
+	defaultNodeRetryTimeInSec = 30
+	hostSeparator             = ","
 )
 
 type Config struct {
+	// Host is the address of a Full Node, or a comma separated list of them
+	// sharing the same credentials. The first healthy node is preferred.
 	Host string
 	User string
 	Pass string
+	// Name keys the metrics of the client, the hosts without credentials if empty
+	Name string
+	// Quorum is the number of nodes which have to agree on a block hash at a height
+	// before it is returned. Zero or one disables the cross-check.
+	Quorum int
+	// NodeMaxFailures is the number of consecutive failures after which a node
+	// is considered unhealthy.
+	NodeMaxFailures int
+	// NodeRetryTimeInSec is how long an unhealthy node is skipped before being tried again.
+	NodeRetryTimeInSec int
+	// Retry & Circuit Breaker settings, zero means the default one.
+	RetryMaxAttempts        int
+	RetryBaseDelayInMs      int
+	RetryMaxDelayInSec      int
+	CallTimeoutInSec        int
+	BreakerFailureThreshold int
+	BreakerOpenTimeInSec    int
+}
+
+func (c Config) Hosts() []string {
+	var hosts []string
+	for _, h := range strings.Split(c.Host, hostSeparator) {
+		h = strings.TrimSpace(h)
+		if len(h) > 0 {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
 }
 
 func (c Config) Validate() error {
 	var errContents []string
-	if len(c.Host) == 0 {
+	if len(c.Hosts()) == 0 {
 		errContents = append(errContents, "Host config for Blockchain Client is required")
 	}
 
@@ -25,9 +64,91 @@ func (c Config) Validate() error {
 		errContents = append(errContents, "Pass config for Blockchain Client is required")
 	}
 
+	if c.Quorum < 0 || c.Quorum > len(c.Hosts()) {
+		errContents = append(errContents, fmt.Sprintf("Quorum config for Blockchain Client must be between 0 and the number of Hosts '%d'", len(c.Hosts())))
+	}
+
+	if c.NodeMaxFailures < 0 {
+		errContents = append(errContents, "NodeMaxFailures config for Blockchain Client must not be negative")
+	}
+
+	if c.NodeRetryTimeInSec < 0 {
+		errContents = append(errContents, "NodeRetryTimeInSec config for Blockchain Client must not be negative")
+	}
+
+	if c.RetryMaxAttempts < 0 || c.RetryBaseDelayInMs < 0 || c.RetryMaxDelayInSec < 0 || c.CallTimeoutInSec < 0 ||
+		c.BreakerFailureThreshold < 0 || c.BreakerOpenTimeInSec < 0 {
+		errContents = append(errContents, "Retry & Breaker configs for Blockchain Client must not be negative")
+	}
+
 	if len(errContents) > 0 {
 		return errors.New(strings.Join(errContents, ", "))
 	}
 
 	return nil
 }
+
+// metricsName returns the configured Name, or the hosts in order without the credentials nor paths of their URLs,
+// so the metrics of the client keep their key whatever the formatting of the hosts.
+func (c Config) metricsName() string {
+	if len(c.Name) > 0 {
+		return c.Name
+	}
+	var names []string
+	for _, h := range c.Hosts() {
+		u, err := url.Parse("http://" + h)
+		if err != nil || len(u.Host) == 0 {
+			continue
+		}
+		names = append(names, u.Host)
+	}
+	if len(names) == 0 {
+		return defaultName
+	}
+	return strings.Join(names, hostSeparator)
+}
+
+func (c Config) nodeMaxFailures() int {
+	if c.NodeMaxFailures > 0 {
+		return c.NodeMaxFailures
+	}
+	return defaultNodeMaxFailures
+}
+
+func (c Config) nodeRetryTimeInSec() int {
+	if c.NodeRetryTimeInSec > 0 {
+		return c.NodeRetryTimeInSec
+	}
+	return defaultNodeRetryTimeInSec
+}
+
+func (c Config) RetryOptions() []Option {
+	opts := []Option{Name(c.metricsName())}
+	if c.RetryMaxAttempts > 0 {
+		opts = append(opts, MaxAttempts(c.RetryMaxAttempts))
+	}
+	if c.RetryBaseDelayInMs > 0 || c.RetryMaxDelayInSec > 0 {
+		baseDelay, maxDelay := defaultBaseDelay, defaultMaxDelay
+		if c.RetryBaseDelayInMs > 0 {
+			baseDelay = time.Millisecond * time.Duration(c.RetryBaseDelayInMs)
+		}
+		if c.RetryMaxDelayInSec > 0 {
+			maxDelay = time.Second * time.Duration(c.RetryMaxDelayInSec)
+		}
+		opts = append(opts, Backoff(baseDelay, maxDelay))
+	}
+	if c.CallTimeoutInSec > 0 {
+		opts = append(opts, CallTimeout(time.Second*time.Duration(c.CallTimeoutInSec)))
+	}
+	if c.BreakerFailureThreshold > 0 || c.BreakerOpenTimeInSec > 0 {
+		threshold, openDuration := defaultFailureThreshold, defaultOpenDuration
+		if c.BreakerFailureThreshold > 0 {
+			threshold = c.BreakerFailureThreshold
+		}
+		if c.BreakerOpenTimeInSec > 0 {
+			openDuration = time.Second * time.Duration(c.BreakerOpenTimeInSec)
+		}
+		opts = append(opts, CircuitBreaker(threshold, openDuration))
+	}
+	return opts
+}
@@ -0,0 +1,15 @@
+package blockchain
+
+import (
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestConfig_MetricsName(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(Config{Host: "node1:8332, user:secret@node2:8332/wallet/hot"}.metricsName()).Should(Equal("node1:8332,node2:8332"))
+	Expect(Config{Host: "node1:8332,node2:8332"}.metricsName()).Should(Equal("node1:8332,node2:8332"))
+	Expect(Config{Host: "user:secret@node1:8332", Name: "primary"}.metricsName()).Should(Equal("primary"))
+	Expect(Config{}.metricsName()).Should(Equal(defaultName))
+}
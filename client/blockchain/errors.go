@@ -0,0 +1,75 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"io"
+	"net"
+	"net/http"
+)
+
+const rpcInWarmupCode btcjson.RPCErrorCode = -28
+
+var (
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	errCallTimeout = errors.New("call timed out")
+)
+
+// callError keeps the cause of a failed call behind its message, so that callers are able to classify it.
+type callError struct {
+	msg   string
+	cause error
+}
+
+func (e *callError) Error() string {
+	return e.msg
+}
+
+func newCallError(cause error, format string, a ...interface{}) error {
+	return &callError{
+		msg:   fmt.Sprintf(format, a...),
+		cause: cause,
+	}
+}
+
+// Cause returns the underlying error of a failed call.
+func Cause(err error) error {
+	for {
+		e, ok := err.(*callError)
+		if !ok {
+			return err
+		}
+		err = e.cause
+	}
+}
+
+// httpStatusError is the status of a HTTP response without JSON-RPC body.
+type httpStatusError struct {
+	code int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("HTTP status '%d'", e.code)
+}
+
+// IsRetryable tells if a failed call is worth retrying, e.g. because of a network error, an overloaded server
+// or a Full Node which is still warming up.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	cause := Cause(err)
+	switch e := cause.(type) {
+	case *btcjson.RPCError:
+		return e.Code == rpcInWarmupCode
+	case net.Error:
+		return true
+	case *quorumError:
+		return true
+	case *httpStatusError:
+		// The server or a proxy is overloaded or failing, unlike a request rejected for its content
+		return e.code >= http.StatusInternalServerError || e.code == http.StatusTooManyRequests
+	}
+	return cause == errCallTimeout || cause == io.EOF || cause == io.ErrUnexpectedEOF
+}
@@ -3,7 +3,7 @@
 package mocks
 
 import btcjson "github.com/btcsuite/btcd/btcjson"
-
+import context "context"
 import mock "github.com/stretchr/testify/mock"
 import wire "github.com/btcsuite/btcd/wire"
 
@@ -12,13 +12,13 @@ type Client struct {
 	mock.Mock
 }
 
-// GetBlockHeaderVerboseByHash provides a mock function with given fields: hash
-func (_m *Client) GetBlockHeaderVerboseByHash(hash string) (*btcjson.GetBlockHeaderVerboseResult, error) {
-	ret := _m.Called(hash)
+// GetBlockHeaderVerboseByHash provides a mock function with given fields: ctx, hash
+func (_m *Client) GetBlockHeaderVerboseByHash(ctx context.Context, hash string) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	ret := _m.Called(ctx, hash)
 
 	var r0 *btcjson.GetBlockHeaderVerboseResult
-	if rf, ok := ret.Get(0).(func(string) *btcjson.GetBlockHeaderVerboseResult); ok {
-		r0 = rf(hash)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *btcjson.GetBlockHeaderVerboseResult); ok {
+		r0 = rf(ctx, hash)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*btcjson.GetBlockHeaderVerboseResult)
@@ -26,8 +26,8 @@ func (_m *Client) GetBlockHeaderVerboseByHash(hash string) (*btcjson.GetBlockHea
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(hash)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,13 +35,13 @@ func (_m *Client) GetBlockHeaderVerboseByHash(hash string) (*btcjson.GetBlockHea
 	return r0, r1
 }
 
-// GetBlockHeaderVerboseByHeight provides a mock function with given fields: height
-func (_m *Client) GetBlockHeaderVerboseByHeight(height int64) (*btcjson.GetBlockHeaderVerboseResult, error) {
-	ret := _m.Called(height)
+// GetBlockHeaderVerboseByHeight provides a mock function with given fields: ctx, height
+func (_m *Client) GetBlockHeaderVerboseByHeight(ctx context.Context, height int64) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	ret := _m.Called(ctx, height)
 
 	var r0 *btcjson.GetBlockHeaderVerboseResult
-	if rf, ok := ret.Get(0).(func(int64) *btcjson.GetBlockHeaderVerboseResult); ok {
-		r0 = rf(height)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *btcjson.GetBlockHeaderVerboseResult); ok {
+		r0 = rf(ctx, height)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*btcjson.GetBlockHeaderVerboseResult)
@@ -49,8 +49,31 @@ func (_m *Client) GetBlockHeaderVerboseByHeight(height int64) (*btcjson.GetBlock
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int64) error); ok {
-		r1 = rf(height)
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, height)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBlockHeadersVerboseByHeightRange provides a mock function with given fields: ctx, from, to
+func (_m *Client) GetBlockHeadersVerboseByHeightRange(ctx context.Context, from int64, to int64) ([]*btcjson.GetBlockHeaderVerboseResult, error) {
+	ret := _m.Called(ctx, from, to)
+
+	var r0 []*btcjson.GetBlockHeaderVerboseResult
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*btcjson.GetBlockHeaderVerboseResult); ok {
+		r0 = rf(ctx, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*btcjson.GetBlockHeaderVerboseResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, from, to)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -58,13 +81,13 @@ func (_m *Client) GetBlockHeaderVerboseByHeight(height int64) (*btcjson.GetBlock
 	return r0, r1
 }
 
-// GetRawBlock provides a mock function with given fields: hash
-func (_m *Client) GetRawBlock(hash string) (*wire.MsgBlock, error) {
-	ret := _m.Called(hash)
+// GetRawBlock provides a mock function with given fields: ctx, hash
+func (_m *Client) GetRawBlock(ctx context.Context, hash string) (*wire.MsgBlock, error) {
+	ret := _m.Called(ctx, hash)
 
 	var r0 *wire.MsgBlock
-	if rf, ok := ret.Get(0).(func(string) *wire.MsgBlock); ok {
-		r0 = rf(hash)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *wire.MsgBlock); ok {
+		r0 = rf(ctx, hash)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*wire.MsgBlock)
@@ -72,8 +95,31 @@ func (_m *Client) GetRawBlock(hash string) (*wire.MsgBlock, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(hash)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRawBlocks provides a mock function with given fields: ctx, hashes
+func (_m *Client) GetRawBlocks(ctx context.Context, hashes []string) ([]*wire.MsgBlock, error) {
+	ret := _m.Called(ctx, hashes)
+
+	var r0 []*wire.MsgBlock
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*wire.MsgBlock); ok {
+		r0 = rf(ctx, hashes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*wire.MsgBlock)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, hashes)
 	} else {
 		r1 = ret.Error(1)
 	}
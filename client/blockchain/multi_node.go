@@ -0,0 +1,268 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+type nodeClient interface {
+	Client
+	getBlockHash(ctx context.Context, height int64) (string, error)
+}
+
+type node struct {
+	host   string
+	client nodeClient
+	// check verifies the network of a node not reachable on creation of the client, nil once done
+	check     func(ctx context.Context) error
+	failures  int
+	downUntil time.Time
+}
+
+// multiNodeClient spreads calls over several Full Nodes. Each call goes to the first healthy node
+// and fails over to the next one on error. With a quorum, block hashes by height are cross-checked
+// across nodes, so a single node on a stale fork cannot feed the Indexer a wrong chain.
+type multiNodeClient struct {
+	mu          sync.Mutex
+	nodes       []*node
+	quorum      int
+	maxFailures int
+	retryTime   time.Duration
+	now         func() time.Time
+}
+
+func newMultiNodeClient(nodes []*node, cfg Config) *multiNodeClient {
+	return &multiNodeClient{
+		nodes:       nodes,
+		quorum:      cfg.Quorum,
+		maxFailures: cfg.nodeMaxFailures(),
+		retryTime:   time.Second * time.Duration(cfg.nodeRetryTimeInSec()),
+		now:         time.Now,
+	}
+}
+
+func (c *multiNodeClient) GetBlockHeaderVerboseByHeight(ctx context.Context, height int64) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	if c.quorum <= 1 {
+		var header *btcjson.GetBlockHeaderVerboseResult
+		err := c.do(ctx, func(n nodeClient) (err error) {
+			header, err = n.GetBlockHeaderVerboseByHeight(ctx, height)
+			return err
+		})
+		return header, err
+	}
+
+	hash, err := c.agreedBlockHash(ctx, height, "")
+	if err != nil {
+		return nil, err
+	}
+	var header *btcjson.GetBlockHeaderVerboseResult
+	err = c.do(ctx, func(n nodeClient) (err error) {
+		header, err = n.GetBlockHeaderVerboseByHash(ctx, hash)
+		return err
+	})
+	return header, err
+}
+
+func (c *multiNodeClient) GetBlockHeaderVerboseByHash(ctx context.Context, hash string) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	var header *btcjson.GetBlockHeaderVerboseResult
+	err := c.do(ctx, func(n nodeClient) (err error) {
+		header, err = n.GetBlockHeaderVerboseByHash(ctx, hash)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.quorum > 1 {
+		_, err = c.agreedBlockHash(ctx, int64(header.Height), header.Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
+
+func (c *multiNodeClient) GetRawBlock(ctx context.Context, hash string) (*wire.MsgBlock, error) {
+	var block *wire.MsgBlock
+	err := c.do(ctx, func(n nodeClient) (err error) {
+		block, err = n.GetRawBlock(ctx, hash)
+		return err
+	})
+	return block, err
+}
+
+func (c *multiNodeClient) GetBlockHeadersVerboseByHeightRange(ctx context.Context, from, to int64) ([]*btcjson.GetBlockHeaderVerboseResult, error) {
+	var headers []*btcjson.GetBlockHeaderVerboseResult
+	if c.quorum <= 1 {
+		err := c.do(ctx, func(n nodeClient) (err error) {
+			headers, err = n.GetBlockHeadersVerboseByHeightRange(ctx, from, to)
+			return err
+		})
+		return headers, err
+	}
+
+	// As the headers are chained, agreeing on the highest one covers the whole range
+	hash, err := c.agreedBlockHash(ctx, to, "")
+	if err != nil {
+		return nil, err
+	}
+	err = c.do(ctx, func(n nodeClient) (err error) {
+		headers, err = n.GetBlockHeadersVerboseByHeightRange(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		if len(headers) == 0 || headers[len(headers)-1].Hash != hash {
+			return fmt.Errorf("not agreed Block Header at Height '%d', expect '%s'", to, hash)
+		}
+		for i := 1; i < len(headers); i++ {
+			if headers[i].PreviousHash != headers[i-1].Hash {
+				return fmt.Errorf("not chained Block Headers at Height '%d'", headers[i].Height)
+			}
+		}
+		return nil
+	})
+	return headers, err
+}
+
+func (c *multiNodeClient) GetRawBlocks(ctx context.Context, hashes []string) ([]*wire.MsgBlock, error) {
+	var blocks []*wire.MsgBlock
+	err := c.do(ctx, func(n nodeClient) (err error) {
+		blocks, err = n.GetRawBlocks(ctx, hashes)
+		return err
+	})
+	return blocks, err
+}
+
+// do calls fn on the nodes in order of preference until one succeeds.
+func (c *multiNodeClient) do(ctx context.Context, fn func(n nodeClient) error) error {
+	var errContents []string
+	var lastErr error
+	for _, n := range c.candidates() {
+		err := c.ready(ctx, n)
+		if err == nil {
+			err = fn(n.client)
+		}
+		if err == nil {
+			c.markSuccess(n)
+			return nil
+		}
+		if ctx.Err() != nil {
+			// Canceled by the caller, neither the node nor the next ones are to blame
+			return err
+		}
+		c.markFailure(n, err)
+		errContents = append(errContents, fmt.Sprintf("host '%s': %v", n.host, err))
+		lastErr = err
+	}
+	return newCallError(lastErr, "%s", strings.Join(errContents, ", "))
+}
+
+// agreedBlockHash asks the nodes for the block hash at height until a quorum of them agrees.
+// If hash is not empty, the agreed hash has to be it.
+func (c *multiNodeClient) agreedBlockHash(ctx context.Context, height int64, hash string) (string, error) {
+	votes := make(map[string]int, len(c.nodes))
+	for _, n := range c.candidates() {
+		err := c.ready(ctx, n)
+		var h string
+		if err == nil {
+			h, err = n.client.getBlockHash(ctx, height)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", err
+			}
+			c.markFailure(n, err)
+			continue
+		}
+		c.markSuccess(n)
+
+		votes[h]++
+		if votes[h] < c.quorum {
+			continue
+		}
+		if len(hash) > 0 && h != hash {
+			return "", &quorumError{msg: fmt.Sprintf("quorum disagrees on Block Hash at Height '%d': expect '%s', agreed '%s'", height, hash, h)}
+		}
+		return h, nil
+	}
+	return "", &quorumError{msg: fmt.Sprintf("quorum '%d' not reached on Block Hash at Height '%d', votes %v", c.quorum, height, votes)}
+}
+
+// quorumError is returned when the nodes do not agree on a block hash, which is
+// usually transient while some of them are catching up.
+type quorumError struct {
+	msg string
+}
+
+func (e *quorumError) Error() string {
+	return e.msg
+}
+
+// candidates returns healthy nodes first, then unhealthy ones as a last resort.
+func (c *multiNodeClient) candidates() []*node {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	healthy := make([]*node, 0, len(c.nodes))
+	var unhealthy []*node
+	for _, n := range c.nodes {
+		if now.Before(n.downUntil) {
+			unhealthy = append(unhealthy, n)
+			continue
+		}
+		healthy = append(healthy, n)
+	}
+	return append(healthy, unhealthy...)
+}
+
+// ready checks the network of the node before its first call.
+func (c *multiNodeClient) ready(ctx context.Context, n *node) error {
+	c.mu.Lock()
+	check := n.check
+	c.mu.Unlock()
+	if check == nil {
+		return nil
+	}
+
+	err := check(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	n.check = nil
+	c.mu.Unlock()
+	log.L().Info("Blockchain Client connected", zap.String("Host", n.host))
+	return nil
+}
+
+func (c *multiNodeClient) markSuccess(n *node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n.failures >= c.maxFailures {
+		log.L().Info("Full Node recovered", zap.String("Host", n.host))
+	}
+	n.failures = 0
+	n.downUntil = time.Time{}
+}
+
+func (c *multiNodeClient) markFailure(n *node, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n.failures++
+	if n.failures < c.maxFailures {
+		log.L().Warn("Full Node call failed", zap.String("Host", n.host), zap.Int("Failures", n.failures), zap.Error(err))
+		return
+	}
+	n.downUntil = c.now().Add(c.retryTime)
+	log.L().Warn("Full Node marked unhealthy", zap.String("Host", n.host), zap.Int("Failures", n.failures), zap.Time("Until", n.downUntil), zap.Error(err))
+}
@@ -0,0 +1,166 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/wire"
+	. "github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+type fakeNodeClient struct {
+	hashes map[int64]string
+	err    error
+	calls  int
+}
+
+func (f *fakeNodeClient) GetBlockHeaderVerboseByHeight(ctx context.Context, height int64) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &btcjson.GetBlockHeaderVerboseResult{Height: int32(height), Hash: f.hashes[height]}, nil
+}
+
+func (f *fakeNodeClient) GetBlockHeaderVerboseByHash(ctx context.Context, hash string) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	for height, h := range f.hashes {
+		if h == hash {
+			return &btcjson.GetBlockHeaderVerboseResult{Height: int32(height), Hash: h}, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeNodeClient) GetRawBlock(ctx context.Context, hash string) (*wire.MsgBlock, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func (f *fakeNodeClient) GetBlockHeadersVerboseByHeightRange(ctx context.Context, from, to int64) ([]*btcjson.GetBlockHeaderVerboseResult, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	var headers []*btcjson.GetBlockHeaderVerboseResult
+	for height := from; height <= to; height++ {
+		headers = append(headers, &btcjson.GetBlockHeaderVerboseResult{Height: int32(height), Hash: f.hashes[height], PreviousHash: f.hashes[height-1]})
+	}
+	return headers, nil
+}
+
+func (f *fakeNodeClient) GetRawBlocks(ctx context.Context, hashes []string) ([]*wire.MsgBlock, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func (f *fakeNodeClient) getBlockHash(ctx context.Context, height int64) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	return f.hashes[height], nil
+}
+
+func TestMultiNodeClient_Failover(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	down := &fakeNodeClient{err: errors.New("connection refused")}
+	up := &fakeNodeClient{hashes: map[int64]string{10: "hash10"}}
+	c := newMultiNodeClient([]*node{{host: "down", client: down}, {host: "up", client: up}}, Config{NodeMaxFailures: 2})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		header, err := c.GetBlockHeaderVerboseByHeight(ctx, 10)
+		Expect(err).Should(Succeed())
+		Expect(header.Hash).Should(Equal("hash10"))
+	}
+	// The failed node is skipped once it is marked unhealthy
+	Expect(down.calls).Should(Equal(2))
+	Expect(up.calls).Should(Equal(3))
+
+	// and tried again after the retry time
+	now = now.Add(time.Second * defaultNodeRetryTimeInSec)
+	down.err = nil
+	down.hashes = up.hashes
+	_, err := c.GetBlockHeaderVerboseByHeight(ctx, 10)
+	Expect(err).Should(Succeed())
+	Expect(down.calls).Should(Equal(3))
+	Expect(up.calls).Should(Equal(3))
+
+	down.err = errors.New("connection refused")
+	up.err = errors.New("connection refused")
+	_, err = c.GetRawBlock(ctx, "hash10")
+	Expect(err).ShouldNot(Succeed())
+}
+
+func TestMultiNodeClient_Quorum(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	forked := &fakeNodeClient{hashes: map[int64]string{10: "stale10"}}
+	a := &fakeNodeClient{hashes: map[int64]string{10: "hash10"}}
+	b := &fakeNodeClient{hashes: map[int64]string{10: "hash10"}}
+	c := newMultiNodeClient([]*node{{host: "forked", client: forked}, {host: "a", client: a}, {host: "b", client: b}}, Config{Quorum: 2})
+
+	header, err := c.GetBlockHeaderVerboseByHeight(ctx, 10)
+	Expect(err).Should(Succeed())
+	Expect(header.Hash).Should(Equal("hash10"))
+
+	_, err = c.GetBlockHeaderVerboseByHash(ctx, "stale10")
+	Expect(err).ShouldNot(Succeed())
+
+	header, err = c.GetBlockHeaderVerboseByHash(ctx, "hash10")
+	Expect(err).Should(Succeed())
+	Expect(header.Height).Should(Equal(int32(10)))
+
+	headers, err := c.GetBlockHeadersVerboseByHeightRange(ctx, 10, 10)
+	Expect(err).Should(Succeed())
+	Expect(headers).Should(HaveLen(1))
+
+	b.hashes = map[int64]string{10: "other10"}
+	_, err = c.GetBlockHeaderVerboseByHeight(ctx, 10)
+	Expect(err).ShouldNot(Succeed())
+}
+
+func TestMultiNodeClient_NotReachable(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	late := &fakeNodeClient{hashes: map[int64]string{10: "hash10"}}
+	up := &fakeNodeClient{hashes: map[int64]string{10: "hash10"}}
+	checkErr := errors.New("connection refused")
+	checks := 0
+	c := newMultiNodeClient([]*node{{host: "late", client: late, check: func(ctx context.Context) error {
+		checks++
+		return checkErr
+	}}, {host: "up", client: up}}, Config{NodeMaxFailures: 1})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	// Not called until its network is checked
+	_, err := c.GetBlockHeaderVerboseByHeight(ctx, 10)
+	Expect(err).Should(Succeed())
+	Expect(checks).Should(Equal(1))
+	Expect(late.calls).Should(BeZero())
+	Expect(up.calls).Should(Equal(1))
+
+	// Reachable after the retry time
+	now = now.Add(time.Second * defaultNodeRetryTimeInSec)
+	checkErr = nil
+	_, err = c.GetBlockHeaderVerboseByHeight(ctx, 10)
+	Expect(err).Should(Succeed())
+	Expect(checks).Should(Equal(2))
+	Expect(late.calls).Should(Equal(1))
+	_, err = c.GetBlockHeaderVerboseByHeight(ctx, 10)
+	Expect(err).Should(Succeed())
+	Expect(checks).Should(Equal(2))
+	Expect(late.calls).Should(Equal(2))
+}
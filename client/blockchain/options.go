@@ -0,0 +1,80 @@
+package blockchain
+
+import "time"
+
+const (
+	defaultMaxAttempts      = 5
+	defaultBaseDelay        = time.Millisecond * 500
+	defaultMaxDelay         = time.Second * 30
+	defaultCallTimeout      = time.Second * 30
+	defaultFailureThreshold = 5
+	defaultOpenDuration     = time.Minute
+	defaultName             = "default"
+)
+
+type Options struct {
+	MaxAttempts      int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	CallTimeout      time.Duration
+	FailureThreshold int
+	OpenDuration     time.Duration
+	// Name keys the metrics of the client, unique per client
+	Name string
+}
+
+type Option func(*Options)
+
+func newOptions(opts ...Option) Options {
+	options := Options{
+		MaxAttempts:      defaultMaxAttempts,
+		BaseDelay:        defaultBaseDelay,
+		MaxDelay:         defaultMaxDelay,
+		CallTimeout:      defaultCallTimeout,
+		FailureThreshold: defaultFailureThreshold,
+		OpenDuration:     defaultOpenDuration,
+		Name:             defaultName,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
+// MaxAttempts is the number of tries of a call, including the first one.
+func MaxAttempts(maxAttempts int) Option {
+	return func(options *Options) {
+		options.MaxAttempts = maxAttempts
+	}
+}
+
+// Backoff sets the delay before the first retry, doubled on every next retry up to maxDelay.
+func Backoff(baseDelay, maxDelay time.Duration) Option {
+	return func(options *Options) {
+		options.BaseDelay = baseDelay
+		options.MaxDelay = maxDelay
+	}
+}
+
+// CallTimeout limits every single try of a call. Zero disables it.
+func CallTimeout(timeout time.Duration) Option {
+	return func(options *Options) {
+		options.CallTimeout = timeout
+	}
+}
+
+// CircuitBreaker opens the circuit after failureThreshold consecutive failed tries,
+// and rejects calls for openDuration before letting a trial call through.
+func CircuitBreaker(failureThreshold int, openDuration time.Duration) Option {
+	return func(options *Options) {
+		options.FailureThreshold = failureThreshold
+		options.OpenDuration = openDuration
+	}
+}
+
+// Name keys the metrics of the client, so the ones of several clients are apart.
+func Name(name string) Option {
+	return func(options *Options) {
+		options.Name = name
+	}
+}
@@ -0,0 +1,260 @@
+package blockchain
+
+import (
+	"context"
+	"expvar"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"go.uber.org/zap"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// metrics is published at /debug/vars, keyed by the name of the client, then by "<Method>.<counter>"
+// plus the circuit state.
+var metrics = expvar.NewMap("blockchain_client")
+
+// retryClient decorates a Client with retries, exponential backoff with jitter,
+// per-call timeouts and a circuit breaker.
+type retryClient struct {
+	client  Client
+	options Options
+
+	mu           sync.Mutex
+	state        circuitState
+	failures     int
+	openedAt     time.Time
+	trialPending bool
+	stateVar     expvar.String
+	metrics      *expvar.Map
+	rand         *rand.Rand
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func NewRetryClient(client Client, opts ...Option) Client {
+	c := &retryClient{
+		client:  client,
+		options: newOptions(opts...),
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:     time.Now,
+		sleep:   sleep,
+		metrics: new(expvar.Map).Init(),
+	}
+	c.stateVar.Set(circuitClosed.String())
+	c.metrics.Set("circuit_state", &c.stateVar)
+	metrics.Set(c.options.Name, c.metrics)
+	return c
+}
+
+func (c *retryClient) GetBlockHeaderVerboseByHeight(ctx context.Context, height int64) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	v, err := c.call(ctx, "GetBlockHeaderVerboseByHeight", func(ctx context.Context) (interface{}, error) {
+		return c.client.GetBlockHeaderVerboseByHeight(ctx, height)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*btcjson.GetBlockHeaderVerboseResult), nil
+}
+
+func (c *retryClient) GetBlockHeaderVerboseByHash(ctx context.Context, hash string) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	v, err := c.call(ctx, "GetBlockHeaderVerboseByHash", func(ctx context.Context) (interface{}, error) {
+		return c.client.GetBlockHeaderVerboseByHash(ctx, hash)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*btcjson.GetBlockHeaderVerboseResult), nil
+}
+
+func (c *retryClient) GetRawBlock(ctx context.Context, hash string) (*wire.MsgBlock, error) {
+	v, err := c.call(ctx, "GetRawBlock", func(ctx context.Context) (interface{}, error) {
+		return c.client.GetRawBlock(ctx, hash)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*wire.MsgBlock), nil
+}
+
+func (c *retryClient) GetBlockHeadersVerboseByHeightRange(ctx context.Context, from, to int64) ([]*btcjson.GetBlockHeaderVerboseResult, error) {
+	v, err := c.call(ctx, "GetBlockHeadersVerboseByHeightRange", func(ctx context.Context) (interface{}, error) {
+		return c.client.GetBlockHeadersVerboseByHeightRange(ctx, from, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*btcjson.GetBlockHeaderVerboseResult), nil
+}
+
+func (c *retryClient) GetRawBlocks(ctx context.Context, hashes []string) ([]*wire.MsgBlock, error) {
+	v, err := c.call(ctx, "GetRawBlocks", func(ctx context.Context) (interface{}, error) {
+		return c.client.GetRawBlocks(ctx, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*wire.MsgBlock), nil
+}
+
+func (c *retryClient) call(ctx context.Context, method string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	for attempt := 1; ; attempt++ {
+		if err := c.allow(); err != nil {
+			c.metrics.Add(method+".rejected", 1)
+			return nil, newCallError(err, "failed to call '%s': %v", method, err)
+		}
+
+		c.metrics.Add(method+".calls", 1)
+		v, err := c.callWithTimeout(ctx, fn)
+		if err != nil && ctx.Err() != nil {
+			// Canceled by the caller, which tells nothing about the Full Node
+			c.release()
+			return nil, err
+		}
+		retryable := IsRetryable(err)
+		c.record(retryable)
+		if err == nil {
+			return v, nil
+		}
+
+		c.metrics.Add(method+".errors", 1)
+		if !retryable || attempt >= c.options.MaxAttempts {
+			return nil, err
+		}
+
+		delay := c.backoff(attempt)
+		c.metrics.Add(method+".retries", 1)
+		log.L().Warn("Blockchain Client call failed, retrying", zap.String("Method", method), zap.Int("Attempt", attempt), zap.Duration("Delay", delay), zap.Error(err))
+		if sleepErr := c.sleep(ctx, delay); sleepErr != nil {
+			return nil, newCallError(sleepErr, "failed to call '%s', canceled while retrying: %v", method, err)
+		}
+	}
+}
+
+// callWithTimeout cancels fn after the call timeout, a Client returning once the context of a call is done.
+func (c *retryClient) callWithTimeout(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if c.options.CallTimeout <= 0 {
+		return fn(ctx)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, c.options.CallTimeout)
+	defer cancel()
+	v, err := fn(callCtx)
+	if err != nil && ctx.Err() == nil && callCtx.Err() == context.DeadlineExceeded {
+		return nil, newCallError(errCallTimeout, "call timed out after %v", c.options.CallTimeout)
+	}
+	return v, err
+}
+
+// sleep waits for the duration, or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// backoff returns a delay growing exponentially with the attempt, half of it randomized.
+func (c *retryClient) backoff(attempt int) time.Duration {
+	delay := c.options.BaseDelay
+	for i := 1; i < attempt && delay < c.options.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.options.MaxDelay {
+		delay = c.options.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return delay/2 + time.Duration(c.rand.Int63n(int64(delay/2)+1))
+}
+
+func (c *retryClient) allow() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case circuitOpen:
+		if c.now().Sub(c.openedAt) < c.options.OpenDuration {
+			return ErrCircuitOpen
+		}
+		c.setState(circuitHalfOpen)
+		c.trialPending = true
+		return nil
+	case circuitHalfOpen:
+		if c.trialPending {
+			return ErrCircuitOpen
+		}
+		c.trialPending = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// release ends a try without outcome, so that a pending trial call doesn't block the next ones.
+func (c *retryClient) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.trialPending = false
+}
+
+// record updates the circuit breaker with the outcome of a try. Only retryable errors are
+// counted as failures, others mean the Full Node is reachable and answering.
+func (c *retryClient) record(failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.trialPending = false
+	if !failed {
+		c.failures = 0
+		c.setState(circuitClosed)
+		return
+	}
+
+	c.failures++
+	if c.state == circuitHalfOpen || (c.state == circuitClosed && c.failures >= c.options.FailureThreshold) {
+		c.openedAt = c.now()
+		c.setState(circuitOpen)
+		c.metrics.Add("circuit_opens", 1)
+	}
+}
+
+func (c *retryClient) setState(state circuitState) {
+	if c.state == state {
+		return
+	}
+	log.L().Info("Blockchain Client circuit breaker state changed", zap.Stringer("From", c.state), zap.Stringer("To", state), zap.Int("Failures", c.failures))
+	c.state = state
+	c.stateVar.Set(state.String())
+}
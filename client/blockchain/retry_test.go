@@ -0,0 +1,208 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/wire"
+	. "github.com/onsi/gomega"
+	"net"
+	"testing"
+	"time"
+)
+
+type fakeClient struct {
+	errs  []error
+	calls int
+	delay time.Duration
+}
+
+// next returns the next error after the delay, or the one of the context if done before, as a Client does.
+func (f *fakeClient) next(ctx context.Context) error {
+	f.calls++
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return newCallError(ctx.Err(), "call canceled: %v", ctx.Err())
+	}
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *fakeClient) GetBlockHeaderVerboseByHeight(ctx context.Context, height int64) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	if err := f.next(ctx); err != nil {
+		return nil, err
+	}
+	return &btcjson.GetBlockHeaderVerboseResult{Height: int32(height)}, nil
+}
+
+func (f *fakeClient) GetBlockHeaderVerboseByHash(ctx context.Context, hash string) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	if err := f.next(ctx); err != nil {
+		return nil, err
+	}
+	return &btcjson.GetBlockHeaderVerboseResult{Hash: hash}, nil
+}
+
+func (f *fakeClient) GetRawBlock(ctx context.Context, hash string) (*wire.MsgBlock, error) {
+	if err := f.next(ctx); err != nil {
+		return nil, err
+	}
+	return &wire.MsgBlock{}, nil
+}
+
+func (f *fakeClient) GetBlockHeadersVerboseByHeightRange(ctx context.Context, from, to int64) ([]*btcjson.GetBlockHeaderVerboseResult, error) {
+	if err := f.next(ctx); err != nil {
+		return nil, err
+	}
+	return []*btcjson.GetBlockHeaderVerboseResult{{Height: int32(from)}, {Height: int32(to)}}, nil
+}
+
+func (f *fakeClient) GetRawBlocks(ctx context.Context, hashes []string) ([]*wire.MsgBlock, error) {
+	if err := f.next(ctx); err != nil {
+		return nil, err
+	}
+	return []*wire.MsgBlock{{}}, nil
+}
+
+func newTestRetryClient(client Client, opts ...Option) *retryClient {
+	c := NewRetryClient(client, opts...).(*retryClient)
+	c.sleep = func(context.Context, time.Duration) error { return nil }
+	return c
+}
+
+func TestIsRetryable(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(IsRetryable(nil)).Should(BeFalse())
+	Expect(IsRetryable(errors.New("unknown"))).Should(BeFalse())
+	Expect(IsRetryable(newCallError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}, "failed"))).Should(BeTrue())
+	Expect(IsRetryable(newCallError(&btcjson.RPCError{Code: rpcInWarmupCode}, "failed"))).Should(BeTrue())
+	Expect(IsRetryable(newCallError(&btcjson.RPCError{Code: btcjson.ErrRPCBlockNotFound}, "failed"))).Should(BeFalse())
+	Expect(IsRetryable(newCallError(newCallError(errCallTimeout, "timeout"), "failed"))).Should(BeTrue())
+	Expect(IsRetryable(newCallError(ErrCircuitOpen, "failed"))).Should(BeFalse())
+}
+
+func TestRetryClient_Retry(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	netErr := newCallError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}, "failed")
+	f := &fakeClient{errs: []error{netErr, netErr}}
+	c := newTestRetryClient(f, MaxAttempts(3))
+	header, err := c.GetBlockHeaderVerboseByHeight(ctx, 10)
+	Expect(err).Should(Succeed())
+	Expect(header.Height).Should(Equal(int32(10)))
+	Expect(f.calls).Should(Equal(3))
+
+	f = &fakeClient{errs: []error{netErr, netErr, netErr}}
+	c = newTestRetryClient(f, MaxAttempts(3))
+	_, err = c.GetRawBlock(ctx, "hash")
+	Expect(err).ShouldNot(Succeed())
+	Expect(f.calls).Should(Equal(3))
+
+	f = &fakeClient{errs: []error{newCallError(&btcjson.RPCError{Code: btcjson.ErrRPCBlockNotFound}, "not found")}}
+	c = newTestRetryClient(f, MaxAttempts(3))
+	_, err = c.GetBlockHeaderVerboseByHash(ctx, "hash")
+	Expect(err).ShouldNot(Succeed())
+	Expect(f.calls).Should(Equal(1))
+}
+
+func TestRetryClient_CallTimeout(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	f := &fakeClient{delay: time.Millisecond * 100}
+	c := newTestRetryClient(f, MaxAttempts(1), CallTimeout(time.Millisecond*10))
+	_, err := c.GetRawBlock(ctx, "hash")
+	Expect(err).ShouldNot(Succeed())
+	Expect(Cause(err)).Should(Equal(errCallTimeout))
+}
+
+func TestRetryClient_Backoff(t *testing.T) {
+	RegisterTestingT(t)
+
+	c := newTestRetryClient(&fakeClient{}, Backoff(time.Second, time.Second*5))
+	for attempt, max := range []time.Duration{time.Second, time.Second * 2, time.Second * 4, time.Second * 5, time.Second * 5} {
+		delay := c.backoff(attempt + 1)
+		Expect(delay).Should(BeNumerically(">=", max/2))
+		Expect(delay).Should(BeNumerically("<=", max))
+	}
+}
+
+func TestRetryClient_CircuitBreaker(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	netErr := newCallError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}, "failed")
+	f := &fakeClient{errs: []error{netErr, netErr, netErr}}
+	c := newTestRetryClient(f, MaxAttempts(1), CircuitBreaker(2, time.Minute))
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, err := c.GetRawBlock(ctx, "hash")
+		Expect(Cause(err)).ShouldNot(Equal(ErrCircuitOpen))
+	}
+	Expect(c.state).Should(Equal(circuitOpen))
+
+	// Calls are rejected without reaching the Full Node while the circuit is open
+	_, err := c.GetRawBlock(ctx, "hash")
+	Expect(Cause(err)).Should(Equal(ErrCircuitOpen))
+	Expect(f.calls).Should(Equal(2))
+
+	// A failed trial call opens the circuit again
+	now = now.Add(time.Minute)
+	_, err = c.GetRawBlock(ctx, "hash")
+	Expect(Cause(err)).ShouldNot(Equal(ErrCircuitOpen))
+	Expect(f.calls).Should(Equal(3))
+	Expect(c.state).Should(Equal(circuitOpen))
+
+	// and a successful one closes it
+	now = now.Add(time.Minute)
+	_, err = c.GetRawBlock(ctx, "hash")
+	Expect(err).Should(Succeed())
+	Expect(c.state).Should(Equal(circuitClosed))
+}
+
+func TestRetryClient_Metrics(t *testing.T) {
+	RegisterTestingT(t)
+
+	netErr := newCallError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}, "failed")
+	a := newTestRetryClient(&fakeClient{errs: []error{netErr}}, Name("a"), MaxAttempts(1), CircuitBreaker(1, time.Minute))
+	newTestRetryClient(&fakeClient{}, Name("b"))
+	_, err := a.GetRawBlock(context.Background(), "hash")
+	Expect(err).Should(HaveOccurred())
+
+	// The state of a client is not overwritten by the one of another
+	Expect(metrics.Get("a").(*expvar.Map).Get("circuit_state").String()).Should(Equal(`"open"`))
+	Expect(metrics.Get("a").(*expvar.Map).Get("GetRawBlock.calls").String()).Should(Equal("1"))
+	Expect(metrics.Get("b").(*expvar.Map).Get("circuit_state").String()).Should(Equal(`"closed"`))
+}
+
+func TestRetryClient_Canceled(t *testing.T) {
+	RegisterTestingT(t)
+
+	f := &fakeClient{delay: time.Millisecond * 100}
+	c := newTestRetryClient(f, MaxAttempts(3), CircuitBreaker(1, time.Minute))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err := c.GetRawBlock(ctx, "hash")
+	Expect(Cause(err)).Should(Equal(context.DeadlineExceeded))
+	// Neither retried nor counted as a failure of the Full Node
+	Expect(f.calls).Should(Equal(1))
+	Expect(c.state).Should(Equal(circuitClosed))
+
+	netErr := newCallError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}, "failed")
+	f = &fakeClient{errs: []error{netErr, netErr}}
+	c = NewRetryClient(f, MaxAttempts(3), Backoff(time.Minute, time.Minute)).(*retryClient)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err = c.GetRawBlock(ctx, "hash")
+	Expect(Cause(err)).Should(Equal(context.DeadlineExceeded))
+	Expect(f.calls).Should(Equal(1))
+}
@@ -5,6 +5,12 @@ import (
 	"fmt"
 )
 
+const (
+	defaultSafeDistance          = 100
+	defaultGetBlockIntervalInSec = 5
+)
+
+// Config of the sync streams, the defaults being used for the zero values.
 type Config struct {
 	SyncClientSafeDistance          int64
 	SyncClientGetBlockIntervalInSec int
@@ -12,11 +18,11 @@ type Config struct {
 
 func (c Config) Validate() error {
 	var errContent string
-	if c.SyncClientSafeDistance < 100 {
+	if c.SyncClientSafeDistance != 0 && c.SyncClientSafeDistance < 100 {
 		errContent = fmt.Sprintf("the Safe Distance should be greater than 100 blocks, configured value '%d'", c.SyncClientSafeDistance)
 	}
 
-	if c.SyncClientGetBlockIntervalInSec < 3 || c.SyncClientGetBlockIntervalInSec > 10 {
+	if c.SyncClientGetBlockIntervalInSec != 0 && (c.SyncClientGetBlockIntervalInSec < 3 || c.SyncClientGetBlockIntervalInSec > 10) {
 		errContent = fmt.Sprintf("%s, the Get Block Interval In Second should be in [3,10], configured value '%d'", errContent, c.SyncClientGetBlockIntervalInSec)
 	}
 
@@ -26,3 +32,17 @@ func (c Config) Validate() error {
 
 	return nil
 }
+
+func (c Config) safeDistance() int64 {
+	if c.SyncClientSafeDistance == 0 {
+		return defaultSafeDistance
+	}
+	return c.SyncClientSafeDistance
+}
+
+func (c Config) getBlockIntervalInSec() int {
+	if c.SyncClientGetBlockIntervalInSec == 0 {
+		return defaultGetBlockIntervalInSec
+	}
+	return c.SyncClientGetBlockIntervalInSec
+}
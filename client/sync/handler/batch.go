@@ -1,8 +1,8 @@
 package handler
 
 import (
+	"context"
 	"fmt"
-	btc_indexer "github.com/darkknightbk52/btc-indexer"
 	"github.com/darkknightbk52/btc-indexer/common"
 	"github.com/darkknightbk52/btc-indexer/model"
 	proto "github.com/darkknightbk52/btc-indexer/proto"
@@ -12,15 +12,16 @@ import (
 const blockBatchSize int64 = 1000
 
 type batchHandler struct {
+	ctx            context.Context
 	stream         proto.BtcIndexer_SyncStream
 	manager        store.Manager
-	addressWatcher btc_indexer.AddressWatcher
+	addressWatcher AddressWatcher
 	fromHeight     int64
 	toHeight       int64
 }
 
-func NewBatchHandler(stream proto.BtcIndexer_SyncStream, manager store.Manager, addressBook btc_indexer.AddressWatcher, fromHeight int64, toHeight int64) *batchHandler {
-	return &batchHandler{stream: stream, manager: manager, addressWatcher: addressBook, fromHeight: fromHeight, toHeight: toHeight}
+func NewBatchHandler(ctx context.Context, stream proto.BtcIndexer_SyncStream, manager store.Manager, addressBook AddressWatcher, fromHeight int64, toHeight int64) *batchHandler {
+	return &batchHandler{ctx: ctx, stream: stream, manager: manager, addressWatcher: addressBook, fromHeight: fromHeight, toHeight: toHeight}
 }
 
 func (h *batchHandler) Handle() error {
@@ -36,7 +37,7 @@ func (h *batchHandler) Handle() error {
 		if h.fromHeight+blockBatchSize < h.fromHeight {
 			targetHeight = h.fromHeight + blockBatchSize
 		}
-		blocks, txIns, txOuts, err := h.manager.GetBlocksData(h.fromHeight, targetHeight, h.addressWatcher.GetAddresses())
+		blocks, txIns, txOuts, err := h.manager.GetBlocksData(h.ctx, h.fromHeight, targetHeight, h.addressWatcher.GetAddresses())
 		if err != nil {
 			return fmt.Errorf("failed to Get Blocks Data, fromHeight '%d', toHeight '%d', No Of WatchingAddresses '%d': %v", h.fromHeight, targetHeight, len(h.addressWatcher.GetAddresses()), err)
 		}
@@ -3,3 +3,8 @@ package handler
 type Handler interface {
 	Handle() error
 }
+
+// AddressWatcher gives the addresses whose data are synced, read again for every block or batch of blocks.
+type AddressWatcher interface {
+	GetAddresses() []string
+}
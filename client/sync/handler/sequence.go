@@ -3,7 +3,6 @@ package handler
 import (
 	"context"
 	"fmt"
-	btc_indexer "github.com/darkknightbk52/btc-indexer"
 	"github.com/darkknightbk52/btc-indexer/common"
 	"github.com/darkknightbk52/btc-indexer/common/log"
 	"github.com/darkknightbk52/btc-indexer/model"
@@ -17,12 +16,12 @@ type sequenceHandler struct {
 	ctx                           context.Context
 	stream                        proto.BtcIndexer_SyncStream
 	manager                       store.Manager
-	addressWatcher                btc_indexer.AddressWatcher
+	addressWatcher                AddressWatcher
 	recentBlocksAscendingByHeight []*proto.Block
 	getBlockIntervalInSec         int
 }
 
-func NewSequenceHandler(ctx context.Context, stream proto.BtcIndexer_SyncStream, manager store.Manager, addressBook btc_indexer.AddressWatcher, recentBlocks []*proto.Block, getBlockIntervalInSec int) *sequenceHandler {
+func NewSequenceHandler(ctx context.Context, stream proto.BtcIndexer_SyncStream, manager store.Manager, addressBook AddressWatcher, recentBlocks []*proto.Block, getBlockIntervalInSec int) *sequenceHandler {
 	return &sequenceHandler{ctx: ctx, stream: stream, manager: manager, addressWatcher: addressBook, recentBlocksAscendingByHeight: recentBlocks, getBlockIntervalInSec: getBlockIntervalInSec}
 }
 
@@ -49,7 +48,7 @@ func (h *sequenceHandler) Handle() error {
 	ticker := time.NewTicker(time.Second * time.Duration(h.getBlockIntervalInSec))
 	defer ticker.Stop()
 	for {
-		nextBlock, err := h.manager.GetBlock(nextHeight)
+		nextBlock, err := h.manager.GetBlock(h.ctx, nextHeight)
 		if err != nil && err != common.ErrNotFound {
 			return fmt.Errorf("failed to Get Next Block, height '%d': %v", nextHeight, err)
 		}
@@ -113,7 +112,7 @@ func (h *sequenceHandler) processReorgBlock() error {
 }
 
 func (h *sequenceHandler) processNewBlock(b *model.Block) error {
-	_, txIns, txOuts, err := h.manager.GetBlocksData(b.Height, b.Height, h.addressWatcher.GetAddresses())
+	_, txIns, txOuts, err := h.manager.GetBlocksData(h.ctx, b.Height, b.Height, h.addressWatcher.GetAddresses())
 	if err != nil {
 		return fmt.Errorf("failed to Get Block Data, Height '%d', No Of WatchingAddresses '%d': %v", b.Height, len(h.addressWatcher.GetAddresses()), err)
 	}
@@ -135,7 +134,7 @@ func (h *sequenceHandler) checkReorg() (branchBlock *proto.Block, newBlock *mode
 	for _, b := range h.recentBlocksAscendingByHeight {
 		heights = append(heights, b.Height)
 	}
-	localBlocks, err := h.manager.GetBlocks(heights)
+	localBlocks, err := h.manager.GetBlocks(h.ctx, heights)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to Get Blocks, block heights '%v': %v", heights, err)
 	}
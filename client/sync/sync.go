@@ -3,36 +3,60 @@ package sync
 import (
 	"context"
 	"fmt"
-	btc_indexer "github.com/darkknightbk52/btc-indexer"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/darkknightbk52/btc-indexer/client/sync/handler"
+	"github.com/darkknightbk52/btc-indexer/common"
 	proto "github.com/darkknightbk52/btc-indexer/proto"
 	"github.com/darkknightbk52/btc-indexer/store"
+	"io"
 	"sort"
 )
 
+// maxStreamAddresses limits the number of addresses declared by a sync request
+const maxStreamAddresses = 10000
+
 type Client interface {
 	Sync() error
 }
 
+// WatchLists gives the addresses of the named watch lists.
+type WatchLists interface {
+	// GetList returns the addresses of a watch list, none if it doesn't exist.
+	GetList(name string) []string
+}
+
 type syncClient struct {
 	config         Config
 	ctx            context.Context
 	stream         proto.BtcIndexer_SyncStream
 	manager        store.Manager
-	addressWatcher btc_indexer.AddressWatcher
+	addressWatcher handler.AddressWatcher
+	watchLists     WatchLists
+	chainParams    *chaincfg.Params
+	// streamWatcher holds the addresses declared by the requests of the stream, nil until the first one
+	streamWatcher handler.AddressWatcher
 }
 
-func NewSyncClient(config Config, ctx context.Context, stream proto.BtcIndexer_SyncStream, manager store.Manager, addressBook btc_indexer.AddressWatcher) *syncClient {
-	return &syncClient{config: config, ctx: ctx, stream: stream, manager: manager, addressWatcher: addressBook}
+func NewSyncClient(config Config, ctx context.Context, stream proto.BtcIndexer_SyncStream, manager store.Manager, addressBook handler.AddressWatcher, watchLists WatchLists, chainParams *chaincfg.Params) *syncClient {
+	return &syncClient{config: config, ctx: ctx, stream: stream, manager: manager, addressWatcher: addressBook, watchLists: watchLists, chainParams: chainParams}
 }
 
+// Sync handles the requests of the stream until the client closes it, nil then.
+// The errors of an invalid request are the ones of package common, not wrapped.
 func (c *syncClient) Sync() error {
 	for {
 		req, err := c.stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
 		if err != nil {
 			return fmt.Errorf("failed to Receive from Streamer: %v", err)
 		}
-		h, err := c.makeHandler(req)
+		addressWatcher, err := c.watcher(req)
+		if err != nil {
+			return err
+		}
+		h, err := c.makeHandler(req, addressWatcher)
 		if err != nil {
 			return fmt.Errorf("failed to Make Handler, req '%v': %v", req, err)
 		}
@@ -43,8 +67,8 @@ func (c *syncClient) Sync() error {
 	}
 }
 
-func (c *syncClient) makeHandler(req *proto.SyncRequest) (handler.Handler, error) {
-	latestBlock, err := c.manager.GetLatestBlock()
+func (c *syncClient) makeHandler(req *proto.SyncRequest, addressWatcher handler.AddressWatcher) (handler.Handler, error) {
+	latestBlock, err := c.manager.GetLatestBlock(c.ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to Get Latest Block: %v", err)
 	}
@@ -61,14 +85,70 @@ func (c *syncClient) makeHandler(req *proto.SyncRequest) (handler.Handler, error
 		return req.RecentBlocks[len(req.RecentBlocks)-1].Height
 	}()
 
-	if mostRecentBlockHeight == 0 || mostRecentBlockHeight < latestBlock.Height-c.config.SyncClientSafeDistance {
+	if mostRecentBlockHeight == 0 || mostRecentBlockHeight < latestBlock.Height-c.config.safeDistance() {
 		fromHeight := func() int64 {
 			if mostRecentBlockHeight == 0 {
 				return 0
 			}
 			return mostRecentBlockHeight + 1
 		}
-		return handler.NewBatchHandler(c.stream, c.manager, c.addressWatcher, fromHeight(), latestBlock.Height-c.config.SyncClientSafeDistance), nil
+		return handler.NewBatchHandler(c.ctx, c.stream, c.manager, addressWatcher, fromHeight(), latestBlock.Height-c.config.safeDistance()), nil
+	}
+	return handler.NewSequenceHandler(c.ctx, c.stream, c.manager, addressWatcher, req.RecentBlocks, c.config.getBlockIntervalInSec()), nil
+}
+
+// watcher returns the addresses of the stream, replaced by the ones declared by the request if any.
+// The first request must declare them, common.ErrNoAddresses otherwise.
+func (c *syncClient) watcher(req *proto.SyncRequest) (handler.AddressWatcher, error) {
+	declared := 0
+	for _, d := range []bool{len(req.Addresses) > 0, len(req.WatchList) > 0, req.AllWatchLists} {
+		if d {
+			declared++
+		}
+	}
+	if declared > 1 {
+		return nil, common.ErrInvalidWatchList
+	}
+
+	switch {
+	case len(req.Addresses) > 0:
+		if len(req.Addresses) > maxStreamAddresses {
+			return nil, common.ErrTooManyAddresses
+		}
+		// Normalized as the indexed addresses, which are in the encoding of the network
+		addresses, err := common.NormalizeAddresses(req.Addresses, c.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		c.streamWatcher = staticAddresses(addresses)
+	case len(req.WatchList) > 0:
+		if c.watchLists == nil || len(c.watchLists.GetList(req.WatchList)) == 0 {
+			return nil, common.ErrNotFound
+		}
+		c.streamWatcher = &watchListWatcher{lists: c.watchLists, name: req.WatchList}
+	case req.AllWatchLists:
+		c.streamWatcher = c.addressWatcher
 	}
-	return handler.NewSequenceHandler(c.ctx, c.stream, c.manager, c.addressWatcher, req.RecentBlocks, c.config.SyncClientGetBlockIntervalInSec), nil
+
+	if c.streamWatcher == nil {
+		return nil, common.ErrNoAddresses
+	}
+	return c.streamWatcher, nil
+}
+
+// staticAddresses is the AddressWatcher of the addresses declared by a request.
+type staticAddresses []string
+
+func (a staticAddresses) GetAddresses() []string {
+	return a
+}
+
+// watchListWatcher is the AddressWatcher of a named watch list, reading it on every call to follow its edits.
+type watchListWatcher struct {
+	lists WatchLists
+	name  string
+}
+
+func (w *watchListWatcher) GetAddresses() []string {
+	return w.lists.GetList(w.name)
 }
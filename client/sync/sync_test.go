@@ -3,16 +3,22 @@ package sync
 import (
 	"context"
 	"fmt"
+	"github.com/btcsuite/btcd/chaincfg"
+	syncMocks "github.com/darkknightbk52/btc-indexer/client/sync/mocks"
 	"github.com/darkknightbk52/btc-indexer/common"
 	"github.com/darkknightbk52/btc-indexer/common/log"
 	indexerMocks "github.com/darkknightbk52/btc-indexer/mocks"
 	"github.com/darkknightbk52/btc-indexer/model"
 	proto "github.com/darkknightbk52/btc-indexer/proto"
 	protoMocks "github.com/darkknightbk52/btc-indexer/proto/mocks"
+	"github.com/darkknightbk52/btc-indexer/store"
 	storeMocks "github.com/darkknightbk52/btc-indexer/store/mocks"
 	"github.com/jinzhu/gorm"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"strings"
 	"testing"
 	"time"
 )
@@ -22,6 +28,7 @@ var _ = Describe("Sync Client test", func() {
 		client             *syncClient
 		cancel             context.CancelFunc
 		mockAddressWatcher *indexerMocks.AddressWatcher
+		mockWatchLists     *syncMocks.WatchLists
 		mockStream         *protoMocks.BtcIndexer_SyncStream
 		mockManager        *storeMocks.Manager
 	)
@@ -29,6 +36,7 @@ var _ = Describe("Sync Client test", func() {
 	BeforeEach(func() {
 		log.Init(false)
 		mockAddressWatcher = new(indexerMocks.AddressWatcher)
+		mockWatchLists = new(syncMocks.WatchLists)
 		mockStream = new(protoMocks.BtcIndexer_SyncStream)
 		mockManager = new(storeMocks.Manager)
 		client = &syncClient{
@@ -37,6 +45,8 @@ var _ = Describe("Sync Client test", func() {
 				SyncClientGetBlockIntervalInSec: 1,
 			},
 			addressWatcher: mockAddressWatcher,
+			watchLists:     mockWatchLists,
+			chainParams:    &chaincfg.TestNet3Params,
 			stream:         mockStream,
 			manager:        mockManager,
 		}
@@ -45,6 +55,7 @@ var _ = Describe("Sync Client test", func() {
 
 	AfterEach(func() {
 		mockAddressWatcher.AssertExpectations(GinkgoT())
+		mockWatchLists.AssertExpectations(GinkgoT())
 		mockStream.AssertExpectations(GinkgoT())
 		mockManager.AssertExpectations(GinkgoT())
 	})
@@ -52,8 +63,8 @@ var _ = Describe("Sync Client test", func() {
 	Context("Functional", func() {
 		It("Rescan", func() {
 			// Client sends request with empty recent blocks
-			mockStream.On("Recv").Return(&proto.SyncRequest{}, nil).Once()
-			mockManager.On("GetLatestBlock").Return(modelBlocks[2], nil).Once()
+			mockStream.On("Recv").Return(&proto.SyncRequest{AllWatchLists: true}, nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[2], nil).Once()
 
 			// Client should rescan, start to stream safe blocks data to client
 			mockStream.On("Send", &proto.SyncResponse{
@@ -69,7 +80,7 @@ var _ = Describe("Sync Client test", func() {
 			blocks[1] = modelBlocks[1]
 			txIns[1] = modelTxIns[1]
 			txOuts[1] = modelTxOuts[1]
-			mockManager.On("GetBlocksData", int64(0), int64(1), watchedAddresses).Return(blocks, txIns, txOuts, nil).Once()
+			mockManager.On("GetBlocksData", mock.Anything, int64(0), int64(1), watchedAddresses).Return(blocks, txIns, txOuts, nil).Once()
 
 			// Send each block at a time
 			mockStream.On("Send", &proto.SyncResponse{
@@ -89,7 +100,7 @@ var _ = Describe("Sync Client test", func() {
 			}).Return(nil).Once()
 
 			// Client sends request with recent blocks as 0 & 1
-			req := &proto.SyncRequest{}
+			req := &proto.SyncRequest{AllWatchLists: true}
 			req.RecentBlocks = append(req.RecentBlocks, &proto.Block{
 				Height:       0,
 				Hash:         modelBlocks[0].Hash,
@@ -101,21 +112,21 @@ var _ = Describe("Sync Client test", func() {
 				PreviousHash: modelBlocks[1].PreviousHash,
 			})
 			mockStream.On("Recv").Return(req, nil).Once()
-			mockManager.On("GetLatestBlock").Return(modelBlocks[2], nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[2], nil).Once()
 
 			// Client expect to get data of block 2 that exceeds the safe distance
 			// Client may encounter New or Reorg block
 			// Client should sync sequentially each block at a request
 			// Firstly, have to check reorg
-			mockManager.On("GetBlocks", []int64{0, 1}).Return(blocks, nil).Once()
-			mockManager.On("GetBlock", int64(2)).Return(modelBlocks[2], nil).Once()
+			mockManager.On("GetBlocks", mock.Anything, []int64{0, 1}).Return(blocks, nil).Once()
+			mockManager.On("GetBlock", mock.Anything, int64(2)).Return(modelBlocks[2], nil).Once()
 
 			// Have no reorg, get data of block 2 from local
 			mockAddressWatcher.On("GetAddresses").Return(watchedAddresses).Once()
 			blocks[2] = modelBlocks[2]
 			txIns[2] = modelTxIns[2]
 			txOuts[2] = modelTxOuts[2]
-			mockManager.On("GetBlocksData", int64(2), int64(2), watchedAddresses).Return(blocks, txIns, txOuts, nil).Once()
+			mockManager.On("GetBlocksData", mock.Anything, int64(2), int64(2), watchedAddresses).Return(blocks, txIns, txOuts, nil).Once()
 
 			// Send block 2
 			mockStream.On("Send", &proto.SyncResponse{
@@ -139,13 +150,13 @@ var _ = Describe("Sync Client test", func() {
 			mockStream.On("Recv").Return(req, nil).Once()
 
 			// Check safe distance
-			mockManager.On("GetLatestBlock").Return(modelBlocks[2], nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[2], nil).Once()
 
 			// Check reorg
-			mockManager.On("GetBlocks", []int64{1, 2}).Return(blocks, nil).Once()
+			mockManager.On("GetBlocks", mock.Anything, []int64{1, 2}).Return(blocks, nil).Once()
 
 			// Have not indexed block 3 yet, retry to get data of block 3 from local at interval in seconds
-			mockManager.On("GetBlock", int64(3)).Return(nil, common.ErrNotFound)
+			mockManager.On("GetBlock", mock.Anything, int64(3)).Return(nil, common.ErrNotFound)
 
 			// Context is cancelled, streamer fails to receive msg
 			mockStream.On("Recv").Return(nil, context.Canceled).Once()
@@ -162,8 +173,18 @@ var _ = Describe("Sync Client test", func() {
 		})
 
 		It("Sequentially sync", func() {
+			// The blocks are read from the memory store, block 2 indexed a while after the request
+			manager := store.NewMemoryManager()
+			client.manager = manager
+			addBlock := func(height int64) {
+				err := manager.AddBlocksData(context.Background(), []*model.Block{modelBlocks[height]}, nil, modelTxIns[height], modelTxOuts[height])
+				Expect(err).Should(Succeed())
+			}
+			addBlock(0)
+			addBlock(1)
+
 			// Client sends request with recent blocks as 0 & 1
-			req := &proto.SyncRequest{}
+			req := &proto.SyncRequest{AllWatchLists: true}
 			req.RecentBlocks = append(req.RecentBlocks, &proto.Block{
 				Height:       0,
 				Hash:         modelBlocks[0].Hash,
@@ -176,38 +197,22 @@ var _ = Describe("Sync Client test", func() {
 			})
 			mockStream.On("Recv").Return(req, nil).Once()
 
-			// Check safe distance
-			mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
-
-			// Check reorg
-			blocks := make(map[int64]*model.Block)
-			blocks[0] = modelBlocks[0]
-			blocks[1] = modelBlocks[1]
-			mockManager.On("GetBlocks", []int64{0, 1}).Return(blocks, nil).Once()
-
-			// Try to get block 2
-			mockManager.On("GetBlock", int64(2)).Return(nil, common.ErrNotFound).Once()
-			mockManager.On("GetBlock", int64(2)).Return(nil, common.ErrNotFound).Once()
-
 			go func() {
 				time.Sleep(time.Second*time.Duration(client.config.SyncClientGetBlockIntervalInSec) - time.Microsecond*500)
-				mockManager.On("GetBlock", int64(2)).Return(modelBlocks[2], nil).Once()
 
-				// Get data of block 3 from local
+				// Get data of block 2 from local, its ins & outs in any order
 				mockAddressWatcher.On("GetAddresses").Return(watchedAddresses).Once()
-				txIns := make(map[int64][]*model.TxIn)
-				txOuts := make(map[int64][]*model.TxOut)
-				blocks[2] = modelBlocks[2]
-				txIns[2] = modelTxIns[2]
-				txOuts[2] = modelTxOuts[2]
-				mockManager.On("GetBlocksData", int64(2), int64(2), watchedAddresses).Return(blocks, txIns, txOuts, nil)
-
-				// Send block 2
-				mockStream.On("Send", &proto.SyncResponse{
-					Response: &proto.SyncResponse_SyncBlock_{
-						SyncBlock: common.BuildProtoMsg(2, blocks[2], txIns[2], txOuts[2]),
-					},
-				}).Return(nil).Once()
+				expected := common.BuildProtoMsg(2, modelBlocks[2], modelTxIns[2], modelTxOuts[2])
+				mockStream.On("Send", mock.MatchedBy(func(resp *proto.SyncResponse) bool {
+					syncBlock, ok := resp.Response.(*proto.SyncResponse_SyncBlock_)
+					if !ok {
+						return false
+					}
+					sameIns, _ := ConsistOf(expected.TxIns).Match(syncBlock.SyncBlock.TxIns)
+					sameOuts, _ := ConsistOf(expected.TxOuts).Match(syncBlock.SyncBlock.TxOuts)
+					sameBlock, _ := Equal(expected.Block).Match(syncBlock.SyncBlock.Block)
+					return sameBlock && sameIns && sameOuts
+				})).Return(nil).Once()
 
 				// Client sends request with recent blocks as 1 & 2
 				req = &proto.SyncRequest{}
@@ -223,17 +228,10 @@ var _ = Describe("Sync Client test", func() {
 				})
 				mockStream.On("Recv").Return(req, nil).Once()
 
-				// Check safe distance
-				mockManager.On("GetLatestBlock").Return(modelBlocks[2], nil).Once()
-
-				// Check reorg
-				mockManager.On("GetBlocks", []int64{1, 2}).Return(blocks, nil).Once()
-
 				// Have not indexed block 3 yet, retry to get data of block 3 from local at interval in seconds
-				mockManager.On("GetBlock", int64(3)).Return(nil, common.ErrNotFound)
-
 				// Context is cancelled, streamer fails to receive msg
 				mockStream.On("Recv").Return(nil, context.Canceled)
+				addBlock(2)
 
 				go func() {
 					time.Sleep(time.Second * time.Duration(client.config.SyncClientGetBlockIntervalInSec) * 2)
@@ -249,7 +247,7 @@ var _ = Describe("Sync Client test", func() {
 
 		It("Reorg", func() {
 			// Client sends request with recent blocks as 2 & 3
-			req := &proto.SyncRequest{}
+			req := &proto.SyncRequest{AllWatchLists: true}
 			req.RecentBlocks = append(req.RecentBlocks, &proto.Block{
 				Height:       2,
 				Hash:         modelBlocks[2].Hash,
@@ -263,27 +261,27 @@ var _ = Describe("Sync Client test", func() {
 			mockStream.On("Recv").Return(req, nil).Once()
 
 			// Check safe distance
-			mockManager.On("GetLatestBlock").Return(modelBlocks[3], nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[3], nil).Once()
 
 			// Check reorg
 			blocks := make(map[int64]*model.Block)
 			blocks[2] = modelBlocks[2]
 			blocks[3] = modelBlocks[3]
-			mockManager.On("GetBlocks", []int64{2, 3}).Return(blocks, nil).Once()
+			mockManager.On("GetBlocks", mock.Anything, []int64{2, 3}).Return(blocks, nil).Once()
 
 			// Try to get block 3
-			mockManager.On("GetBlock", int64(4)).Return(nil, common.ErrNotFound).Once()
-			mockManager.On("GetBlock", int64(4)).Return(nil, common.ErrNotFound).Once()
+			mockManager.On("GetBlock", mock.Anything, int64(4)).Return(nil, common.ErrNotFound).Once()
+			mockManager.On("GetBlock", mock.Anything, int64(4)).Return(nil, common.ErrNotFound).Once()
 
 			go func() {
 				time.Sleep(time.Second*time.Duration(client.config.SyncClientGetBlockIntervalInSec) - time.Microsecond*500)
-				mockManager.On("GetBlock", int64(4)).Return(reorgModelBlocks[4], nil).Once()
+				mockManager.On("GetBlock", mock.Anything, int64(4)).Return(reorgModelBlocks[4], nil).Once()
 
 				// Check reorg
 				blocks := make(map[int64]*model.Block)
 				blocks[2] = modelBlocks[2]
 				blocks[3] = reorgModelBlocks[3]
-				mockManager.On("GetBlocks", []int64{2, 3}).Return(blocks, nil).Once()
+				mockManager.On("GetBlocks", mock.Anything, []int64{2, 3}).Return(blocks, nil).Once()
 
 				// Send reorg msg
 				mockStream.On("Send", &proto.SyncResponse{
@@ -309,7 +307,7 @@ var _ = Describe("Sync Client test", func() {
 				mockStream.On("Recv").Return(req, nil).Once()
 
 				// Check safe distance
-				mockManager.On("GetLatestBlock").Return(modelBlocks[4], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[4], nil).Once()
 
 				// Client should rescan block 3
 				mockStream.On("Send", &proto.SyncResponse{
@@ -320,7 +318,7 @@ var _ = Describe("Sync Client test", func() {
 				txOuts := make(map[int64][]*model.TxOut)
 				txIns[3] = modelTxIns[3]
 				txOuts[3] = modelTxOuts[3]
-				mockManager.On("GetBlocksData", int64(3), int64(3), watchedAddresses).Return(blocks, txIns, txOuts, nil)
+				mockManager.On("GetBlocksData", mock.Anything, int64(3), int64(3), watchedAddresses).Return(blocks, txIns, txOuts, nil)
 
 				// Send block 3
 				mockStream.On("Send", &proto.SyncResponse{
@@ -347,18 +345,18 @@ var _ = Describe("Sync Client test", func() {
 					PreviousHash: reorgModelBlocks[3].PreviousHash,
 				})
 				mockStream.On("Recv").Return(req, nil).Once()
-				mockManager.On("GetLatestBlock").Return(reorgModelBlocks[3], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(reorgModelBlocks[3], nil).Once()
 
 				// Check reorg
-				mockManager.On("GetBlocks", []int64{2, 3}).Return(blocks, nil).Once()
-				mockManager.On("GetBlock", int64(4)).Return(reorgModelBlocks[4], nil).Once()
+				mockManager.On("GetBlocks", mock.Anything, []int64{2, 3}).Return(blocks, nil).Once()
+				mockManager.On("GetBlock", mock.Anything, int64(4)).Return(reorgModelBlocks[4], nil).Once()
 
 				// Have no reorg, get data of block 2 from local
 				mockAddressWatcher.On("GetAddresses").Return(watchedAddresses).Once()
 				blocks[4] = reorgModelBlocks[4]
 				txIns[4] = reorgModelTxIns[4]
 				txOuts[4] = reorgModelTxOuts[4]
-				mockManager.On("GetBlocksData", int64(4), int64(4), watchedAddresses).Return(blocks, txIns, txOuts, nil).Once()
+				mockManager.On("GetBlocksData", mock.Anything, int64(4), int64(4), watchedAddresses).Return(blocks, txIns, txOuts, nil).Once()
 
 				// Send block 4
 				mockStream.On("Send", &proto.SyncResponse{
@@ -382,13 +380,13 @@ var _ = Describe("Sync Client test", func() {
 				mockStream.On("Recv").Return(req, nil).Once()
 
 				// Check safe distance
-				mockManager.On("GetLatestBlock").Return(reorgModelBlocks[4], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(reorgModelBlocks[4], nil).Once()
 
 				// Check reorg
-				mockManager.On("GetBlocks", []int64{3, 4}).Return(blocks, nil).Once()
+				mockManager.On("GetBlocks", mock.Anything, []int64{3, 4}).Return(blocks, nil).Once()
 
 				// Try to get block 5 from local
-				mockManager.On("GetBlock", int64(5)).Return(nil, common.ErrNotFound).Once()
+				mockManager.On("GetBlock", mock.Anything, int64(5)).Return(nil, common.ErrNotFound).Once()
 
 				// Context is cancelled, streamer fails to receive msg
 				mockStream.On("Recv").Return(nil, context.Canceled)
@@ -406,7 +404,119 @@ var _ = Describe("Sync Client test", func() {
 		})
 	})
 
+	Context("Stream addresses", func() {
+		streamAddresses := []string{"mrCgfoh4ZbGWLg1Lm7pcnFzWGq1fpxGURp", "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"}
+
+		It("Addresses", func() {
+			// The addresses of the stream are synced instead of the watched ones, in the encoding of the network
+			req := &proto.SyncRequest{Addresses: []string{streamAddresses[0], strings.ToUpper(streamAddresses[1])}}
+			mockStream.On("Recv").Return(req, nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[2], nil).Once()
+			mockStream.On("Send", &proto.SyncResponse{
+				Response: &proto.SyncResponse_BeginStream_{},
+			}).Return(nil).Once()
+			mockManager.On("GetBlocksData", mock.Anything, int64(0), int64(1), streamAddresses).Return(nil, nil, nil, common.ErrNotFound).Once()
+
+			err := client.Sync()
+			Expect(err).ShouldNot(BeNil())
+			errContent := fmt.Sprintf("failed to Get Blocks Data, fromHeight '%d', toHeight '%d', No Of WatchingAddresses '%d': %v", 0, 1, len(streamAddresses), common.ErrNotFound)
+			errContent = fmt.Sprintf("failed to Handle req '%v': %s", req, errContent)
+			Expect(err.Error()).Should(Equal(errContent))
+		})
+
+		It("Kept by the next requests", func() {
+			mockStream.On("Recv").Return(&proto.SyncRequest{Addresses: streamAddresses}, nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[0], nil).Once()
+			mockStream.On("Send", &proto.SyncResponse{
+				Response: &proto.SyncResponse_BeginStream_{},
+			}).Return(nil).Once()
+			mockStream.On("Send", &proto.SyncResponse{
+				Response: &proto.SyncResponse_EndStream_{},
+			}).Return(nil).Once()
+
+			req := &proto.SyncRequest{}
+			mockStream.On("Recv").Return(req, nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[2], nil).Once()
+			mockStream.On("Send", &proto.SyncResponse{
+				Response: &proto.SyncResponse_BeginStream_{},
+			}).Return(nil).Once()
+			mockManager.On("GetBlocksData", mock.Anything, int64(0), int64(1), streamAddresses).Return(nil, nil, nil, common.ErrNotFound).Once()
+
+			err := client.Sync()
+			Expect(err).ShouldNot(BeNil())
+			errContent := fmt.Sprintf("failed to Get Blocks Data, fromHeight '%d', toHeight '%d', No Of WatchingAddresses '%d': %v", 0, 1, len(streamAddresses), common.ErrNotFound)
+			errContent = fmt.Sprintf("failed to Handle req '%v': %s", req, errContent)
+			Expect(err.Error()).Should(Equal(errContent))
+		})
+
+		It("Watch list", func() {
+			// The watch list is read on every call, so its edits are picked up
+			req := &proto.SyncRequest{WatchList: "hot"}
+			mockStream.On("Recv").Return(req, nil).Once()
+			mockWatchLists.On("GetList", "hot").Return(streamAddresses).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[2], nil).Once()
+			mockStream.On("Send", &proto.SyncResponse{
+				Response: &proto.SyncResponse_BeginStream_{},
+			}).Return(nil).Once()
+			mockWatchLists.On("GetList", "hot").Return(streamAddresses[:1]).Twice()
+			mockManager.On("GetBlocksData", mock.Anything, int64(0), int64(1), streamAddresses[:1]).Return(nil, nil, nil, common.ErrNotFound).Once()
+
+			err := client.Sync()
+			Expect(err).ShouldNot(BeNil())
+			errContent := fmt.Sprintf("failed to Get Blocks Data, fromHeight '%d', toHeight '%d', No Of WatchingAddresses '%d': %v", 0, 1, 1, common.ErrNotFound)
+			errContent = fmt.Sprintf("failed to Handle req '%v': %s", req, errContent)
+			Expect(err.Error()).Should(Equal(errContent))
+		})
+
+		It("Unknown watch list", func() {
+			req := &proto.SyncRequest{WatchList: "unknown"}
+			mockStream.On("Recv").Return(req, nil).Once()
+			mockWatchLists.On("GetList", "unknown").Return(nil).Once()
+
+			err := client.Sync()
+			Expect(err).Should(Equal(common.ErrNotFound))
+		})
+
+		It("Both addresses and watch list", func() {
+			req := &proto.SyncRequest{Addresses: streamAddresses, WatchList: "hot"}
+			mockStream.On("Recv").Return(req, nil).Once()
+
+			err := client.Sync()
+			Expect(err).Should(Equal(common.ErrInvalidWatchList))
+		})
+
+		It("Both watch list and all watch lists", func() {
+			req := &proto.SyncRequest{WatchList: "hot", AllWatchLists: true}
+			mockStream.On("Recv").Return(req, nil).Once()
+
+			err := client.Sync()
+			Expect(err).Should(Equal(common.ErrInvalidWatchList))
+		})
+
+		It("Not declared by the first request", func() {
+			mockStream.On("Recv").Return(&proto.SyncRequest{}, nil).Once()
+
+			err := client.Sync()
+			Expect(err).Should(Equal(common.ErrNoAddresses))
+		})
+
+		It("Address of another network", func() {
+			req := &proto.SyncRequest{Addresses: []string{"1BoatSLRHtKNngkdXEeobR76b53LETtpyT"}}
+			mockStream.On("Recv").Return(req, nil).Once()
+
+			err := client.Sync()
+			Expect(err).Should(Equal(common.ErrInvalidAddress))
+		})
+	})
+
 	Context("Errors happen", func() {
+		It("Closed by client", func() {
+			mockStream.On("Recv").Return(nil, io.EOF).Once()
+
+			err := client.Sync()
+			Expect(err).Should(BeNil())
+		})
+
 		It("Recv", func() {
 			mockStream.On("Recv").Return(nil, context.Canceled).Once()
 
@@ -418,11 +528,11 @@ var _ = Describe("Sync Client test", func() {
 
 		It("GetLatestBlock", func() {
 			// Client sends request with empty recent blocks
-			req := &proto.SyncRequest{}
+			req := &proto.SyncRequest{AllWatchLists: true}
 			mockStream.On("Recv").Return(req, nil).Once()
 
 			// Check safe distance
-			mockManager.On("GetLatestBlock").Return(nil, common.ErrNotFound).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(nil, common.ErrNotFound).Once()
 
 			err := client.Sync()
 			Expect(err).ShouldNot(BeNil())
@@ -433,16 +543,16 @@ var _ = Describe("Sync Client test", func() {
 
 		It("GetBlocksData", func() {
 			// Client sends request with empty recent blocks
-			req := &proto.SyncRequest{}
+			req := &proto.SyncRequest{AllWatchLists: true}
 			mockStream.On("Recv").Return(req, nil).Once()
-			mockManager.On("GetLatestBlock").Return(modelBlocks[2], nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[2], nil).Once()
 
 			// Client should rescan, start to stream safe blocks data to client
 			mockStream.On("Send", &proto.SyncResponse{
 				Response: &proto.SyncResponse_BeginStream_{},
 			}).Return(nil).Once()
 			mockAddressWatcher.On("GetAddresses").Return([]string{}).Once()
-			mockManager.On("GetBlocksData", int64(0), int64(1), []string{}).Return(nil, nil, nil, common.ErrNotFound).Once()
+			mockManager.On("GetBlocksData", mock.Anything, int64(0), int64(1), []string{}).Return(nil, nil, nil, common.ErrNotFound).Once()
 			mockAddressWatcher.On("GetAddresses").Return([]string{}).Once()
 
 			err := client.Sync()
@@ -454,9 +564,9 @@ var _ = Describe("Sync Client test", func() {
 
 		It("Send", func() {
 			// Client sends request with empty recent blocks
-			req := &proto.SyncRequest{}
+			req := &proto.SyncRequest{AllWatchLists: true}
 			mockStream.On("Recv").Return(req, nil).Once()
-			mockManager.On("GetLatestBlock").Return(modelBlocks[2], nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[2], nil).Once()
 
 			// Client should rescan, start to stream safe blocks data to client
 			mockStream.On("Send", &proto.SyncResponse{
@@ -472,7 +582,7 @@ var _ = Describe("Sync Client test", func() {
 			blocks[1] = modelBlocks[1]
 			txIns[1] = modelTxIns[1]
 			txOuts[1] = modelTxOuts[1]
-			mockManager.On("GetBlocksData", int64(0), int64(1), watchedAddresses).Return(blocks, txIns, txOuts, nil).Once()
+			mockManager.On("GetBlocksData", mock.Anything, int64(0), int64(1), watchedAddresses).Return(blocks, txIns, txOuts, nil).Once()
 
 			// Send each block at a time
 			mockStream.On("Send", &proto.SyncResponse{
@@ -491,7 +601,7 @@ var _ = Describe("Sync Client test", func() {
 
 		It("GetBlocks", func() {
 			// Client sends request with recent blocks as 0 & 1
-			req := &proto.SyncRequest{}
+			req := &proto.SyncRequest{AllWatchLists: true}
 			req.RecentBlocks = append(req.RecentBlocks, &proto.Block{
 				Height:       0,
 				Hash:         modelBlocks[0].Hash,
@@ -505,11 +615,11 @@ var _ = Describe("Sync Client test", func() {
 			mockStream.On("Recv").Return(req, nil).Once()
 
 			// Check safe distance
-			mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[1], nil).Once()
 
 			// Check reorg
 			heights := []int64{0, 1}
-			mockManager.On("GetBlocks", heights).Return(nil, common.ErrNotFound).Once()
+			mockManager.On("GetBlocks", mock.Anything, heights).Return(nil, common.ErrNotFound).Once()
 			err := client.Sync()
 			Expect(err).ShouldNot(BeNil())
 			errContent := fmt.Sprintf("failed to Get Blocks, block heights '%v': %v", heights, common.ErrNotFound)
@@ -520,7 +630,7 @@ var _ = Describe("Sync Client test", func() {
 
 		It("GetBlock", func() {
 			// Client sends request with recent blocks as 0 & 1
-			req := &proto.SyncRequest{}
+			req := &proto.SyncRequest{AllWatchLists: true}
 			req.RecentBlocks = append(req.RecentBlocks, &proto.Block{
 				Height:       0,
 				Hash:         modelBlocks[0].Hash,
@@ -534,16 +644,16 @@ var _ = Describe("Sync Client test", func() {
 			mockStream.On("Recv").Return(req, nil).Once()
 
 			// Check safe distance
-			mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[1], nil).Once()
 
 			// Check reorg
 			blocks := make(map[int64]*model.Block)
 			blocks[0] = modelBlocks[0]
 			blocks[1] = modelBlocks[1]
-			mockManager.On("GetBlocks", []int64{0, 1}).Return(blocks, nil).Once()
+			mockManager.On("GetBlocks", mock.Anything, []int64{0, 1}).Return(blocks, nil).Once()
 
 			// Try to get block 2
-			mockManager.On("GetBlock", int64(2)).Return(nil, gorm.ErrInvalidSQL).Once()
+			mockManager.On("GetBlock", mock.Anything, int64(2)).Return(nil, gorm.ErrInvalidSQL).Once()
 
 			err := client.Sync()
 			Expect(err).ShouldNot(BeNil())
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	btc_indexer "github.com/darkknightbk52/btc-indexer"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/service/export"
+	"github.com/darkknightbk52/btc-indexer/store"
+	"go.uber.org/zap"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// export writes the indexed data of a height range into partitioned Parquet or CSV files.
+// Run it again with the same flags to resume an interrupted export from its checkpoint file.
+func main() {
+	prod := flag.Bool("prod", false, "Enable production mode")
+	from := flag.Int64("from", 0, "Height to export from")
+	to := flag.Int64("to", -1, "Height to export to, the latest indexed block having the confirmations if negative")
+	confirmations := flag.Int64("confirmations", 0, "Confirmations of the block to export to if not given, 6 if zero")
+	format := flag.String("format", export.FormatParquet, "Format of the files, 'parquet' or 'csv'")
+	dir := flag.String("dir", "export", "Dir to write the files in")
+	addresses := flag.String("addresses", "", "Comma separated addresses to export the data of only")
+	addressesFile := flag.String("addresses-file", "", "File of the addresses to export the data of only, one per line")
+	heightsPerFile := flag.Int64("heights-per-file", 0, "Heights per file, 10000 if zero")
+	heightsPerBatch := flag.Int64("heights-per-batch", 0, "Heights read from DB at once, 100 if zero")
+	checkpoint := flag.String("checkpoint", "", "Checkpoint file, checkpoint.json in the dir if empty")
+	flag.Parse()
+	log.Init(*prod)
+
+	cfg, err := btc_indexer.LoadConfig()
+	if err != nil {
+		log.L().Fatal("Failed to Load Configs", zap.Error(err))
+	}
+
+	addressList := splitAddresses(*addresses, ",")
+	if len(*addressesFile) > 0 {
+		data, err := ioutil.ReadFile(*addressesFile)
+		if err != nil {
+			log.L().Fatal("Failed to Read Addresses File", zap.Error(err))
+		}
+		addressList = append(addressList, splitAddresses(string(data), "\n")...)
+	}
+
+	exportCfg := export.Config{
+		Dir:             *dir,
+		Format:          *format,
+		FromHeight:      *from,
+		ToHeight:        *to,
+		Confirmations:   *confirmations,
+		Addresses:       addressList,
+		HeightsPerFile:  *heightsPerFile,
+		HeightsPerBatch: *heightsPerBatch,
+		CheckpointFile:  *checkpoint,
+	}
+	err = exportCfg.Validate()
+	if err != nil {
+		log.L().Fatal("Invalid Export Configs", zap.Error(err))
+	}
+
+	manager, err := store.NewManager(cfg.DB)
+	if err != nil {
+		log.L().Fatal("Failed to Create Store Manager", zap.Error(err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+		log.L().Info("Stop Export, it resumes from the checkpoint on the next run")
+		cancel()
+	}()
+
+	err = export.NewExporter(exportCfg, manager).Export(ctx)
+	if err != nil {
+		log.L().Fatal("Failed to Export", zap.Error(err))
+	}
+}
+
+func splitAddresses(s, sep string) []string {
+	var addresses []string
+	for _, address := range strings.Split(s, sep) {
+		address = strings.TrimSpace(address)
+		if len(address) > 0 {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	btc_indexer "github.com/darkknightbk52/btc-indexer"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/store"
+	"go.uber.org/zap"
+	"os"
+)
+
+const usage = `Usage: migrate [OPTIONS] up|down|status
+
+Migrate the DB schema of BTC Indexer.
+
+Commands:
+  up      Apply the migrations up to version '-to', the latest one by default
+  down    Revert the migrations down to version '-to'
+  status  Print the versions of DB schema & binary
+
+Migration 8 rewrites all the rows of the indexed tables in one transaction, locking them
+for hours on a fully indexed chain: stop the services, then allow it with '-allow-rewrite'.
+
+Options:
+`
+
+func main() {
+	prod := flag.Bool("prod", false, "Enable production mode")
+	to := flag.Int64("to", -1, "Version to migrate to")
+	allowRewrite := flag.Bool("allow-rewrite", false, "Allow the migrations rewriting the indexed tables, the services stopped")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	log.Init(*prod)
+
+	cfg, err := btc_indexer.LoadDBConfig()
+	if err != nil {
+		log.L().Fatal("Failed to Load Configs", zap.Error(err))
+	}
+
+	migrator, err := store.NewPostgresMigrator(cfg.DSN())
+	if err != nil {
+		log.L().Fatal("Failed to Create Migrator", zap.Error(err))
+	}
+	defer migrator.Close()
+	if *allowRewrite {
+		migrator.AllowRewrites()
+	}
+
+	switch flag.Arg(0) {
+	case "up":
+		err = migrator.Up(*to)
+	case "down":
+		if *to < 0 {
+			log.L().Fatal("Version to migrate down to required")
+		}
+		err = migrator.Down(*to)
+	case "status":
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.L().Fatal("Failed to Migrate", zap.String("Command", flag.Arg(0)), zap.Error(err))
+	}
+
+	version, err := migrator.Version()
+	if err != nil {
+		log.L().Fatal("Failed to Get Schema Version", zap.Error(err))
+	}
+	fmt.Printf("DB schema version: %d\nBinary schema version: %d\n", version, store.LatestSchemaVersion())
+}
@@ -2,26 +2,34 @@ package main
 
 import (
 	"context"
+	_ "expvar"
 	btc_indexer "github.com/darkknightbk52/btc-indexer"
 	"github.com/darkknightbk52/btc-indexer/client/blockchain"
 	"github.com/darkknightbk52/btc-indexer/common/log"
+	proto "github.com/darkknightbk52/btc-indexer/proto"
 	"github.com/darkknightbk52/btc-indexer/service/indexer"
+	"github.com/darkknightbk52/btc-indexer/service/leader"
 	"github.com/darkknightbk52/btc-indexer/store"
 	"github.com/darkknightbk52/btc-indexer/subscriber"
 	"github.com/micro/cli"
 	"github.com/micro/go-micro"
-	"github.com/micro/go-micro/config"
-	"github.com/micro/go-micro/config/source/env"
 	"github.com/micro/go-micro/registry/consul"
 	"go.uber.org/zap"
+	"net/http"
 	"sync"
 	"time"
 )
 
-const prodFlag = "prod"
+const (
+	prodFlag           = "prod"
+	devFlag            = "dev"
+	metricsAddressFlag = "metrics_address"
+)
 
 func main() {
 	prod := false
+	dev := false
+	metricsAddress := ""
 	opts := []micro.Option{
 		micro.RegisterTTL(time.Second * 30),
 		micro.RegisterInterval(time.Second * 15),
@@ -31,33 +39,33 @@ func main() {
 				Name:  prodFlag,
 				Usage: "Enable production mode",
 			},
+			cli.BoolFlag{
+				Name:  devFlag,
+				Usage: "Enable development mode, keeping the data in memory without DB",
+			},
+			cli.StringFlag{
+				Name:  metricsAddressFlag,
+				Usage: "Address to serve metrics at /debug/vars, disabled if empty",
+			},
 		),
 		micro.Name("go.micro.srv.btc.indexer"),
 		micro.Action(func(ctx *cli.Context) {
 			prod = ctx.Bool(prodFlag)
+			dev = ctx.Bool(devFlag)
+			metricsAddress = ctx.String(metricsAddressFlag)
 		}),
 	}
 	microSrv := micro.NewService(opts...)
 	microSrv.Init()
 	log.Init(prod)
 
-	cfgScanner := config.NewConfig()
-	err := cfgScanner.Load(
-		//consul.NewSource(),
-		env.NewSource(env.WithStrippedPrefix("IDX")),
-	)
-	if err != nil {
-		log.L().Fatal("Failed to load configs:", zap.Error(err))
+	loadConfig := btc_indexer.LoadConfig
+	if dev {
+		loadConfig = btc_indexer.LoadDevConfig
 	}
-	cfg := btc_indexer.Config{}
-	err = cfgScanner.Scan(&cfg)
+	cfg, err := loadConfig()
 	if err != nil {
-		log.L().Fatal("Failed to load configs", zap.Error(err))
-	}
-
-	err = cfg.Validate()
-	if err != nil {
-		log.L().Fatal("Invalid config values", zap.Error(err))
+		log.L().Fatal("Failed to Load Configs", zap.Error(err))
 	}
 
 	subOpts := []subscriber.Option{
@@ -71,24 +79,87 @@ func main() {
 	}
 	sub := subscriber.NewSubscriber(subOpts...)
 
-	manager, err := store.NewPostgresManager(cfg.DB.DSN())
-	if err != nil {
-		log.L().Fatal("Failed to Create Store Manager", zap.Error(err))
+	// The indexer writes & reads through the primary, the queries & sync streams read from the replicas if any
+	var manager, readManager store.Manager
+	if dev {
+		log.L().Warn("Development mode, the indexed data are lost on exit")
+		manager = store.NewMemoryManager()
+		readManager = manager
+	} else {
+		manager, err = store.NewManager(cfg.DB)
+		if err != nil {
+			log.L().Fatal("Failed to Create Store Manager", zap.Error(err))
+		}
+		readManager, err = store.NewReadManager(cfg.DB, manager)
+		if err != nil {
+			log.L().Fatal("Failed to Create Read Store Manager", zap.Error(err))
+		}
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
 
-	client, err := blockchain.NewBlockchainClient(ctx, &wg, cfg.BlockchainClient, cfg.Indexer.ChainParams())
+	client, err := blockchain.NewBlockchainClient(ctx, cfg.BlockchainClient, cfg.Indexer.ChainParams())
 	if err != nil {
 		log.L().Fatal("Failed to Create Blockchain Client", zap.Error(err))
 	}
+	client = blockchain.NewRetryClient(client, cfg.BlockchainClient.RetryOptions()...)
+
+	if len(metricsAddress) > 0 {
+		go func() {
+			// expvar registers its handler at /debug/vars on the default mux
+			err := http.ListenAndServe(metricsAddress, nil)
+			if err != nil {
+				log.L().Error("Failed to Serve Metrics", zap.Error(err))
+			}
+		}()
+	}
 
+	chainParams := cfg.Indexer.ChainParams()
 	indexerSrv := indexer.NewIndexer(cfg.Indexer, sub, manager, client)
 
+	if dev || len(cfg.DB.BoltFile) > 0 {
+		// The data are owned by a single process, there is no other instance to elect from
+		cfg.Leader.Disabled = true
+	}
+	var elector *leader.Elector
+	isLeader := func() bool { return true }
+	if !cfg.Leader.Disabled {
+		lock, err := store.NewPostgresLeaderLock(cfg.DB.DSN(), cfg.Leader.Key())
+		if err != nil {
+			log.L().Fatal("Failed to Create Leader Lock", zap.Error(err))
+		}
+		elector = leader.NewElector(lock, cfg.Leader)
+		isLeader = elector.IsLeader
+	}
+
+	// The watch lists are edited through the primary, then read from the cache, reloaded for the edits of the other instances
+	addressBook, err := btc_indexer.NewAddressBook(ctx, manager, &chainParams)
+	if err != nil {
+		log.L().Fatal("Failed to Load Address Book", zap.Error(err))
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		addressBook.Run(ctx, btc_indexer.WatchListReloadInterval)
+	}()
+
+	err = proto.RegisterBtcIndexerHandler(microSrv.Server(), btc_indexer.NewHandler(indexerSrv, readManager, addressBook, &chainParams, cfg.Sync, cfg.Admin, isLeader))
+	if err != nil {
+		log.L().Fatal("Failed to Register Handler", zap.Error(err))
+	}
+
 	microSrv.Init(
 		micro.AfterStart(func() error {
-			err = indexerSrv.Listen(ctx, cfg.Indexer.FromBlockHeight)
+			listen := func(ctx context.Context) error {
+				return indexerSrv.Listen(ctx, cfg.Indexer.FromBlockHeight)
+			}
+			if cfg.Leader.Disabled {
+				err = listen(ctx)
+			} else {
+				// Only the leader indexes, the others keep serving
+				err = elector.Run(ctx, listen)
+			}
 			if err != context.Canceled {
 				return err
 			}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	btc_indexer "github.com/darkknightbk52/btc-indexer"
+	"github.com/darkknightbk52/btc-indexer/client/blockchain"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/service/indexer"
+	"github.com/darkknightbk52/btc-indexer/store"
+	"go.uber.org/zap"
+	"os"
+)
+
+// verify audits the indexed data in DB against Full Node, then prints the report in JSON.
+// It exits with code 1 if any issue is left unrepaired.
+// The repair takes the leader lock, for no instance of the service to index meanwhile, so it fails while one is the leader.
+// Without leader election, the instances of the service must be stopped before repairing.
+func main() {
+	prod := flag.Bool("prod", false, "Enable production mode")
+	from := flag.Int64("from", 0, "Height to verify from")
+	to := flag.Int64("to", -1, "Height to verify to, the latest indexed block if negative")
+	repair := flag.Bool("repair", false, "Index again the blocks having issues, holding the leader lock")
+	flag.Parse()
+	log.Init(*prod)
+
+	cfg, err := btc_indexer.LoadConfig()
+	if err != nil {
+		log.L().Fatal("Failed to Load Configs", zap.Error(err))
+	}
+
+	manager, err := store.NewManager(cfg.DB)
+	if err != nil {
+		log.L().Fatal("Failed to Create Store Manager", zap.Error(err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A Bolt file is already locked by the service using it
+	if *repair && len(cfg.DB.BoltFile) == 0 {
+		lock, err := store.NewPostgresLeaderLock(cfg.DB.DSN(), cfg.Leader.Key())
+		if err != nil {
+			log.L().Fatal("Failed to Create Leader Lock", zap.Error(err))
+		}
+		locked, err := lock.TryLock(ctx)
+		if err != nil {
+			log.L().Fatal("Failed to Try Leader Lock", zap.Error(err))
+		}
+		if !locked {
+			log.L().Fatal("Leader Lock held by another instance, the repair would race with its indexing", zap.Int64("Key", cfg.Leader.Key()))
+		}
+		defer func() {
+			err := lock.Unlock()
+			if err != nil {
+				log.L().Warn("Failed to Unlock Leader Lock", zap.Error(err))
+			}
+		}()
+	}
+
+	client, err := blockchain.NewBlockchainClient(ctx, cfg.BlockchainClient, cfg.Indexer.ChainParams())
+	if err != nil {
+		log.L().Fatal("Failed to Create Blockchain Client", zap.Error(err))
+	}
+	client = blockchain.NewRetryClient(client, cfg.BlockchainClient.RetryOptions()...)
+
+	toHeight := *to
+	if toHeight < 0 {
+		block, err := manager.GetLatestBlock(ctx)
+		if err != nil {
+			log.L().Fatal("Failed to Get Latest Block", zap.Error(err))
+		}
+		toHeight = block.Height
+	}
+
+	idx := indexer.NewIndexer(cfg.Indexer, nil, manager, client)
+	report, err := idx.Verify(ctx, *from, toHeight, *repair)
+	if err != nil {
+		log.L().Fatal("Failed to Verify", zap.Error(err))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		log.L().Fatal("Failed to Encode Report", zap.Error(err))
+	}
+
+	if len(report.Issues) > 0 && !*repair {
+		cancel()
+		os.Exit(1)
+	}
+}
@@ -3,5 +3,18 @@ package common
 import "errors"
 
 var (
-	ErrNotFound = errors.New("record not found")
+	ErrNotFound         = errors.New("record not found")
+	ErrInvalidRange     = errors.New("invalid height range")
+	ErrInvalidHash      = errors.New("invalid hash")
+	ErrNoAddresses      = errors.New("no addresses")
+	ErrTooManyAddresses = errors.New("too many addresses")
+	// ErrInvalidCursor is returned for a cursor of pagination not given by a previous page
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidAddress is returned for an address not valid for the configured network
+	ErrInvalidAddress   = errors.New("invalid address")
+	ErrInvalidWatchList = errors.New("invalid watch list")
+	// ErrRepairDisabled is returned for a repair of the indexed data while the admin operations are not enabled
+	ErrRepairDisabled = errors.New("repair disabled")
+	// ErrNotLeader is returned for a write of the indexed data to an instance other than the leader
+	ErrNotLeader = errors.New("not the leader")
 )
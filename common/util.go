@@ -7,6 +7,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
 	"github.com/darkknightbk52/btc-indexer/model"
 	proto "github.com/darkknightbk52/btc-indexer/proto"
 	"strings"
@@ -68,38 +69,75 @@ func GetAddrFromTxOut(out *wire.TxOut, chainParams *chaincfg.Params) (string, er
 	return addrs[0].String(), nil
 }
 
+// NormalizeAddresses returns the addresses in the encoding of the network, ErrInvalidAddress if one is not of it.
+func NormalizeAddresses(addresses []string, chainParams *chaincfg.Params) ([]string, error) {
+	normalized := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		address, err := btcutil.DecodeAddress(a, chainParams)
+		if err != nil || !address.IsForNet(chainParams) {
+			return nil, ErrInvalidAddress
+		}
+		normalized = append(normalized, address.EncodeAddress())
+	}
+	return normalized, nil
+}
+
 func BuildProtoMsg(height int64, block *model.Block, txIns []*model.TxIn, txOuts []*model.TxOut) *proto.SyncResponse_SyncBlock {
 	msg := new(proto.SyncResponse_SyncBlock)
-	msg.Block = &proto.Block{
+	msg.Block = ToProtoBlock(block)
+	for _, txIn := range txIns {
+		msg.TxIns = append(msg.TxIns, ToProtoTxIn(txIn))
+	}
+	for _, txOut := range txOuts {
+		msg.TxOuts = append(msg.TxOuts, ToProtoTxOut(txOut))
+	}
+	return msg
+}
+
+func ToProtoBlock(block *model.Block) *proto.Block {
+	return &proto.Block{
 		Height:       block.Height,
 		Hash:         block.Hash,
 		PreviousHash: block.PreviousHash,
 	}
+}
 
-	for _, txIn := range txIns {
-		msg.TxIns = append(msg.TxIns, &proto.TxIn{
-			TxHash:          txIn.TxHash,
-			TxIndex:         txIn.TxIndex,
-			Height:          txIn.Height,
-			Address:         txIn.Address,
-			PreviousTxHash:  txIn.PreviousTxHash,
-			PreviousTxIndex: txIn.PreviousTxIndex,
-		})
+func ToProtoTxIn(txIn *model.TxIn) *proto.TxIn {
+	return &proto.TxIn{
+		TxHash:          txIn.TxHash,
+		TxIndex:         txIn.TxIndex,
+		Height:          txIn.Height,
+		Address:         txIn.Address,
+		PreviousTxHash:  txIn.PreviousTxHash,
+		PreviousTxIndex: txIn.PreviousTxIndex,
 	}
+}
 
-	for _, txOut := range txOuts {
-		msg.TxOuts = append(msg.TxOuts, &proto.TxOut{
-			TxHash:       txOut.TxHash,
-			TxIndex:      txOut.TxIndex,
-			Height:       txOut.Height,
-			Value:        txOut.Value,
-			Address:      txOut.Address,
-			ScriptPubKey: hex.EncodeToString(txOut.ScriptPubKey),
-			CoinBase:     *txOut.CoinBase,
-		})
+func ToProtoTxOut(txOut *model.TxOut) *proto.TxOut {
+	return &proto.TxOut{
+		TxHash:       txOut.TxHash,
+		TxIndex:      txOut.TxIndex,
+		Height:       txOut.Height,
+		Value:        txOut.Value,
+		Address:      txOut.Address,
+		ScriptPubKey: hex.EncodeToString(txOut.ScriptPubKey),
+		CoinBase:     *txOut.CoinBase,
 	}
+}
 
-	return msg
+func ToProtoReorg(reorg *model.Reorg) *proto.Reorg {
+	return &proto.Reorg{
+		Id:           reorg.Id,
+		FromHeight:   reorg.FromHeight,
+		FromHash:     reorg.FromHash,
+		Depth:        reorg.Depth(),
+		OldTipHeight: reorg.ToHeight,
+		OldTipHash:   reorg.ToHash,
+		NewTipHeight: reorg.NewHeight,
+		NewTipHash:   reorg.NewHash,
+		TxNo:         reorg.TxNo,
+		Time:         reorg.CreatedAt.Unix(),
+	}
 }
 
 func GenerateSqlValuesPart(columnNo, rowNo int) string {
@@ -2,10 +2,15 @@ package btc_indexer
 
 import (
 	"errors"
+	"fmt"
 	"github.com/darkknightbk52/btc-indexer/client/blockchain"
+	"github.com/darkknightbk52/btc-indexer/client/sync"
 	"github.com/darkknightbk52/btc-indexer/service/indexer"
+	"github.com/darkknightbk52/btc-indexer/service/leader"
 	"github.com/darkknightbk52/btc-indexer/store"
 	"github.com/darkknightbk52/btc-indexer/subscriber"
+	"github.com/micro/go-micro/config"
+	"github.com/micro/go-micro/config/source/env"
 	"strings"
 )
 
@@ -14,9 +19,23 @@ type Config struct {
 	BlockchainClient     blockchain.Config
 	BlockchainSubscriber subscriber.Config
 	DB                   store.Config
+	Leader               leader.Config
+	Sync                 sync.Config
+	Admin                AdminConfig
+}
+
+// AdminConfig enables the admin operations of the RPC API, all disabled by default.
+type AdminConfig struct {
+	// EnableRepair allows the Verify requests to repair the indexed data, on the leader only
+	EnableRepair bool
 }
 
 func (c Config) Validate() error {
+	return c.validate(true)
+}
+
+// validate checks the configs, the ones of DB only if withDB is set.
+func (c Config) validate(withDB bool) error {
 	var errContents []string
 	err := c.Indexer.Validate()
 	if err != nil {
@@ -33,7 +52,19 @@ func (c Config) Validate() error {
 		errContents = append(errContents, err.Error())
 	}
 
-	err = c.DB.Validate()
+	if withDB {
+		err = c.DB.Validate()
+		if err != nil {
+			errContents = append(errContents, err.Error())
+		}
+	}
+
+	err = c.Leader.Validate()
+	if err != nil {
+		errContents = append(errContents, err.Error())
+	}
+
+	err = c.Sync.Validate()
 	if err != nil {
 		errContents = append(errContents, err.Error())
 	}
@@ -43,3 +74,66 @@ func (c Config) Validate() error {
 	}
 	return nil
 }
+
+// LoadConfig reads the configs from environment variables prefixed by 'IDX', then validates them.
+func LoadConfig() (Config, error) {
+	cfg, err := scanConfig()
+	if err != nil {
+		return cfg, err
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return cfg, fmt.Errorf("invalid Config values: %v", err)
+	}
+	return cfg, nil
+}
+
+// LoadDevConfig is like LoadConfig, for development mode having the data in memory, so without DB configs.
+func LoadDevConfig() (Config, error) {
+	cfg, err := scanConfig()
+	if err != nil {
+		return cfg, err
+	}
+
+	err = cfg.validate(false)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid Config values: %v", err)
+	}
+	return cfg, nil
+}
+
+// LoadDBConfig is like LoadConfig, for tools only working with DB.
+func LoadDBConfig() (store.Config, error) {
+	cfg, err := scanConfig()
+	if err != nil {
+		return cfg.DB, err
+	}
+
+	err = cfg.DB.Validate()
+	if err != nil {
+		return cfg.DB, fmt.Errorf("invalid DB Config values: %v", err)
+	}
+	return cfg.DB, nil
+}
+
+func scanConfig() (Config, error) {
+	cfg := Config{}
+	cfgScanner := config.NewConfig()
+	err := cfgScanner.Load(
+		//consul.NewSource(),
+		env.NewSource(env.WithStrippedPrefix("IDX")),
+	)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to Load Configs: %v", err)
+	}
+	err = cfgScanner.Scan(&cfg)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to Scan Configs: %v", err)
+	}
+	err = cfg.DB.LoadPasswordFile()
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
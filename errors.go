@@ -1,6 +1,7 @@
 package btc_indexer
 
 import (
+	"github.com/darkknightbk52/btc-indexer/common"
 	"github.com/micro/go-micro/errors"
 	"github.com/micro/go-micro/server"
 )
@@ -8,6 +9,15 @@ import (
 func RPCError(method string, err error) error {
 	id := server.DefaultOptions().Name + "." + method
 	switch err {
+	case common.ErrInvalidRange, common.ErrInvalidHash, common.ErrInvalidCursor,
+		common.ErrNoAddresses, common.ErrTooManyAddresses, common.ErrInvalidAddress, common.ErrInvalidWatchList:
+		return errors.BadRequest(id, err.Error())
+	case common.ErrRepairDisabled:
+		return errors.Forbidden(id, err.Error())
+	case common.ErrNotLeader:
+		return errors.Conflict(id, err.Error())
+	case common.ErrNotFound:
+		return errors.NotFound(id, err.Error())
 	default:
 		return errors.InternalServerError(id, err.Error())
 	}
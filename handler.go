@@ -2,12 +2,304 @@ package btc_indexer
 
 import (
 	"context"
+	"encoding/hex"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/darkknightbk52/btc-indexer/client/sync"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/model"
 	proto "github.com/darkknightbk52/btc-indexer/proto"
+	"github.com/darkknightbk52/btc-indexer/service/indexer"
+	"github.com/darkknightbk52/btc-indexer/store"
+	"go.uber.org/zap"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Limits of the number of txs of a page of address history
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// maxUTXOsAddresses limits the number of addresses of a UTXOs query
+const maxUTXOsAddresses = 1000
+
+// Limits of the number of UTXOs of a page
+const (
+	defaultUTXOsLimit = 1000
+	maxUTXOsLimit     = 10000
 )
 
 type handler struct {
+	indexer     *indexer.Indexer
+	manager     store.Manager
+	addressBook *AddressBook
+	chainParams *chaincfg.Params
+	syncConfig  sync.Config
+	adminConfig AdminConfig
+	// isLeader tells if the instance indexes, so is the only one allowed to write the indexed data
+	isLeader func() bool
 }
 
+func NewHandler(indexer *indexer.Indexer, manager store.Manager, addressBook *AddressBook, chainParams *chaincfg.Params, syncConfig sync.Config, adminConfig AdminConfig, isLeader func() bool) proto.BtcIndexerHandler {
+	return &handler{
+		indexer:     indexer,
+		manager:     manager,
+		addressBook: addressBook,
+		chainParams: chainParams,
+		syncConfig:  syncConfig,
+		adminConfig: adminConfig,
+		isLeader:    isLeader,
+	}
+}
+
+// Sync streams the data of the watched addresses, or of the ones declared by the requests, until the client closes
+// the stream or the context is done, on disconnection or shutdown of the service.
 func (h *handler) Sync(ctx context.Context, stream proto.BtcIndexer_SyncStream) error {
-	panic("implement me")
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		err := stream.Close()
+		if err != nil {
+			log.L().Debug("Failed to Close Sync stream", zap.Error(err))
+		}
+	}()
+
+	log.L().Info("Start Sync stream")
+	// The reads of the stream are pinned to one DB, for its blocks not to go back to the ones of a replica lagging more
+	err := sync.NewSyncClient(h.syncConfig, ctx, stream, store.Pin(h.manager), h.addressBook, h.addressBook, h.chainParams).Sync()
+	if err == nil || ctx.Err() != nil {
+		// Ended by the client, nobody is left to receive an error
+		log.L().Info("End Sync stream", zap.NamedError("Cause", err))
+		return nil
+	}
+	log.L().Error("Failed to Sync", zap.Error(err))
+	return RPCError("Sync", err)
+}
+
+// Verify audits the indexed data, repairing them on request if the admin operations allow it.
+// The repair is done by the leader only, in turn with its sync, for no other instance to write concurrently
+// nor to leave the current block of the leader stale.
+func (h *handler) Verify(ctx context.Context, req *proto.VerifyRequest, resp *proto.VerifyResponse) error {
+	if req.Repair {
+		if !h.adminConfig.EnableRepair {
+			return RPCError("Verify", common.ErrRepairDisabled)
+		}
+		if !h.isLeader() {
+			return RPCError("Verify", common.ErrNotLeader)
+		}
+	}
+	report, err := h.indexer.Verify(ctx, req.FromHeight, req.ToHeight, req.Repair)
+	if err != nil {
+		return RPCError("Verify", err)
+	}
+
+	resp.FromHeight = report.FromHeight
+	resp.ToHeight = report.ToHeight
+	resp.CheckedBlocks = report.CheckedBlocks
+	resp.RepairedHeights = report.RepairedHeights
+	for _, issue := range report.Issues {
+		resp.Issues = append(resp.Issues, &proto.VerifyIssue{
+			Height:   issue.Height,
+			Kind:     issue.Kind,
+			Expected: issue.Expected,
+			Actual:   issue.Actual,
+		})
+	}
+	return nil
+}
+
+func (h *handler) GetTransaction(ctx context.Context, req *proto.GetTransactionRequest, resp *proto.GetTransactionResponse) error {
+	if _, err := hex.DecodeString(req.Hash); err != nil || len(req.Hash) != 2*chainhash.HashSize {
+		return RPCError("GetTransaction", common.ErrInvalidHash)
+	}
+	// The hashes are indexed in lower case
+	hash := strings.ToLower(req.Hash)
+
+	detail, err := h.manager.GetTx(ctx, hash)
+	if err != nil {
+		return RPCError("GetTransaction", err)
+	}
+
+	resp.Hash = detail.Tx.Hash
+	resp.CoinBase = detail.Tx.CoinBase != nil && *detail.Tx.CoinBase
+	resp.Block = common.ToProtoBlock(detail.Block)
+	resp.Confirmations = detail.Confirmations
+	for _, txIn := range detail.TxIns {
+		resp.TxIns = append(resp.TxIns, common.ToProtoTxIn(txIn))
+	}
+	for _, txOut := range detail.TxOuts {
+		resp.TxOuts = append(resp.TxOuts, common.ToProtoTxOut(txOut))
+	}
+	return nil
+}
+
+func (h *handler) GetAddressHistory(ctx context.Context, req *proto.GetAddressHistoryRequest, resp *proto.GetAddressHistoryResponse) error {
+	query := &model.AddressHistoryQuery{
+		Address:    req.Address,
+		FromHeight: req.FromHeight,
+		ToHeight:   req.ToHeight,
+		Cursor:     req.Cursor,
+		Limit:      int(req.Limit),
+		Descending: req.Descending,
+	}
+	if query.ToHeight == 0 {
+		query.ToHeight = math.MaxInt64
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultHistoryLimit
+	} else if query.Limit > maxHistoryLimit {
+		query.Limit = maxHistoryLimit
+	}
+
+	history, err := h.manager.GetAddressHistory(ctx, query)
+	if err != nil {
+		return RPCError("GetAddressHistory", err)
+	}
+
+	resp.NextCursor = history.NextCursor
+	for _, tx := range history.Txs {
+		addressTx := new(proto.GetAddressHistoryResponse_AddressTx)
+		if tx.TxIn != nil {
+			addressTx.Tx = &proto.GetAddressHistoryResponse_AddressTx_TxIn{TxIn: common.ToProtoTxIn(tx.TxIn)}
+		} else {
+			addressTx.Tx = &proto.GetAddressHistoryResponse_AddressTx_TxOut{TxOut: common.ToProtoTxOut(tx.TxOut)}
+		}
+		resp.Txs = append(resp.Txs, addressTx)
+	}
+	return nil
+}
+
+func (h *handler) GetUTXOs(ctx context.Context, req *proto.GetUTXOsRequest, resp *proto.GetUTXOsResponse) error {
+	if len(req.Addresses) == 0 {
+		return RPCError("GetUTXOs", common.ErrNoAddresses)
+	}
+	if len(req.Addresses) > maxUTXOsAddresses {
+		return RPCError("GetUTXOs", common.ErrTooManyAddresses)
+	}
+
+	query := &model.UTXOsQuery{
+		Addresses: req.Addresses,
+		Cursor:    req.Cursor,
+		Limit:     int(req.Limit),
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultUTXOsLimit
+	} else if query.Limit > maxUTXOsLimit {
+		query.Limit = maxUTXOsLimit
+	}
+
+	page, err := h.manager.GetUTXOs(ctx, query, int64(h.chainParams.CoinbaseMaturity))
+	if err != nil {
+		return RPCError("GetUTXOs", err)
+	}
+
+	resp.NextCursor = page.NextCursor
+	for _, utxo := range page.UTXOs {
+		resp.Utxos = append(resp.Utxos, &proto.UTXO{
+			TxOut:         common.ToProtoTxOut(utxo.TxOut),
+			Confirmations: utxo.Confirmations,
+			Mature:        utxo.Mature,
+		})
+	}
+	return nil
+}
+
+func (h *handler) GetAddressBalance(ctx context.Context, req *proto.GetAddressBalanceRequest, resp *proto.GetAddressBalanceResponse) error {
+	if len(req.Address) == 0 {
+		return RPCError("GetAddressBalance", common.ErrNoAddresses)
+	}
+	if req.Height < 0 {
+		return RPCError("GetAddressBalance", common.ErrInvalidRange)
+	}
+
+	var balance *model.AddressBalance
+	var err error
+	if req.Height == 0 {
+		balance, err = h.manager.GetAddressBalance(ctx, req.Address)
+	} else {
+		balance, err = h.manager.GetAddressBalanceAtHeight(ctx, req.Address, req.Height)
+	}
+	if err != nil {
+		return RPCError("GetAddressBalance", err)
+	}
+
+	resp.Address = balance.Address
+	resp.Received = balance.Received
+	resp.Sent = balance.Sent
+	resp.Balance = balance.Balance
+	resp.TxNo = balance.TxNo
+	resp.FirstSeenHeight = balance.FirstSeenHeight
+	resp.LastSeenHeight = balance.LastSeenHeight
+	return nil
+}
+
+func (h *handler) GetReorgs(ctx context.Context, req *proto.GetReorgsRequest, resp *proto.GetReorgsResponse) error {
+	query := &model.ReorgQuery{
+		FromHeight: req.FromHeight,
+		ToHeight:   req.ToHeight,
+	}
+	if query.ToHeight == 0 {
+		query.ToHeight = math.MaxInt64
+	}
+	if req.FromTime > 0 {
+		query.FromTime = time.Unix(req.FromTime, 0)
+	}
+	if req.ToTime > 0 {
+		query.ToTime = time.Unix(req.ToTime, 0)
+	}
+
+	reorgs, err := h.manager.GetReorgs(ctx, query)
+	if err != nil {
+		return RPCError("GetReorgs", err)
+	}
+
+	for _, reorg := range reorgs {
+		resp.Reorgs = append(resp.Reorgs, common.ToProtoReorg(reorg))
+	}
+	return nil
+}
+
+func (h *handler) AddWatchedAddresses(ctx context.Context, req *proto.AddWatchedAddressesRequest, resp *proto.AddWatchedAddressesResponse) error {
+	added, err := h.addressBook.Add(ctx, req.List, req.Addresses)
+	if err != nil {
+		return RPCError("AddWatchedAddresses", err)
+	}
+	resp.Added = added
+	return nil
+}
+
+func (h *handler) RemoveWatchedAddresses(ctx context.Context, req *proto.RemoveWatchedAddressesRequest, resp *proto.RemoveWatchedAddressesResponse) error {
+	removed, err := h.addressBook.Remove(ctx, req.List, req.Addresses)
+	if err != nil {
+		return RPCError("RemoveWatchedAddresses", err)
+	}
+	resp.Removed = removed
+	return nil
+}
+
+func (h *handler) ListWatchedAddresses(ctx context.Context, req *proto.ListWatchedAddressesRequest, resp *proto.ListWatchedAddressesResponse) error {
+	if len(req.List) > 0 {
+		addresses := h.addressBook.GetList(req.List)
+		if len(addresses) == 0 {
+			return RPCError("ListWatchedAddresses", common.ErrNotFound)
+		}
+		resp.Lists = []*proto.WatchList{{Name: req.List, Addresses: addresses}}
+		return nil
+	}
+
+	lists := h.addressBook.GetLists()
+	names := make([]string, 0, len(lists))
+	for name := range lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		resp.Lists = append(resp.Lists, &proto.WatchList{Name: name, Addresses: lists[name]})
+	}
+	return nil
 }
@@ -0,0 +1,102 @@
+package btc_indexer
+
+import (
+	"context"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/darkknightbk52/btc-indexer/client/sync"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/model"
+	proto "github.com/darkknightbk52/btc-indexer/proto"
+	protoMocks "github.com/darkknightbk52/btc-indexer/proto/mocks"
+	"github.com/darkknightbk52/btc-indexer/store"
+	"github.com/micro/go-micro/errors"
+	. "github.com/onsi/gomega"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Sync(t *testing.T) {
+	RegisterTestingT(t)
+	log.Init(false)
+	ctx := context.Background()
+	manager := store.NewMemoryManager()
+	book, err := NewAddressBook(ctx, manager, &chaincfg.MainNetParams)
+	Expect(err).Should(Succeed())
+	h := NewHandler(nil, manager, book, &chaincfg.MainNetParams, sync.Config{}, AdminConfig{}, nil)
+
+	// Closed by the client
+	stream := new(protoMocks.BtcIndexer_SyncStream)
+	stream.On("Recv").Return(nil, io.EOF).Once()
+	stream.On("Close").Return(nil).Once()
+	Expect(h.Sync(ctx, stream)).Should(Succeed())
+	stream.AssertExpectations(t)
+
+	// An invalid request is rejected
+	stream = new(protoMocks.BtcIndexer_SyncStream)
+	stream.On("Recv").Return(&proto.SyncRequest{WatchList: "unknown"}, nil).Once()
+	stream.On("Close").Return(nil).Once()
+	err = h.Sync(ctx, stream)
+	Expect(err).Should(HaveOccurred())
+	Expect(err.(*errors.Error).Code).Should(Equal(int32(http.StatusNotFound)))
+	stream.AssertExpectations(t)
+
+	// Disconnected
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	stream = new(protoMocks.BtcIndexer_SyncStream)
+	stream.On("Recv").Return(nil, context.Canceled).Once()
+	stream.On("Close").Return(nil).Once()
+	Expect(h.Sync(canceledCtx, stream)).Should(Succeed())
+	stream.AssertExpectations(t)
+}
+
+func TestHandler_GetTransaction(t *testing.T) {
+	RegisterTestingT(t)
+	log.Init(false)
+	ctx := context.Background()
+	manager := store.NewMemoryManager()
+	h := NewHandler(nil, manager, nil, &chaincfg.MainNetParams, sync.Config{}, AdminConfig{}, nil)
+
+	// The coinbase of the block 1 of mainnet
+	hash := "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+	coinBase := true
+	err := manager.AddBlocksData(ctx,
+		[]*model.Block{{Height: 1, Hash: "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048", PreviousHash: "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"}},
+		[]*model.Tx{{Height: 1, Hash: hash, CoinBase: &coinBase}},
+		nil, nil)
+	Expect(err).Should(Succeed())
+
+	// Hashes are case insensitive
+	for _, reqHash := range []string{hash, strings.ToUpper(hash)} {
+		resp := new(proto.GetTransactionResponse)
+		err = h.GetTransaction(ctx, &proto.GetTransactionRequest{Hash: reqHash}, resp)
+		Expect(err).Should(Succeed())
+		Expect(resp.Hash).Should(Equal(hash))
+		Expect(resp.CoinBase).Should(BeTrue())
+	}
+
+	err = h.GetTransaction(ctx, &proto.GetTransactionRequest{Hash: hash[1:]}, new(proto.GetTransactionResponse))
+	Expect(err.(*errors.Error).Code).Should(Equal(int32(http.StatusBadRequest)))
+	err = h.GetTransaction(ctx, &proto.GetTransactionRequest{Hash: strings.Repeat("0", len(hash))}, new(proto.GetTransactionResponse))
+	Expect(err.(*errors.Error).Code).Should(Equal(int32(http.StatusNotFound)))
+}
+
+func TestHandler_Verify(t *testing.T) {
+	RegisterTestingT(t)
+	log.Init(false)
+	ctx := context.Background()
+	leading := false
+	isLeader := func() bool { return leading }
+
+	// The repair is an admin operation, disabled by default
+	h := NewHandler(nil, store.NewMemoryManager(), nil, &chaincfg.MainNetParams, sync.Config{}, AdminConfig{}, isLeader)
+	err := h.Verify(ctx, &proto.VerifyRequest{FromHeight: 0, ToHeight: 10, Repair: true}, new(proto.VerifyResponse))
+	Expect(err.(*errors.Error).Code).Should(Equal(int32(http.StatusForbidden)))
+
+	// Then done by the leader only
+	h = NewHandler(nil, store.NewMemoryManager(), nil, &chaincfg.MainNetParams, sync.Config{}, AdminConfig{EnableRepair: true}, isLeader)
+	err = h.Verify(ctx, &proto.VerifyRequest{FromHeight: 0, ToHeight: 10, Repair: true}, new(proto.VerifyResponse))
+	Expect(err.(*errors.Error).Code).Should(Equal(int32(http.StatusConflict)))
+}
@@ -1,11 +1,13 @@
 package model
 
+import "time"
+
 const (
 	NonStandardAddr = "NonStandard"
 )
 
 type Block struct {
-	Height       int64  `gorm:"not null;"`
+	Height       int64  `gorm:"not null;unique_index:idx_blocks_height"`
 	Hash         string `gorm:"type:varchar(64);not null"`
 	PreviousHash string `gorm:"type:varchar(64);not null"`
 }
@@ -22,9 +24,15 @@ func (m Block) ColumnNames() []string {
 	}
 }
 
+func (m Block) KeyColumnNames() []string {
+	return []string{
+		"height",
+	}
+}
+
 type Tx struct {
-	Height   int64  `gorm:"not null;"`
-	Hash     string `gorm:"type:varchar(64);not null"`
+	Height   int64  `gorm:"not null;unique_index:idx_txes_height_hash"`
+	Hash     string `gorm:"type:varchar(64);not null;unique_index:idx_txes_height_hash;index:idx_txes_hash"`
 	CoinBase *bool  `gorm:"not null;default:false"`
 }
 
@@ -40,13 +48,23 @@ func (m Tx) ColumnNames() []string {
 	}
 }
 
+// KeyColumnNames of a tx include the height, as the coinbases of 2 pairs of blocks before BIP30 have the same hashes.
+func (m Tx) KeyColumnNames() []string {
+	return []string{
+		"height",
+		"hash",
+	}
+}
+
 type TxIn struct {
-	Height          int64  `gorm:"not null"`
-	TxHash          string `gorm:"type:varchar(64);not null"`
-	TxIndex         int32  `gorm:"not null"`
+	Height          int64  `gorm:"not null;unique_index:idx_tx_ins_height_tx_hash_tx_index"`
+	TxHash          string `gorm:"type:varchar(64);not null;unique_index:idx_tx_ins_height_tx_hash_tx_index"`
+	TxIndex         int32  `gorm:"not null;unique_index:idx_tx_ins_height_tx_hash_tx_index"`
 	Address         string `gorm:"type:varchar(62);not null;"` // max length of a bech32 address
 	PreviousTxHash  string `gorm:"type:varchar(64);not null"`
 	PreviousTxIndex int32  `gorm:"not null"`
+	// TxPosition is the position of the tx in its block, the order of the txs in the history of an address
+	TxPosition int32 `gorm:"not null;default:0"`
 }
 
 func (m TxIn) TableName() string {
@@ -64,14 +82,24 @@ func (m TxIn) ColumnNames() []string {
 	}
 }
 
+func (m TxIn) KeyColumnNames() []string {
+	return []string{
+		"height",
+		"tx_hash",
+		"tx_index",
+	}
+}
+
 type TxOut struct {
-	Height       int64  `gorm:"not null"`
-	TxHash       string `gorm:"type:varchar(64);not null"`
-	TxIndex      int32  `gorm:"not null"`
+	Height       int64  `gorm:"not null;unique_index:idx_tx_outs_height_tx_hash_tx_index"`
+	TxHash       string `gorm:"type:varchar(64);not null;unique_index:idx_tx_outs_height_tx_hash_tx_index"`
+	TxIndex      int32  `gorm:"not null;unique_index:idx_tx_outs_height_tx_hash_tx_index"`
 	Value        int64  `gorm:"not null"`
 	Address      string `gorm:"type:varchar(62);not null"` // max length of a bech32 address
 	ScriptPubKey []byte `gorm:"not null"`                  // max length 16 MB
 	CoinBase     *bool  `gorm:"not null;default:false"`
+	// TxPosition is the position of the tx in its block, the order of the txs in the history of an address
+	TxPosition int32 `gorm:"not null;default:0"`
 }
 
 func (m TxOut) TableName() string {
@@ -90,10 +118,157 @@ func (m TxOut) ColumnNames() []string {
 	}
 }
 
+func (m TxOut) KeyColumnNames() []string {
+	return []string{
+		"height",
+		"tx_hash",
+		"tx_index",
+	}
+}
+
+// Reorg orphans the blocks from height 'FromHeight' to the old tip at 'ToHeight'.
 type Reorg struct {
 	Id         int64  `gorm:"primary"`
 	FromHeight int64  `gorm:"not null"`
 	FromHash   string `gorm:"not null"`
 	ToHeight   int64  `gorm:"not null"`
 	ToHash     string `gorm:"not null"`
+	// NewHeight & NewHash are of the tip of the new branch revealing the reorg
+	NewHeight int64  `gorm:"not null;default:0"`
+	NewHash   string `gorm:"not null;default:''"`
+	// TxNo is the number of txs of the orphaned blocks, counted by the Manager
+	TxNo      int64 `gorm:"not null;default:0"`
+	CreatedAt time.Time
+}
+
+// Depth is the number of orphaned blocks.
+func (m Reorg) Depth() int64 {
+	return m.ToHeight - m.FromHeight + 1
+}
+
+// ReorgQuery selects the reorgs orphaning blocks from height 'FromHeight' to 'ToHeight',
+// happened from 'FromTime' to 'ToTime', a zero time is not a bound.
+type ReorgQuery struct {
+	FromHeight int64
+	ToHeight   int64
+	FromTime   time.Time
+	ToTime     time.Time
+}
+
+// BlockStats summarizes the indexed data of a block, it's not a table.
+type BlockStats struct {
+	Height     int64
+	TxNo       int64
+	TxOutNo    int64
+	TxOutValue int64
+}
+
+// TxDetail is a tx with its block, ins & outs in order of index, it's not a table.
+type TxDetail struct {
+	Tx            *Tx
+	Block         *Block
+	TxIns         []*TxIn
+	TxOuts        []*TxOut
+	Confirmations int64
+}
+
+// AddressTx is a tx in debiting or a tx out crediting an address, only one of them is set. It's not a table.
+type AddressTx struct {
+	TxIn  *TxIn
+	TxOut *TxOut
+}
+
+// AddressHistoryQuery selects a page of the txs of an address, from height 'FromHeight' to 'ToHeight'.
+// The txs are ordered by height, position in the block, ins before outs, then tx index. A page starts after the
+// position of an opaque Cursor returned by the previous page, from the first tx if empty.
+type AddressHistoryQuery struct {
+	Address    string
+	FromHeight int64
+	ToHeight   int64
+	Cursor     string
+	Limit      int
+	Descending bool
+}
+
+// AddressHistory is a page of the txs of an address. NextCursor is empty for the last page.
+type AddressHistory struct {
+	Txs        []*AddressTx
+	NextCursor string
+}
+
+// UTXOsQuery selects a page of the unspent tx outs of addresses, ordered by height, position in the block, tx hash
+// then tx index. A page starts after the position of an opaque Cursor returned by the previous page, from the first tx out if empty.
+type UTXOsQuery struct {
+	Addresses []string
+	Cursor    string
+	Limit     int
+}
+
+// UTXOsPage is a page of the unspent tx outs of addresses. NextCursor is empty for the last page.
+type UTXOsPage struct {
+	UTXOs      []*UTXO
+	NextCursor string
+}
+
+// UTXO is an unspent tx out, it's not a table. A coinbase tx out is Mature once spendable by the next block.
+type UTXO struct {
+	TxOut         *TxOut
+	Confirmations int64
+	Mature        bool
+}
+
+// AddressBalance is the balance of an address, from the txs up to the latest block.
+type AddressBalance struct {
+	Address         string `gorm:"type:varchar(62);primary_key"`
+	Received        int64  `gorm:"not null"`
+	Sent            int64  `gorm:"not null"`
+	Balance         int64  `gorm:"not null"`
+	TxNo            int64  `gorm:"not null"`
+	FirstSeenHeight int64  `gorm:"not null"`
+	LastSeenHeight  int64  `gorm:"not null"`
+}
+
+func (m AddressBalance) TableName() string {
+	return "address_balances"
+}
+
+// AddressBalanceChange sums the txs of an address in a block, the balance at a height is the sum of the changes up to it.
+type AddressBalanceChange struct {
+	Address  string `gorm:"type:varchar(62);not null;unique_index:idx_address_balance_changes_address_height"`
+	Height   int64  `gorm:"not null;unique_index:idx_address_balance_changes_address_height;index:idx_address_balance_changes_height"`
+	Received int64  `gorm:"not null"`
+	Sent     int64  `gorm:"not null"`
+	TxNo     int64  `gorm:"not null"`
+}
+
+func (m AddressBalanceChange) TableName() string {
+	return "address_balance_changes"
+}
+
+func (m AddressBalanceChange) ColumnNames() []string {
+	return []string{
+		"address",
+		"height",
+		"received",
+		"sent",
+		"tx_no",
+	}
+}
+
+func (m AddressBalanceChange) KeyColumnNames() []string {
+	return []string{
+		"address",
+		"height",
+	}
+}
+
+// WatchedAddress is an address of a named watch list of the address book.
+type WatchedAddress struct {
+	List      string `gorm:"type:varchar(64);not null;unique_index:idx_watched_addresses_list_address"`
+	Address   string `gorm:"type:varchar(62);not null;unique_index:idx_watched_addresses_list_address"`
+	CreatedAt time.Time
+}
+
+func (m WatchedAddress) TableName() string {
+	return "watched_addresses"
 }
@@ -10,9 +10,31 @@ It is generated from these files:
 It has these top-level messages:
 	SyncRequest
 	SyncResponse
+	VerifyRequest
+	VerifyResponse
+	GetTransactionRequest
+	GetTransactionResponse
+	GetAddressHistoryRequest
+	GetAddressHistoryResponse
+	GetUTXOsRequest
+	GetUTXOsResponse
+	GetAddressBalanceRequest
+	GetAddressBalanceResponse
+	GetReorgsRequest
+	GetReorgsResponse
+	AddWatchedAddressesRequest
+	AddWatchedAddressesResponse
+	RemoveWatchedAddressesRequest
+	RemoveWatchedAddressesResponse
+	ListWatchedAddressesRequest
+	ListWatchedAddressesResponse
 	Block
 	TxIn
 	TxOut
+	VerifyIssue
+	UTXO
+	Reorg
+	WatchList
 */
 package btcindexersrv
 
@@ -46,6 +68,15 @@ var _ server.Option
 
 type BtcIndexerService interface {
 	Sync(ctx context.Context, opts ...client.CallOption) (BtcIndexer_SyncService, error)
+	Verify(ctx context.Context, in *VerifyRequest, opts ...client.CallOption) (*VerifyResponse, error)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...client.CallOption) (*GetTransactionResponse, error)
+	GetAddressHistory(ctx context.Context, in *GetAddressHistoryRequest, opts ...client.CallOption) (*GetAddressHistoryResponse, error)
+	GetUTXOs(ctx context.Context, in *GetUTXOsRequest, opts ...client.CallOption) (*GetUTXOsResponse, error)
+	GetAddressBalance(ctx context.Context, in *GetAddressBalanceRequest, opts ...client.CallOption) (*GetAddressBalanceResponse, error)
+	GetReorgs(ctx context.Context, in *GetReorgsRequest, opts ...client.CallOption) (*GetReorgsResponse, error)
+	AddWatchedAddresses(ctx context.Context, in *AddWatchedAddressesRequest, opts ...client.CallOption) (*AddWatchedAddressesResponse, error)
+	RemoveWatchedAddresses(ctx context.Context, in *RemoveWatchedAddressesRequest, opts ...client.CallOption) (*RemoveWatchedAddressesResponse, error)
+	ListWatchedAddresses(ctx context.Context, in *ListWatchedAddressesRequest, opts ...client.CallOption) (*ListWatchedAddressesResponse, error)
 }
 
 type btcIndexerService struct {
@@ -112,15 +143,123 @@ func (x *btcIndexerServiceSync) Recv() (*SyncResponse, error) {
 	return m, nil
 }
 
+func (c *btcIndexerService) Verify(ctx context.Context, in *VerifyRequest, opts ...client.CallOption) (*VerifyResponse, error) {
+	req := c.c.NewRequest(c.name, "BtcIndexer.Verify", in)
+	out := new(VerifyResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerService) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...client.CallOption) (*GetTransactionResponse, error) {
+	req := c.c.NewRequest(c.name, "BtcIndexer.GetTransaction", in)
+	out := new(GetTransactionResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerService) GetAddressHistory(ctx context.Context, in *GetAddressHistoryRequest, opts ...client.CallOption) (*GetAddressHistoryResponse, error) {
+	req := c.c.NewRequest(c.name, "BtcIndexer.GetAddressHistory", in)
+	out := new(GetAddressHistoryResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerService) GetUTXOs(ctx context.Context, in *GetUTXOsRequest, opts ...client.CallOption) (*GetUTXOsResponse, error) {
+	req := c.c.NewRequest(c.name, "BtcIndexer.GetUTXOs", in)
+	out := new(GetUTXOsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerService) GetAddressBalance(ctx context.Context, in *GetAddressBalanceRequest, opts ...client.CallOption) (*GetAddressBalanceResponse, error) {
+	req := c.c.NewRequest(c.name, "BtcIndexer.GetAddressBalance", in)
+	out := new(GetAddressBalanceResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerService) GetReorgs(ctx context.Context, in *GetReorgsRequest, opts ...client.CallOption) (*GetReorgsResponse, error) {
+	req := c.c.NewRequest(c.name, "BtcIndexer.GetReorgs", in)
+	out := new(GetReorgsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerService) AddWatchedAddresses(ctx context.Context, in *AddWatchedAddressesRequest, opts ...client.CallOption) (*AddWatchedAddressesResponse, error) {
+	req := c.c.NewRequest(c.name, "BtcIndexer.AddWatchedAddresses", in)
+	out := new(AddWatchedAddressesResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerService) RemoveWatchedAddresses(ctx context.Context, in *RemoveWatchedAddressesRequest, opts ...client.CallOption) (*RemoveWatchedAddressesResponse, error) {
+	req := c.c.NewRequest(c.name, "BtcIndexer.RemoveWatchedAddresses", in)
+	out := new(RemoveWatchedAddressesResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerService) ListWatchedAddresses(ctx context.Context, in *ListWatchedAddressesRequest, opts ...client.CallOption) (*ListWatchedAddressesResponse, error) {
+	req := c.c.NewRequest(c.name, "BtcIndexer.ListWatchedAddresses", in)
+	out := new(ListWatchedAddressesResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for BtcIndexer service
 
 type BtcIndexerHandler interface {
 	Sync(context.Context, BtcIndexer_SyncStream) error
+	Verify(context.Context, *VerifyRequest, *VerifyResponse) error
+	GetTransaction(context.Context, *GetTransactionRequest, *GetTransactionResponse) error
+	GetAddressHistory(context.Context, *GetAddressHistoryRequest, *GetAddressHistoryResponse) error
+	GetUTXOs(context.Context, *GetUTXOsRequest, *GetUTXOsResponse) error
+	GetAddressBalance(context.Context, *GetAddressBalanceRequest, *GetAddressBalanceResponse) error
+	GetReorgs(context.Context, *GetReorgsRequest, *GetReorgsResponse) error
+	AddWatchedAddresses(context.Context, *AddWatchedAddressesRequest, *AddWatchedAddressesResponse) error
+	RemoveWatchedAddresses(context.Context, *RemoveWatchedAddressesRequest, *RemoveWatchedAddressesResponse) error
+	ListWatchedAddresses(context.Context, *ListWatchedAddressesRequest, *ListWatchedAddressesResponse) error
 }
 
 func RegisterBtcIndexerHandler(s server.Server, hdlr BtcIndexerHandler, opts ...server.HandlerOption) error {
 	type btcIndexer interface {
 		Sync(ctx context.Context, stream server.Stream) error
+		Verify(ctx context.Context, in *VerifyRequest, out *VerifyResponse) error
+		GetTransaction(ctx context.Context, in *GetTransactionRequest, out *GetTransactionResponse) error
+		GetAddressHistory(ctx context.Context, in *GetAddressHistoryRequest, out *GetAddressHistoryResponse) error
+		GetUTXOs(ctx context.Context, in *GetUTXOsRequest, out *GetUTXOsResponse) error
+		GetAddressBalance(ctx context.Context, in *GetAddressBalanceRequest, out *GetAddressBalanceResponse) error
+		GetReorgs(ctx context.Context, in *GetReorgsRequest, out *GetReorgsResponse) error
+		AddWatchedAddresses(ctx context.Context, in *AddWatchedAddressesRequest, out *AddWatchedAddressesResponse) error
+		RemoveWatchedAddresses(ctx context.Context, in *RemoveWatchedAddressesRequest, out *RemoveWatchedAddressesResponse) error
+		ListWatchedAddresses(ctx context.Context, in *ListWatchedAddressesRequest, out *ListWatchedAddressesResponse) error
 	}
 	type BtcIndexer struct {
 		btcIndexer
@@ -172,3 +311,39 @@ func (x *btcIndexerSyncStream) Recv() (*SyncRequest, error) {
 	}
 	return m, nil
 }
+
+func (h *btcIndexerHandler) Verify(ctx context.Context, in *VerifyRequest, out *VerifyResponse) error {
+	return h.BtcIndexerHandler.Verify(ctx, in, out)
+}
+
+func (h *btcIndexerHandler) GetTransaction(ctx context.Context, in *GetTransactionRequest, out *GetTransactionResponse) error {
+	return h.BtcIndexerHandler.GetTransaction(ctx, in, out)
+}
+
+func (h *btcIndexerHandler) GetAddressHistory(ctx context.Context, in *GetAddressHistoryRequest, out *GetAddressHistoryResponse) error {
+	return h.BtcIndexerHandler.GetAddressHistory(ctx, in, out)
+}
+
+func (h *btcIndexerHandler) GetUTXOs(ctx context.Context, in *GetUTXOsRequest, out *GetUTXOsResponse) error {
+	return h.BtcIndexerHandler.GetUTXOs(ctx, in, out)
+}
+
+func (h *btcIndexerHandler) GetAddressBalance(ctx context.Context, in *GetAddressBalanceRequest, out *GetAddressBalanceResponse) error {
+	return h.BtcIndexerHandler.GetAddressBalance(ctx, in, out)
+}
+
+func (h *btcIndexerHandler) GetReorgs(ctx context.Context, in *GetReorgsRequest, out *GetReorgsResponse) error {
+	return h.BtcIndexerHandler.GetReorgs(ctx, in, out)
+}
+
+func (h *btcIndexerHandler) AddWatchedAddresses(ctx context.Context, in *AddWatchedAddressesRequest, out *AddWatchedAddressesResponse) error {
+	return h.BtcIndexerHandler.AddWatchedAddresses(ctx, in, out)
+}
+
+func (h *btcIndexerHandler) RemoveWatchedAddresses(ctx context.Context, in *RemoveWatchedAddressesRequest, out *RemoveWatchedAddressesResponse) error {
+	return h.BtcIndexerHandler.RemoveWatchedAddresses(ctx, in, out)
+}
+
+func (h *btcIndexerHandler) ListWatchedAddresses(ctx context.Context, in *ListWatchedAddressesRequest, out *ListWatchedAddressesResponse) error {
+	return h.BtcIndexerHandler.ListWatchedAddresses(ctx, in, out)
+}
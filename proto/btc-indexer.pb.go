@@ -25,7 +25,14 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 // Request/Response messages
 type SyncRequest struct {
-	RecentBlocks         []*Block `protobuf:"bytes,1,rep,name=recent_blocks,json=recentBlocks,proto3" json:"recent_blocks,omitempty"`
+	RecentBlocks []*Block `protobuf:"bytes,1,rep,name=recent_blocks,json=recentBlocks,proto3" json:"recent_blocks,omitempty"`
+	// Addresses of the stream, exclusive with 'watch_list' & 'all_watch_lists'. The first request of a stream
+	// declares its addresses by one of them, the next ones keep the ones of the previous request if none is given.
+	Addresses []string `protobuf:"bytes,2,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	// Name of the watch list of the stream, its edits being picked up while streaming
+	WatchList string `protobuf:"bytes,3,opt,name=watch_list,json=watchList,proto3" json:"watch_list,omitempty"`
+	// Streams the addresses of all the watch lists, their edits being picked up while streaming
+	AllWatchLists        bool     `protobuf:"varint,4,opt,name=all_watch_lists,json=allWatchLists,proto3" json:"all_watch_lists,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -35,7 +42,7 @@ func (m *SyncRequest) Reset()         { *m = SyncRequest{} }
 func (m *SyncRequest) String() string { return proto.CompactTextString(m) }
 func (*SyncRequest) ProtoMessage()    {}
 func (*SyncRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_btc_indexer_e6885b9d3d85d89a, []int{0}
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{0}
 }
 func (m *SyncRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SyncRequest.Unmarshal(m, b)
@@ -62,6 +69,27 @@ func (m *SyncRequest) GetRecentBlocks() []*Block {
 	return nil
 }
 
+func (m *SyncRequest) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *SyncRequest) GetWatchList() string {
+	if m != nil {
+		return m.WatchList
+	}
+	return ""
+}
+
+func (m *SyncRequest) GetAllWatchLists() bool {
+	if m != nil {
+		return m.AllWatchLists
+	}
+	return false
+}
+
 type SyncResponse struct {
 	// Types that are valid to be assigned to Response:
 	//	*SyncResponse_BeginStream_
@@ -78,7 +106,7 @@ func (m *SyncResponse) Reset()         { *m = SyncResponse{} }
 func (m *SyncResponse) String() string { return proto.CompactTextString(m) }
 func (*SyncResponse) ProtoMessage()    {}
 func (*SyncResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_btc_indexer_e6885b9d3d85d89a, []int{1}
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{1}
 }
 func (m *SyncResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SyncResponse.Unmarshal(m, b)
@@ -283,7 +311,7 @@ func (m *SyncResponse_BeginStream) Reset()         { *m = SyncResponse_BeginStre
 func (m *SyncResponse_BeginStream) String() string { return proto.CompactTextString(m) }
 func (*SyncResponse_BeginStream) ProtoMessage()    {}
 func (*SyncResponse_BeginStream) Descriptor() ([]byte, []int) {
-	return fileDescriptor_btc_indexer_e6885b9d3d85d89a, []int{1, 0}
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{1, 0}
 }
 func (m *SyncResponse_BeginStream) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SyncResponse_BeginStream.Unmarshal(m, b)
@@ -313,7 +341,7 @@ func (m *SyncResponse_EndStream) Reset()         { *m = SyncResponse_EndStream{}
 func (m *SyncResponse_EndStream) String() string { return proto.CompactTextString(m) }
 func (*SyncResponse_EndStream) ProtoMessage()    {}
 func (*SyncResponse_EndStream) Descriptor() ([]byte, []int) {
-	return fileDescriptor_btc_indexer_e6885b9d3d85d89a, []int{1, 1}
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{1, 1}
 }
 func (m *SyncResponse_EndStream) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SyncResponse_EndStream.Unmarshal(m, b)
@@ -346,7 +374,7 @@ func (m *SyncResponse_SyncBlock) Reset()         { *m = SyncResponse_SyncBlock{}
 func (m *SyncResponse_SyncBlock) String() string { return proto.CompactTextString(m) }
 func (*SyncResponse_SyncBlock) ProtoMessage()    {}
 func (*SyncResponse_SyncBlock) Descriptor() ([]byte, []int) {
-	return fileDescriptor_btc_indexer_e6885b9d3d85d89a, []int{1, 2}
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{1, 2}
 }
 func (m *SyncResponse_SyncBlock) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SyncResponse_SyncBlock.Unmarshal(m, b)
@@ -400,7 +428,7 @@ func (m *SyncResponse_ReorgBlock) Reset()         { *m = SyncResponse_ReorgBlock
 func (m *SyncResponse_ReorgBlock) String() string { return proto.CompactTextString(m) }
 func (*SyncResponse_ReorgBlock) ProtoMessage()    {}
 func (*SyncResponse_ReorgBlock) Descriptor() ([]byte, []int) {
-	return fileDescriptor_btc_indexer_e6885b9d3d85d89a, []int{1, 3}
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{1, 3}
 }
 func (m *SyncResponse_ReorgBlock) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SyncResponse_ReorgBlock.Unmarshal(m, b)
@@ -441,311 +469,1806 @@ func (m *SyncResponse_ReorgBlock) GetNewHash() string {
 	return ""
 }
 
-// Data messages
-type Block struct {
-	Height               int64    `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
-	Hash                 string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
-	PreviousHash         string   `protobuf:"bytes,3,opt,name=previous_hash,json=previousHash,proto3" json:"previous_hash,omitempty"`
+type VerifyRequest struct {
+	FromHeight           int64    `protobuf:"varint,1,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	ToHeight             int64    `protobuf:"varint,2,opt,name=to_height,json=toHeight,proto3" json:"to_height,omitempty"`
+	Repair               bool     `protobuf:"varint,3,opt,name=repair,proto3" json:"repair,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Block) Reset()         { *m = Block{} }
-func (m *Block) String() string { return proto.CompactTextString(m) }
-func (*Block) ProtoMessage()    {}
-func (*Block) Descriptor() ([]byte, []int) {
-	return fileDescriptor_btc_indexer_e6885b9d3d85d89a, []int{2}
+func (m *VerifyRequest) Reset()         { *m = VerifyRequest{} }
+func (m *VerifyRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifyRequest) ProtoMessage()    {}
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{2}
 }
-func (m *Block) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Block.Unmarshal(m, b)
+func (m *VerifyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyRequest.Unmarshal(m, b)
 }
-func (m *Block) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Block.Marshal(b, m, deterministic)
+func (m *VerifyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyRequest.Marshal(b, m, deterministic)
 }
-func (dst *Block) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Block.Merge(dst, src)
+func (dst *VerifyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyRequest.Merge(dst, src)
 }
-func (m *Block) XXX_Size() int {
-	return xxx_messageInfo_Block.Size(m)
+func (m *VerifyRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifyRequest.Size(m)
 }
-func (m *Block) XXX_DiscardUnknown() {
-	xxx_messageInfo_Block.DiscardUnknown(m)
+func (m *VerifyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Block proto.InternalMessageInfo
+var xxx_messageInfo_VerifyRequest proto.InternalMessageInfo
 
-func (m *Block) GetHeight() int64 {
+func (m *VerifyRequest) GetFromHeight() int64 {
 	if m != nil {
-		return m.Height
+		return m.FromHeight
 	}
 	return 0
 }
 
-func (m *Block) GetHash() string {
+func (m *VerifyRequest) GetToHeight() int64 {
 	if m != nil {
-		return m.Hash
+		return m.ToHeight
 	}
-	return ""
+	return 0
 }
 
-func (m *Block) GetPreviousHash() string {
+func (m *VerifyRequest) GetRepair() bool {
 	if m != nil {
-		return m.PreviousHash
+		return m.Repair
 	}
-	return ""
+	return false
 }
 
-type TxIn struct {
-	TxHash               string   `protobuf:"bytes,1,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
-	TxIndex              int32    `protobuf:"varint,2,opt,name=tx_index,json=txIndex,proto3" json:"tx_index,omitempty"`
-	Height               int64    `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
-	Address              string   `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
-	PreviousTxHash       string   `protobuf:"bytes,5,opt,name=previous_tx_hash,json=previousTxHash,proto3" json:"previous_tx_hash,omitempty"`
-	PreviousTxIndex      int32    `protobuf:"varint,6,opt,name=previous_tx_index,json=previousTxIndex,proto3" json:"previous_tx_index,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+type VerifyResponse struct {
+	FromHeight           int64          `protobuf:"varint,1,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	ToHeight             int64          `protobuf:"varint,2,opt,name=to_height,json=toHeight,proto3" json:"to_height,omitempty"`
+	CheckedBlocks        int64          `protobuf:"varint,3,opt,name=checked_blocks,json=checkedBlocks,proto3" json:"checked_blocks,omitempty"`
+	Issues               []*VerifyIssue `protobuf:"bytes,4,rep,name=issues,proto3" json:"issues,omitempty"`
+	RepairedHeights      []int64        `protobuf:"varint,5,rep,packed,name=repaired_heights,json=repairedHeights,proto3" json:"repaired_heights,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
-func (m *TxIn) Reset()         { *m = TxIn{} }
-func (m *TxIn) String() string { return proto.CompactTextString(m) }
-func (*TxIn) ProtoMessage()    {}
-func (*TxIn) Descriptor() ([]byte, []int) {
-	return fileDescriptor_btc_indexer_e6885b9d3d85d89a, []int{3}
+func (m *VerifyResponse) Reset()         { *m = VerifyResponse{} }
+func (m *VerifyResponse) String() string { return proto.CompactTextString(m) }
+func (*VerifyResponse) ProtoMessage()    {}
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{3}
 }
-func (m *TxIn) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_TxIn.Unmarshal(m, b)
+func (m *VerifyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyResponse.Unmarshal(m, b)
 }
-func (m *TxIn) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_TxIn.Marshal(b, m, deterministic)
+func (m *VerifyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyResponse.Marshal(b, m, deterministic)
 }
-func (dst *TxIn) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_TxIn.Merge(dst, src)
+func (dst *VerifyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyResponse.Merge(dst, src)
 }
-func (m *TxIn) XXX_Size() int {
-	return xxx_messageInfo_TxIn.Size(m)
+func (m *VerifyResponse) XXX_Size() int {
+	return xxx_messageInfo_VerifyResponse.Size(m)
 }
-func (m *TxIn) XXX_DiscardUnknown() {
-	xxx_messageInfo_TxIn.DiscardUnknown(m)
+func (m *VerifyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_TxIn proto.InternalMessageInfo
+var xxx_messageInfo_VerifyResponse proto.InternalMessageInfo
 
-func (m *TxIn) GetTxHash() string {
+func (m *VerifyResponse) GetFromHeight() int64 {
 	if m != nil {
-		return m.TxHash
+		return m.FromHeight
 	}
-	return ""
+	return 0
 }
 
-func (m *TxIn) GetTxIndex() int32 {
+func (m *VerifyResponse) GetToHeight() int64 {
 	if m != nil {
-		return m.TxIndex
+		return m.ToHeight
 	}
 	return 0
 }
 
-func (m *TxIn) GetHeight() int64 {
+func (m *VerifyResponse) GetCheckedBlocks() int64 {
 	if m != nil {
-		return m.Height
+		return m.CheckedBlocks
 	}
 	return 0
 }
 
-func (m *TxIn) GetAddress() string {
+func (m *VerifyResponse) GetIssues() []*VerifyIssue {
 	if m != nil {
-		return m.Address
+		return m.Issues
 	}
-	return ""
+	return nil
 }
 
-func (m *TxIn) GetPreviousTxHash() string {
+func (m *VerifyResponse) GetRepairedHeights() []int64 {
 	if m != nil {
-		return m.PreviousTxHash
+		return m.RepairedHeights
 	}
-	return ""
+	return nil
 }
 
-func (m *TxIn) GetPreviousTxIndex() int32 {
+type GetTransactionRequest struct {
+	Hash                 string   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetTransactionRequest) Reset()         { *m = GetTransactionRequest{} }
+func (m *GetTransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*GetTransactionRequest) ProtoMessage()    {}
+func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{4}
+}
+func (m *GetTransactionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTransactionRequest.Unmarshal(m, b)
+}
+func (m *GetTransactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTransactionRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetTransactionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTransactionRequest.Merge(dst, src)
+}
+func (m *GetTransactionRequest) XXX_Size() int {
+	return xxx_messageInfo_GetTransactionRequest.Size(m)
+}
+func (m *GetTransactionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTransactionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetTransactionRequest proto.InternalMessageInfo
+
+func (m *GetTransactionRequest) GetHash() string {
 	if m != nil {
-		return m.PreviousTxIndex
+		return m.Hash
 	}
-	return 0
+	return ""
 }
 
-type TxOut struct {
-	TxHash               string   `protobuf:"bytes,1,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
-	TxIndex              int32    `protobuf:"varint,2,opt,name=tx_index,json=txIndex,proto3" json:"tx_index,omitempty"`
-	Height               int64    `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
-	Value                int64    `protobuf:"varint,4,opt,name=value,proto3" json:"value,omitempty"`
-	Address              string   `protobuf:"bytes,5,opt,name=address,proto3" json:"address,omitempty"`
-	ScriptPubKey         string   `protobuf:"bytes,6,opt,name=script_pub_key,json=scriptPubKey,proto3" json:"script_pub_key,omitempty"`
-	CoinBase             bool     `protobuf:"varint,7,opt,name=coin_base,json=coinBase,proto3" json:"coin_base,omitempty"`
+type GetTransactionResponse struct {
+	Hash                 string   `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	CoinBase             bool     `protobuf:"varint,2,opt,name=coin_base,json=coinBase,proto3" json:"coin_base,omitempty"`
+	Block                *Block   `protobuf:"bytes,3,opt,name=block,proto3" json:"block,omitempty"`
+	Confirmations        int64    `protobuf:"varint,4,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	TxIns                []*TxIn  `protobuf:"bytes,5,rep,name=tx_ins,json=txIns,proto3" json:"tx_ins,omitempty"`
+	TxOuts               []*TxOut `protobuf:"bytes,6,rep,name=tx_outs,json=txOuts,proto3" json:"tx_outs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *TxOut) Reset()         { *m = TxOut{} }
-func (m *TxOut) String() string { return proto.CompactTextString(m) }
-func (*TxOut) ProtoMessage()    {}
-func (*TxOut) Descriptor() ([]byte, []int) {
-	return fileDescriptor_btc_indexer_e6885b9d3d85d89a, []int{4}
+func (m *GetTransactionResponse) Reset()         { *m = GetTransactionResponse{} }
+func (m *GetTransactionResponse) String() string { return proto.CompactTextString(m) }
+func (*GetTransactionResponse) ProtoMessage()    {}
+func (*GetTransactionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{5}
 }
-func (m *TxOut) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_TxOut.Unmarshal(m, b)
+func (m *GetTransactionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetTransactionResponse.Unmarshal(m, b)
 }
-func (m *TxOut) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_TxOut.Marshal(b, m, deterministic)
+func (m *GetTransactionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetTransactionResponse.Marshal(b, m, deterministic)
 }
-func (dst *TxOut) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_TxOut.Merge(dst, src)
+func (dst *GetTransactionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetTransactionResponse.Merge(dst, src)
 }
-func (m *TxOut) XXX_Size() int {
-	return xxx_messageInfo_TxOut.Size(m)
+func (m *GetTransactionResponse) XXX_Size() int {
+	return xxx_messageInfo_GetTransactionResponse.Size(m)
 }
-func (m *TxOut) XXX_DiscardUnknown() {
-	xxx_messageInfo_TxOut.DiscardUnknown(m)
+func (m *GetTransactionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetTransactionResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_TxOut proto.InternalMessageInfo
+var xxx_messageInfo_GetTransactionResponse proto.InternalMessageInfo
 
-func (m *TxOut) GetTxHash() string {
+func (m *GetTransactionResponse) GetHash() string {
 	if m != nil {
-		return m.TxHash
+		return m.Hash
 	}
 	return ""
 }
 
-func (m *TxOut) GetTxIndex() int32 {
+func (m *GetTransactionResponse) GetCoinBase() bool {
 	if m != nil {
-		return m.TxIndex
+		return m.CoinBase
 	}
-	return 0
+	return false
 }
 
-func (m *TxOut) GetHeight() int64 {
+func (m *GetTransactionResponse) GetBlock() *Block {
 	if m != nil {
-		return m.Height
+		return m.Block
 	}
-	return 0
+	return nil
 }
 
-func (m *TxOut) GetValue() int64 {
+func (m *GetTransactionResponse) GetConfirmations() int64 {
 	if m != nil {
-		return m.Value
+		return m.Confirmations
 	}
 	return 0
 }
 
-func (m *TxOut) GetAddress() string {
+func (m *GetTransactionResponse) GetTxIns() []*TxIn {
 	if m != nil {
-		return m.Address
+		return m.TxIns
 	}
-	return ""
+	return nil
 }
 
-func (m *TxOut) GetScriptPubKey() string {
+func (m *GetTransactionResponse) GetTxOuts() []*TxOut {
 	if m != nil {
-		return m.ScriptPubKey
+		return m.TxOuts
 	}
-	return ""
+	return nil
 }
 
-func (m *TxOut) GetCoinBase() bool {
-	if m != nil {
-		return m.CoinBase
-	}
-	return false
+type GetAddressHistoryRequest struct {
+	Address    string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	FromHeight int64  `protobuf:"varint,2,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	// No upper bound if 0
+	ToHeight int64 `protobuf:"varint,3,opt,name=to_height,json=toHeight,proto3" json:"to_height,omitempty"`
+	// Opaque cursor of the next page, given by the previous response
+	Cursor               string   `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                int32    `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Descending           bool     `protobuf:"varint,6,opt,name=descending,proto3" json:"descending,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func init() {
-	proto.RegisterType((*SyncRequest)(nil), "btcindexersrv.SyncRequest")
-	proto.RegisterType((*SyncResponse)(nil), "btcindexersrv.SyncResponse")
-	proto.RegisterType((*SyncResponse_BeginStream)(nil), "btcindexersrv.SyncResponse.BeginStream")
-	proto.RegisterType((*SyncResponse_EndStream)(nil), "btcindexersrv.SyncResponse.EndStream")
-	proto.RegisterType((*SyncResponse_SyncBlock)(nil), "btcindexersrv.SyncResponse.SyncBlock")
-	proto.RegisterType((*SyncResponse_ReorgBlock)(nil), "btcindexersrv.SyncResponse.ReorgBlock")
-	proto.RegisterType((*Block)(nil), "btcindexersrv.Block")
-	proto.RegisterType((*TxIn)(nil), "btcindexersrv.TxIn")
-	proto.RegisterType((*TxOut)(nil), "btcindexersrv.TxOut")
+func (m *GetAddressHistoryRequest) Reset()         { *m = GetAddressHistoryRequest{} }
+func (m *GetAddressHistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*GetAddressHistoryRequest) ProtoMessage()    {}
+func (*GetAddressHistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{6}
+}
+func (m *GetAddressHistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAddressHistoryRequest.Unmarshal(m, b)
+}
+func (m *GetAddressHistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAddressHistoryRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetAddressHistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAddressHistoryRequest.Merge(dst, src)
+}
+func (m *GetAddressHistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_GetAddressHistoryRequest.Size(m)
+}
+func (m *GetAddressHistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAddressHistoryRequest.DiscardUnknown(m)
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+var xxx_messageInfo_GetAddressHistoryRequest proto.InternalMessageInfo
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *GetAddressHistoryRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
 
-// BtcIndexerClient is the client API for BtcIndexer service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type BtcIndexerClient interface {
-	Sync(ctx context.Context, opts ...grpc.CallOption) (BtcIndexer_SyncClient, error)
+func (m *GetAddressHistoryRequest) GetFromHeight() int64 {
+	if m != nil {
+		return m.FromHeight
+	}
+	return 0
 }
 
-type btcIndexerClient struct {
-	cc *grpc.ClientConn
+func (m *GetAddressHistoryRequest) GetToHeight() int64 {
+	if m != nil {
+		return m.ToHeight
+	}
+	return 0
 }
 
-func NewBtcIndexerClient(cc *grpc.ClientConn) BtcIndexerClient {
-	return &btcIndexerClient{cc}
+func (m *GetAddressHistoryRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
 }
 
-func (c *btcIndexerClient) Sync(ctx context.Context, opts ...grpc.CallOption) (BtcIndexer_SyncClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_BtcIndexer_serviceDesc.Streams[0], "/btcindexersrv.BtcIndexer/Sync", opts...)
-	if err != nil {
-		return nil, err
+func (m *GetAddressHistoryRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
 	}
-	x := &btcIndexerSyncClient{stream}
-	return x, nil
+	return 0
 }
 
-type BtcIndexer_SyncClient interface {
-	Send(*SyncRequest) error
-	Recv() (*SyncResponse, error)
-	grpc.ClientStream
+func (m *GetAddressHistoryRequest) GetDescending() bool {
+	if m != nil {
+		return m.Descending
+	}
+	return false
 }
 
-type btcIndexerSyncClient struct {
-	grpc.ClientStream
+type GetAddressHistoryResponse struct {
+	Txs []*GetAddressHistoryResponse_AddressTx `protobuf:"bytes,1,rep,name=txs,proto3" json:"txs,omitempty"`
+	// Empty for the last page
+	NextCursor           string   `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (x *btcIndexerSyncClient) Send(m *SyncRequest) error {
-	return x.ClientStream.SendMsg(m)
+func (m *GetAddressHistoryResponse) Reset()         { *m = GetAddressHistoryResponse{} }
+func (m *GetAddressHistoryResponse) String() string { return proto.CompactTextString(m) }
+func (*GetAddressHistoryResponse) ProtoMessage()    {}
+func (*GetAddressHistoryResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{7}
+}
+func (m *GetAddressHistoryResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAddressHistoryResponse.Unmarshal(m, b)
+}
+func (m *GetAddressHistoryResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAddressHistoryResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetAddressHistoryResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAddressHistoryResponse.Merge(dst, src)
+}
+func (m *GetAddressHistoryResponse) XXX_Size() int {
+	return xxx_messageInfo_GetAddressHistoryResponse.Size(m)
+}
+func (m *GetAddressHistoryResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAddressHistoryResponse.DiscardUnknown(m)
 }
 
-func (x *btcIndexerSyncClient) Recv() (*SyncResponse, error) {
-	m := new(SyncResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+var xxx_messageInfo_GetAddressHistoryResponse proto.InternalMessageInfo
+
+func (m *GetAddressHistoryResponse) GetTxs() []*GetAddressHistoryResponse_AddressTx {
+	if m != nil {
+		return m.Txs
 	}
-	return m, nil
+	return nil
 }
 
-// BtcIndexerServer is the server API for BtcIndexer service.
-type BtcIndexerServer interface {
-	Sync(BtcIndexer_SyncServer) error
+func (m *GetAddressHistoryResponse) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
 }
 
-func RegisterBtcIndexerServer(s *grpc.Server, srv BtcIndexerServer) {
-	s.RegisterService(&_BtcIndexer_serviceDesc, srv)
+type GetAddressHistoryResponse_AddressTx struct {
+	// Types that are valid to be assigned to Tx:
+	//	*GetAddressHistoryResponse_AddressTx_TxIn
+	//	*GetAddressHistoryResponse_AddressTx_TxOut
+	Tx                   isGetAddressHistoryResponse_AddressTx_Tx `protobuf_oneof:"tx"`
+	XXX_NoUnkeyedLiteral struct{}                                 `json:"-"`
+	XXX_unrecognized     []byte                                   `json:"-"`
+	XXX_sizecache        int32                                    `json:"-"`
 }
 
-func _BtcIndexer_Sync_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(BtcIndexerServer).Sync(&btcIndexerSyncServer{stream})
+func (m *GetAddressHistoryResponse_AddressTx) Reset()         { *m = GetAddressHistoryResponse_AddressTx{} }
+func (m *GetAddressHistoryResponse_AddressTx) String() string { return proto.CompactTextString(m) }
+func (*GetAddressHistoryResponse_AddressTx) ProtoMessage()    {}
+func (*GetAddressHistoryResponse_AddressTx) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{7, 0}
 }
-
-type BtcIndexer_SyncServer interface {
-	Send(*SyncResponse) error
-	Recv() (*SyncRequest, error)
-	grpc.ServerStream
+func (m *GetAddressHistoryResponse_AddressTx) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAddressHistoryResponse_AddressTx.Unmarshal(m, b)
 }
-
-type btcIndexerSyncServer struct {
+func (m *GetAddressHistoryResponse_AddressTx) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAddressHistoryResponse_AddressTx.Marshal(b, m, deterministic)
+}
+func (dst *GetAddressHistoryResponse_AddressTx) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAddressHistoryResponse_AddressTx.Merge(dst, src)
+}
+func (m *GetAddressHistoryResponse_AddressTx) XXX_Size() int {
+	return xxx_messageInfo_GetAddressHistoryResponse_AddressTx.Size(m)
+}
+func (m *GetAddressHistoryResponse_AddressTx) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAddressHistoryResponse_AddressTx.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAddressHistoryResponse_AddressTx proto.InternalMessageInfo
+
+type isGetAddressHistoryResponse_AddressTx_Tx interface {
+	isGetAddressHistoryResponse_AddressTx_Tx()
+}
+
+type GetAddressHistoryResponse_AddressTx_TxIn struct {
+	TxIn *TxIn `protobuf:"bytes,1,opt,name=tx_in,json=txIn,proto3,oneof"`
+}
+
+type GetAddressHistoryResponse_AddressTx_TxOut struct {
+	TxOut *TxOut `protobuf:"bytes,2,opt,name=tx_out,json=txOut,proto3,oneof"`
+}
+
+func (*GetAddressHistoryResponse_AddressTx_TxIn) isGetAddressHistoryResponse_AddressTx_Tx() {}
+
+func (*GetAddressHistoryResponse_AddressTx_TxOut) isGetAddressHistoryResponse_AddressTx_Tx() {}
+
+func (m *GetAddressHistoryResponse_AddressTx) GetTx() isGetAddressHistoryResponse_AddressTx_Tx {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+func (m *GetAddressHistoryResponse_AddressTx) GetTxIn() *TxIn {
+	if x, ok := m.GetTx().(*GetAddressHistoryResponse_AddressTx_TxIn); ok {
+		return x.TxIn
+	}
+	return nil
+}
+
+func (m *GetAddressHistoryResponse_AddressTx) GetTxOut() *TxOut {
+	if x, ok := m.GetTx().(*GetAddressHistoryResponse_AddressTx_TxOut); ok {
+		return x.TxOut
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*GetAddressHistoryResponse_AddressTx) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _GetAddressHistoryResponse_AddressTx_OneofMarshaler, _GetAddressHistoryResponse_AddressTx_OneofUnmarshaler, _GetAddressHistoryResponse_AddressTx_OneofSizer, []interface{}{
+		(*GetAddressHistoryResponse_AddressTx_TxIn)(nil),
+		(*GetAddressHistoryResponse_AddressTx_TxOut)(nil),
+	}
+}
+
+func _GetAddressHistoryResponse_AddressTx_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*GetAddressHistoryResponse_AddressTx)
+	// tx
+	switch x := m.Tx.(type) {
+	case *GetAddressHistoryResponse_AddressTx_TxIn:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TxIn); err != nil {
+			return err
+		}
+	case *GetAddressHistoryResponse_AddressTx_TxOut:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TxOut); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("GetAddressHistoryResponse_AddressTx.Tx has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _GetAddressHistoryResponse_AddressTx_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*GetAddressHistoryResponse_AddressTx)
+	switch tag {
+	case 1: // tx.tx_in
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TxIn)
+		err := b.DecodeMessage(msg)
+		m.Tx = &GetAddressHistoryResponse_AddressTx_TxIn{msg}
+		return true, err
+	case 2: // tx.tx_out
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TxOut)
+		err := b.DecodeMessage(msg)
+		m.Tx = &GetAddressHistoryResponse_AddressTx_TxOut{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _GetAddressHistoryResponse_AddressTx_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*GetAddressHistoryResponse_AddressTx)
+	// tx
+	switch x := m.Tx.(type) {
+	case *GetAddressHistoryResponse_AddressTx_TxIn:
+		s := proto.Size(x.TxIn)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *GetAddressHistoryResponse_AddressTx_TxOut:
+		s := proto.Size(x.TxOut)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type GetUTXOsRequest struct {
+	Addresses []string `protobuf:"bytes,1,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	// Opaque cursor of the next page, given by the previous response
+	Cursor               string   `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetUTXOsRequest) Reset()         { *m = GetUTXOsRequest{} }
+func (m *GetUTXOsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetUTXOsRequest) ProtoMessage()    {}
+func (*GetUTXOsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{8}
+}
+func (m *GetUTXOsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetUTXOsRequest.Unmarshal(m, b)
+}
+func (m *GetUTXOsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetUTXOsRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetUTXOsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetUTXOsRequest.Merge(dst, src)
+}
+func (m *GetUTXOsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetUTXOsRequest.Size(m)
+}
+func (m *GetUTXOsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetUTXOsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetUTXOsRequest proto.InternalMessageInfo
+
+func (m *GetUTXOsRequest) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *GetUTXOsRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *GetUTXOsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type GetUTXOsResponse struct {
+	Utxos []*UTXO `protobuf:"bytes,1,rep,name=utxos,proto3" json:"utxos,omitempty"`
+	// Empty for the last page
+	NextCursor           string   `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetUTXOsResponse) Reset()         { *m = GetUTXOsResponse{} }
+func (m *GetUTXOsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetUTXOsResponse) ProtoMessage()    {}
+func (*GetUTXOsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{9}
+}
+func (m *GetUTXOsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetUTXOsResponse.Unmarshal(m, b)
+}
+func (m *GetUTXOsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetUTXOsResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetUTXOsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetUTXOsResponse.Merge(dst, src)
+}
+func (m *GetUTXOsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetUTXOsResponse.Size(m)
+}
+func (m *GetUTXOsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetUTXOsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetUTXOsResponse proto.InternalMessageInfo
+
+func (m *GetUTXOsResponse) GetUtxos() []*UTXO {
+	if m != nil {
+		return m.Utxos
+	}
+	return nil
+}
+
+func (m *GetUTXOsResponse) GetNextCursor() string {
+	if m != nil {
+		return m.NextCursor
+	}
+	return ""
+}
+
+type GetAddressBalanceRequest struct {
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// Balance after the block at the height, the latest one if 0
+	Height               int64    `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetAddressBalanceRequest) Reset()         { *m = GetAddressBalanceRequest{} }
+func (m *GetAddressBalanceRequest) String() string { return proto.CompactTextString(m) }
+func (*GetAddressBalanceRequest) ProtoMessage()    {}
+func (*GetAddressBalanceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{10}
+}
+func (m *GetAddressBalanceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAddressBalanceRequest.Unmarshal(m, b)
+}
+func (m *GetAddressBalanceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAddressBalanceRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetAddressBalanceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAddressBalanceRequest.Merge(dst, src)
+}
+func (m *GetAddressBalanceRequest) XXX_Size() int {
+	return xxx_messageInfo_GetAddressBalanceRequest.Size(m)
+}
+func (m *GetAddressBalanceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAddressBalanceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAddressBalanceRequest proto.InternalMessageInfo
+
+func (m *GetAddressBalanceRequest) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *GetAddressBalanceRequest) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+type GetAddressBalanceResponse struct {
+	Address              string   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Received             int64    `protobuf:"varint,2,opt,name=received,proto3" json:"received,omitempty"`
+	Sent                 int64    `protobuf:"varint,3,opt,name=sent,proto3" json:"sent,omitempty"`
+	Balance              int64    `protobuf:"varint,4,opt,name=balance,proto3" json:"balance,omitempty"`
+	TxNo                 int64    `protobuf:"varint,5,opt,name=tx_no,json=txNo,proto3" json:"tx_no,omitempty"`
+	FirstSeenHeight      int64    `protobuf:"varint,6,opt,name=first_seen_height,json=firstSeenHeight,proto3" json:"first_seen_height,omitempty"`
+	LastSeenHeight       int64    `protobuf:"varint,7,opt,name=last_seen_height,json=lastSeenHeight,proto3" json:"last_seen_height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetAddressBalanceResponse) Reset()         { *m = GetAddressBalanceResponse{} }
+func (m *GetAddressBalanceResponse) String() string { return proto.CompactTextString(m) }
+func (*GetAddressBalanceResponse) ProtoMessage()    {}
+func (*GetAddressBalanceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{11}
+}
+func (m *GetAddressBalanceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAddressBalanceResponse.Unmarshal(m, b)
+}
+func (m *GetAddressBalanceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAddressBalanceResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetAddressBalanceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAddressBalanceResponse.Merge(dst, src)
+}
+func (m *GetAddressBalanceResponse) XXX_Size() int {
+	return xxx_messageInfo_GetAddressBalanceResponse.Size(m)
+}
+func (m *GetAddressBalanceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAddressBalanceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAddressBalanceResponse proto.InternalMessageInfo
+
+func (m *GetAddressBalanceResponse) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *GetAddressBalanceResponse) GetReceived() int64 {
+	if m != nil {
+		return m.Received
+	}
+	return 0
+}
+
+func (m *GetAddressBalanceResponse) GetSent() int64 {
+	if m != nil {
+		return m.Sent
+	}
+	return 0
+}
+
+func (m *GetAddressBalanceResponse) GetBalance() int64 {
+	if m != nil {
+		return m.Balance
+	}
+	return 0
+}
+
+func (m *GetAddressBalanceResponse) GetTxNo() int64 {
+	if m != nil {
+		return m.TxNo
+	}
+	return 0
+}
+
+func (m *GetAddressBalanceResponse) GetFirstSeenHeight() int64 {
+	if m != nil {
+		return m.FirstSeenHeight
+	}
+	return 0
+}
+
+func (m *GetAddressBalanceResponse) GetLastSeenHeight() int64 {
+	if m != nil {
+		return m.LastSeenHeight
+	}
+	return 0
+}
+
+type GetReorgsRequest struct {
+	// Reorgs orphaning a block from height 'from_height', to 'to_height' or no upper bound if 0
+	FromHeight int64 `protobuf:"varint,1,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	ToHeight   int64 `protobuf:"varint,2,opt,name=to_height,json=toHeight,proto3" json:"to_height,omitempty"`
+	// Unix times in seconds, no bound if 0
+	FromTime             int64    `protobuf:"varint,3,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"`
+	ToTime               int64    `protobuf:"varint,4,opt,name=to_time,json=toTime,proto3" json:"to_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetReorgsRequest) Reset()         { *m = GetReorgsRequest{} }
+func (m *GetReorgsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetReorgsRequest) ProtoMessage()    {}
+func (*GetReorgsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{12}
+}
+func (m *GetReorgsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetReorgsRequest.Unmarshal(m, b)
+}
+func (m *GetReorgsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetReorgsRequest.Marshal(b, m, deterministic)
+}
+func (dst *GetReorgsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetReorgsRequest.Merge(dst, src)
+}
+func (m *GetReorgsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetReorgsRequest.Size(m)
+}
+func (m *GetReorgsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetReorgsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetReorgsRequest proto.InternalMessageInfo
+
+func (m *GetReorgsRequest) GetFromHeight() int64 {
+	if m != nil {
+		return m.FromHeight
+	}
+	return 0
+}
+
+func (m *GetReorgsRequest) GetToHeight() int64 {
+	if m != nil {
+		return m.ToHeight
+	}
+	return 0
+}
+
+func (m *GetReorgsRequest) GetFromTime() int64 {
+	if m != nil {
+		return m.FromTime
+	}
+	return 0
+}
+
+func (m *GetReorgsRequest) GetToTime() int64 {
+	if m != nil {
+		return m.ToTime
+	}
+	return 0
+}
+
+type GetReorgsResponse struct {
+	Reorgs               []*Reorg `protobuf:"bytes,1,rep,name=reorgs,proto3" json:"reorgs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetReorgsResponse) Reset()         { *m = GetReorgsResponse{} }
+func (m *GetReorgsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetReorgsResponse) ProtoMessage()    {}
+func (*GetReorgsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{13}
+}
+func (m *GetReorgsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetReorgsResponse.Unmarshal(m, b)
+}
+func (m *GetReorgsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetReorgsResponse.Marshal(b, m, deterministic)
+}
+func (dst *GetReorgsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetReorgsResponse.Merge(dst, src)
+}
+func (m *GetReorgsResponse) XXX_Size() int {
+	return xxx_messageInfo_GetReorgsResponse.Size(m)
+}
+func (m *GetReorgsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetReorgsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetReorgsResponse proto.InternalMessageInfo
+
+func (m *GetReorgsResponse) GetReorgs() []*Reorg {
+	if m != nil {
+		return m.Reorgs
+	}
+	return nil
+}
+
+type AddWatchedAddressesRequest struct {
+	List                 string   `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Addresses            []string `protobuf:"bytes,2,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddWatchedAddressesRequest) Reset()         { *m = AddWatchedAddressesRequest{} }
+func (m *AddWatchedAddressesRequest) String() string { return proto.CompactTextString(m) }
+func (*AddWatchedAddressesRequest) ProtoMessage()    {}
+func (*AddWatchedAddressesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{14}
+}
+func (m *AddWatchedAddressesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddWatchedAddressesRequest.Unmarshal(m, b)
+}
+func (m *AddWatchedAddressesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddWatchedAddressesRequest.Marshal(b, m, deterministic)
+}
+func (dst *AddWatchedAddressesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddWatchedAddressesRequest.Merge(dst, src)
+}
+func (m *AddWatchedAddressesRequest) XXX_Size() int {
+	return xxx_messageInfo_AddWatchedAddressesRequest.Size(m)
+}
+func (m *AddWatchedAddressesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddWatchedAddressesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddWatchedAddressesRequest proto.InternalMessageInfo
+
+func (m *AddWatchedAddressesRequest) GetList() string {
+	if m != nil {
+		return m.List
+	}
+	return ""
+}
+
+func (m *AddWatchedAddressesRequest) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+type AddWatchedAddressesResponse struct {
+	// Number of addresses not in the list before
+	Added                int64    `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddWatchedAddressesResponse) Reset()         { *m = AddWatchedAddressesResponse{} }
+func (m *AddWatchedAddressesResponse) String() string { return proto.CompactTextString(m) }
+func (*AddWatchedAddressesResponse) ProtoMessage()    {}
+func (*AddWatchedAddressesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{15}
+}
+func (m *AddWatchedAddressesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddWatchedAddressesResponse.Unmarshal(m, b)
+}
+func (m *AddWatchedAddressesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddWatchedAddressesResponse.Marshal(b, m, deterministic)
+}
+func (dst *AddWatchedAddressesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddWatchedAddressesResponse.Merge(dst, src)
+}
+func (m *AddWatchedAddressesResponse) XXX_Size() int {
+	return xxx_messageInfo_AddWatchedAddressesResponse.Size(m)
+}
+func (m *AddWatchedAddressesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddWatchedAddressesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddWatchedAddressesResponse proto.InternalMessageInfo
+
+func (m *AddWatchedAddressesResponse) GetAdded() int64 {
+	if m != nil {
+		return m.Added
+	}
+	return 0
+}
+
+type RemoveWatchedAddressesRequest struct {
+	List                 string   `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Addresses            []string `protobuf:"bytes,2,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveWatchedAddressesRequest) Reset()         { *m = RemoveWatchedAddressesRequest{} }
+func (m *RemoveWatchedAddressesRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveWatchedAddressesRequest) ProtoMessage()    {}
+func (*RemoveWatchedAddressesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{16}
+}
+func (m *RemoveWatchedAddressesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveWatchedAddressesRequest.Unmarshal(m, b)
+}
+func (m *RemoveWatchedAddressesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveWatchedAddressesRequest.Marshal(b, m, deterministic)
+}
+func (dst *RemoveWatchedAddressesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveWatchedAddressesRequest.Merge(dst, src)
+}
+func (m *RemoveWatchedAddressesRequest) XXX_Size() int {
+	return xxx_messageInfo_RemoveWatchedAddressesRequest.Size(m)
+}
+func (m *RemoveWatchedAddressesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveWatchedAddressesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveWatchedAddressesRequest proto.InternalMessageInfo
+
+func (m *RemoveWatchedAddressesRequest) GetList() string {
+	if m != nil {
+		return m.List
+	}
+	return ""
+}
+
+func (m *RemoveWatchedAddressesRequest) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+type RemoveWatchedAddressesResponse struct {
+	// Number of addresses in the list before
+	Removed              int64    `protobuf:"varint,1,opt,name=removed,proto3" json:"removed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveWatchedAddressesResponse) Reset()         { *m = RemoveWatchedAddressesResponse{} }
+func (m *RemoveWatchedAddressesResponse) String() string { return proto.CompactTextString(m) }
+func (*RemoveWatchedAddressesResponse) ProtoMessage()    {}
+func (*RemoveWatchedAddressesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{17}
+}
+func (m *RemoveWatchedAddressesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveWatchedAddressesResponse.Unmarshal(m, b)
+}
+func (m *RemoveWatchedAddressesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveWatchedAddressesResponse.Marshal(b, m, deterministic)
+}
+func (dst *RemoveWatchedAddressesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveWatchedAddressesResponse.Merge(dst, src)
+}
+func (m *RemoveWatchedAddressesResponse) XXX_Size() int {
+	return xxx_messageInfo_RemoveWatchedAddressesResponse.Size(m)
+}
+func (m *RemoveWatchedAddressesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveWatchedAddressesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveWatchedAddressesResponse proto.InternalMessageInfo
+
+func (m *RemoveWatchedAddressesResponse) GetRemoved() int64 {
+	if m != nil {
+		return m.Removed
+	}
+	return 0
+}
+
+type ListWatchedAddressesRequest struct {
+	// All lists if empty
+	List                 string   `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListWatchedAddressesRequest) Reset()         { *m = ListWatchedAddressesRequest{} }
+func (m *ListWatchedAddressesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListWatchedAddressesRequest) ProtoMessage()    {}
+func (*ListWatchedAddressesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{18}
+}
+func (m *ListWatchedAddressesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListWatchedAddressesRequest.Unmarshal(m, b)
+}
+func (m *ListWatchedAddressesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListWatchedAddressesRequest.Marshal(b, m, deterministic)
+}
+func (dst *ListWatchedAddressesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListWatchedAddressesRequest.Merge(dst, src)
+}
+func (m *ListWatchedAddressesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListWatchedAddressesRequest.Size(m)
+}
+func (m *ListWatchedAddressesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListWatchedAddressesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListWatchedAddressesRequest proto.InternalMessageInfo
+
+func (m *ListWatchedAddressesRequest) GetList() string {
+	if m != nil {
+		return m.List
+	}
+	return ""
+}
+
+type ListWatchedAddressesResponse struct {
+	Lists                []*WatchList `protobuf:"bytes,1,rep,name=lists,proto3" json:"lists,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ListWatchedAddressesResponse) Reset()         { *m = ListWatchedAddressesResponse{} }
+func (m *ListWatchedAddressesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListWatchedAddressesResponse) ProtoMessage()    {}
+func (*ListWatchedAddressesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{19}
+}
+func (m *ListWatchedAddressesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListWatchedAddressesResponse.Unmarshal(m, b)
+}
+func (m *ListWatchedAddressesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListWatchedAddressesResponse.Marshal(b, m, deterministic)
+}
+func (dst *ListWatchedAddressesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListWatchedAddressesResponse.Merge(dst, src)
+}
+func (m *ListWatchedAddressesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListWatchedAddressesResponse.Size(m)
+}
+func (m *ListWatchedAddressesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListWatchedAddressesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListWatchedAddressesResponse proto.InternalMessageInfo
+
+func (m *ListWatchedAddressesResponse) GetLists() []*WatchList {
+	if m != nil {
+		return m.Lists
+	}
+	return nil
+}
+
+// Data messages
+type Block struct {
+	Height               int64    `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Hash                 string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	PreviousHash         string   `protobuf:"bytes,3,opt,name=previous_hash,json=previousHash,proto3" json:"previous_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Block) Reset()         { *m = Block{} }
+func (m *Block) String() string { return proto.CompactTextString(m) }
+func (*Block) ProtoMessage()    {}
+func (*Block) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{20}
+}
+func (m *Block) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Block.Unmarshal(m, b)
+}
+func (m *Block) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Block.Marshal(b, m, deterministic)
+}
+func (dst *Block) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Block.Merge(dst, src)
+}
+func (m *Block) XXX_Size() int {
+	return xxx_messageInfo_Block.Size(m)
+}
+func (m *Block) XXX_DiscardUnknown() {
+	xxx_messageInfo_Block.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Block proto.InternalMessageInfo
+
+func (m *Block) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *Block) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *Block) GetPreviousHash() string {
+	if m != nil {
+		return m.PreviousHash
+	}
+	return ""
+}
+
+type TxIn struct {
+	TxHash               string   `protobuf:"bytes,1,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	TxIndex              int32    `protobuf:"varint,2,opt,name=tx_index,json=txIndex,proto3" json:"tx_index,omitempty"`
+	Height               int64    `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Address              string   `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	PreviousTxHash       string   `protobuf:"bytes,5,opt,name=previous_tx_hash,json=previousTxHash,proto3" json:"previous_tx_hash,omitempty"`
+	PreviousTxIndex      int32    `protobuf:"varint,6,opt,name=previous_tx_index,json=previousTxIndex,proto3" json:"previous_tx_index,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TxIn) Reset()         { *m = TxIn{} }
+func (m *TxIn) String() string { return proto.CompactTextString(m) }
+func (*TxIn) ProtoMessage()    {}
+func (*TxIn) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{21}
+}
+func (m *TxIn) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxIn.Unmarshal(m, b)
+}
+func (m *TxIn) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxIn.Marshal(b, m, deterministic)
+}
+func (dst *TxIn) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxIn.Merge(dst, src)
+}
+func (m *TxIn) XXX_Size() int {
+	return xxx_messageInfo_TxIn.Size(m)
+}
+func (m *TxIn) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxIn.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TxIn proto.InternalMessageInfo
+
+func (m *TxIn) GetTxHash() string {
+	if m != nil {
+		return m.TxHash
+	}
+	return ""
+}
+
+func (m *TxIn) GetTxIndex() int32 {
+	if m != nil {
+		return m.TxIndex
+	}
+	return 0
+}
+
+func (m *TxIn) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *TxIn) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *TxIn) GetPreviousTxHash() string {
+	if m != nil {
+		return m.PreviousTxHash
+	}
+	return ""
+}
+
+func (m *TxIn) GetPreviousTxIndex() int32 {
+	if m != nil {
+		return m.PreviousTxIndex
+	}
+	return 0
+}
+
+type TxOut struct {
+	TxHash               string   `protobuf:"bytes,1,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	TxIndex              int32    `protobuf:"varint,2,opt,name=tx_index,json=txIndex,proto3" json:"tx_index,omitempty"`
+	Height               int64    `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Value                int64    `protobuf:"varint,4,opt,name=value,proto3" json:"value,omitempty"`
+	Address              string   `protobuf:"bytes,5,opt,name=address,proto3" json:"address,omitempty"`
+	ScriptPubKey         string   `protobuf:"bytes,6,opt,name=script_pub_key,json=scriptPubKey,proto3" json:"script_pub_key,omitempty"`
+	CoinBase             bool     `protobuf:"varint,7,opt,name=coin_base,json=coinBase,proto3" json:"coin_base,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TxOut) Reset()         { *m = TxOut{} }
+func (m *TxOut) String() string { return proto.CompactTextString(m) }
+func (*TxOut) ProtoMessage()    {}
+func (*TxOut) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{22}
+}
+func (m *TxOut) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TxOut.Unmarshal(m, b)
+}
+func (m *TxOut) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TxOut.Marshal(b, m, deterministic)
+}
+func (dst *TxOut) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TxOut.Merge(dst, src)
+}
+func (m *TxOut) XXX_Size() int {
+	return xxx_messageInfo_TxOut.Size(m)
+}
+func (m *TxOut) XXX_DiscardUnknown() {
+	xxx_messageInfo_TxOut.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TxOut proto.InternalMessageInfo
+
+func (m *TxOut) GetTxHash() string {
+	if m != nil {
+		return m.TxHash
+	}
+	return ""
+}
+
+func (m *TxOut) GetTxIndex() int32 {
+	if m != nil {
+		return m.TxIndex
+	}
+	return 0
+}
+
+func (m *TxOut) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *TxOut) GetValue() int64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *TxOut) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *TxOut) GetScriptPubKey() string {
+	if m != nil {
+		return m.ScriptPubKey
+	}
+	return ""
+}
+
+func (m *TxOut) GetCoinBase() bool {
+	if m != nil {
+		return m.CoinBase
+	}
+	return false
+}
+
+type VerifyIssue struct {
+	Height               int64    `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Kind                 string   `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Expected             string   `protobuf:"bytes,3,opt,name=expected,proto3" json:"expected,omitempty"`
+	Actual               string   `protobuf:"bytes,4,opt,name=actual,proto3" json:"actual,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyIssue) Reset()         { *m = VerifyIssue{} }
+func (m *VerifyIssue) String() string { return proto.CompactTextString(m) }
+func (*VerifyIssue) ProtoMessage()    {}
+func (*VerifyIssue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{23}
+}
+func (m *VerifyIssue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyIssue.Unmarshal(m, b)
+}
+func (m *VerifyIssue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyIssue.Marshal(b, m, deterministic)
+}
+func (dst *VerifyIssue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyIssue.Merge(dst, src)
+}
+func (m *VerifyIssue) XXX_Size() int {
+	return xxx_messageInfo_VerifyIssue.Size(m)
+}
+func (m *VerifyIssue) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyIssue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyIssue proto.InternalMessageInfo
+
+func (m *VerifyIssue) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *VerifyIssue) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *VerifyIssue) GetExpected() string {
+	if m != nil {
+		return m.Expected
+	}
+	return ""
+}
+
+func (m *VerifyIssue) GetActual() string {
+	if m != nil {
+		return m.Actual
+	}
+	return ""
+}
+
+type UTXO struct {
+	TxOut         *TxOut `protobuf:"bytes,1,opt,name=tx_out,json=txOut,proto3" json:"tx_out,omitempty"`
+	Confirmations int64  `protobuf:"varint,2,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	// A coinbase tx out is spendable once mature
+	Mature               bool     `protobuf:"varint,3,opt,name=mature,proto3" json:"mature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UTXO) Reset()         { *m = UTXO{} }
+func (m *UTXO) String() string { return proto.CompactTextString(m) }
+func (*UTXO) ProtoMessage()    {}
+func (*UTXO) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{24}
+}
+func (m *UTXO) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UTXO.Unmarshal(m, b)
+}
+func (m *UTXO) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UTXO.Marshal(b, m, deterministic)
+}
+func (dst *UTXO) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UTXO.Merge(dst, src)
+}
+func (m *UTXO) XXX_Size() int {
+	return xxx_messageInfo_UTXO.Size(m)
+}
+func (m *UTXO) XXX_DiscardUnknown() {
+	xxx_messageInfo_UTXO.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UTXO proto.InternalMessageInfo
+
+func (m *UTXO) GetTxOut() *TxOut {
+	if m != nil {
+		return m.TxOut
+	}
+	return nil
+}
+
+func (m *UTXO) GetConfirmations() int64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+func (m *UTXO) GetMature() bool {
+	if m != nil {
+		return m.Mature
+	}
+	return false
+}
+
+type Reorg struct {
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// First orphaned block
+	FromHeight int64  `protobuf:"varint,2,opt,name=from_height,json=fromHeight,proto3" json:"from_height,omitempty"`
+	FromHash   string `protobuf:"bytes,3,opt,name=from_hash,json=fromHash,proto3" json:"from_hash,omitempty"`
+	// Number of orphaned blocks
+	Depth        int64  `protobuf:"varint,4,opt,name=depth,proto3" json:"depth,omitempty"`
+	OldTipHeight int64  `protobuf:"varint,5,opt,name=old_tip_height,json=oldTipHeight,proto3" json:"old_tip_height,omitempty"`
+	OldTipHash   string `protobuf:"bytes,6,opt,name=old_tip_hash,json=oldTipHash,proto3" json:"old_tip_hash,omitempty"`
+	NewTipHeight int64  `protobuf:"varint,7,opt,name=new_tip_height,json=newTipHeight,proto3" json:"new_tip_height,omitempty"`
+	NewTipHash   string `protobuf:"bytes,8,opt,name=new_tip_hash,json=newTipHash,proto3" json:"new_tip_hash,omitempty"`
+	// Number of txs of the orphaned blocks
+	TxNo int64 `protobuf:"varint,9,opt,name=tx_no,json=txNo,proto3" json:"tx_no,omitempty"`
+	// Unix time in seconds
+	Time                 int64    `protobuf:"varint,10,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Reorg) Reset()         { *m = Reorg{} }
+func (m *Reorg) String() string { return proto.CompactTextString(m) }
+func (*Reorg) ProtoMessage()    {}
+func (*Reorg) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{25}
+}
+func (m *Reorg) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Reorg.Unmarshal(m, b)
+}
+func (m *Reorg) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Reorg.Marshal(b, m, deterministic)
+}
+func (dst *Reorg) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Reorg.Merge(dst, src)
+}
+func (m *Reorg) XXX_Size() int {
+	return xxx_messageInfo_Reorg.Size(m)
+}
+func (m *Reorg) XXX_DiscardUnknown() {
+	xxx_messageInfo_Reorg.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Reorg proto.InternalMessageInfo
+
+func (m *Reorg) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Reorg) GetFromHeight() int64 {
+	if m != nil {
+		return m.FromHeight
+	}
+	return 0
+}
+
+func (m *Reorg) GetFromHash() string {
+	if m != nil {
+		return m.FromHash
+	}
+	return ""
+}
+
+func (m *Reorg) GetDepth() int64 {
+	if m != nil {
+		return m.Depth
+	}
+	return 0
+}
+
+func (m *Reorg) GetOldTipHeight() int64 {
+	if m != nil {
+		return m.OldTipHeight
+	}
+	return 0
+}
+
+func (m *Reorg) GetOldTipHash() string {
+	if m != nil {
+		return m.OldTipHash
+	}
+	return ""
+}
+
+func (m *Reorg) GetNewTipHeight() int64 {
+	if m != nil {
+		return m.NewTipHeight
+	}
+	return 0
+}
+
+func (m *Reorg) GetNewTipHash() string {
+	if m != nil {
+		return m.NewTipHash
+	}
+	return ""
+}
+
+func (m *Reorg) GetTxNo() int64 {
+	if m != nil {
+		return m.TxNo
+	}
+	return 0
+}
+
+func (m *Reorg) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+type WatchList struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Addresses            []string `protobuf:"bytes,2,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchList) Reset()         { *m = WatchList{} }
+func (m *WatchList) String() string { return proto.CompactTextString(m) }
+func (*WatchList) ProtoMessage()    {}
+func (*WatchList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_btc_indexer_4183b4237ebb2cdb, []int{26}
+}
+func (m *WatchList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchList.Unmarshal(m, b)
+}
+func (m *WatchList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchList.Marshal(b, m, deterministic)
+}
+func (dst *WatchList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchList.Merge(dst, src)
+}
+func (m *WatchList) XXX_Size() int {
+	return xxx_messageInfo_WatchList.Size(m)
+}
+func (m *WatchList) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchList proto.InternalMessageInfo
+
+func (m *WatchList) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *WatchList) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*SyncRequest)(nil), "btcindexersrv.SyncRequest")
+	proto.RegisterType((*SyncResponse)(nil), "btcindexersrv.SyncResponse")
+	proto.RegisterType((*SyncResponse_BeginStream)(nil), "btcindexersrv.SyncResponse.BeginStream")
+	proto.RegisterType((*SyncResponse_EndStream)(nil), "btcindexersrv.SyncResponse.EndStream")
+	proto.RegisterType((*SyncResponse_SyncBlock)(nil), "btcindexersrv.SyncResponse.SyncBlock")
+	proto.RegisterType((*SyncResponse_ReorgBlock)(nil), "btcindexersrv.SyncResponse.ReorgBlock")
+	proto.RegisterType((*VerifyRequest)(nil), "btcindexersrv.VerifyRequest")
+	proto.RegisterType((*VerifyResponse)(nil), "btcindexersrv.VerifyResponse")
+	proto.RegisterType((*GetTransactionRequest)(nil), "btcindexersrv.GetTransactionRequest")
+	proto.RegisterType((*GetTransactionResponse)(nil), "btcindexersrv.GetTransactionResponse")
+	proto.RegisterType((*GetAddressHistoryRequest)(nil), "btcindexersrv.GetAddressHistoryRequest")
+	proto.RegisterType((*GetAddressHistoryResponse)(nil), "btcindexersrv.GetAddressHistoryResponse")
+	proto.RegisterType((*GetAddressHistoryResponse_AddressTx)(nil), "btcindexersrv.GetAddressHistoryResponse.AddressTx")
+	proto.RegisterType((*GetUTXOsRequest)(nil), "btcindexersrv.GetUTXOsRequest")
+	proto.RegisterType((*GetUTXOsResponse)(nil), "btcindexersrv.GetUTXOsResponse")
+	proto.RegisterType((*GetAddressBalanceRequest)(nil), "btcindexersrv.GetAddressBalanceRequest")
+	proto.RegisterType((*GetAddressBalanceResponse)(nil), "btcindexersrv.GetAddressBalanceResponse")
+	proto.RegisterType((*GetReorgsRequest)(nil), "btcindexersrv.GetReorgsRequest")
+	proto.RegisterType((*GetReorgsResponse)(nil), "btcindexersrv.GetReorgsResponse")
+	proto.RegisterType((*AddWatchedAddressesRequest)(nil), "btcindexersrv.AddWatchedAddressesRequest")
+	proto.RegisterType((*AddWatchedAddressesResponse)(nil), "btcindexersrv.AddWatchedAddressesResponse")
+	proto.RegisterType((*RemoveWatchedAddressesRequest)(nil), "btcindexersrv.RemoveWatchedAddressesRequest")
+	proto.RegisterType((*RemoveWatchedAddressesResponse)(nil), "btcindexersrv.RemoveWatchedAddressesResponse")
+	proto.RegisterType((*ListWatchedAddressesRequest)(nil), "btcindexersrv.ListWatchedAddressesRequest")
+	proto.RegisterType((*ListWatchedAddressesResponse)(nil), "btcindexersrv.ListWatchedAddressesResponse")
+	proto.RegisterType((*Block)(nil), "btcindexersrv.Block")
+	proto.RegisterType((*TxIn)(nil), "btcindexersrv.TxIn")
+	proto.RegisterType((*TxOut)(nil), "btcindexersrv.TxOut")
+	proto.RegisterType((*VerifyIssue)(nil), "btcindexersrv.VerifyIssue")
+	proto.RegisterType((*UTXO)(nil), "btcindexersrv.UTXO")
+	proto.RegisterType((*Reorg)(nil), "btcindexersrv.Reorg")
+	proto.RegisterType((*WatchList)(nil), "btcindexersrv.WatchList")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// BtcIndexerClient is the client API for BtcIndexer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type BtcIndexerClient interface {
+	Sync(ctx context.Context, opts ...grpc.CallOption) (BtcIndexer_SyncClient, error)
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
+	GetAddressHistory(ctx context.Context, in *GetAddressHistoryRequest, opts ...grpc.CallOption) (*GetAddressHistoryResponse, error)
+	GetUTXOs(ctx context.Context, in *GetUTXOsRequest, opts ...grpc.CallOption) (*GetUTXOsResponse, error)
+	GetAddressBalance(ctx context.Context, in *GetAddressBalanceRequest, opts ...grpc.CallOption) (*GetAddressBalanceResponse, error)
+	GetReorgs(ctx context.Context, in *GetReorgsRequest, opts ...grpc.CallOption) (*GetReorgsResponse, error)
+	AddWatchedAddresses(ctx context.Context, in *AddWatchedAddressesRequest, opts ...grpc.CallOption) (*AddWatchedAddressesResponse, error)
+	RemoveWatchedAddresses(ctx context.Context, in *RemoveWatchedAddressesRequest, opts ...grpc.CallOption) (*RemoveWatchedAddressesResponse, error)
+	ListWatchedAddresses(ctx context.Context, in *ListWatchedAddressesRequest, opts ...grpc.CallOption) (*ListWatchedAddressesResponse, error)
+}
+
+type btcIndexerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBtcIndexerClient(cc *grpc.ClientConn) BtcIndexerClient {
+	return &btcIndexerClient{cc}
+}
+
+func (c *btcIndexerClient) Sync(ctx context.Context, opts ...grpc.CallOption) (BtcIndexer_SyncClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_BtcIndexer_serviceDesc.Streams[0], "/btcindexersrv.BtcIndexer/Sync", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &btcIndexerSyncClient{stream}
+	return x, nil
+}
+
+type BtcIndexer_SyncClient interface {
+	Send(*SyncRequest) error
+	Recv() (*SyncResponse, error)
+	grpc.ClientStream
+}
+
+type btcIndexerSyncClient struct {
+	grpc.ClientStream
+}
+
+func (x *btcIndexerSyncClient) Send(m *SyncRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *btcIndexerSyncClient) Recv() (*SyncResponse, error) {
+	m := new(SyncResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *btcIndexerClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, "/btcindexersrv.BtcIndexer/Verify", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error) {
+	out := new(GetTransactionResponse)
+	err := c.cc.Invoke(ctx, "/btcindexersrv.BtcIndexer/GetTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerClient) GetAddressHistory(ctx context.Context, in *GetAddressHistoryRequest, opts ...grpc.CallOption) (*GetAddressHistoryResponse, error) {
+	out := new(GetAddressHistoryResponse)
+	err := c.cc.Invoke(ctx, "/btcindexersrv.BtcIndexer/GetAddressHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerClient) GetUTXOs(ctx context.Context, in *GetUTXOsRequest, opts ...grpc.CallOption) (*GetUTXOsResponse, error) {
+	out := new(GetUTXOsResponse)
+	err := c.cc.Invoke(ctx, "/btcindexersrv.BtcIndexer/GetUTXOs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerClient) GetAddressBalance(ctx context.Context, in *GetAddressBalanceRequest, opts ...grpc.CallOption) (*GetAddressBalanceResponse, error) {
+	out := new(GetAddressBalanceResponse)
+	err := c.cc.Invoke(ctx, "/btcindexersrv.BtcIndexer/GetAddressBalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerClient) GetReorgs(ctx context.Context, in *GetReorgsRequest, opts ...grpc.CallOption) (*GetReorgsResponse, error) {
+	out := new(GetReorgsResponse)
+	err := c.cc.Invoke(ctx, "/btcindexersrv.BtcIndexer/GetReorgs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerClient) AddWatchedAddresses(ctx context.Context, in *AddWatchedAddressesRequest, opts ...grpc.CallOption) (*AddWatchedAddressesResponse, error) {
+	out := new(AddWatchedAddressesResponse)
+	err := c.cc.Invoke(ctx, "/btcindexersrv.BtcIndexer/AddWatchedAddresses", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerClient) RemoveWatchedAddresses(ctx context.Context, in *RemoveWatchedAddressesRequest, opts ...grpc.CallOption) (*RemoveWatchedAddressesResponse, error) {
+	out := new(RemoveWatchedAddressesResponse)
+	err := c.cc.Invoke(ctx, "/btcindexersrv.BtcIndexer/RemoveWatchedAddresses", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *btcIndexerClient) ListWatchedAddresses(ctx context.Context, in *ListWatchedAddressesRequest, opts ...grpc.CallOption) (*ListWatchedAddressesResponse, error) {
+	out := new(ListWatchedAddressesResponse)
+	err := c.cc.Invoke(ctx, "/btcindexersrv.BtcIndexer/ListWatchedAddresses", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BtcIndexerServer is the server API for BtcIndexer service.
+type BtcIndexerServer interface {
+	Sync(BtcIndexer_SyncServer) error
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
+	GetAddressHistory(context.Context, *GetAddressHistoryRequest) (*GetAddressHistoryResponse, error)
+	GetUTXOs(context.Context, *GetUTXOsRequest) (*GetUTXOsResponse, error)
+	GetAddressBalance(context.Context, *GetAddressBalanceRequest) (*GetAddressBalanceResponse, error)
+	GetReorgs(context.Context, *GetReorgsRequest) (*GetReorgsResponse, error)
+	AddWatchedAddresses(context.Context, *AddWatchedAddressesRequest) (*AddWatchedAddressesResponse, error)
+	RemoveWatchedAddresses(context.Context, *RemoveWatchedAddressesRequest) (*RemoveWatchedAddressesResponse, error)
+	ListWatchedAddresses(context.Context, *ListWatchedAddressesRequest) (*ListWatchedAddressesResponse, error)
+}
+
+func RegisterBtcIndexerServer(s *grpc.Server, srv BtcIndexerServer) {
+	s.RegisterService(&_BtcIndexer_serviceDesc, srv)
+}
+
+func _BtcIndexer_Sync_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BtcIndexerServer).Sync(&btcIndexerSyncServer{stream})
+}
+
+type BtcIndexer_SyncServer interface {
+	Send(*SyncResponse) error
+	Recv() (*SyncRequest, error)
+	grpc.ServerStream
+}
+
+type btcIndexerSyncServer struct {
 	grpc.ServerStream
 }
 
@@ -761,10 +2284,209 @@ func (x *btcIndexerSyncServer) Recv() (*SyncRequest, error) {
 	return m, nil
 }
 
+func _BtcIndexer_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BtcIndexerServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btcindexersrv.BtcIndexer/Verify",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BtcIndexerServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BtcIndexer_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BtcIndexerServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btcindexersrv.BtcIndexer/GetTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BtcIndexerServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BtcIndexer_GetAddressHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAddressHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BtcIndexerServer).GetAddressHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btcindexersrv.BtcIndexer/GetAddressHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BtcIndexerServer).GetAddressHistory(ctx, req.(*GetAddressHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BtcIndexer_GetUTXOs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUTXOsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BtcIndexerServer).GetUTXOs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btcindexersrv.BtcIndexer/GetUTXOs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BtcIndexerServer).GetUTXOs(ctx, req.(*GetUTXOsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BtcIndexer_GetAddressBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAddressBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BtcIndexerServer).GetAddressBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btcindexersrv.BtcIndexer/GetAddressBalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BtcIndexerServer).GetAddressBalance(ctx, req.(*GetAddressBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BtcIndexer_GetReorgs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReorgsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BtcIndexerServer).GetReorgs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btcindexersrv.BtcIndexer/GetReorgs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BtcIndexerServer).GetReorgs(ctx, req.(*GetReorgsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BtcIndexer_AddWatchedAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddWatchedAddressesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BtcIndexerServer).AddWatchedAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btcindexersrv.BtcIndexer/AddWatchedAddresses",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BtcIndexerServer).AddWatchedAddresses(ctx, req.(*AddWatchedAddressesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BtcIndexer_RemoveWatchedAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveWatchedAddressesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BtcIndexerServer).RemoveWatchedAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btcindexersrv.BtcIndexer/RemoveWatchedAddresses",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BtcIndexerServer).RemoveWatchedAddresses(ctx, req.(*RemoveWatchedAddressesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BtcIndexer_ListWatchedAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWatchedAddressesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BtcIndexerServer).ListWatchedAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/btcindexersrv.BtcIndexer/ListWatchedAddresses",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BtcIndexerServer).ListWatchedAddresses(ctx, req.(*ListWatchedAddressesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _BtcIndexer_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "btcindexersrv.BtcIndexer",
 	HandlerType: (*BtcIndexerServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Verify",
+			Handler:    _BtcIndexer_Verify_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _BtcIndexer_GetTransaction_Handler,
+		},
+		{
+			MethodName: "GetAddressHistory",
+			Handler:    _BtcIndexer_GetAddressHistory_Handler,
+		},
+		{
+			MethodName: "GetUTXOs",
+			Handler:    _BtcIndexer_GetUTXOs_Handler,
+		},
+		{
+			MethodName: "GetAddressBalance",
+			Handler:    _BtcIndexer_GetAddressBalance_Handler,
+		},
+		{
+			MethodName: "GetReorgs",
+			Handler:    _BtcIndexer_GetReorgs_Handler,
+		},
+		{
+			MethodName: "AddWatchedAddresses",
+			Handler:    _BtcIndexer_AddWatchedAddresses_Handler,
+		},
+		{
+			MethodName: "RemoveWatchedAddresses",
+			Handler:    _BtcIndexer_RemoveWatchedAddresses_Handler,
+		},
+		{
+			MethodName: "ListWatchedAddresses",
+			Handler:    _BtcIndexer_ListWatchedAddresses_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Sync",
@@ -777,46 +2499,114 @@ var _BtcIndexer_serviceDesc = grpc.ServiceDesc{
 }
 
 func init() {
-	proto.RegisterFile("srv/btc-indexer/proto/btc-indexer.proto", fileDescriptor_btc_indexer_e6885b9d3d85d89a)
-}
-
-var fileDescriptor_btc_indexer_e6885b9d3d85d89a = []byte{
-	// 589 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x54, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0xc5, 0x75, 0xec, 0xc4, 0xe3, 0xa4, 0xc0, 0x52, 0x81, 0x71, 0x2f, 0x55, 0xf8, 0x68, 0x54,
-	0xa9, 0x29, 0x2a, 0x27, 0xae, 0x41, 0xa0, 0x46, 0x20, 0x01, 0xdb, 0x1e, 0x10, 0x17, 0xcb, 0x1f,
-	0xab, 0xc6, 0x6a, 0x58, 0x87, 0xdd, 0x75, 0xea, 0xfc, 0x0d, 0x7e, 0x12, 0x07, 0xce, 0xfc, 0x24,
-	0xb4, 0xb3, 0x71, 0xe2, 0x54, 0x51, 0xb8, 0x70, 0x4a, 0xde, 0xec, 0xf3, 0x9b, 0xf7, 0x66, 0xd6,
-	0x86, 0x63, 0x29, 0xe6, 0x67, 0x89, 0x4a, 0x4f, 0x73, 0x9e, 0xb1, 0x8a, 0x89, 0xb3, 0x99, 0x28,
-	0x54, 0xd1, 0xac, 0x0c, 0xb1, 0x42, 0x7a, 0x89, 0x4a, 0x97, 0x15, 0x29, 0xe6, 0xfd, 0x0b, 0xf0,
-	0x2f, 0x17, 0x3c, 0xa5, 0xec, 0x47, 0xc9, 0xa4, 0x22, 0x6f, 0xa0, 0x27, 0x58, 0xca, 0xb8, 0x8a,
-	0x92, 0x69, 0x91, 0xde, 0xc8, 0xc0, 0x3a, 0xb2, 0x07, 0xfe, 0xf9, 0xc1, 0x70, 0xe3, 0xa9, 0xe1,
-	0x48, 0x1f, 0xd2, 0xae, 0xa1, 0x22, 0x90, 0xfd, 0x3f, 0x2d, 0xe8, 0x1a, 0x29, 0x39, 0x2b, 0xb8,
-	0x64, 0xe4, 0x23, 0x74, 0x13, 0x76, 0x9d, 0xf3, 0x48, 0x2a, 0xc1, 0xe2, 0xef, 0x81, 0x75, 0x64,
-	0x0d, 0xfc, 0xf3, 0xe3, 0x3b, 0x52, 0xcd, 0x47, 0x86, 0x23, 0xcd, 0xbf, 0x44, 0xfa, 0xc5, 0x3d,
-	0xea, 0x27, 0x6b, 0x48, 0xde, 0x03, 0x30, 0x9e, 0xd5, 0x5a, 0x7b, 0xa8, 0xf5, 0x62, 0x97, 0xd6,
-	0x3b, 0x9e, 0xad, 0x94, 0x3c, 0x56, 0x03, 0xad, 0x23, 0x17, 0x3c, 0x35, 0xf9, 0x02, 0xfb, 0xdf,
-	0x3a, 0x1a, 0x60, 0x44, 0xad, 0x23, 0x6b, 0x40, 0xc6, 0xe0, 0x0b, 0x56, 0x88, 0xeb, 0xa5, 0x50,
-	0x0b, 0x85, 0x5e, 0xee, 0x12, 0xa2, 0x9a, 0x5e, 0x2b, 0x81, 0x58, 0xa1, 0xb0, 0x07, 0x7e, 0x23,
-	0x78, 0xe8, 0x83, 0xb7, 0xf2, 0x1e, 0xfe, 0xb4, 0xc0, 0x5b, 0x39, 0x20, 0x27, 0xe0, 0x98, 0x76,
-	0x66, 0x96, 0xdb, 0xd7, 0x62, 0x28, 0xe4, 0x04, 0x5c, 0x55, 0x45, 0x39, 0x97, 0xc1, 0x1e, 0xee,
-	0xf0, 0xd1, 0x1d, 0xf2, 0x55, 0x35, 0xe6, 0xd4, 0x51, 0xd5, 0x98, 0x4b, 0x72, 0x0a, 0x6d, 0x55,
-	0x45, 0x45, 0xa9, 0x64, 0x60, 0x6f, 0x5d, 0xf8, 0x55, 0xf5, 0xa9, 0x54, 0xd4, 0x55, 0xfa, 0x47,
-	0x86, 0xdf, 0x00, 0xd6, 0x61, 0xc8, 0x63, 0x70, 0x27, 0x2c, 0xbf, 0x9e, 0x28, 0x74, 0x65, 0xd3,
-	0x25, 0x22, 0x4f, 0xa1, 0x53, 0x4c, 0xb3, 0x68, 0x12, 0xcb, 0x09, 0xee, 0xcb, 0xa3, 0xed, 0x62,
-	0x9a, 0x5d, 0xc4, 0x72, 0xa2, 0x8f, 0x38, 0xbb, 0x35, 0x47, 0xb6, 0x39, 0xe2, 0xec, 0x56, 0x1f,
-	0x8d, 0x00, 0x3a, 0x62, 0x39, 0xb1, 0xfe, 0x57, 0x70, 0x76, 0xb7, 0x20, 0xd0, 0x6a, 0xc8, 0xe3,
-	0x7f, 0xf2, 0x0c, 0x7a, 0x33, 0xc1, 0xe6, 0x79, 0x51, 0xca, 0x66, 0x83, 0x6e, 0x5d, 0xd4, 0x5d,
-	0xfa, 0xbf, 0x2c, 0x68, 0xe9, 0x01, 0x90, 0x27, 0x98, 0x1c, 0x79, 0x16, 0xf2, 0x5c, 0x55, 0xd5,
-	0x16, 0x71, 0x7c, 0x19, 0xab, 0x50, 0xde, 0xa1, 0x6d, 0x3d, 0xab, 0x8c, 0x55, 0x0d, 0x37, 0xf6,
-	0x86, 0x9b, 0x00, 0xda, 0x71, 0x96, 0x09, 0x26, 0x25, 0x5e, 0x07, 0x8f, 0xd6, 0x90, 0x0c, 0xe0,
-	0xc1, 0xca, 0x53, 0xdd, 0xce, 0x41, 0xca, 0x7e, 0x5d, 0xbf, 0x32, 0x6d, 0x4f, 0xe0, 0x61, 0x93,
-	0x69, 0xfa, 0xbb, 0xd8, 0xff, 0xfe, 0x9a, 0x8a, 0x3e, 0xfa, 0xbf, 0x2d, 0x70, 0x70, 0x31, 0xff,
-	0x35, 0xc5, 0x01, 0x38, 0xf3, 0x78, 0x5a, 0x32, 0xcc, 0x60, 0x53, 0x03, 0x9a, 0xd9, 0x9c, 0xcd,
-	0x6c, 0xcf, 0x61, 0x5f, 0xa6, 0x22, 0x9f, 0xa9, 0x68, 0x56, 0x26, 0xd1, 0x0d, 0x5b, 0xa0, 0x5d,
-	0x8f, 0x76, 0x4d, 0xf5, 0x73, 0x99, 0x7c, 0x60, 0x0b, 0x72, 0x08, 0x5e, 0x5a, 0xe4, 0x3c, 0x4a,
-	0x62, 0xc9, 0x82, 0xf6, 0x91, 0x35, 0xe8, 0xd0, 0x8e, 0x2e, 0x8c, 0x62, 0xc9, 0xce, 0xbf, 0x00,
-	0x8c, 0x54, 0x3a, 0x36, 0xd7, 0x8d, 0xbc, 0x85, 0x96, 0xbe, 0xf1, 0x24, 0xdc, 0xfa, 0x32, 0xe1,
-	0x77, 0x2a, 0x3c, 0xdc, 0xf1, 0xa2, 0x0d, 0xac, 0x57, 0x56, 0xe2, 0xe2, 0xd7, 0xee, 0xf5, 0xdf,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0x0f, 0x1b, 0x3f, 0x76, 0x18, 0x05, 0x00, 0x00,
+	proto.RegisterFile("srv/btc-indexer/proto/btc-indexer.proto", fileDescriptor_btc_indexer_4183b4237ebb2cdb)
+}
+
+var fileDescriptor_btc_indexer_4183b4237ebb2cdb = []byte{
+	// 1666 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0xcd, 0x72, 0xdb, 0x46,
+	0x12, 0x36, 0x08, 0x82, 0x3f, 0xcd, 0x1f, 0xc9, 0x63, 0xad, 0x96, 0x86, 0xfc, 0xc3, 0xc2, 0xca,
+	0x36, 0x2d, 0x5b, 0xf2, 0xae, 0x7c, 0xda, 0xad, 0xda, 0x83, 0xe4, 0x38, 0x96, 0xca, 0x2e, 0x39,
+	0x81, 0x99, 0xc4, 0x95, 0x2a, 0x87, 0x05, 0x02, 0x23, 0x11, 0x25, 0x10, 0xa0, 0x31, 0x43, 0x09,
+	0x7a, 0x81, 0x3c, 0x40, 0x9e, 0x21, 0xd7, 0xbc, 0x40, 0x4e, 0xa9, 0x1c, 0x72, 0xce, 0x21, 0x4f,
+	0x91, 0x53, 0x4e, 0x39, 0xa7, 0xe6, 0x0f, 0x04, 0x29, 0x90, 0x94, 0x2b, 0x3e, 0x89, 0xdd, 0xf3,
+	0x4d, 0x4f, 0x4f, 0x77, 0x4f, 0xf7, 0x07, 0xc1, 0x03, 0x12, 0x9f, 0x3d, 0xe9, 0x53, 0x77, 0xdb,
+	0x0f, 0x3d, 0x9c, 0xe0, 0xf8, 0xc9, 0x28, 0x8e, 0x68, 0x94, 0xd5, 0xec, 0x70, 0x0d, 0x6a, 0xf4,
+	0xa9, 0x2b, 0x35, 0x24, 0x3e, 0xb3, 0x7e, 0xd0, 0xa0, 0xf6, 0xe6, 0x22, 0x74, 0x6d, 0xfc, 0x7e,
+	0x8c, 0x09, 0x45, 0xff, 0x85, 0x46, 0x8c, 0x5d, 0x1c, 0xd2, 0x5e, 0x3f, 0x88, 0xdc, 0x53, 0xd2,
+	0xd2, 0xda, 0x7a, 0xa7, 0xb6, 0xbb, 0xb6, 0x33, 0xb5, 0x6d, 0x67, 0x9f, 0x2d, 0xda, 0x75, 0x01,
+	0xe5, 0x02, 0x41, 0xb7, 0xa0, 0xea, 0x78, 0x5e, 0x8c, 0x09, 0xc1, 0xa4, 0x55, 0x68, 0xeb, 0x9d,
+	0xaa, 0x3d, 0x51, 0xa0, 0xdb, 0x00, 0xe7, 0x0e, 0x75, 0x07, 0xbd, 0xc0, 0x27, 0xb4, 0xa5, 0xb7,
+	0x35, 0xb6, 0xcc, 0x35, 0xaf, 0x7c, 0x42, 0xd1, 0x7d, 0x58, 0x71, 0x82, 0xa0, 0x37, 0x81, 0x90,
+	0x56, 0xb1, 0xad, 0x75, 0x2a, 0x76, 0xc3, 0x09, 0x82, 0xaf, 0x14, 0x8c, 0x58, 0xbf, 0x16, 0xa1,
+	0x2e, 0xfc, 0x25, 0xa3, 0x28, 0x24, 0x18, 0xbd, 0x82, 0x7a, 0x1f, 0x9f, 0xf8, 0x61, 0x8f, 0xd0,
+	0x18, 0x3b, 0xc3, 0x96, 0xd6, 0xd6, 0x3a, 0xb5, 0xdd, 0x07, 0x33, 0xfe, 0x66, 0xb7, 0xec, 0xec,
+	0x33, 0xfc, 0x1b, 0x0e, 0x3f, 0xb8, 0x66, 0xd7, 0xfa, 0x13, 0x11, 0x7d, 0x0a, 0x80, 0x43, 0x4f,
+	0xd9, 0x2a, 0x70, 0x5b, 0xf7, 0x16, 0xd9, 0x7a, 0x1e, 0x7a, 0xa9, 0xa5, 0x2a, 0x0e, 0xbd, 0x89,
+	0x1d, 0x72, 0x11, 0xba, 0x22, 0x88, 0x2d, 0x7d, 0xb9, 0x1d, 0x26, 0xf0, 0x38, 0x32, 0x3b, 0x44,
+	0x09, 0xe8, 0x10, 0x6a, 0x31, 0x8e, 0xe2, 0x13, 0x69, 0xa8, 0xc8, 0x0d, 0xdd, 0x5f, 0x64, 0xc8,
+	0x66, 0x70, 0x65, 0x09, 0xe2, 0x54, 0x32, 0x1b, 0x50, 0xcb, 0x5c, 0xdc, 0xac, 0x41, 0x35, 0xf5,
+	0xdd, 0xfc, 0x4e, 0x83, 0x6a, 0xea, 0x01, 0xda, 0x02, 0x43, 0x1c, 0x27, 0x62, 0x99, 0x9f, 0x7b,
+	0x01, 0x41, 0x5b, 0x50, 0xa2, 0x49, 0xcf, 0x0f, 0x45, 0xc6, 0x6b, 0xbb, 0x37, 0x66, 0xc0, 0xdd,
+	0xe4, 0x30, 0xb4, 0x0d, 0x9a, 0x1c, 0x86, 0x04, 0x6d, 0x43, 0x99, 0x26, 0xbd, 0x68, 0x4c, 0x49,
+	0x4b, 0xcf, 0xad, 0xaa, 0x6e, 0xf2, 0x7a, 0x4c, 0xed, 0x12, 0x65, 0x7f, 0x88, 0xf9, 0x35, 0xc0,
+	0xe4, 0x32, 0x68, 0x1d, 0x4a, 0x03, 0xec, 0x9f, 0x0c, 0x28, 0xf7, 0x4a, 0xb7, 0xa5, 0x84, 0x6e,
+	0x42, 0x25, 0x0a, 0xbc, 0xde, 0xc0, 0x21, 0x03, 0x9e, 0xaf, 0xaa, 0x5d, 0x8e, 0x02, 0xef, 0xc0,
+	0x21, 0x03, 0xb6, 0x14, 0xe2, 0x73, 0xb1, 0x24, 0x0a, 0xae, 0x1c, 0xe2, 0x73, 0xb6, 0xb4, 0x0f,
+	0x50, 0x89, 0x65, 0xc4, 0x2c, 0x0c, 0x8d, 0x2f, 0x71, 0xec, 0x1f, 0x5f, 0xa8, 0x37, 0x70, 0x17,
+	0x6a, 0xc7, 0x71, 0x34, 0xec, 0x4d, 0x9d, 0x07, 0x4c, 0x75, 0x20, 0xce, 0xdc, 0x80, 0x2a, 0x8d,
+	0xd4, 0x72, 0x81, 0x2f, 0x57, 0x68, 0x24, 0x17, 0xd7, 0xa1, 0x14, 0xe3, 0x91, 0xe3, 0xc7, 0xfc,
+	0xcc, 0x8a, 0x2d, 0x25, 0xeb, 0x37, 0x0d, 0x9a, 0xea, 0x1c, 0x59, 0xbb, 0x7f, 0xef, 0xa0, 0x7b,
+	0xd0, 0x74, 0x07, 0xd8, 0x3d, 0xc5, 0x9e, 0x7a, 0xab, 0x3a, 0x47, 0x34, 0xa4, 0x56, 0x3e, 0xcb,
+	0x5d, 0x28, 0xf9, 0x84, 0x8c, 0x31, 0x7b, 0x50, 0x2c, 0xe8, 0xe6, 0x4c, 0xd0, 0x85, 0x4f, 0x87,
+	0x0c, 0x62, 0x4b, 0x24, 0x7a, 0x08, 0xab, 0xc2, 0x6b, 0xec, 0xc9, 0xd3, 0x49, 0xcb, 0x68, 0xeb,
+	0x1d, 0xdd, 0x5e, 0x51, 0x7a, 0xe1, 0x04, 0xb1, 0x1e, 0xc1, 0x3f, 0x5e, 0x60, 0xda, 0x8d, 0x9d,
+	0x90, 0x38, 0x2e, 0xf5, 0xa3, 0x50, 0x45, 0x11, 0x41, 0x91, 0x47, 0x5e, 0xe3, 0x91, 0xe7, 0xbf,
+	0xad, 0x3f, 0x35, 0x58, 0x9f, 0x45, 0xcb, 0x58, 0xe4, 0xc0, 0xd9, 0xf5, 0xdd, 0xc8, 0x0f, 0x7b,
+	0x7d, 0x87, 0x60, 0x7e, 0xfd, 0x8a, 0x5d, 0x61, 0x8a, 0x7d, 0x87, 0xe0, 0x49, 0x95, 0xea, 0xcb,
+	0xab, 0x74, 0x13, 0x1a, 0x6e, 0x14, 0x1e, 0xfb, 0xf1, 0xd0, 0x61, 0x87, 0x8a, 0xde, 0xa2, 0xdb,
+	0xd3, 0xca, 0x4c, 0x2d, 0x1b, 0x1f, 0x52, 0xcb, 0xa5, 0xe5, 0xb5, 0x6c, 0xfd, 0xa4, 0x41, 0xeb,
+	0x05, 0xa6, 0x7b, 0xa2, 0x1d, 0x1e, 0xf8, 0x84, 0x46, 0x71, 0x5a, 0x6f, 0x2d, 0x28, 0xcb, 0x3e,
+	0x29, 0x6f, 0xaf, 0xc4, 0xd9, 0x02, 0x29, 0x2c, 0x2e, 0x10, 0xfd, 0x72, 0x25, 0xba, 0xe3, 0x98,
+	0x44, 0x31, 0xbf, 0x6e, 0xd5, 0x96, 0x12, 0x5a, 0x03, 0x23, 0xf0, 0x87, 0x3e, 0x6d, 0x19, 0x6d,
+	0xad, 0x63, 0xd8, 0x42, 0x40, 0x77, 0x00, 0x3c, 0x4c, 0x5c, 0x1c, 0x7a, 0x7e, 0x78, 0xd2, 0x2a,
+	0xf1, 0x68, 0x67, 0x34, 0xd6, 0x1f, 0x1a, 0xdc, 0xcc, 0xb9, 0x82, 0x4c, 0xdf, 0x27, 0xa0, 0xd3,
+	0x44, 0x4d, 0x8b, 0xdd, 0x99, 0x58, 0xcc, 0xdd, 0xb6, 0x23, 0xd5, 0xdd, 0xc4, 0x66, 0xdb, 0xd9,
+	0x7d, 0x43, 0x9c, 0xd0, 0x9e, 0x74, 0x5b, 0xbc, 0x67, 0x60, 0xaa, 0x67, 0x5c, 0x63, 0x06, 0x50,
+	0x4d, 0xb7, 0xb0, 0x0a, 0xe0, 0xf9, 0x92, 0x7d, 0x2a, 0x2f, 0x5d, 0x07, 0xd7, 0xec, 0x22, 0x4b,
+	0x18, 0xda, 0xe6, 0xb9, 0x8d, 0xc6, 0x54, 0x36, 0xf5, 0xdc, 0x74, 0x1d, 0x5c, 0x63, 0xe9, 0x7d,
+	0x3d, 0xa6, 0xfb, 0x45, 0x28, 0xd0, 0xc4, 0x7a, 0x07, 0x2b, 0x2f, 0x30, 0xfd, 0xa2, 0xfb, 0xf6,
+	0x35, 0x51, 0xb9, 0x9a, 0x1a, 0x72, 0xda, 0xec, 0x90, 0x9b, 0x44, 0xbc, 0x90, 0x1f, 0x71, 0x3d,
+	0x13, 0x71, 0xeb, 0x1b, 0x58, 0x9d, 0x98, 0x97, 0x71, 0x7c, 0x08, 0xc6, 0x98, 0x26, 0x91, 0x8a,
+	0xe4, 0xec, 0x9d, 0x18, 0xd8, 0x16, 0x88, 0xa5, 0xc1, 0xb2, 0x5e, 0x65, 0x6b, 0x6e, 0xdf, 0x09,
+	0x9c, 0xd0, 0xc5, 0xcb, 0x6b, 0x6e, 0xd2, 0x68, 0x0b, 0xd9, 0x46, 0x6b, 0xfd, 0x3e, 0x95, 0xff,
+	0xd4, 0x9c, 0xf4, 0x7b, 0xbe, 0x3d, 0x93, 0xb5, 0x5a, 0x17, 0xfb, 0x67, 0xd8, 0x53, 0x2d, 0x4c,
+	0xc9, 0xec, 0xd1, 0x13, 0x1c, 0xaa, 0xca, 0xe5, 0xbf, 0x99, 0xa5, 0xbe, 0x30, 0x2e, 0x5f, 0xa9,
+	0x12, 0xd1, 0x0d, 0x9e, 0xef, 0x30, 0xe2, 0x75, 0xab, 0xb3, 0xc4, 0x1e, 0x45, 0x68, 0x0b, 0xae,
+	0x1f, 0xfb, 0x31, 0xa1, 0x3d, 0x82, 0x71, 0xa8, 0x5e, 0x42, 0x89, 0x03, 0x56, 0xf8, 0xc2, 0x1b,
+	0x8c, 0x43, 0xf9, 0x20, 0x3a, 0xb0, 0x1a, 0x38, 0x33, 0xd0, 0x32, 0x87, 0x36, 0x03, 0x27, 0x8b,
+	0xb4, 0xbe, 0xd5, 0x78, 0x6e, 0xf8, 0xfc, 0x21, 0x1f, 0x67, 0x2e, 0x6c, 0x40, 0x95, 0xef, 0xa6,
+	0xfe, 0x10, 0xab, 0xa7, 0xca, 0x14, 0x5d, 0x7f, 0x88, 0xd1, 0x3f, 0xa1, 0x4c, 0x23, 0xb1, 0x24,
+	0x2e, 0x5d, 0xa2, 0x11, 0x5b, 0xb0, 0xf6, 0xe0, 0x7a, 0xc6, 0x0f, 0x19, 0xec, 0xc7, 0x6c, 0xc4,
+	0x30, 0xcd, 0x1c, 0x76, 0xc6, 0xe1, 0xb6, 0xc4, 0x58, 0x47, 0x60, 0xee, 0x79, 0x1e, 0xe7, 0x50,
+	0xd8, 0xdb, 0x53, 0xb5, 0x9a, 0x69, 0xd3, 0x9c, 0x91, 0xc9, 0xbe, 0x1b, 0xf8, 0xb3, 0x45, 0x3e,
+	0xcb, 0xe4, 0xac, 0xa7, 0xb0, 0x91, 0x6b, 0x4f, 0x3a, 0xb7, 0x06, 0x86, 0xe3, 0x79, 0xd8, 0x93,
+	0xf1, 0x11, 0x82, 0xf5, 0x39, 0xdc, 0xb6, 0xf1, 0x30, 0x3a, 0xc3, 0x1f, 0xcf, 0x8f, 0xff, 0xc1,
+	0x9d, 0x79, 0x26, 0x27, 0x45, 0x19, 0x73, 0x84, 0x72, 0x46, 0x89, 0xd6, 0x7f, 0x60, 0x83, 0xf1,
+	0xc9, 0x0f, 0x70, 0xc6, 0x3a, 0x82, 0x5b, 0xf9, 0x5b, 0xe4, 0x61, 0x3b, 0x60, 0x08, 0xde, 0x2a,
+	0x72, 0xd2, 0x9a, 0xc9, 0x49, 0xca, 0x61, 0x6d, 0x01, 0xb3, 0xde, 0x82, 0xb1, 0x98, 0xd9, 0xa8,
+	0x89, 0x58, 0xc8, 0x4c, 0xc4, 0x7f, 0x41, 0x63, 0x14, 0xe3, 0x33, 0x3f, 0x1a, 0x93, 0x2c, 0xaf,
+	0xa9, 0x2b, 0x25, 0x23, 0x37, 0xd6, 0xcf, 0x1a, 0x14, 0x59, 0xf3, 0xe3, 0x55, 0x95, 0xf4, 0x32,
+	0x63, 0xb5, 0x44, 0x13, 0xc5, 0x8c, 0x78, 0xe7, 0xf4, 0x70, 0xc2, 0xcd, 0x1b, 0x76, 0x99, 0x75,
+	0x49, 0x0f, 0x27, 0x19, 0x6f, 0xf4, 0x29, 0x6f, 0x32, 0x0f, 0xbc, 0x38, 0xfd, 0xc0, 0x3b, 0xb0,
+	0x9a, 0xfa, 0xa4, 0x8e, 0x33, 0x38, 0xa4, 0xa9, 0xf4, 0x5d, 0x71, 0xec, 0x16, 0x5c, 0xcf, 0x22,
+	0xc5, 0xf9, 0x25, 0x7e, 0xfe, 0xca, 0x04, 0xca, 0xfd, 0xb0, 0x7e, 0xd1, 0xc0, 0xe0, 0x4d, 0xf9,
+	0xa3, 0xde, 0x62, 0x0d, 0x8c, 0x33, 0x27, 0x18, 0xab, 0x57, 0x26, 0x84, 0xec, 0xdd, 0x8c, 0xe9,
+	0xbb, 0x6d, 0x42, 0x93, 0xb8, 0xb1, 0x3f, 0xa2, 0xbd, 0xd1, 0xb8, 0xdf, 0x3b, 0xc5, 0x17, 0xdc,
+	0xdd, 0xaa, 0x5d, 0x17, 0xda, 0xcf, 0xc6, 0xfd, 0x97, 0xf8, 0x62, 0x9a, 0xa7, 0x94, 0xa7, 0x79,
+	0x8a, 0x35, 0x84, 0x5a, 0x86, 0x62, 0x2d, 0xca, 0xf6, 0xa9, 0x1f, 0x7a, 0x2a, 0xdb, 0xec, 0x37,
+	0x6b, 0x9d, 0x38, 0x19, 0x61, 0x97, 0x62, 0x4f, 0x26, 0x3a, 0x95, 0x99, 0x1d, 0xc7, 0xa5, 0x63,
+	0x27, 0x50, 0xc3, 0x5d, 0x48, 0xd6, 0x7b, 0x28, 0xb2, 0x21, 0x81, 0x1e, 0xa5, 0x03, 0x4f, 0x9b,
+	0x3f, 0xf0, 0xe4, 0xb8, 0xbb, 0xcc, 0x8f, 0x0a, 0x79, 0xfc, 0x68, 0x1d, 0x4a, 0x43, 0x87, 0x8e,
+	0x63, 0xac, 0x98, 0xad, 0x90, 0xac, 0xef, 0x0b, 0x60, 0xf0, 0x96, 0x83, 0x9a, 0x50, 0xf0, 0xd5,
+	0x5b, 0x2b, 0xf8, 0xde, 0x95, 0xf8, 0x8b, 0x00, 0x4c, 0x6a, 0x99, 0x37, 0x45, 0x9e, 0xdf, 0x35,
+	0x30, 0x3c, 0x3c, 0xa2, 0x03, 0x95, 0x2c, 0x2e, 0xb0, 0x94, 0x30, 0xc2, 0x4f, 0xfd, 0x91, 0x32,
+	0x2b, 0xc6, 0x41, 0x3d, 0x0a, 0xbc, 0xae, 0x3f, 0x92, 0x86, 0xdb, 0x50, 0x4f, 0x51, 0xcc, 0xb6,
+	0x48, 0x1b, 0x48, 0x0c, 0xb3, 0xbe, 0x09, 0x4d, 0xf6, 0x75, 0x90, 0xb1, 0x23, 0x46, 0x41, 0x3d,
+	0xc4, 0xe7, 0x53, 0x76, 0x52, 0x14, 0xb3, 0x53, 0x51, 0x53, 0xf6, 0x5c, 0xd9, 0x49, 0xa7, 0x52,
+	0x35, 0x33, 0x95, 0x10, 0x14, 0x79, 0x33, 0x07, 0xa9, 0x63, 0xad, 0xfc, 0xff, 0x50, 0x4d, 0x9b,
+	0x00, 0x03, 0x84, 0xce, 0x10, 0xab, 0x0e, 0xc3, 0x7e, 0x2f, 0x6e, 0x77, 0xbb, 0x3f, 0x96, 0x01,
+	0xf6, 0xa9, 0x7b, 0x28, 0x52, 0x88, 0x9e, 0x41, 0x91, 0x7d, 0xb1, 0x21, 0x33, 0xf7, 0x63, 0x90,
+	0xb7, 0x31, 0x73, 0x63, 0xc1, 0x87, 0x62, 0x47, 0xfb, 0xb7, 0x86, 0x9e, 0x43, 0x49, 0xd4, 0x26,
+	0xba, 0x95, 0xfb, 0x55, 0xa0, 0x0c, 0xdd, 0x9e, 0xb3, 0x2a, 0x5b, 0xdf, 0x3b, 0x68, 0x4e, 0xb3,
+	0x7a, 0xb4, 0x79, 0x99, 0x01, 0x5e, 0xfe, 0x44, 0x30, 0xef, 0x2d, 0x41, 0x49, 0xf3, 0xc7, 0x7c,
+	0x06, 0x4e, 0x33, 0x48, 0xf4, 0x60, 0x39, 0xc7, 0x14, 0x87, 0x74, 0xae, 0x4a, 0x46, 0xd1, 0x4b,
+	0xa8, 0x28, 0x3e, 0x86, 0xee, 0x5c, 0xde, 0x95, 0xe5, 0x81, 0xe6, 0xdd, 0xb9, 0xeb, 0x79, 0x4e,
+	0x4b, 0xb6, 0xb4, 0xc0, 0xe9, 0x69, 0x7a, 0x66, 0x76, 0x96, 0x03, 0xe5, 0x39, 0x47, 0x50, 0x4d,
+	0x09, 0x02, 0xca, 0xf1, 0x6a, 0x8a, 0xc2, 0x98, 0xed, 0xf9, 0x00, 0x69, 0x2f, 0x80, 0x1b, 0x39,
+	0xd3, 0x1d, 0x3d, 0x9c, 0xd9, 0x38, 0x9f, 0x51, 0x98, 0x5b, 0x57, 0x81, 0xca, 0xd3, 0xc6, 0xb0,
+	0x9e, 0x3f, 0xc3, 0xd1, 0xe3, 0x4b, 0x9c, 0x66, 0x01, 0x7b, 0x30, 0xb7, 0xaf, 0x88, 0x96, 0xc7,
+	0x46, 0xb0, 0x96, 0x37, 0xcb, 0xd1, 0xac, 0xeb, 0x0b, 0x38, 0x82, 0xf9, 0xe8, 0x4a, 0x58, 0x71,
+	0x60, 0xbf, 0xc4, 0xff, 0xf9, 0xf6, 0xf4, 0xaf, 0x01, 0x00, 0xa5, 0xfe, 0x06, 0x18, 0xa7, 0x13,
+	0x00, 0x00,
 }
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// checkpoint records how far an export went, so a restarted one continues from the first partition not written yet.
+type checkpoint struct {
+	Format         string    `json:"format"`
+	FromHeight     int64     `json:"from_height"`
+	ToHeight       int64     `json:"to_height"`
+	ToHash         string    `json:"to_hash,omitempty"` // hash of the block at ToHeight when the export started
+	HeightsPerFile int64     `json:"heights_per_file"`
+	Addresses      string    `json:"addresses,omitempty"` // digest of the filtered addresses
+	NextHeight     int64     `json:"next_height"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// sameExport tells whether both checkpoints are of the exports of the same files
+func (c *checkpoint) sameExport(other *checkpoint) bool {
+	return c.Format == other.Format &&
+		c.FromHeight == other.FromHeight &&
+		c.ToHeight == other.ToHeight &&
+		c.HeightsPerFile == other.HeightsPerFile &&
+		c.Addresses == other.Addresses
+}
+
+func addressesDigest(addresses []string) string {
+	if len(addresses) == 0 {
+		return ""
+	}
+	sorted := append([]string(nil), addresses...)
+	sort.Strings(sorted)
+	digest := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return hex.EncodeToString(digest[:])
+}
+
+// loadCheckpoint returns nil if there is no checkpoint file yet
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to Read Checkpoint file '%s': %v", path, err)
+	}
+
+	var c checkpoint
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Unmarshal Checkpoint file '%s': %v", path, err)
+	}
+	return &c, nil
+}
+
+// saveCheckpoint replaces the checkpoint file atomically, so a crash never leaves it half written
+func saveCheckpoint(path string, c *checkpoint) error {
+	c.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to Marshal Checkpoint: %v", err)
+	}
+
+	tmpPath := path + ".tmp"
+	err = ioutil.WriteFile(tmpPath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to Write Checkpoint file '%s': %v", tmpPath, err)
+	}
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("failed to Rename Checkpoint file '%s': %v", tmpPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatParquet = "parquet"
+	FormatCSV     = "csv"
+
+	defaultHeightsPerFile  = 10000
+	defaultHeightsPerBatch = 100
+	defaultConfirmations   = 6
+	defaultCheckpointFile  = "checkpoint.json"
+)
+
+type Config struct {
+	// Dir holds a sub directory of partition files per table
+	Dir string
+	// Format is FormatParquet or FormatCSV
+	Format     string
+	FromHeight int64
+	// ToHeight is the latest indexed block having 'Confirmations' when the export starts if negative
+	ToHeight int64
+	// Confirmations keeps the blocks likely to be reorganized out of an export to the latest block, 6 if zero
+	Confirmations int64
+	// Addresses restricts the exported ins & outs to theirs, with their txs, if not empty
+	Addresses []string
+	// HeightsPerFile is how many heights a partition file covers
+	HeightsPerFile int64
+	// HeightsPerBatch is how many heights are read from DB at once, also the size of a Parquet row group
+	HeightsPerBatch int64
+	// CheckpointFile defaults to checkpoint.json in Dir
+	CheckpointFile string
+}
+
+func (c Config) Validate() error {
+	var errContents []string
+	if len(c.Dir) == 0 {
+		errContents = append(errContents, "Dir config for Export required")
+	}
+	if c.Format != FormatParquet && c.Format != FormatCSV {
+		errContents = append(errContents, fmt.Sprintf("Format config for Export must be '%s' or '%s'", FormatParquet, FormatCSV))
+	}
+	if c.FromHeight < 0 {
+		errContents = append(errContents, "FromHeight config for Export must not be negative")
+	}
+	if c.ToHeight >= 0 && c.ToHeight < c.FromHeight {
+		errContents = append(errContents, "ToHeight config for Export must not be less than FromHeight")
+	}
+	if c.Confirmations < 0 {
+		errContents = append(errContents, "Confirmations config for Export must not be negative")
+	}
+	if c.HeightsPerFile < 0 {
+		errContents = append(errContents, "HeightsPerFile config for Export must not be negative")
+	}
+	if c.HeightsPerBatch < 0 {
+		errContents = append(errContents, "HeightsPerBatch config for Export must not be negative")
+	}
+	if len(errContents) > 0 {
+		return errors.New(strings.Join(errContents, ", "))
+	}
+	return nil
+}
+
+func (c Config) heightsPerFile() int64 {
+	if c.HeightsPerFile == 0 {
+		return defaultHeightsPerFile
+	}
+	return c.HeightsPerFile
+}
+
+func (c Config) heightsPerBatch() int64 {
+	if c.HeightsPerBatch == 0 {
+		return defaultHeightsPerBatch
+	}
+	return c.HeightsPerBatch
+}
+
+func (c Config) confirmations() int64 {
+	if c.Confirmations == 0 {
+		return defaultConfirmations
+	}
+	return c.Confirmations
+}
+
+func (c Config) checkpointFile() string {
+	if len(c.CheckpointFile) == 0 {
+		return filepath.Join(c.Dir, defaultCheckpointFile)
+	}
+	return c.CheckpointFile
+}
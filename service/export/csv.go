@@ -0,0 +1,58 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvWriter writes a header of the column names, then one record per row. Bytes are hex encoded.
+type csvWriter struct {
+	table  table
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, t table) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	header := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		header[i] = c.name
+	}
+	err := writer.Write(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Write CSV header: %v", err)
+	}
+	return &csvWriter{table: t, writer: writer}, nil
+}
+
+func (w *csvWriter) writeRows(rows [][]interface{}) error {
+	record := make([]string, len(w.table.columns))
+	for _, row := range rows {
+		for i, c := range w.table.columns {
+			switch c.typ {
+			case int32Column:
+				record[i] = strconv.FormatInt(int64(row[i].(int32)), 10)
+			case int64Column:
+				record[i] = strconv.FormatInt(row[i].(int64), 10)
+			case boolColumn:
+				record[i] = strconv.FormatBool(row[i].(bool))
+			case stringColumn:
+				record[i] = row[i].(string)
+			case bytesColumn:
+				record[i] = hex.EncodeToString(row[i].([]byte))
+			}
+		}
+		err := w.writer.Write(record)
+		if err != nil {
+			return fmt.Errorf("failed to Write CSV record: %v", err)
+		}
+	}
+	return nil
+}
+
+func (w *csvWriter) close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
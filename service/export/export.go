@@ -0,0 +1,227 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/store"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Exporter writes the indexed data of a height range into files per table & partition of heights.
+type Exporter struct {
+	cfg     Config
+	manager store.Manager
+}
+
+func NewExporter(cfg Config, manager store.Manager) *Exporter {
+	return &Exporter{
+		cfg:     cfg,
+		manager: manager,
+	}
+}
+
+// Export writes the partitions not written yet, the checkpoint file being updated after each one.
+// A partition file is only renamed to its final name once complete, so an interrupted export is resumed
+// by calling Export again with the same config.
+func (e *Exporter) Export(ctx context.Context) error {
+	err := e.cfg.Validate()
+	if err != nil {
+		return err
+	}
+
+	checkpointFile := e.cfg.checkpointFile()
+	saved, err := loadCheckpoint(checkpointFile)
+	if err != nil {
+		return err
+	}
+
+	toHeight := e.cfg.ToHeight
+	if toHeight < 0 {
+		if saved != nil {
+			toHeight = saved.ToHeight
+		} else {
+			block, err := e.manager.GetLatestBlock(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to Get Latest Block: %v", err)
+			}
+			toHeight = block.Height - e.cfg.confirmations() + 1
+		}
+	}
+	if toHeight < e.cfg.FromHeight {
+		return common.ErrInvalidRange
+	}
+	toBlock, err := e.manager.GetBlock(ctx, toHeight)
+	if err != nil {
+		return fmt.Errorf("failed to Get Block at height '%d' to export to: %v", toHeight, err)
+	}
+
+	state := &checkpoint{
+		Format:         e.cfg.Format,
+		FromHeight:     e.cfg.FromHeight,
+		ToHeight:       toHeight,
+		ToHash:         toBlock.Hash,
+		HeightsPerFile: e.cfg.heightsPerFile(),
+		Addresses:      addressesDigest(e.cfg.Addresses),
+		NextHeight:     e.cfg.FromHeight,
+	}
+	if saved != nil {
+		if !saved.sameExport(state) {
+			return fmt.Errorf("checkpoint file '%s' is of another export: %+v", checkpointFile, saved)
+		}
+		if len(saved.ToHash) > 0 && saved.ToHash != state.ToHash {
+			return fmt.Errorf("block at height '%d' of checkpoint file '%s' reorganized since the export started, hash '%s' instead of '%s'",
+				toHeight, checkpointFile, state.ToHash, saved.ToHash)
+		}
+		state.NextHeight = saved.NextHeight
+		log.L().Info("Resume Export", zap.Int64("NextHeight", state.NextHeight), zap.Int64("ToHeight", toHeight))
+	}
+
+	err = os.MkdirAll(filepath.Dir(checkpointFile), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to Create Checkpoint dir: %v", err)
+	}
+
+	for state.NextHeight <= toHeight {
+		fromHeight := state.NextHeight
+		partitionToHeight := fromHeight + state.HeightsPerFile - 1
+		if partitionToHeight > toHeight {
+			partitionToHeight = toHeight
+		}
+
+		err = e.exportPartition(ctx, fromHeight, partitionToHeight)
+		if err != nil {
+			return fmt.Errorf("failed to Export Partition from height '%d' to '%d': %v", fromHeight, partitionToHeight, err)
+		}
+
+		state.NextHeight = partitionToHeight + 1
+		err = saveCheckpoint(checkpointFile, state)
+		if err != nil {
+			return err
+		}
+		log.L().Info("Exported Partition", zap.Int64("FromHeight", fromHeight), zap.Int64("ToHeight", partitionToHeight))
+	}
+	return nil
+}
+
+func (e *Exporter) exportPartition(ctx context.Context, fromHeight, toHeight int64) (err error) {
+	files := make([]*partitionFile, 0, len(tables))
+	defer func() {
+		if err != nil {
+			for _, f := range files {
+				f.abort()
+			}
+		}
+	}()
+	for _, t := range tables {
+		f, err := e.createPartitionFile(t, fromHeight, toHeight)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	for height := fromHeight; height <= toHeight; height += e.cfg.heightsPerBatch() {
+		batchToHeight := height + e.cfg.heightsPerBatch() - 1
+		if batchToHeight > toHeight {
+			batchToHeight = toHeight
+		}
+
+		blocks, txs, txIns, txOuts, err := e.manager.GetBlocksDataInRange(ctx, height, batchToHeight, e.cfg.Addresses)
+		if err != nil {
+			return fmt.Errorf("failed to Get Blocks Data from height '%d' to '%d': %v", height, batchToHeight, err)
+		}
+
+		data := &blocksData{blocks: blocks, txs: txs, txIns: txIns, txOuts: txOuts}
+		for i, t := range tables {
+			err = files[i].writer.writeRows(t.rows(data))
+			if err != nil {
+				return fmt.Errorf("failed to Write Rows of '%s': %v", t.name, err)
+			}
+		}
+	}
+
+	for _, f := range files {
+		err = f.finish()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fileWriter interface {
+	writeRows(rows [][]interface{}) error
+	close() error
+}
+
+func newFileWriter(format string, w io.Writer, t table) (fileWriter, error) {
+	if format == FormatCSV {
+		return newCSVWriter(w, t)
+	}
+	return newParquetWriter(w, t)
+}
+
+// partitionFile is written under a temporary name, renamed to the final one by finish
+type partitionFile struct {
+	path   string
+	file   *os.File
+	buf    *bufio.Writer
+	writer fileWriter
+}
+
+func (e *Exporter) createPartitionFile(t table, fromHeight, toHeight int64) (*partitionFile, error) {
+	dir := filepath.Join(e.cfg.Dir, t.name)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Create dir '%s': %v", dir, err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%010d-%010d.%s", fromHeight, toHeight, e.cfg.Format))
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to Create file '%s': %v", path+".tmp", err)
+	}
+
+	f := &partitionFile{path: path, file: file, buf: bufio.NewWriter(file)}
+	f.writer, err = newFileWriter(e.cfg.Format, f.buf, t)
+	if err != nil {
+		f.abort()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *partitionFile) finish() error {
+	err := f.writer.close()
+	if err != nil {
+		return fmt.Errorf("failed to Close writer of '%s': %v", f.path, err)
+	}
+	err = f.buf.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to Flush file '%s': %v", f.path, err)
+	}
+	err = f.file.Sync()
+	if err != nil {
+		return fmt.Errorf("failed to Sync file '%s': %v", f.path, err)
+	}
+	err = f.file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to Close file '%s': %v", f.path, err)
+	}
+	err = os.Rename(f.file.Name(), f.path)
+	if err != nil {
+		return fmt.Errorf("failed to Rename file '%s': %v", f.file.Name(), err)
+	}
+	return nil
+}
+
+func (f *partitionFile) abort() {
+	_ = f.file.Close()
+	_ = os.Remove(f.file.Name())
+}
@@ -0,0 +1,196 @@
+package export
+
+import (
+	"context"
+	"encoding/hex"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"github.com/darkknightbk52/btc-indexer/store"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func init() {
+	log.Init(false)
+}
+
+// hashOf returns a valid hash made of the name, as the store only takes valid hashes.
+func hashOf(name string) string {
+	h := hex.EncodeToString([]byte(name))
+	return h + strings.Repeat("0", 64-len(h))
+}
+
+func newTestManager(t *testing.T, numBlocks int64) store.Manager {
+	manager := store.NewMemoryManager()
+	coinBase := true
+	for height := int64(0); height < numBlocks; height++ {
+		h := strconv.FormatInt(height, 10)
+		err := manager.AddBlocksData(context.Background(),
+			[]*model.Block{{Height: height, Hash: hashOf("block" + h), PreviousHash: hashOf("block" + strconv.FormatInt(height-1, 10))}},
+			[]*model.Tx{{Height: height, Hash: hashOf("tx" + h), CoinBase: &coinBase}},
+			nil,
+			[]*model.TxOut{{Height: height, TxHash: hashOf("tx" + h), TxIndex: 0, Value: 50, Address: "miner" + h, ScriptPubKey: []byte{0xab, byte(height)}, CoinBase: &coinBase}},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return manager
+}
+
+func TestExporter_CSV(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "export")
+	Expect(err).Should(Succeed())
+	defer os.RemoveAll(dir)
+
+	cfg := Config{Dir: dir, Format: FormatCSV, ToHeight: -1, Confirmations: 1, HeightsPerFile: 2, HeightsPerBatch: 1}
+	err = NewExporter(cfg, newTestManager(t, 5)).Export(context.Background())
+	Expect(err).Should(Succeed())
+
+	for _, name := range []string{"0000000000-0000000001.csv", "0000000002-0000000003.csv", "0000000004-0000000004.csv"} {
+		for _, tableName := range []string{"blocks", "txes", "tx_ins", "tx_outs"} {
+			Expect(filepath.Join(dir, tableName, name)).Should(BeAnExistingFile())
+		}
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "tx_outs", "0000000002-0000000003.csv"))
+	Expect(err).Should(Succeed())
+	Expect(string(data)).Should(Equal("height,tx_hash,tx_index,value,address,script_pub_key,coin_base\n" +
+		"2," + hashOf("tx2") + ",0,50,miner2,ab02,true\n" +
+		"3," + hashOf("tx3") + ",0,50,miner3,ab03,true\n"))
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "tx_ins", "0000000004-0000000004.csv"))
+	Expect(err).Should(Succeed())
+	Expect(string(data)).Should(Equal("height,tx_hash,tx_index,address,previous_tx_hash,previous_tx_index\n"))
+
+	saved, err := loadCheckpoint(filepath.Join(dir, defaultCheckpointFile))
+	Expect(err).Should(Succeed())
+	Expect(saved.NextHeight).Should(Equal(int64(5)))
+	Expect(saved.ToHeight).Should(Equal(int64(4)))
+	Expect(saved.ToHash).Should(Equal(hashOf("block4")))
+}
+
+func TestExporter_Confirmations(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "export")
+	Expect(err).Should(Succeed())
+	defer os.RemoveAll(dir)
+
+	// Up to the latest block having 6 confirmations
+	manager := newTestManager(t, 10)
+	cfg := Config{Dir: dir, Format: FormatCSV, ToHeight: -1, HeightsPerFile: 2}
+	err = NewExporter(cfg, manager).Export(context.Background())
+	Expect(err).Should(Succeed())
+	saved, err := loadCheckpoint(filepath.Join(dir, defaultCheckpointFile))
+	Expect(err).Should(Succeed())
+	Expect(saved.ToHeight).Should(Equal(int64(4)))
+	Expect(saved.ToHash).Should(Equal(hashOf("block4")))
+
+	// The block exported to is reorganized before the export is resumed
+	saved.NextHeight = 2
+	Expect(saveCheckpoint(filepath.Join(dir, defaultCheckpointFile), saved)).Should(Succeed())
+	coinBase := true
+	err = manager.ReplaceBlocksData(context.Background(), []int64{4}, []*model.Block{{Height: 4, Hash: hashOf("other4"), PreviousHash: hashOf("block3")}},
+		[]*model.Tx{{Height: 4, Hash: hashOf("other4"), CoinBase: &coinBase}}, nil, nil)
+	Expect(err).Should(Succeed())
+	err = NewExporter(cfg, manager).Export(context.Background())
+	Expect(err).Should(HaveOccurred())
+
+	// Not enough confirmed blocks
+	cfg.Confirmations = 11
+	cfg.Dir, cfg.CheckpointFile = dir, filepath.Join(dir, "other.json")
+	err = NewExporter(cfg, manager).Export(context.Background())
+	Expect(err).Should(Equal(common.ErrInvalidRange))
+}
+
+func TestExporter_Addresses(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "export")
+	Expect(err).Should(Succeed())
+	defer os.RemoveAll(dir)
+
+	cfg := Config{Dir: dir, Format: FormatCSV, ToHeight: 2, Addresses: []string{"miner1"}}
+	err = NewExporter(cfg, newTestManager(t, 5)).Export(context.Background())
+	Expect(err).Should(Succeed())
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "txes", "0000000000-0000000002.csv"))
+	Expect(err).Should(Succeed())
+	Expect(string(data)).Should(Equal("height,hash,coin_base\n1," + hashOf("tx1") + ",true\n"))
+}
+
+func TestExporter_Resume(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "export")
+	Expect(err).Should(Succeed())
+	defer os.RemoveAll(dir)
+
+	manager := newTestManager(t, 5)
+	cfg := Config{Dir: dir, Format: FormatParquet, HeightsPerFile: 2, ToHeight: -1}
+	checkpointFile := filepath.Join(dir, defaultCheckpointFile)
+	err = saveCheckpoint(checkpointFile, &checkpoint{Format: FormatParquet, ToHeight: 4, HeightsPerFile: 2, NextHeight: 2})
+	Expect(err).Should(Succeed())
+
+	// A block indexed since the export started is not exported by the resumed one
+	coinBase := true
+	err = manager.AddBlocksData(context.Background(), []*model.Block{{Height: 5, Hash: hashOf("block5"), PreviousHash: hashOf("block4")}},
+		[]*model.Tx{{Height: 5, Hash: hashOf("tx5"), CoinBase: &coinBase}}, nil, nil)
+	Expect(err).Should(Succeed())
+
+	err = NewExporter(cfg, manager).Export(context.Background())
+	Expect(err).Should(Succeed())
+	Expect(filepath.Join(dir, "blocks", "0000000000-0000000001.parquet")).ShouldNot(BeAnExistingFile())
+	Expect(filepath.Join(dir, "blocks", "0000000002-0000000003.parquet")).Should(BeAnExistingFile())
+	Expect(filepath.Join(dir, "blocks", "0000000004-0000000004.parquet")).Should(BeAnExistingFile())
+	Expect(filepath.Join(dir, "blocks", "0000000004-0000000005.parquet")).ShouldNot(BeAnExistingFile())
+
+	saved, err := loadCheckpoint(checkpointFile)
+	Expect(err).Should(Succeed())
+	Expect(saved.NextHeight).Should(Equal(int64(5)))
+
+	// Nothing left to export
+	err = NewExporter(cfg, manager).Export(context.Background())
+	Expect(err).Should(Succeed())
+
+	// The checkpoint of another export is not resumed
+	cfg.Addresses = []string{"miner1"}
+	err = NewExporter(cfg, manager).Export(context.Background())
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestExporter_Canceled(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "export")
+	Expect(err).Should(Succeed())
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cfg := Config{Dir: dir, Format: FormatCSV, ToHeight: 4}
+	err = NewExporter(cfg, &canceledManager{Manager: newTestManager(t, 5)}).Export(ctx)
+	Expect(err).Should(HaveOccurred())
+
+	// Neither partial files nor checkpoint are left
+	files, err := ioutil.ReadDir(filepath.Join(dir, "blocks"))
+	Expect(err).Should(Succeed())
+	Expect(files).Should(BeEmpty())
+	Expect(filepath.Join(dir, defaultCheckpointFile)).ShouldNot(BeAnExistingFile())
+}
+
+// canceledManager fails reads of canceled contexts like the DB backed managers, unlike the memory one
+type canceledManager struct {
+	store.Manager
+}
+
+func (m *canceledManager) GetBlocksDataInRange(ctx context.Context, fromHeight, toHeight int64, addresses []string) ([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut, error) {
+	if ctx.Err() != nil {
+		return nil, nil, nil, nil, ctx.Err()
+	}
+	return m.Manager.GetBlocksDataInRange(ctx, fromHeight, toHeight, addresses)
+}
@@ -0,0 +1,307 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// parquetWriter writes a Parquet file having one row group per written batch. Each column chunk holds one
+// uncompressed PLAIN data page, all columns being required, so any Parquet reader can load the files
+// without pulling a Parquet library in here.
+type parquetWriter struct {
+	table     table
+	writer    io.Writer
+	offset    int64
+	numRows   int64
+	rowGroups []rowGroup
+}
+
+type rowGroup struct {
+	numRows   int64
+	totalSize int64
+	chunks    []columnChunk
+}
+
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+const (
+	parquetMagic     = "PAR1"
+	parquetCreatedBy = "btc-indexer"
+
+	// Parquet physical types
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetRequired   = 0
+	parquetUTF8       = 0
+	parquetPlain      = 0
+	parquetRLE        = 3
+	parquetDataPage   = 0
+	parquetUncompress = 0
+)
+
+func newParquetWriter(w io.Writer, t table) (*parquetWriter, error) {
+	p := &parquetWriter{table: t, writer: w}
+	err := p.write([]byte(parquetMagic))
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.writer.Write(b)
+	p.offset += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to Write Parquet file: %v", err)
+	}
+	return nil
+}
+
+func (p *parquetWriter) writeRows(rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	group := rowGroup{numRows: int64(len(rows))}
+	for i, c := range p.table.columns {
+		values := encodePlain(c.typ, rows, i)
+
+		header := newCompactWriter()
+		header.i32(1, parquetDataPage)
+		header.i32(2, int32(len(values)))
+		header.i32(3, int32(len(values)))
+		header.beginStruct(5)
+		header.i32(1, int32(len(rows)))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.endStruct()
+		header.endStruct()
+
+		chunk := columnChunk{offset: p.offset, size: int64(header.buf.Len() + len(values))}
+		err := p.write(header.buf.Bytes())
+		if err != nil {
+			return err
+		}
+		err = p.write(values)
+		if err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		group.totalSize += chunk.size
+	}
+	p.rowGroups = append(p.rowGroups, group)
+	p.numRows += group.numRows
+	return nil
+}
+
+func (p *parquetWriter) close() error {
+	meta := newCompactWriter()
+	meta.i32(1, 1)
+	meta.list(2, compactStruct, len(p.table.columns)+1)
+	meta.beginElement()
+	meta.string(4, "schema")
+	meta.i32(5, int32(len(p.table.columns)))
+	meta.endStruct()
+	for _, c := range p.table.columns {
+		meta.beginElement()
+		meta.i32(1, physicalType(c.typ))
+		meta.i32(3, parquetRequired)
+		meta.string(4, c.name)
+		if c.typ == stringColumn {
+			meta.i32(6, parquetUTF8)
+		}
+		meta.endStruct()
+	}
+	meta.i64(3, p.numRows)
+	meta.list(4, compactStruct, len(p.rowGroups))
+	for _, group := range p.rowGroups {
+		meta.beginElement()
+		meta.list(1, compactStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			c := p.table.columns[i]
+			meta.beginElement()
+			meta.i64(2, chunk.offset)
+			meta.beginStruct(3)
+			meta.i32(1, physicalType(c.typ))
+			meta.list(2, compactI32, 1)
+			meta.i32Element(parquetPlain)
+			meta.list(3, compactBinary, 1)
+			meta.stringElement(c.name)
+			meta.i32(4, parquetUncompress)
+			meta.i64(5, group.numRows)
+			meta.i64(6, chunk.size)
+			meta.i64(7, chunk.size)
+			meta.i64(9, chunk.offset)
+			meta.endStruct()
+			meta.endStruct()
+		}
+		meta.i64(2, group.totalSize)
+		meta.i64(3, group.numRows)
+		meta.endStruct()
+	}
+	meta.string(6, parquetCreatedBy)
+	meta.endStruct()
+
+	err := p.write(meta.buf.Bytes())
+	if err != nil {
+		return err
+	}
+	footer := make([]byte, 4, 4+len(parquetMagic))
+	binary.LittleEndian.PutUint32(footer, uint32(meta.buf.Len()))
+	return p.write(append(footer, parquetMagic...))
+}
+
+func physicalType(typ columnType) int32 {
+	switch typ {
+	case int32Column:
+		return parquetInt32
+	case int64Column:
+		return parquetInt64
+	case boolColumn:
+		return parquetBoolean
+	default:
+		return parquetByteArray
+	}
+}
+
+// encodePlain encodes the values of the column 'index' of rows with the PLAIN encoding:
+// little endian numbers, bit packed booleans and length prefixed byte arrays.
+func encodePlain(typ columnType, rows [][]interface{}, index int) []byte {
+	var buf bytes.Buffer
+	var b [8]byte
+	switch typ {
+	case boolColumn:
+		packed := make([]byte, (len(rows)+7)/8)
+		for i, row := range rows {
+			if row[index].(bool) {
+				packed[i/8] |= 1 << uint(i%8)
+			}
+		}
+		buf.Write(packed)
+	case int32Column:
+		for _, row := range rows {
+			binary.LittleEndian.PutUint32(b[:4], uint32(row[index].(int32)))
+			buf.Write(b[:4])
+		}
+	case int64Column:
+		for _, row := range rows {
+			binary.LittleEndian.PutUint64(b[:], uint64(row[index].(int64)))
+			buf.Write(b[:])
+		}
+	case stringColumn, bytesColumn:
+		for _, row := range rows {
+			var value []byte
+			if s, ok := row[index].(string); ok {
+				value = []byte(s)
+			} else {
+				value = row[index].([]byte)
+			}
+			binary.LittleEndian.PutUint32(b[:4], uint32(len(value)))
+			buf.Write(b[:4])
+			buf.Write(value)
+		}
+	}
+	return buf.Bytes()
+}
+
+// Thrift compact protocol types, the one used by the Parquet metadata
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// compactWriter encodes a Thrift struct with the compact protocol. Fields of a struct must be written
+// in increasing id order, and every struct, list elements included, must be ended with endStruct.
+type compactWriter struct {
+	buf bytes.Buffer
+	// lastIDs are the ids of the last fields written in the nested structs
+	lastIDs []int16
+}
+
+func newCompactWriter() *compactWriter {
+	return &compactWriter{lastIDs: []int16{0}}
+}
+
+func (w *compactWriter) field(id int16, typ byte) {
+	last := len(w.lastIDs) - 1
+	if delta := id - w.lastIDs[last]; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(zigzag(int64(id)))
+	}
+	w.lastIDs[last] = id
+}
+
+func (w *compactWriter) varint(v uint64) {
+	for v >= 0x80 {
+		w.buf.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	w.buf.WriteByte(byte(v))
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func (w *compactWriter) i32(id int16, v int32) {
+	w.field(id, compactI32)
+	w.i32Element(v)
+}
+
+func (w *compactWriter) i64(id int16, v int64) {
+	w.field(id, compactI64)
+	w.varint(zigzag(v))
+}
+
+func (w *compactWriter) string(id int16, s string) {
+	w.field(id, compactBinary)
+	w.stringElement(s)
+}
+
+func (w *compactWriter) beginStruct(id int16) {
+	w.field(id, compactStruct)
+	w.beginElement()
+}
+
+func (w *compactWriter) endStruct() {
+	w.buf.WriteByte(0)
+	w.lastIDs = w.lastIDs[:len(w.lastIDs)-1]
+}
+
+func (w *compactWriter) list(id int16, elemType byte, size int) {
+	w.field(id, compactList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		w.buf.WriteByte(0xf0 | elemType)
+		w.varint(uint64(size))
+	}
+}
+
+// beginElement begins a struct element of a list
+func (w *compactWriter) beginElement() {
+	w.lastIDs = append(w.lastIDs, 0)
+}
+
+func (w *compactWriter) i32Element(v int32) {
+	w.varint(zigzag(int64(v)))
+}
+
+func (w *compactWriter) stringElement(s string) {
+	w.varint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
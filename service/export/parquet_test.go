@@ -0,0 +1,166 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// compactReader decodes the Thrift compact structs written by compactWriter into maps of field ids to values
+type compactReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *compactReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *compactReader) varint() uint64 {
+	var v uint64
+	for shift := uint(0); ; shift += 7 {
+		b := r.byte()
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return v
+		}
+	}
+}
+
+func (r *compactReader) int() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *compactReader) value(typ byte) interface{} {
+	switch typ {
+	case compactI32, compactI64:
+		return r.int()
+	case compactBinary:
+		n := int(r.varint())
+		s := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case compactList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case compactStruct:
+		return r.structValue()
+	}
+	panic("unexpected type")
+}
+
+func (r *compactReader) structValue() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var id int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		if delta := int16(header >> 4); delta > 0 {
+			id += delta
+		} else {
+			id = int16(r.int())
+		}
+		fields[id] = r.value(header & 0x0f)
+	}
+}
+
+// update rewrites the golden files instead of comparing with them
+var update = flag.Bool("update", false, "update the golden files")
+
+// writeTestRows writes 20 rows of tx outs in 2 row groups, returning them.
+func writeTestRows(w *parquetWriter) [][]interface{} {
+	var rows [][]interface{}
+	for i := int64(0); i < 20; i++ {
+		rows = append(rows, []interface{}{i, "tx", int32(i), i * 100, "address", []byte{byte(i)}, i%3 == 0})
+	}
+	Expect(w.writeRows(rows[:16])).Should(Succeed())
+	Expect(w.writeRows(nil)).Should(Succeed())
+	Expect(w.writeRows(rows[16:])).Should(Succeed())
+	Expect(w.close()).Should(Succeed())
+	return rows
+}
+
+// TestParquetWriter_Golden compares with a file read by another implementation, github.com/xitongsys/parquet-go v1.6.2,
+// giving the 20 rows in 2 row groups with the types of the schema. After a change of the format, run the test
+// with -update then read testdata/tx_outs.parquet again with another implementation, e.g. pyarrow or parquet-tools.
+func TestParquetWriter_Golden(t *testing.T) {
+	RegisterTestingT(t)
+	var buf bytes.Buffer
+	w, err := newParquetWriter(&buf, tables[3])
+	Expect(err).Should(Succeed())
+	writeTestRows(w)
+
+	golden := filepath.Join("testdata", "tx_outs.parquet")
+	if *update {
+		Expect(ioutil.WriteFile(golden, buf.Bytes(), 0644)).Should(Succeed())
+	}
+	expected, err := ioutil.ReadFile(golden)
+	Expect(err).Should(Succeed())
+	Expect(buf.Bytes()).Should(Equal(expected))
+}
+
+func TestParquetWriter(t *testing.T) {
+	RegisterTestingT(t)
+	var buf bytes.Buffer
+	outs := tables[3]
+	w, err := newParquetWriter(&buf, outs)
+	Expect(err).Should(Succeed())
+
+	rows := writeTestRows(w)
+
+	data := buf.Bytes()
+	Expect(string(data[:4])).Should(Equal(parquetMagic))
+	Expect(string(data[len(data)-4:])).Should(Equal(parquetMagic))
+	metaSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := (&compactReader{data: data[len(data)-8-metaSize : len(data)-8]}).structValue()
+
+	Expect(meta[3]).Should(Equal(int64(20)))
+	Expect(meta[6]).Should(Equal(parquetCreatedBy))
+	schema := meta[2].([]interface{})
+	Expect(schema).Should(HaveLen(len(outs.columns) + 1))
+	Expect(schema[0].(map[int16]interface{})[5]).Should(Equal(int64(len(outs.columns))))
+	for i, c := range outs.columns {
+		Expect(schema[i+1].(map[int16]interface{})[4]).Should(Equal(c.name))
+	}
+
+	rowGroups := meta[4].([]interface{})
+	Expect(rowGroups).Should(HaveLen(2))
+	Expect(rowGroups[0].(map[int16]interface{})[3]).Should(Equal(int64(16)))
+	Expect(rowGroups[1].(map[int16]interface{})[3]).Should(Equal(int64(4)))
+
+	// The value column of the 2nd row group
+	chunk := rowGroups[1].(map[int16]interface{})[1].([]interface{})[3].(map[int16]interface{})
+	columnMeta := chunk[3].(map[int16]interface{})
+	Expect(columnMeta[3]).Should(Equal([]interface{}{"value"}))
+	Expect(columnMeta[5]).Should(Equal(int64(4)))
+	page := &compactReader{data: data, pos: int(columnMeta[9].(int64))}
+	header := page.structValue()
+	Expect(header[2]).Should(Equal(int64(4 * 8)))
+	Expect(header[5].(map[int16]interface{})[1]).Should(Equal(int64(4)))
+	for i := int64(16); i < 20; i++ {
+		Expect(int64(binary.LittleEndian.Uint64(data[page.pos:]))).Should(Equal(i * 100))
+		page.pos += 8
+	}
+	Expect(int64(page.pos)).Should(Equal(columnMeta[9].(int64) + columnMeta[7].(int64)))
+
+	// Booleans are bit packed
+	values := encodePlain(boolColumn, rows, 6)
+	Expect(values).Should(Equal([]byte{0x49, 0x92, 0x04}))
+}
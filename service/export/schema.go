@@ -0,0 +1,109 @@
+package export
+
+import (
+	"github.com/darkknightbk52/btc-indexer/model"
+)
+
+type columnType int
+
+const (
+	int32Column columnType = iota
+	int64Column
+	boolColumn
+	stringColumn
+	bytesColumn
+)
+
+type column struct {
+	name string
+	typ  columnType
+}
+
+// table is the stable schema of an exported table: columns are only ever appended, never renamed or reordered,
+// so files of different exports can be read together.
+type table struct {
+	name    string
+	columns []column
+	rows    func(data *blocksData) [][]interface{}
+}
+
+type blocksData struct {
+	blocks []*model.Block
+	txs    []*model.Tx
+	txIns  []*model.TxIn
+	txOuts []*model.TxOut
+}
+
+var tables = []table{
+	{
+		name: model.Block{}.TableName(),
+		columns: []column{
+			{name: "height", typ: int64Column},
+			{name: "hash", typ: stringColumn},
+			{name: "previous_hash", typ: stringColumn},
+		},
+		rows: func(data *blocksData) [][]interface{} {
+			rows := make([][]interface{}, 0, len(data.blocks))
+			for _, b := range data.blocks {
+				rows = append(rows, []interface{}{b.Height, b.Hash, b.PreviousHash})
+			}
+			return rows
+		},
+	},
+	{
+		name: model.Tx{}.TableName(),
+		columns: []column{
+			{name: "height", typ: int64Column},
+			{name: "hash", typ: stringColumn},
+			{name: "coin_base", typ: boolColumn},
+		},
+		rows: func(data *blocksData) [][]interface{} {
+			rows := make([][]interface{}, 0, len(data.txs))
+			for _, tx := range data.txs {
+				rows = append(rows, []interface{}{tx.Height, tx.Hash, isTrue(tx.CoinBase)})
+			}
+			return rows
+		},
+	},
+	{
+		name: model.TxIn{}.TableName(),
+		columns: []column{
+			{name: "height", typ: int64Column},
+			{name: "tx_hash", typ: stringColumn},
+			{name: "tx_index", typ: int32Column},
+			{name: "address", typ: stringColumn},
+			{name: "previous_tx_hash", typ: stringColumn},
+			{name: "previous_tx_index", typ: int32Column},
+		},
+		rows: func(data *blocksData) [][]interface{} {
+			rows := make([][]interface{}, 0, len(data.txIns))
+			for _, in := range data.txIns {
+				rows = append(rows, []interface{}{in.Height, in.TxHash, in.TxIndex, in.Address, in.PreviousTxHash, in.PreviousTxIndex})
+			}
+			return rows
+		},
+	},
+	{
+		name: model.TxOut{}.TableName(),
+		columns: []column{
+			{name: "height", typ: int64Column},
+			{name: "tx_hash", typ: stringColumn},
+			{name: "tx_index", typ: int32Column},
+			{name: "value", typ: int64Column},
+			{name: "address", typ: stringColumn},
+			{name: "script_pub_key", typ: bytesColumn},
+			{name: "coin_base", typ: boolColumn},
+		},
+		rows: func(data *blocksData) [][]interface{} {
+			rows := make([][]interface{}, 0, len(data.txOuts))
+			for _, out := range data.txOuts {
+				rows = append(rows, []interface{}{out.Height, out.TxHash, out.TxIndex, out.Value, out.Address, out.ScriptPubKey, isTrue(out.CoinBase)})
+			}
+			return rows
+		},
+	},
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
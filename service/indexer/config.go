@@ -10,6 +10,8 @@ type Config struct {
 	Network            string
 	IncludeNonStandard bool
 	FromBlockHeight    int64
+	// DisableBlockValidation turns off the local checks of blocks given by Full Node, e.g. for private networks
+	DisableBlockValidation bool
 }
 
 func (c Config) Validate() error {
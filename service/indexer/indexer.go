@@ -27,23 +27,35 @@ type Indexer struct {
 	subscriber   subscriber.Subscriber
 	manager      store.Manager
 	client       bcClient.Client
+	validator    *validator
+	// mu serializes the sync of Listen and the repair of Verify, both writing blocks data from currentBlock
+	mu sync.Mutex
 }
 
 const (
 	blockBatchSize = 100
+	// reorgBatchSize is the number of headers fetched at once while looking for the fork point in local chain
+	reorgBatchSize = 6
 )
 
 func NewIndexer(config Config, subscriber subscriber.Subscriber, manager store.Manager, client bcClient.Client) *Indexer {
-	return &Indexer{
+	idx := &Indexer{
 		config:     config,
 		subscriber: subscriber,
 		manager:    manager,
 		client:     client,
 	}
+	if !config.DisableBlockValidation {
+		chainParams := config.ChainParams()
+		idx.validator = newValidator(&chainParams, client)
+	}
+	return idx
 }
 
 func (idx *Indexer) Listen(ctx context.Context, fromBlockHeight int64) error {
-	err := idx.initState(fromBlockHeight)
+	idx.mu.Lock()
+	err := idx.initState(ctx, fromBlockHeight)
+	idx.mu.Unlock()
 	if err != nil {
 		return fmt.Errorf("failed to Init State: %v", err)
 	}
@@ -73,7 +85,9 @@ func (idx *Indexer) Listen(ctx context.Context, fromBlockHeight int64) error {
 				continue
 			}
 
-			err := idx.sync(msg)
+			idx.mu.Lock()
+			err := idx.sync(listenCtx, msg)
+			idx.mu.Unlock()
 			if err != nil {
 				log.L().Warn("Failed to Sync", zap.Error(err))
 			}
@@ -81,7 +95,7 @@ func (idx *Indexer) Listen(ctx context.Context, fromBlockHeight int64) error {
 	}
 }
 
-func (idx *Indexer) sync(msg [][]byte) error {
+func (idx *Indexer) sync(ctx context.Context, msg [][]byte) error {
 	msgType := string(msg[0])
 	switch msgType {
 	case "rawblock":
@@ -91,7 +105,7 @@ func (idx *Indexer) sync(msg [][]byte) error {
 			return fmt.Errorf("failed to Deserialize Raw Block: %v", err)
 		}
 		sequence := binary.LittleEndian.Uint32(msg[2])
-		return idx.syncBlock(rawBlock, sequence)
+		return idx.syncBlock(ctx, rawBlock, sequence)
 	case "rawtx":
 		rawTx := new(wire.MsgTx)
 		err := rawTx.Deserialize(bytes.NewBuffer(msg[1]))
@@ -99,7 +113,7 @@ func (idx *Indexer) sync(msg [][]byte) error {
 			return fmt.Errorf("failed to Deserialize Raw Tx: %v", err)
 		}
 		sequence := binary.LittleEndian.Uint32(msg[2])
-		return idx.syncTx(rawTx, sequence)
+		return idx.syncTx(ctx, rawTx, sequence)
 	default:
 		// It's possible that the message wasn't fully read if
 		// Full Node shuts down, which will produce an unreadable
@@ -113,8 +127,8 @@ func (idx *Indexer) sync(msg [][]byte) error {
 	}
 }
 
-func (idx *Indexer) syncBlock(rawBlock *wire.MsgBlock, sequence uint32) error {
-	targetBlockHeader, err := idx.client.GetBlockHeaderVerboseByHash(rawBlock.BlockHash().String())
+func (idx *Indexer) syncBlock(ctx context.Context, rawBlock *wire.MsgBlock, sequence uint32) error {
+	targetBlockHeader, err := idx.client.GetBlockHeaderVerboseByHash(ctx, rawBlock.BlockHash().String())
 	if err != nil {
 		return fmt.Errorf("failed to Get Block Header Verbose By Hash '%s': %v", rawBlock.BlockHash().String(), err)
 	}
@@ -128,7 +142,7 @@ func (idx *Indexer) syncBlock(rawBlock *wire.MsgBlock, sequence uint32) error {
 		nextBlockHeader := targetBlockHeader
 		if targetBlockHeight-idx.currentBlock.Height > blockBatchSize {
 			nextBlockHeight := idx.currentBlock.Height + blockBatchSize
-			header, err := idx.client.GetBlockHeaderVerboseByHeight(nextBlockHeight)
+			header, err := idx.client.GetBlockHeaderVerboseByHeight(ctx, nextBlockHeight)
 			if err != nil {
 				return fmt.Errorf("failed to Get Block Header Verbose By Height '%d': %v", targetBlockHeight, err)
 			}
@@ -139,7 +153,7 @@ func (idx *Indexer) syncBlock(rawBlock *wire.MsgBlock, sequence uint32) error {
 			zap.Int32("Target Height", nextBlockHeader.Height), zap.String("Target Hash", nextBlockHeader.Hash),
 			zap.Int64("Current Height", idx.currentBlock.Height), zap.String("Current Hash", idx.currentBlock.Hash))
 
-		header, err := idx.syncBlockMaybeReorg(nextBlockHeader)
+		header, err := idx.syncBlockMaybeReorg(ctx, nextBlockHeader)
 		if err != nil {
 			return fmt.Errorf("failed to Sync Block Maybe Reorg, to Height '%d': %v", nextBlockHeader.Height, err)
 		}
@@ -154,14 +168,14 @@ func (idx *Indexer) syncBlock(rawBlock *wire.MsgBlock, sequence uint32) error {
 	return nil
 }
 
-func (idx *Indexer) syncBlockMaybeReorg(header *btcjson.GetBlockHeaderVerboseResult) (*btcjson.GetBlockHeaderVerboseResult, error) {
+func (idx *Indexer) syncBlockMaybeReorg(ctx context.Context, header *btcjson.GetBlockHeaderVerboseResult) (*btcjson.GetBlockHeaderVerboseResult, error) {
 	if idx.currentBlock.Height >= int64(header.Height) {
 		// Ignore old block
 		return nil, nil
 	}
 
 	if idx.currentBlock.Height == int64(header.Height)-1 && idx.currentBlock.Hash == header.PreviousHash {
-		highestBlockHeader, err := idx.addBlocks([]*btcjson.GetBlockHeaderVerboseResult{header})
+		highestBlockHeader, err := idx.addBlocks(ctx, []*btcjson.GetBlockHeaderVerboseResult{header})
 		if err != nil {
 			return nil, fmt.Errorf("failed to Add A New Block: %v", err)
 		}
@@ -173,8 +187,11 @@ func (idx *Indexer) syncBlockMaybeReorg(header *btcjson.GetBlockHeaderVerboseRes
 		FromHash:   idx.currentBlock.Hash,
 		ToHeight:   idx.currentBlock.Height,
 		ToHash:     idx.currentBlock.Hash,
+		NewHeight:  int64(header.Height),
+		NewHash:    header.Hash,
 	}
 	headers := []*btcjson.GetBlockHeaderVerboseResult{header}
+	var previousHeaders []*btcjson.GetBlockHeaderVerboseResult
 	var err error
 	for {
 		if idx.currentBlock.Height == int64(header.Height)-1 && idx.currentBlock.Hash == header.PreviousHash {
@@ -183,7 +200,7 @@ func (idx *Indexer) syncBlockMaybeReorg(header *btcjson.GetBlockHeaderVerboseRes
 		}
 
 		if idx.currentBlock.Height > int64(header.Height)-1 {
-			block, err := idx.manager.GetBlock(int64(header.Height) - 1)
+			block, err := idx.manager.GetBlock(ctx, int64(header.Height)-1)
 			if err != nil && err == common.ErrNotFound {
 				log.L().Warn("reorg examining - not found block in DB", zap.Int32("blockHeight", header.Height-1))
 				break
@@ -197,16 +214,19 @@ func (idx *Indexer) syncBlockMaybeReorg(header *btcjson.GetBlockHeaderVerboseRes
 			reorg.FromHeight = block.Height
 			reorg.FromHash = block.Hash
 		}
-		previousHash := header.PreviousHash
-		header, err = idx.client.GetBlockHeaderVerboseByHash(previousHash)
-		if err != nil {
-			return nil, fmt.Errorf("reorg examining - failed to Get Block eader Verbose By Hash '%s': %v", previousHash, err)
+		if len(previousHeaders) == 0 {
+			previousHeaders, err = idx.getPreviousHeaders(ctx, header)
+			if err != nil {
+				return nil, fmt.Errorf("reorg examining - failed to Get Previous Block Headers of '%s': %v", header.Hash, err)
+			}
 		}
+		header = previousHeaders[len(previousHeaders)-1]
+		previousHeaders = previousHeaders[:len(previousHeaders)-1]
 		headers = append(headers, header)
 	}
 
 	if reorg == nil {
-		highestBlockHeader, err := idx.addBlocks(headers)
+		highestBlockHeader, err := idx.addBlocks(ctx, headers)
 		if err != nil {
 			return nil, fmt.Errorf("failed to Add New Blocks, BlockNo '%d': %v", len(headers), err)
 		}
@@ -215,40 +235,95 @@ func (idx *Indexer) syncBlockMaybeReorg(header *btcjson.GetBlockHeaderVerboseRes
 
 	log.L().Info("Reorg happened", zap.Any("event", reorg))
 
-	err = idx.manager.Reorg(reorg)
+	err = idx.manager.Reorg(ctx, reorg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to Reorg: %v", err)
 	}
 	reorgBlock := header
-	bh, err := idx.client.GetBlockHeaderVerboseByHash(reorgBlock.PreviousHash)
+	bh, err := idx.client.GetBlockHeaderVerboseByHash(ctx, reorgBlock.PreviousHash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to Get Block Header Verbose By Hash '%s': %v", reorgBlock.PreviousHash, err)
 	}
 	return bh, nil
 }
 
-func (idx *Indexer) addBlocks(headers []*btcjson.GetBlockHeaderVerboseResult) (*btcjson.GetBlockHeaderVerboseResult, error) {
-	blocks, txs, txIns, txOuts, err := idx.buildBlocksData(headers)
+// getPreviousHeaders fetches in a batch the headers chained before header, in ascending order.
+// They go down to the current block while it's behind, or a few blocks into local chain to look for the fork point.
+func (idx *Indexer) getPreviousHeaders(ctx context.Context, header *btcjson.GetBlockHeaderVerboseResult) ([]*btcjson.GetBlockHeaderVerboseResult, error) {
+	to := int64(header.Height) - 1
+	if to < 0 {
+		return nil, fmt.Errorf("no previous block of Height '%d'", header.Height)
+	}
+	from := to - reorgBatchSize + 1
+	if to > idx.currentBlock.Height {
+		from = idx.currentBlock.Height + 1
+		if to-from+1 > blockBatchSize {
+			from = to - blockBatchSize + 1
+		}
+	}
+	if from < 0 {
+		from = 0
+	}
+
+	headers, err := idx.client.GetBlockHeadersVerboseByHeightRange(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Block Headers Verbose By Height Range '%d' - '%d': %v", from, to, err)
+	}
+
+	// Full Node may have switched to another chain meanwhile, just keep the headers chained to header
+	i := len(headers)
+	previousHash := header.PreviousHash
+	for i > 0 && headers[i-1].Hash == previousHash {
+		i--
+		previousHash = headers[i].PreviousHash
+	}
+	headers = headers[i:]
+	if len(headers) > 0 {
+		return headers, nil
+	}
+
+	previousHeader, err := idx.client.GetBlockHeaderVerboseByHash(ctx, header.PreviousHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Block Header Verbose By Hash '%s': %v", header.PreviousHash, err)
+	}
+	return []*btcjson.GetBlockHeaderVerboseResult{previousHeader}, nil
+}
+
+func (idx *Indexer) addBlocks(ctx context.Context, headers []*btcjson.GetBlockHeaderVerboseResult) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	blocks, txs, txIns, txOuts, err := idx.buildBlocksData(ctx, headers)
 	if err != nil {
 		return nil, fmt.Errorf("failed to Build Blocks Data: %v", err)
 	}
-	err = idx.manager.AddBlocksData(blocks, txs, txIns, txOuts)
+	err = idx.manager.AddBlocksData(ctx, blocks, txs, txIns, txOuts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to Add Blocks Data: %v", err)
 	}
 	return headers[0], nil
 }
 
-func (idx *Indexer) buildBlocksData(headers []*btcjson.GetBlockHeaderVerboseResult) ([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut, error) {
-	rawBlocks := make([]*wire.MsgBlock, 0, len(headers))
-	blocks := make([]*model.Block, 0, len(headers))
-	blockHashWithHeight := make(map[string]int64, len(headers))
+func (idx *Indexer) buildBlocksData(ctx context.Context, headers []*btcjson.GetBlockHeaderVerboseResult) ([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut, error) {
+	hashes := make([]string, 0, len(headers))
 	for _, h := range headers {
-		b, err := idx.client.GetRawBlock(h.Hash)
+		hashes = append(hashes, h.Hash)
+	}
+	rawBlocks, err := idx.client.GetRawBlocks(ctx, hashes)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to Get Raw Blocks, Hashes %v: %v", hashes, err)
+	}
+
+	if idx.validator != nil {
+		err = idx.validator.validate(ctx, headers, rawBlocks)
+		if e, ok := err.(*InvalidBlockError); ok {
+			log.L().Error("Rejected invalid Block from Full Node", zap.Int64("Height", e.Height), zap.String("Hash", e.Hash), zap.String("Reason", e.Reason))
+		}
 		if err != nil {
-			return nil, nil, nil, nil, fmt.Errorf("failed to Get Raw Block, Hash '%s': %v", h.Hash, err)
+			return nil, nil, nil, nil, fmt.Errorf("failed to Validate Blocks: %v", err)
 		}
-		rawBlocks = append(rawBlocks, b)
+	}
+
+	blocks := make([]*model.Block, 0, len(headers))
+	blockHashWithHeight := make(map[string]int64, len(headers))
+	for _, h := range headers {
 		blocks = append(blocks, &model.Block{
 			Height:       int64(h.Height),
 			Hash:         h.Hash,
@@ -270,7 +345,7 @@ func (idx *Indexer) buildBlocksData(headers []*btcjson.GetBlockHeaderVerboseResu
 	txIns := make([]*model.TxIn, 0, txInNo)
 	txOuts := make([]*model.TxOut, 0, txOutNo)
 	for _, b := range rawBlocks {
-		for _, tx := range b.Transactions {
+		for position, tx := range b.Transactions {
 			isCoinBase := blockchain.IsCoinBaseTx(tx)
 			height := blockHashWithHeight[b.BlockHash().String()]
 			txs = append(txs, &model.Tx{
@@ -278,7 +353,7 @@ func (idx *Indexer) buildBlocksData(headers []*btcjson.GetBlockHeaderVerboseResu
 				Hash:     tx.TxHash().String(),
 				CoinBase: &isCoinBase,
 			})
-			ins, outs := idx.buildTxData(height, tx, isCoinBase)
+			ins, outs := idx.buildTxData(height, int32(position), tx, isCoinBase)
 			txIns = append(txIns, ins...)
 			txOuts = append(txOuts, outs...)
 		}
@@ -287,7 +362,8 @@ func (idx *Indexer) buildBlocksData(headers []*btcjson.GetBlockHeaderVerboseResu
 	return blocks, txs, txIns, txOuts, nil
 }
 
-func (idx *Indexer) buildTxData(height int64, tx *wire.MsgTx, isCoinBase bool) ([]*model.TxIn, []*model.TxOut) {
+// buildTxData returns the ins & outs of the tx at a position in the block at a height.
+func (idx *Indexer) buildTxData(height int64, position int32, tx *wire.MsgTx, isCoinBase bool) ([]*model.TxIn, []*model.TxOut) {
 	txIns := make([]*model.TxIn, 0, len(tx.TxIn))
 	txOuts := make([]*model.TxOut, 0, len(tx.TxOut))
 	chainParams := idx.config.ChainParams()
@@ -305,6 +381,7 @@ func (idx *Indexer) buildTxData(height int64, tx *wire.MsgTx, isCoinBase bool) (
 			Address:         addr,
 			PreviousTxHash:  in.PreviousOutPoint.Hash.String(),
 			PreviousTxIndex: int32(in.PreviousOutPoint.Index),
+			TxPosition:      position,
 		})
 	}
 
@@ -326,28 +403,29 @@ func (idx *Indexer) buildTxData(height int64, tx *wire.MsgTx, isCoinBase bool) (
 			Address:      addr,
 			ScriptPubKey: out.PkScript,
 			CoinBase:     &isCoinBase,
+			TxPosition:   position,
 		})
 	}
 
 	return txIns, txOuts
 }
 
-func (idx *Indexer) syncTx(rawTx *wire.MsgTx, sequence uint32) error {
+func (idx *Indexer) syncTx(ctx context.Context, rawTx *wire.MsgTx, sequence uint32) error {
 	return nil
 }
 
-func (idx *Indexer) initState(fromBlockHeight int64) error {
-	block, err := idx.manager.GetLatestBlock()
+func (idx *Indexer) initState(ctx context.Context, fromBlockHeight int64) error {
+	block, err := idx.manager.GetLatestBlock(ctx)
 	if err != nil && err != common.ErrNotFound {
 		return fmt.Errorf("failed to Get Latest Block: %v", err)
 	}
 
 	if block == nil {
-		header, err := idx.client.GetBlockHeaderVerboseByHeight(fromBlockHeight)
+		header, err := idx.client.GetBlockHeaderVerboseByHeight(ctx, fromBlockHeight)
 		if err != nil {
 			return fmt.Errorf("failed to Get Block Header Verbose By Height '%d': %v", fromBlockHeight, err)
 		}
-		result, err := idx.addBlocks([]*btcjson.GetBlockHeaderVerboseResult{header})
+		result, err := idx.addBlocks(ctx, []*btcjson.GetBlockHeaderVerboseResult{header})
 		if err != nil {
 			return fmt.Errorf("failed to Add Initial Block: %v", err)
 		}
@@ -3,11 +3,14 @@ package indexer
 import (
 	"context"
 	"errors"
+	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
 	clientMock "github.com/darkknightbk52/btc-indexer/client/blockchain/mocks"
 	commonIndexer "github.com/darkknightbk52/btc-indexer/common"
 	"github.com/darkknightbk52/btc-indexer/common/log"
 	"github.com/darkknightbk52/btc-indexer/model"
+	"github.com/darkknightbk52/btc-indexer/store"
 	managerMock "github.com/darkknightbk52/btc-indexer/store/mocks"
 	subMock "github.com/darkknightbk52/btc-indexer/subscriber/mocks"
 	. "github.com/onsi/ginkgo"
@@ -56,21 +59,21 @@ var _ = Describe("Indexer Test", func() {
 	Context("Functional", func() {
 		Context("Listen - sync normally", func() {
 			It("Empty DB, full scan from genesis block", func() {
+				// Indexed into the memory store rather than expected by the mock
+				manager := store.NewMemoryManager()
+				indexer.manager = manager
+
 				// Start syncing from block 0
-				mockManager.On("GetLatestBlock").Return(nil, commonIndexer.ErrNotFound).Once()
-				mockClient.On("GetBlockHeaderVerboseByHeight", int64(0)).Return(rawBlockHeaders[0], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[0].Hash).Return(rawBlocks[0], nil).Once()
-				mockManager.On("AddBlocksData", []*model.Block{modelBlocks[0]}, modelTxs[0], modelTxIns[0], modelTxOuts[0]).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHeight", mock.Anything, int64(0)).Return(rawBlockHeaders[0], nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[0].Hash}).Return([]*wire.MsgBlock{rawBlocks[0]}, nil).Once()
 
 				// Sync block 1
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[1].BlockHash().String()).Return(rawBlockHeaders[1], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[1].Hash).Return(rawBlocks[1], nil).Once()
-				mockManager.On("AddBlocksData", []*model.Block{modelBlocks[1]}, modelTxs[1], modelTxIns[1], modelTxOuts[1]).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[1].BlockHash().String()).Return(rawBlockHeaders[1], nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[1].Hash}).Return([]*wire.MsgBlock{rawBlocks[1]}, nil).Once()
 
 				// Sync block 2
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[2].Hash).Return(rawBlocks[2], nil).Once()
-				mockManager.On("AddBlocksData", []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[2].Hash}).Return([]*wire.MsgBlock{rawBlocks[2]}, nil).Once()
 
 				ctx, cancel := context.WithCancel(context.Background())
 				go func() {
@@ -86,16 +89,23 @@ var _ = Describe("Indexer Test", func() {
 
 				err := indexer.Listen(ctx, 0)
 				Expect(err).Should(Equal(context.Canceled))
+
+				blocks, txs, txIns, txOuts, err := manager.GetBlocksDataInRange(context.Background(), 0, 2, nil)
+				Expect(err).Should(Succeed())
+				Expect(blocks).Should(Equal([]*model.Block{modelBlocks[0], modelBlocks[1], modelBlocks[2]}))
+				Expect(txs).Should(ConsistOf(append(append(append([]*model.Tx(nil), modelTxs[0]...), modelTxs[1]...), modelTxs[2]...)))
+				Expect(txIns).Should(ConsistOf(append(append(append([]*model.TxIn(nil), modelTxIns[0]...), modelTxIns[1]...), modelTxIns[2]...)))
+				Expect(txOuts).Should(ConsistOf(append(append(append([]*model.TxOut(nil), modelTxOuts[0]...), modelTxOuts[1]...), modelTxOuts[2]...)))
 			})
 
 			It("Local latest block as 1, be notified with block 2", func() {
 				// Start syncing from block 1
-				mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[1], nil).Once()
 
 				// Sync block 2
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[2].Hash).Return(rawBlocks[2], nil).Once()
-				mockManager.On("AddBlocksData", []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[2].Hash}).Return([]*wire.MsgBlock{rawBlocks[2]}, nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
 
 				ctx, cancel := context.WithCancel(context.Background())
 				go func() {
@@ -114,15 +124,12 @@ var _ = Describe("Indexer Test", func() {
 
 			It("Local latest block as 1, be notified with block 4 => rescan from block 4 to 2", func() {
 				// Start syncing from block 1
-				mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[1], nil).Once()
 
 				// Sync block 4 to 2
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[4].BlockHash().String()).Return(rawBlockHeaders[4], nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[3].BlockHash().String()).Return(rawBlockHeaders[3], nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[4].Hash).Return(rawBlocks[4], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[3].Hash).Return(rawBlocks[3], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[2].Hash).Return(rawBlocks[2], nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[4].BlockHash().String()).Return(rawBlockHeaders[4], nil).Once()
+				mockClient.On("GetBlockHeadersVerboseByHeightRange", mock.Anything, int64(2), int64(3)).Return([]*btcjson.GetBlockHeaderVerboseResult{rawBlockHeaders[2], rawBlockHeaders[3]}, nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[4].Hash, rawBlockHeaders[3].Hash, rawBlockHeaders[2].Hash}).Return([]*wire.MsgBlock{rawBlocks[4], rawBlocks[3], rawBlocks[2]}, nil).Once()
 				blocks := []*model.Block{
 					modelBlocks[4],
 					modelBlocks[3],
@@ -131,7 +138,7 @@ var _ = Describe("Indexer Test", func() {
 				txs := append(append(modelTxs[4], modelTxs[3]...), modelTxs[2]...)
 				txIns := append(append(modelTxIns[4], modelTxIns[3]...), modelTxIns[2]...)
 				txOuts := append(append(modelTxOuts[4], modelTxOuts[3]...), modelTxOuts[2]...)
-				mockManager.On("AddBlocksData", blocks, txs, txIns, txOuts).Return(nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, blocks, txs, txIns, txOuts).Return(nil).Once()
 
 				ctx, cancel := context.WithCancel(context.Background())
 				go func() {
@@ -155,32 +162,35 @@ var _ = Describe("Indexer Test", func() {
 				initReorgBlock5(reorgRawBlockHeaders[4].Hash)
 
 				// Start syncing from block 4
-				mockManager.On("GetLatestBlock").Return(modelBlocks[4], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[4], nil).Once()
 
 				// Notified reorg block 4 => just ignore
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[4].BlockHash().String()).Return(reorgRawBlockHeaders[4], nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlocks[4].BlockHash().String()).Return(reorgRawBlockHeaders[4], nil).Once()
 
 				// Notified block 5 chained with reorg block 4 => start to handle reorg
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[5].BlockHash().String()).Return(reorgRawBlockHeaders[5], nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlocks[5].BlockHash().String()).Return(reorgRawBlockHeaders[5], nil).Once()
 
 				// Tracing blocks backward in local DB by height to calculate reorg depth, in the case, as 1 block (block 4)
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[4].BlockHash().String()).Return(reorgRawBlockHeaders[4], nil).Once()
-				mockManager.On("GetBlock", int64(3)).Return(modelBlocks[3], nil).Once()
+				mockClient.On("GetBlockHeadersVerboseByHeightRange", mock.Anything, int64(0), int64(4)).Return([]*btcjson.GetBlockHeaderVerboseResult{
+					rawBlockHeaders[0], rawBlockHeaders[1], rawBlockHeaders[2], rawBlockHeaders[3], reorgRawBlockHeaders[4],
+				}, nil).Once()
+				mockManager.On("GetBlock", mock.Anything, int64(3)).Return(modelBlocks[3], nil).Once()
 				reorg := &model.Reorg{
 					FromHeight: 4,
 					FromHash:   rawBlocks[4].BlockHash().String(),
 					ToHeight:   4,
 					ToHash:     rawBlocks[4].BlockHash().String(),
+					NewHeight:  5,
+					NewHash:    reorgRawBlocks[5].BlockHash().String(),
 				}
 
 				// Process reorg event, expect delete old block 4, now the current block header points to block 3
-				mockManager.On("Reorg", reorg).Return(nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlockHeaders[4].PreviousHash).Return(rawBlockHeaders[3], nil).Once()
+				mockManager.On("Reorg", mock.Anything, reorg).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlockHeaders[4].PreviousHash).Return(rawBlockHeaders[3], nil).Once()
 
 				// Rescan from branch block as block 4, local DB be updated with reorg blocks 4 & 5
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlockHeaders[4].Hash).Return(reorgRawBlockHeaders[4], nil).Once()
-				mockClient.On("GetRawBlock", reorgRawBlockHeaders[5].Hash).Return(reorgRawBlocks[5], nil).Once()
-				mockClient.On("GetRawBlock", reorgRawBlockHeaders[4].Hash).Return(reorgRawBlocks[4], nil).Once()
+				mockClient.On("GetBlockHeadersVerboseByHeightRange", mock.Anything, int64(4), int64(4)).Return([]*btcjson.GetBlockHeaderVerboseResult{reorgRawBlockHeaders[4]}, nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{reorgRawBlockHeaders[5].Hash, reorgRawBlockHeaders[4].Hash}).Return([]*wire.MsgBlock{reorgRawBlocks[5], reorgRawBlocks[4]}, nil).Once()
 				blocks := []*model.Block{
 					reorgModelBlocks[5],
 					reorgModelBlocks[4],
@@ -188,7 +198,7 @@ var _ = Describe("Indexer Test", func() {
 				txs := append(reorgModelTxs[5], reorgModelTxs[4]...)
 				txIns := append(reorgModelTxIns[5], reorgModelTxIns[4]...)
 				txOuts := append(reorgModelTxOuts[5], reorgModelTxOuts[4]...)
-				mockManager.On("AddBlocksData", blocks, txs, txIns, txOuts).Return(nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, blocks, txs, txIns, txOuts).Return(nil).Once()
 
 				ctx, cancel := context.WithCancel(context.Background())
 				go func() {
@@ -212,37 +222,37 @@ var _ = Describe("Indexer Test", func() {
 				initReorgBlock5(reorgRawBlockHeaders[4].Hash)
 
 				// Start syncing from block 4
-				mockManager.On("GetLatestBlock").Return(modelBlocks[4], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[4], nil).Once()
 
 				// Notified reorg block 3,4 => just ignore
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[3].BlockHash().String()).Return(reorgRawBlockHeaders[3], nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[4].BlockHash().String()).Return(reorgRawBlockHeaders[4], nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlocks[3].BlockHash().String()).Return(reorgRawBlockHeaders[3], nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlocks[4].BlockHash().String()).Return(reorgRawBlockHeaders[4], nil).Once()
 
 				// Notified block 5 chained with reorg block 4, 3 => start to handle reorg
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[5].BlockHash().String()).Return(reorgRawBlockHeaders[5], nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlocks[5].BlockHash().String()).Return(reorgRawBlockHeaders[5], nil).Once()
 
 				// Tracing blocks backward in local DB by height to calculate reorg depth, in the case, as 2 blocks (block 4 & 3)
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[4].BlockHash().String()).Return(reorgRawBlockHeaders[4], nil).Once()
-				mockManager.On("GetBlock", int64(3)).Return(modelBlocks[3], nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[3].BlockHash().String()).Return(reorgRawBlockHeaders[3], nil).Once()
-				mockManager.On("GetBlock", int64(2)).Return(modelBlocks[2], nil).Once()
+				mockClient.On("GetBlockHeadersVerboseByHeightRange", mock.Anything, int64(0), int64(4)).Return([]*btcjson.GetBlockHeaderVerboseResult{
+					rawBlockHeaders[0], rawBlockHeaders[1], rawBlockHeaders[2], reorgRawBlockHeaders[3], reorgRawBlockHeaders[4],
+				}, nil).Once()
+				mockManager.On("GetBlock", mock.Anything, int64(3)).Return(modelBlocks[3], nil).Once()
+				mockManager.On("GetBlock", mock.Anything, int64(2)).Return(modelBlocks[2], nil).Once()
 				reorg := &model.Reorg{
 					FromHeight: 3,
 					FromHash:   rawBlocks[3].BlockHash().String(),
 					ToHeight:   4,
 					ToHash:     rawBlocks[4].BlockHash().String(),
+					NewHeight:  5,
+					NewHash:    reorgRawBlocks[5].BlockHash().String(),
 				}
 
 				// Process reorg event, expect delete old blocks 4 & 3, now the current block header points to block 2
-				mockManager.On("Reorg", reorg).Return(nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlockHeaders[3].PreviousHash).Return(rawBlockHeaders[2], nil).Once()
+				mockManager.On("Reorg", mock.Anything, reorg).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlockHeaders[3].PreviousHash).Return(rawBlockHeaders[2], nil).Once()
 
 				// Rescan from branch block as block 3, local DB be updated with reorg blocks 3, 4 & 5
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlockHeaders[4].Hash).Return(reorgRawBlockHeaders[4], nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlockHeaders[3].Hash).Return(reorgRawBlockHeaders[3], nil).Once()
-				mockClient.On("GetRawBlock", reorgRawBlockHeaders[5].Hash).Return(reorgRawBlocks[5], nil).Once()
-				mockClient.On("GetRawBlock", reorgRawBlockHeaders[4].Hash).Return(reorgRawBlocks[4], nil).Once()
-				mockClient.On("GetRawBlock", reorgRawBlockHeaders[3].Hash).Return(reorgRawBlocks[3], nil).Once()
+				mockClient.On("GetBlockHeadersVerboseByHeightRange", mock.Anything, int64(3), int64(4)).Return([]*btcjson.GetBlockHeaderVerboseResult{reorgRawBlockHeaders[3], reorgRawBlockHeaders[4]}, nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{reorgRawBlockHeaders[5].Hash, reorgRawBlockHeaders[4].Hash, reorgRawBlockHeaders[3].Hash}).Return([]*wire.MsgBlock{reorgRawBlocks[5], reorgRawBlocks[4], reorgRawBlocks[3]}, nil).Once()
 				blocks := []*model.Block{
 					reorgModelBlocks[5],
 					reorgModelBlocks[4],
@@ -251,7 +261,7 @@ var _ = Describe("Indexer Test", func() {
 				txs := append(append(reorgModelTxs[5], reorgModelTxs[4]...), reorgModelTxs[3]...)
 				txIns := append(append(reorgModelTxIns[5], reorgModelTxIns[4]...), reorgModelTxIns[3]...)
 				txOuts := append(append(reorgModelTxOuts[5], reorgModelTxOuts[4]...), reorgModelTxOuts[3]...)
-				mockManager.On("AddBlocksData", blocks, txs, txIns, txOuts).Return(nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, blocks, txs, txIns, txOuts).Return(nil).Once()
 
 				ctx, cancel := context.WithCancel(context.Background())
 				go func() {
@@ -276,15 +286,12 @@ var _ = Describe("Indexer Test", func() {
 				initReorgBlock5(reorgRawBlockHeaders[4].Hash)
 
 				// Start syncing from block 1
-				mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[1], nil).Once()
 
 				// Sync block 4 to 2
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[4].BlockHash().String()).Return(rawBlockHeaders[4], nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[3].BlockHash().String()).Return(rawBlockHeaders[3], nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[4].Hash).Return(rawBlocks[4], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[3].Hash).Return(rawBlocks[3], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[2].Hash).Return(rawBlocks[2], nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[4].BlockHash().String()).Return(rawBlockHeaders[4], nil).Once()
+				mockClient.On("GetBlockHeadersVerboseByHeightRange", mock.Anything, int64(2), int64(3)).Return([]*btcjson.GetBlockHeaderVerboseResult{rawBlockHeaders[2], rawBlockHeaders[3]}, nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[4].Hash, rawBlockHeaders[3].Hash, rawBlockHeaders[2].Hash}).Return([]*wire.MsgBlock{rawBlocks[4], rawBlocks[3], rawBlocks[2]}, nil).Once()
 				blocks := []*model.Block{
 					modelBlocks[4],
 					modelBlocks[3],
@@ -293,37 +300,37 @@ var _ = Describe("Indexer Test", func() {
 				txs := append(append(modelTxs[4], modelTxs[3]...), modelTxs[2]...)
 				txIns := append(append(modelTxIns[4], modelTxIns[3]...), modelTxIns[2]...)
 				txOuts := append(append(modelTxOuts[4], modelTxOuts[3]...), modelTxOuts[2]...)
-				mockManager.On("AddBlocksData", blocks, txs, txIns, txOuts).Return(nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, blocks, txs, txIns, txOuts).Return(nil).Once()
 
 				// Notified reorg block 3,4 => just ignore
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[3].BlockHash().String()).Return(reorgRawBlockHeaders[3], nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[4].BlockHash().String()).Return(reorgRawBlockHeaders[4], nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlocks[3].BlockHash().String()).Return(reorgRawBlockHeaders[3], nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlocks[4].BlockHash().String()).Return(reorgRawBlockHeaders[4], nil).Once()
 
 				// Notified block 5 chained with reorg block 4, 3 => start to handle reorg
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[5].BlockHash().String()).Return(reorgRawBlockHeaders[5], nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlocks[5].BlockHash().String()).Return(reorgRawBlockHeaders[5], nil).Once()
 
 				// Tracing blocks backward in local DB by height to calculate reorg depth, in the case, as 2 blocks (block 4 & 3)
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[4].BlockHash().String()).Return(reorgRawBlockHeaders[4], nil).Once()
-				mockManager.On("GetBlock", int64(3)).Return(modelBlocks[3], nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlocks[3].BlockHash().String()).Return(reorgRawBlockHeaders[3], nil).Once()
-				mockManager.On("GetBlock", int64(2)).Return(modelBlocks[2], nil).Once()
+				mockClient.On("GetBlockHeadersVerboseByHeightRange", mock.Anything, int64(0), int64(4)).Return([]*btcjson.GetBlockHeaderVerboseResult{
+					rawBlockHeaders[0], rawBlockHeaders[1], rawBlockHeaders[2], reorgRawBlockHeaders[3], reorgRawBlockHeaders[4],
+				}, nil).Once()
+				mockManager.On("GetBlock", mock.Anything, int64(3)).Return(modelBlocks[3], nil).Once()
+				mockManager.On("GetBlock", mock.Anything, int64(2)).Return(modelBlocks[2], nil).Once()
 				reorg := &model.Reorg{
 					FromHeight: 3,
 					FromHash:   rawBlocks[3].BlockHash().String(),
 					ToHeight:   4,
 					ToHash:     rawBlocks[4].BlockHash().String(),
+					NewHeight:  5,
+					NewHash:    reorgRawBlocks[5].BlockHash().String(),
 				}
 
 				// Process reorg event, expect delete old blocks 4 & 3, now the current block header points to block 2
-				mockManager.On("Reorg", reorg).Return(nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlockHeaders[3].PreviousHash).Return(rawBlockHeaders[2], nil).Once()
+				mockManager.On("Reorg", mock.Anything, reorg).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, reorgRawBlockHeaders[3].PreviousHash).Return(rawBlockHeaders[2], nil).Once()
 
 				// Rescan from branch block as block 3, local DB be updated with reorg blocks 3, 4 & 5
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlockHeaders[4].Hash).Return(reorgRawBlockHeaders[4], nil).Once()
-				mockClient.On("GetBlockHeaderVerboseByHash", reorgRawBlockHeaders[3].Hash).Return(reorgRawBlockHeaders[3], nil).Once()
-				mockClient.On("GetRawBlock", reorgRawBlockHeaders[5].Hash).Return(reorgRawBlocks[5], nil).Once()
-				mockClient.On("GetRawBlock", reorgRawBlockHeaders[4].Hash).Return(reorgRawBlocks[4], nil).Once()
-				mockClient.On("GetRawBlock", reorgRawBlockHeaders[3].Hash).Return(reorgRawBlocks[3], nil).Once()
+				mockClient.On("GetBlockHeadersVerboseByHeightRange", mock.Anything, int64(3), int64(4)).Return([]*btcjson.GetBlockHeaderVerboseResult{reorgRawBlockHeaders[3], reorgRawBlockHeaders[4]}, nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{reorgRawBlockHeaders[5].Hash, reorgRawBlockHeaders[4].Hash, reorgRawBlockHeaders[3].Hash}).Return([]*wire.MsgBlock{reorgRawBlocks[5], reorgRawBlocks[4], reorgRawBlocks[3]}, nil).Once()
 				blocks = []*model.Block{
 					reorgModelBlocks[5],
 					reorgModelBlocks[4],
@@ -332,7 +339,7 @@ var _ = Describe("Indexer Test", func() {
 				txs = append(append(reorgModelTxs[5], reorgModelTxs[4]...), reorgModelTxs[3]...)
 				txIns = append(append(reorgModelTxIns[5], reorgModelTxIns[4]...), reorgModelTxIns[3]...)
 				txOuts = append(append(reorgModelTxOuts[5], reorgModelTxOuts[4]...), reorgModelTxOuts[3]...)
-				mockManager.On("AddBlocksData", blocks, txs, txIns, txOuts).Return(nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, blocks, txs, txIns, txOuts).Return(nil).Once()
 
 				ctx, cancel := context.WithCancel(context.Background())
 				go func() {
@@ -357,22 +364,22 @@ var _ = Describe("Indexer Test", func() {
 	Context("Occur errors", func() {
 		Context("InitState failed", func() {
 			It("GetLatestBlock failed", func() {
-				mockManager.On("GetLatestBlock").Return(nil, errors.New("failed")).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(nil, errors.New("failed")).Once()
 
 				e := indexer.Listen(context.Background(), 13)
 				Expect(e).Should(Equal(errors.New("failed to Init State: failed to Get Latest Block: failed")))
 			})
 
 			It("GetBlockHeaderVerboseByHeight failed", func() {
-				mockManager.On("GetLatestBlock").Return(nil, commonIndexer.ErrNotFound).Once()
-				mockClient.On("GetBlockHeaderVerboseByHeight", int64(13)).Return(nil, errors.New("failed")).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(nil, commonIndexer.ErrNotFound).Once()
+				mockClient.On("GetBlockHeaderVerboseByHeight", mock.Anything, int64(13)).Return(nil, errors.New("failed")).Once()
 
 				e := indexer.Listen(context.Background(), 13)
 				Expect(e).Should(Equal(errors.New("failed to Init State: failed to Get Block Header Verbose By Height '13': failed")))
 			})
 
 			It("Invalid starting Block Height", func() {
-				mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[1], nil).Once()
 
 				e := indexer.Listen(context.Background(), 13)
 				Expect(e).Should(Equal(errors.New("failed to Init State: invalid starting Block Height: Latest Block '1', From Block '13'")))
@@ -380,7 +387,7 @@ var _ = Describe("Indexer Test", func() {
 		})
 
 		It("SubscribeNotification failed", func() {
-			mockManager.On("GetLatestBlock").Return(modelBlocks[3], nil).Once()
+			mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[3], nil).Once()
 			mockSubscriber.On("SubscribeNotification", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("failed")).Once()
 
 			e := indexer.Listen(context.Background(), 2)
@@ -390,12 +397,12 @@ var _ = Describe("Indexer Test", func() {
 		Context("Occur errors while syncing, log & retry", func() {
 			It("Invalid notification", func() {
 				// Start syncing from block 1
-				mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[1], nil).Once()
 
 				// Sync block 2
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[2].Hash).Return(rawBlocks[2], nil).Once()
-				mockManager.On("AddBlocksData", []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[2].Hash}).Return([]*wire.MsgBlock{rawBlocks[2]}, nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
 
 				ctx, cancel := context.WithCancel(context.Background())
 				go func() {
@@ -415,15 +422,15 @@ var _ = Describe("Indexer Test", func() {
 
 			It("GetBlockHeaderVerboseByHash failed", func() {
 				// Start syncing from block 1
-				mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[1], nil).Once()
 
 				// Occur error
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(nil, errors.New("failed")).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[2].BlockHash().String()).Return(nil, errors.New("failed")).Once()
 
 				// Sync block 2
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[2].Hash).Return(rawBlocks[2], nil).Once()
-				mockManager.On("AddBlocksData", []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[2].Hash}).Return([]*wire.MsgBlock{rawBlocks[2]}, nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
 
 				ctx, cancel := context.WithCancel(context.Background())
 				go func() {
@@ -441,18 +448,18 @@ var _ = Describe("Indexer Test", func() {
 				Expect(err).Should(Equal(context.Canceled))
 			})
 
-			It("GetRawBlock failed", func() {
+			It("GetRawBlocks failed", func() {
 				// Start syncing from block 1
-				mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[1], nil).Once()
 
 				// Occur error
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[2].Hash).Return(nil, errors.New("failed")).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[2].Hash}).Return(nil, errors.New("failed")).Once()
 
 				// Sync block 2
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[2].Hash).Return(rawBlocks[2], nil).Once()
-				mockManager.On("AddBlocksData", []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[2].Hash}).Return([]*wire.MsgBlock{rawBlocks[2]}, nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
 
 				ctx, cancel := context.WithCancel(context.Background())
 				go func() {
@@ -472,17 +479,17 @@ var _ = Describe("Indexer Test", func() {
 
 			It("AddBlocksData failed", func() {
 				// Start syncing from block 1
-				mockManager.On("GetLatestBlock").Return(modelBlocks[1], nil).Once()
+				mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[1], nil).Once()
 
 				// Occur error
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[2].Hash).Return(rawBlocks[2], nil).Once()
-				mockManager.On("AddBlocksData", []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(errors.New("failed")).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[2].Hash}).Return([]*wire.MsgBlock{rawBlocks[2]}, nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(errors.New("failed")).Once()
 
 				// Sync block 2
-				mockClient.On("GetBlockHeaderVerboseByHash", rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
-				mockClient.On("GetRawBlock", rawBlockHeaders[2].Hash).Return(rawBlocks[2], nil).Once()
-				mockManager.On("AddBlocksData", []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
+				mockClient.On("GetBlockHeaderVerboseByHash", mock.Anything, rawBlocks[2].BlockHash().String()).Return(rawBlockHeaders[2], nil).Once()
+				mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[2].Hash}).Return([]*wire.MsgBlock{rawBlocks[2]}, nil).Once()
+				mockManager.On("AddBlocksData", mock.Anything, []*model.Block{modelBlocks[2]}, modelTxs[2], modelTxIns[2], modelTxOuts[2]).Return(nil).Once()
 
 				ctx, cancel := context.WithCancel(context.Background())
 				go func() {
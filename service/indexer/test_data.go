@@ -443,6 +443,7 @@ func initBlock2() {
 			Address:         mikeAddress,
 			PreviousTxHash:  validTxHash,
 			PreviousTxIndex: 13,
+			TxPosition:      1,
 		},
 	}
 	modelTxOuts[height] = []*model.TxOut{
@@ -463,6 +464,7 @@ func initBlock2() {
 			Address:      johnAddress,
 			ScriptPubKey: johnPkScript,
 			CoinBase:     &falseValue,
+			TxPosition:   1,
 		},
 	}
 }
@@ -709,6 +711,7 @@ func initBlock4() {
 			Address:         mikeAddress,
 			PreviousTxHash:  validTxHash,
 			PreviousTxIndex: 13,
+			TxPosition:      1,
 		},
 	}
 	modelTxOuts[height] = []*model.TxOut{
@@ -729,6 +732,7 @@ func initBlock4() {
 			Address:      johnAddress,
 			ScriptPubKey: johnPkScript,
 			CoinBase:     &falseValue,
+			TxPosition:   1,
 		},
 	}
 }
@@ -1067,6 +1071,7 @@ func initReorgBlock5(previousHash string) {
 			Address:         mikeAddress,
 			PreviousTxHash:  validTxHash,
 			PreviousTxIndex: 13,
+			TxPosition:      1,
 		},
 	}
 	reorgModelTxOuts[height] = []*model.TxOut{
@@ -1087,6 +1092,7 @@ func initReorgBlock5(previousHash string) {
 			Address:      johnAddress,
 			ScriptPubKey: johnPkScript,
 			CoinBase:     &falseValue,
+			TxPosition:   1,
 		},
 	}
 }
@@ -0,0 +1,289 @@
+package indexer
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	bcClient "github.com/darkknightbk52/btc-indexer/client/blockchain"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var invalidBlocks = expvar.NewInt("indexer_invalid_blocks")
+
+// InvalidBlockError is returned when a block from Full Node breaks the consensus rules checked locally.
+type InvalidBlockError struct {
+	Height int64
+	Hash   string
+	Reason string
+}
+
+func (e *InvalidBlockError) Error() string {
+	return fmt.Sprintf("invalid Block, Height '%d', Hash '%s': %s", e.Height, e.Hash, e.Reason)
+}
+
+type headerInfo struct {
+	height       int64
+	hash         string
+	previousHash string
+	bits         uint32
+	time         int64
+}
+
+// validator checks the blocks given by Full Node before they are indexed: header linkage, proof of work,
+// difficulty retargeting & merkle root. Headers of ancestors are kept to compute the required difficulty.
+type validator struct {
+	mu                sync.Mutex
+	params            *chaincfg.Params
+	client            bcClient.Client
+	blocksPerRetarget int64
+	headers           map[string]*headerInfo
+	highest           int64
+}
+
+func newValidator(params *chaincfg.Params, client bcClient.Client) *validator {
+	return &validator{
+		params:            params,
+		client:            client,
+		blocksPerRetarget: int64(params.TargetTimespan / params.TargetTimePerBlock),
+		headers:           make(map[string]*headerInfo),
+	}
+}
+
+// validate checks the raw blocks of the headers, both given in descending order of height.
+func (v *validator) validate(ctx context.Context, headers []*btcjson.GetBlockHeaderVerboseResult, rawBlocks []*wire.MsgBlock) error {
+	if len(headers) != len(rawBlocks) {
+		return fmt.Errorf("not matched numbers of Headers '%d' and Raw Blocks '%d'", len(headers), len(rawBlocks))
+	}
+
+	// Blocks may be validated by Verify meanwhile syncing
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for i := len(headers) - 1; i >= 0; i-- {
+		err := v.validateBlock(ctx, headers[i], rawBlocks[i])
+		if err != nil {
+			if _, ok := err.(*InvalidBlockError); ok {
+				invalidBlocks.Add(1)
+			}
+			return err
+		}
+	}
+	v.prune()
+	return nil
+}
+
+func (v *validator) validateBlock(ctx context.Context, header *btcjson.GetBlockHeaderVerboseResult, rawBlock *wire.MsgBlock) error {
+	height := int64(header.Height)
+	invalid := func(format string, a ...interface{}) error {
+		return &InvalidBlockError{Height: height, Hash: header.Hash, Reason: fmt.Sprintf(format, a...)}
+	}
+
+	blockHash := rawBlock.BlockHash()
+	if blockHash.String() != header.Hash {
+		return invalid("hash of Raw Block '%s' not matched", blockHash.String())
+	}
+
+	if len(rawBlock.Transactions) == 0 {
+		return invalid("no transactions")
+	}
+	// A block with duplicated txs may have the merkle root of the valid one (CVE-2012-2459), so is rejected as the nodes do
+	seen := make(map[chainhash.Hash]bool, len(rawBlock.Transactions))
+	for _, tx := range rawBlock.Transactions {
+		txHash := tx.TxHash()
+		if seen[txHash] {
+			return invalid("duplicated transaction '%s'", txHash.String())
+		}
+		seen[txHash] = true
+	}
+	merkleRoot := calcMerkleRoot(rawBlock.Transactions)
+	if !rawBlock.Header.MerkleRoot.IsEqual(&merkleRoot) {
+		return invalid("merkle root '%s' not matched, expect '%s'", rawBlock.Header.MerkleRoot.String(), merkleRoot.String())
+	}
+
+	err := blockchain.CheckProofOfWork(btcutil.NewBlock(rawBlock), v.params.PowLimit)
+	if err != nil {
+		return invalid("%v", err)
+	}
+
+	if height == 0 {
+		if !blockHash.IsEqual(v.params.GenesisHash) {
+			return invalid("not the Genesis block of '%s'", v.params.Name)
+		}
+		v.add(height, rawBlock)
+		return nil
+	}
+
+	if header.PreviousHash != rawBlock.Header.PrevBlock.String() {
+		return invalid("previous hash '%s' not matched the one of Raw Block '%s'", header.PreviousHash, rawBlock.Header.PrevBlock.String())
+	}
+	parent, err := v.header(ctx, header.PreviousHash, height-1)
+	if err != nil {
+		return fmt.Errorf("failed to Get Parent Header '%s': %v", header.PreviousHash, err)
+	}
+	if parent.height != height-1 {
+		return invalid("not chained to parent '%s' at Height '%d'", parent.hash, parent.height)
+	}
+
+	requiredBits, err := v.requiredBits(ctx, parent, rawBlock.Header.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to Calculate Required Difficulty, Height '%d': %v", height, err)
+	}
+	if rawBlock.Header.Bits != requiredBits {
+		return invalid("difficulty '%08x' not matched, expect '%08x'", rawBlock.Header.Bits, requiredBits)
+	}
+
+	v.add(height, rawBlock)
+	return nil
+}
+
+// requiredBits follows the difficulty retargeting rules of the chain for the block after parent.
+func (v *validator) requiredBits(ctx context.Context, parent *headerInfo, timestamp time.Time) (uint32, error) {
+	// Like Bitcoin Core, regression test network never retargets
+	if v.params.Net == chaincfg.RegressionNetParams.Net {
+		return parent.bits, nil
+	}
+
+	if (parent.height+1)%v.blocksPerRetarget != 0 {
+		if !v.params.ReduceMinDifficulty {
+			return parent.bits, nil
+		}
+
+		// Test networks allow a block with the minimum difficulty if it comes late enough,
+		// otherwise the difficulty is the one of the last block not using the rule
+		if timestamp.Unix() > parent.time+int64(v.params.MinDiffReductionTime/time.Second) {
+			return v.params.PowLimitBits, nil
+		}
+		h := parent
+		var err error
+		for h.height%v.blocksPerRetarget != 0 && h.bits == v.params.PowLimitBits {
+			h, err = v.header(ctx, h.previousHash, h.height-1)
+			if err != nil {
+				return 0, err
+			}
+		}
+		return h.bits, nil
+	}
+
+	first := parent
+	var err error
+	for first.height > parent.height+1-v.blocksPerRetarget {
+		first, err = v.header(ctx, first.previousHash, first.height-1)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	targetTimespan := int64(v.params.TargetTimespan / time.Second)
+	minTimespan := targetTimespan / v.params.RetargetAdjustmentFactor
+	maxTimespan := targetTimespan * v.params.RetargetAdjustmentFactor
+	timespan := parent.time - first.time
+	if timespan < minTimespan {
+		timespan = minTimespan
+	} else if timespan > maxTimespan {
+		timespan = maxTimespan
+	}
+
+	target := new(big.Int).Mul(blockchain.CompactToBig(parent.bits), big.NewInt(timespan))
+	target.Div(target, big.NewInt(targetTimespan))
+	if target.Cmp(v.params.PowLimit) > 0 {
+		target.Set(v.params.PowLimit)
+	}
+	return blockchain.BigToCompact(target), nil
+}
+
+// header returns a known header, or fetches it from Full Node together with its ancestors in a batch.
+func (v *validator) header(ctx context.Context, hash string, height int64) (*headerInfo, error) {
+	if h, ok := v.headers[hash]; ok {
+		return h, nil
+	}
+
+	from := height - blockBatchSize + 1
+	if from < 0 {
+		from = 0
+	}
+	headers, err := v.client.GetBlockHeadersVerboseByHeightRange(ctx, from, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Block Headers Verbose By Height Range '%d' - '%d': %v", from, height, err)
+	}
+	previousHash := hash
+	for i := len(headers) - 1; i >= 0 && headers[i].Hash == previousHash; i-- {
+		h, err := toHeaderInfo(headers[i])
+		if err != nil {
+			return nil, err
+		}
+		v.headers[h.hash] = h
+		previousHash = h.previousHash
+	}
+
+	if h, ok := v.headers[hash]; ok {
+		return h, nil
+	}
+	// Full Node has another block at the height in its main chain
+	header, err := v.client.GetBlockHeaderVerboseByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Block Header Verbose By Hash '%s': %v", hash, err)
+	}
+	h, err := toHeaderInfo(header)
+	if err != nil {
+		return nil, err
+	}
+	v.headers[h.hash] = h
+	return h, nil
+}
+
+func (v *validator) add(height int64, rawBlock *wire.MsgBlock) {
+	v.headers[rawBlock.BlockHash().String()] = &headerInfo{
+		height:       height,
+		hash:         rawBlock.BlockHash().String(),
+		previousHash: rawBlock.Header.PrevBlock.String(),
+		bits:         rawBlock.Header.Bits,
+		time:         rawBlock.Header.Timestamp.Unix(),
+	}
+	if height > v.highest {
+		v.highest = height
+	}
+}
+
+// prune forgets the headers not needed anymore to calculate the difficulty of next blocks.
+func (v *validator) prune() {
+	if int64(len(v.headers)) <= 3*v.blocksPerRetarget {
+		return
+	}
+	for hash, h := range v.headers {
+		if h.height < v.highest-2*v.blocksPerRetarget {
+			delete(v.headers, hash)
+		}
+	}
+}
+
+func toHeaderInfo(header *btcjson.GetBlockHeaderVerboseResult) (*headerInfo, error) {
+	bits, err := strconv.ParseUint(header.Bits, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Parse Bits '%s' of Block Header '%s': %v", header.Bits, header.Hash, err)
+	}
+	return &headerInfo{
+		height:       int64(header.Height),
+		hash:         header.Hash,
+		previousHash: header.PreviousHash,
+		bits:         uint32(bits),
+		time:         header.Time,
+	}, nil
+}
+
+// calcMerkleRoot computes the merkle root of the transactions, which must not be empty.
+func calcMerkleRoot(txs []*wire.MsgTx) chainhash.Hash {
+	utilTxs := make([]*btcutil.Tx, 0, len(txs))
+	for _, tx := range txs {
+		utilTxs = append(utilTxs, btcutil.NewTx(tx))
+	}
+	merkles := blockchain.BuildMerkleTreeStore(utilTxs, false)
+	return *merkles[len(merkles)-1]
+}
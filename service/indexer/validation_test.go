@@ -0,0 +1,216 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	clientMock "github.com/darkknightbk52/btc-indexer/client/blockchain/mocks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"math/big"
+	"time"
+)
+
+var _ = Describe("Validator Test", func() {
+	var (
+		mockClient *clientMock.Client
+		params     chaincfg.Params
+		blocks     []*wire.MsgBlock
+		headers    []*btcjson.GetBlockHeaderVerboseResult
+	)
+
+	mine := func(block *wire.MsgBlock) {
+		target := blockchain.CompactToBig(block.Header.Bits)
+		for {
+			hash := block.BlockHash()
+			if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+				return
+			}
+			block.Header.Nonce++
+		}
+	}
+
+	newBlock := func(height int64, previousHash chainhash.Hash, bits uint32) *wire.MsgBlock {
+		coinBase := &wire.MsgTx{
+			Version: 1,
+			TxIn: []*wire.TxIn{{
+				PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+				SignatureScript:  []byte(fmt.Sprintf("height %d", height)),
+				Sequence:         wire.MaxTxInSequenceNum,
+			}},
+			TxOut: []*wire.TxOut{{Value: 5000000000, PkScript: alicePkScript}},
+		}
+		block := &wire.MsgBlock{
+			Header: wire.BlockHeader{
+				Version:   1,
+				PrevBlock: previousHash,
+				Timestamp: time.Unix(1500000000+height*int64(params.TargetTimePerBlock/time.Second), 0),
+				Bits:      bits,
+			},
+			Transactions: []*wire.MsgTx{coinBase},
+		}
+		block.Header.MerkleRoot = calcMerkleRoot(block.Transactions)
+		mine(block)
+		return block
+	}
+
+	toHeader := func(height int64, block *wire.MsgBlock) *btcjson.GetBlockHeaderVerboseResult {
+		return &btcjson.GetBlockHeaderVerboseResult{
+			Hash:         block.BlockHash().String(),
+			Height:       int32(height),
+			PreviousHash: block.Header.PrevBlock.String(),
+			Bits:         fmt.Sprintf("%08x", block.Header.Bits),
+			Time:         block.Header.Timestamp.Unix(),
+		}
+	}
+
+	// descending returns the headers & blocks from height 'to' down to 'from'
+	descending := func(from, to int) ([]*btcjson.GetBlockHeaderVerboseResult, []*wire.MsgBlock) {
+		var hs []*btcjson.GetBlockHeaderVerboseResult
+		var bs []*wire.MsgBlock
+		for i := to; i >= from; i-- {
+			hs = append(hs, headers[i])
+			bs = append(bs, blocks[i])
+		}
+		return hs, bs
+	}
+
+	BeforeEach(func() {
+		mockClient = new(clientMock.Client)
+		params = chaincfg.SimNetParams
+		params.ReduceMinDifficulty = false
+		// Retarget every 4 blocks
+		params.TargetTimespan = params.TargetTimePerBlock * 4
+
+		// The 4th block is 3 block times after the first one of the period, so the difficulty goes up
+		retargetBits := blockchain.BigToCompact(new(big.Int).Div(new(big.Int).Mul(blockchain.CompactToBig(params.PowLimitBits), big.NewInt(3)), big.NewInt(4)))
+		blocks = nil
+		headers = nil
+		previousHash := chainhash.Hash{}
+		for height := int64(0); height <= 5; height++ {
+			bits := params.PowLimitBits
+			if height >= 4 {
+				bits = retargetBits
+			}
+			block := newBlock(height, previousHash, bits)
+			blocks = append(blocks, block)
+			headers = append(headers, toHeader(height, block))
+			previousHash = block.BlockHash()
+		}
+		genesisHash := blocks[0].BlockHash()
+		params.GenesisHash = &genesisHash
+	})
+
+	AfterEach(func() {
+		mockClient.AssertExpectations(GinkgoT())
+	})
+
+	It("Valid blocks", func() {
+		v := newValidator(&params, mockClient)
+		hs, bs := descending(0, 5)
+		Expect(v.validate(context.Background(), hs, bs)).Should(Succeed())
+	})
+
+	It("Valid block, fetch parent headers from Full Node", func() {
+		mockClient.On("GetBlockHeadersVerboseByHeightRange", mock.Anything, int64(0), int64(4)).Return(headers[:5], nil).Once()
+
+		v := newValidator(&params, mockClient)
+		hs, bs := descending(5, 5)
+		Expect(v.validate(context.Background(), hs, bs)).Should(Succeed())
+	})
+
+	It("Not matched merkle root", func() {
+		blocks[2].Transactions[0].TxOut[0].Value++
+
+		v := newValidator(&params, mockClient)
+		hs, bs := descending(0, 5)
+		err := v.validate(context.Background(), hs, bs)
+		Expect(err).Should(BeAssignableToTypeOf(&InvalidBlockError{}))
+		Expect(err.(*InvalidBlockError).Height).Should(Equal(int64(2)))
+	})
+
+	It("Duplicated transactions of the same merkle root", func() {
+		// With 3 txs the last one is paired with itself, so duplicating it keeps the merkle root & block hash (CVE-2012-2459)
+		for i := 1; i <= 2; i++ {
+			blocks[2].Transactions = append(blocks[2].Transactions, &wire.MsgTx{
+				Version: 1,
+				TxIn: []*wire.TxIn{{
+					PreviousOutPoint: wire.OutPoint{Hash: blocks[1].Transactions[0].TxHash(), Index: uint32(i)},
+					Sequence:         wire.MaxTxInSequenceNum,
+				}},
+				TxOut: []*wire.TxOut{{Value: int64(i), PkScript: alicePkScript}},
+			})
+		}
+		blocks[2].Header.MerkleRoot = calcMerkleRoot(blocks[2].Transactions)
+		mine(blocks[2])
+		headers[2] = toHeader(2, blocks[2])
+		v := newValidator(&params, mockClient)
+		hs, bs := descending(0, 2)
+		Expect(v.validate(context.Background(), hs, bs)).Should(Succeed())
+
+		mutated := *blocks[2]
+		mutated.Transactions = append(mutated.Transactions[:3:3], mutated.Transactions[2])
+		Expect(calcMerkleRoot(mutated.Transactions)).Should(Equal(mutated.Header.MerkleRoot))
+		Expect(mutated.BlockHash()).Should(Equal(blocks[2].BlockHash()))
+
+		v = newValidator(&params, mockClient)
+		bs[0] = &mutated
+		err := v.validate(context.Background(), hs, bs)
+		Expect(err).Should(BeAssignableToTypeOf(&InvalidBlockError{}))
+		Expect(err.(*InvalidBlockError).Height).Should(Equal(int64(2)))
+		Expect(err.Error()).Should(ContainSubstring("duplicated transaction"))
+	})
+
+	It("Not enough proof of work", func() {
+		target := blockchain.CompactToBig(blocks[3].Header.Bits)
+		for {
+			blocks[3].Header.Nonce++
+			hash := blocks[3].BlockHash()
+			if blockchain.HashToBig(&hash).Cmp(target) > 0 {
+				break
+			}
+		}
+		headers[3] = toHeader(3, blocks[3])
+
+		v := newValidator(&params, mockClient)
+		hs, bs := descending(0, 3)
+		err := v.validate(context.Background(), hs, bs)
+		Expect(err).Should(BeAssignableToTypeOf(&InvalidBlockError{}))
+		Expect(err.(*InvalidBlockError).Height).Should(Equal(int64(3)))
+	})
+
+	It("Not retargeted difficulty", func() {
+		blocks[4] = newBlock(4, blocks[3].BlockHash(), params.PowLimitBits)
+		headers[4] = toHeader(4, blocks[4])
+
+		v := newValidator(&params, mockClient)
+		hs, bs := descending(0, 4)
+		err := v.validate(context.Background(), hs, bs)
+		Expect(err).Should(BeAssignableToTypeOf(&InvalidBlockError{}))
+		Expect(err.(*InvalidBlockError).Height).Should(Equal(int64(4)))
+	})
+
+	It("Not chained headers", func() {
+		blocks[3] = newBlock(3, blocks[1].BlockHash(), params.PowLimitBits)
+		headers[3] = toHeader(3, blocks[3])
+
+		v := newValidator(&params, mockClient)
+		hs, bs := descending(0, 3)
+		err := v.validate(context.Background(), hs, bs)
+		Expect(err).Should(BeAssignableToTypeOf(&InvalidBlockError{}))
+		Expect(err.(*InvalidBlockError).Height).Should(Equal(int64(3)))
+	})
+
+	It("Not matched Raw Block", func() {
+		v := newValidator(&params, mockClient)
+		hs, bs := descending(0, 3)
+		bs[0] = blocks[2]
+		err := v.validate(context.Background(), hs, bs)
+		Expect(err).Should(BeAssignableToTypeOf(&InvalidBlockError{}))
+	})
+})
@@ -0,0 +1,248 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// Kinds of VerifyIssue
+const (
+	IssueGap                 = "gap"
+	IssueDuplicate           = "duplicate"
+	IssueHashMismatch        = "hash_mismatch"
+	IssueBrokenLink          = "broken_link"
+	IssueTxCountMismatch     = "tx_count_mismatch"
+	IssueOutputCountMismatch = "output_count_mismatch"
+	IssueOutputSumMismatch   = "output_sum_mismatch"
+)
+
+// VerifyIssue is an inconsistency found at a height between DB and Full Node.
+type VerifyIssue struct {
+	Height   int64  `json:"height"`
+	Kind     string `json:"kind"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+type VerifyReport struct {
+	FromHeight      int64          `json:"from_height"`
+	ToHeight        int64          `json:"to_height"`
+	CheckedBlocks   int64          `json:"checked_blocks"`
+	Issues          []*VerifyIssue `json:"issues"`
+	RepairedHeights []int64        `json:"repaired_heights"`
+}
+
+// Verify audits the indexed data from height 'from' to 'to' against Full Node: block hashes, previous hash linkage,
+// number of txs, number & sum of tx outs per block, gaps and duplicated heights.
+// Heights beyond the latest indexed block are not checked. If repair is set, the data at the heights
+// having issues are indexed again from Full Node, in turn with the sync of Listen.
+func (idx *Indexer) Verify(ctx context.Context, from, to int64, repair bool) (*VerifyReport, error) {
+	if from < 0 || from > to {
+		return nil, common.ErrInvalidRange
+	}
+
+	report := &VerifyReport{
+		FromHeight:      from,
+		ToHeight:        to,
+		Issues:          []*VerifyIssue{},
+		RepairedHeights: []int64{},
+	}
+	latestBlock, err := idx.manager.GetLatestBlock(ctx)
+	if err == common.ErrNotFound {
+		return report, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Latest Block: %v", err)
+	}
+	if to > latestBlock.Height {
+		to = latestBlock.Height
+		report.ToHeight = to
+	}
+
+	for start := from; start <= to; start += blockBatchSize {
+		end := start + blockBatchSize - 1
+		if end > to {
+			end = to
+		}
+
+		issues, heights, err := idx.checkBlocks(ctx, start, end, repair)
+		if err != nil {
+			return nil, fmt.Errorf("failed to Check Blocks from '%d' to '%d' height: %v", start, end, err)
+		}
+		report.CheckedBlocks += end - start + 1
+		report.Issues = append(report.Issues, issues...)
+		report.RepairedHeights = append(report.RepairedHeights, heights...)
+	}
+
+	log.L().Info("Verified indexed data", zap.Int64("From Height", report.FromHeight), zap.Int64("To Height", report.ToHeight),
+		zap.Int("Issues", len(report.Issues)), zap.Int("Repaired", len(report.RepairedHeights)))
+	return report, nil
+}
+
+// checkBlocks verifies the heights from 'from' to 'to', then repairs the ones having issues if repair is set.
+// Repairing holds the sync lock from the verification on, so Listen neither writes the same heights
+// nor changes the chain in between.
+func (idx *Indexer) checkBlocks(ctx context.Context, from, to int64, repair bool) ([]*VerifyIssue, []int64, error) {
+	if repair {
+		idx.mu.Lock()
+		defer idx.mu.Unlock()
+	}
+
+	headers, issues, err := idx.verifyBlocks(ctx, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to Verify Blocks: %v", err)
+	}
+	if !repair || len(issues) == 0 {
+		return issues, nil, nil
+	}
+
+	heights, err := idx.repairBlocks(ctx, headers, issues)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to Repair Blocks: %v", err)
+	}
+	return issues, heights, nil
+}
+
+// verifyBlocks checks the heights from 'from' to 'to', and returns the headers of Full Node in ascending order with the issues found.
+func (idx *Indexer) verifyBlocks(ctx context.Context, from, to int64) ([]*btcjson.GetBlockHeaderVerboseResult, []*VerifyIssue, error) {
+	// The block before the range is needed to check the linkage of the first one
+	storedFrom := from
+	if storedFrom > 0 {
+		storedFrom--
+	}
+	storedBlocks, err := idx.manager.GetBlocksInRange(ctx, storedFrom, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to Get Blocks In Range: %v", err)
+	}
+	blocksByHeight := make(map[int64][]*model.Block, to-storedFrom+1)
+	for _, b := range storedBlocks {
+		blocksByHeight[b.Height] = append(blocksByHeight[b.Height], b)
+	}
+
+	stats, err := idx.manager.GetBlockStats(ctx, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to Get Block Stats: %v", err)
+	}
+
+	headers, err := idx.client.GetBlockHeadersVerboseByHeightRange(ctx, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to Get Block Headers Verbose By Height Range '%d' - '%d': %v", from, to, err)
+	}
+	if int64(len(headers)) != to-from+1 {
+		return nil, nil, fmt.Errorf("unexpected number of Block Headers '%d' from '%d' to '%d' height", len(headers), from, to)
+	}
+	hashes := make([]string, 0, len(headers))
+	for _, h := range headers {
+		hashes = append(hashes, h.Hash)
+	}
+	rawBlocks, err := idx.client.GetRawBlocks(ctx, hashes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to Get Raw Blocks, Hashes %v: %v", hashes, err)
+	}
+	if len(rawBlocks) != len(headers) {
+		return nil, nil, fmt.Errorf("not matched numbers of Headers '%d' and Raw Blocks '%d'", len(headers), len(rawBlocks))
+	}
+
+	var issues []*VerifyIssue
+	for i, header := range headers {
+		height := from + int64(i)
+		blocks := blocksByHeight[height]
+		switch {
+		case len(blocks) == 0:
+			issues = append(issues, &VerifyIssue{Height: height, Kind: IssueGap, Expected: header.Hash})
+			continue
+		case len(blocks) > 1:
+			issues = append(issues, &VerifyIssue{Height: height, Kind: IssueDuplicate, Expected: "1", Actual: strconv.Itoa(len(blocks))})
+			continue
+		}
+
+		block := blocks[0]
+		if block.Hash != header.Hash {
+			issues = append(issues, &VerifyIssue{Height: height, Kind: IssueHashMismatch, Expected: header.Hash, Actual: block.Hash})
+			continue
+		}
+		if previousBlocks := blocksByHeight[height-1]; len(previousBlocks) == 1 && previousBlocks[0].Hash != block.PreviousHash {
+			issues = append(issues, &VerifyIssue{Height: height, Kind: IssueBrokenLink, Expected: previousBlocks[0].Hash, Actual: block.PreviousHash})
+		}
+
+		s, ok := stats[height]
+		if !ok {
+			s = &model.BlockStats{Height: height}
+		}
+		issues = append(issues, idx.verifyBlockStats(height, rawBlocks[i], s)...)
+	}
+	return headers, issues, nil
+}
+
+func (idx *Indexer) verifyBlockStats(height int64, rawBlock *wire.MsgBlock, stats *model.BlockStats) []*VerifyIssue {
+	var txOutNo, txOutValue int64
+	for position, tx := range rawBlock.Transactions {
+		_, outs := idx.buildTxData(height, int32(position), tx, blockchain.IsCoinBaseTx(tx))
+		txOutNo += int64(len(outs))
+		for _, out := range outs {
+			txOutValue += out.Value
+		}
+	}
+
+	var issues []*VerifyIssue
+	if txNo := int64(len(rawBlock.Transactions)); txNo != stats.TxNo {
+		issues = append(issues, &VerifyIssue{Height: height, Kind: IssueTxCountMismatch, Expected: strconv.FormatInt(txNo, 10), Actual: strconv.FormatInt(stats.TxNo, 10)})
+	}
+	if txOutNo != stats.TxOutNo {
+		issues = append(issues, &VerifyIssue{Height: height, Kind: IssueOutputCountMismatch, Expected: strconv.FormatInt(txOutNo, 10), Actual: strconv.FormatInt(stats.TxOutNo, 10)})
+	}
+	if txOutValue != stats.TxOutValue {
+		issues = append(issues, &VerifyIssue{Height: height, Kind: IssueOutputSumMismatch, Expected: strconv.FormatInt(txOutValue, 10), Actual: strconv.FormatInt(stats.TxOutValue, 10)})
+	}
+	return issues
+}
+
+// repairBlocks indexes again the heights having issues, given the headers of Full Node in ascending order.
+// The caller must hold the sync lock.
+func (idx *Indexer) repairBlocks(ctx context.Context, headers []*btcjson.GetBlockHeaderVerboseResult, issues []*VerifyIssue) ([]int64, error) {
+	from := int64(headers[0].Height)
+	seen := make(map[int64]bool, len(issues))
+	var heights []int64
+	var repairHeaders []*btcjson.GetBlockHeaderVerboseResult
+	// Descending order as expected to build blocks data
+	for i := len(issues) - 1; i >= 0; i-- {
+		height := issues[i].Height
+		if seen[height] {
+			continue
+		}
+		seen[height] = true
+		heights = append(heights, height)
+		repairHeaders = append(repairHeaders, headers[height-from])
+	}
+
+	blocks, txs, txIns, txOuts, err := idx.buildBlocksData(ctx, repairHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Build Blocks Data: %v", err)
+	}
+	err = idx.manager.ReplaceBlocksData(ctx, heights, blocks, txs, txIns, txOuts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Replace Blocks Data, Heights %v: %v", heights, err)
+	}
+
+	log.L().Info("Repaired indexed data", zap.Int64s("Heights", heights))
+	// Listen goes on from the current block, which may have been replaced
+	if idx.currentBlock != nil {
+		block, err := idx.manager.GetLatestBlock(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to Get Latest Block: %v", err)
+		}
+		idx.currentBlock = block
+	}
+	for i, j := 0, len(heights)-1; i < j; i, j = i+1, j-1 {
+		heights[i], heights[j] = heights[j], heights[i]
+	}
+	return heights, nil
+}
@@ -0,0 +1,151 @@
+package indexer
+
+import (
+	"context"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	clientMock "github.com/darkknightbk52/btc-indexer/client/blockchain/mocks"
+	commonIndexer "github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/model"
+	managerMock "github.com/darkknightbk52/btc-indexer/store/mocks"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"strconv"
+)
+
+var _ = Describe("Verify Test", func() {
+	var (
+		mockClient  *clientMock.Client
+		mockManager *managerMock.Manager
+		indexer     *Indexer
+	)
+
+	statsOf := func(height int64) *model.BlockStats {
+		s := &model.BlockStats{Height: height, TxNo: int64(len(modelTxs[height]))}
+		for _, out := range modelTxOuts[height] {
+			s.TxOutNo++
+			s.TxOutValue += out.Value
+		}
+		return s
+	}
+
+	expectNode := func(from, to int64) {
+		var headers []*btcjson.GetBlockHeaderVerboseResult
+		var hashes []string
+		var blocks []*wire.MsgBlock
+		for height := from; height <= to; height++ {
+			headers = append(headers, rawBlockHeaders[height])
+			hashes = append(hashes, rawBlockHeaders[height].Hash)
+			blocks = append(blocks, rawBlocks[height])
+		}
+		mockClient.On("GetBlockHeadersVerboseByHeightRange", mock.Anything, from, to).Return(headers, nil).Once()
+		mockClient.On("GetRawBlocks", mock.Anything, hashes).Return(blocks, nil).Once()
+	}
+
+	BeforeEach(func() {
+		log.Init(false)
+		mockClient = new(clientMock.Client)
+		mockManager = new(managerMock.Manager)
+		indexer = &Indexer{
+			config: Config{
+				Network: "TestNet3",
+			},
+			netParams: chaincfg.TestNet3Params,
+			manager:   mockManager,
+			client:    mockClient,
+		}
+	})
+
+	AfterEach(func() {
+		mockClient.AssertExpectations(GinkgoT())
+		mockManager.AssertExpectations(GinkgoT())
+	})
+
+	It("Consistent data", func() {
+		mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[4], nil).Once()
+		mockManager.On("GetBlocksInRange", mock.Anything, int64(0), int64(4)).Return([]*model.Block{modelBlocks[0], modelBlocks[1], modelBlocks[2], modelBlocks[3], modelBlocks[4]}, nil).Once()
+		mockManager.On("GetBlockStats", mock.Anything, int64(1), int64(4)).Return(map[int64]*model.BlockStats{1: statsOf(1), 2: statsOf(2), 3: statsOf(3), 4: statsOf(4)}, nil).Once()
+		expectNode(1, 4)
+
+		report, err := indexer.Verify(context.Background(), 1, 10, false)
+		Expect(err).Should(Succeed())
+		Expect(report.ToHeight).Should(Equal(int64(4)))
+		Expect(report.CheckedBlocks).Should(Equal(int64(4)))
+		Expect(report.Issues).Should(BeEmpty())
+		Expect(report.RepairedHeights).Should(BeEmpty())
+	})
+
+	It("Inconsistent data", func() {
+		badStats := statsOf(1)
+		badStats.TxOutValue++
+		mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[4], nil).Once()
+		mockManager.On("GetBlocksInRange", mock.Anything, int64(0), int64(4)).Return([]*model.Block{modelBlocks[0], modelBlocks[1], modelBlocks[2], modelBlocks[2], reorgModelBlocks[3], modelBlocks[4]}, nil).Once()
+		mockManager.On("GetBlockStats", mock.Anything, int64(1), int64(4)).Return(map[int64]*model.BlockStats{1: badStats, 2: statsOf(2), 3: statsOf(3), 4: statsOf(4)}, nil).Once()
+		expectNode(1, 4)
+
+		report, err := indexer.Verify(context.Background(), 1, 4, false)
+		Expect(err).Should(Succeed())
+		Expect(report.Issues).Should(Equal([]*VerifyIssue{
+			{Height: 1, Kind: IssueOutputSumMismatch, Expected: strconv.FormatInt(statsOf(1).TxOutValue, 10), Actual: strconv.FormatInt(badStats.TxOutValue, 10)},
+			{Height: 2, Kind: IssueDuplicate, Expected: "1", Actual: "2"},
+			{Height: 3, Kind: IssueHashMismatch, Expected: modelBlocks[3].Hash, Actual: reorgModelBlocks[3].Hash},
+			{Height: 4, Kind: IssueBrokenLink, Expected: reorgModelBlocks[3].Hash, Actual: modelBlocks[3].Hash},
+		}))
+	})
+
+	It("Repair gap", func() {
+		mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[4], nil).Once()
+		mockManager.On("GetBlocksInRange", mock.Anything, int64(1), int64(4)).Return([]*model.Block{modelBlocks[1], modelBlocks[2], modelBlocks[4]}, nil).Once()
+		mockManager.On("GetBlockStats", mock.Anything, int64(2), int64(4)).Return(map[int64]*model.BlockStats{2: statsOf(2), 4: statsOf(4)}, nil).Once()
+		expectNode(2, 4)
+		mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[3].Hash}).Return([]*wire.MsgBlock{rawBlocks[3]}, nil).Once()
+		mockManager.On("ReplaceBlocksData", mock.Anything, []int64{3}, []*model.Block{modelBlocks[3]}, modelTxs[3], modelTxIns[3], modelTxOuts[3]).Return(nil).Once()
+
+		report, err := indexer.Verify(context.Background(), 2, 4, true)
+		Expect(err).Should(Succeed())
+		Expect(report.Issues).Should(Equal([]*VerifyIssue{{Height: 3, Kind: IssueGap, Expected: modelBlocks[3].Hash}}))
+		Expect(report.RepairedHeights).Should(Equal([]int64{3}))
+	})
+
+	It("Repair while listening", func() {
+		mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(modelBlocks[4], nil).Twice()
+		mockManager.On("GetBlocksInRange", mock.Anything, int64(3), int64(4)).Return([]*model.Block{modelBlocks[3], reorgModelBlocks[4]}, nil).Once()
+		mockManager.On("GetBlockStats", mock.Anything, int64(4), int64(4)).Return(map[int64]*model.BlockStats{4: statsOf(4)}, nil).Once()
+		expectNode(4, 4)
+		mockClient.On("GetRawBlocks", mock.Anything, []string{rawBlockHeaders[4].Hash}).Return([]*wire.MsgBlock{rawBlocks[4]}, nil).Once()
+		mockManager.On("ReplaceBlocksData", mock.Anything, []int64{4}, []*model.Block{modelBlocks[4]}, modelTxs[4], modelTxIns[4], modelTxOuts[4]).Return(nil).Once()
+		indexer.currentBlock = reorgModelBlocks[4]
+
+		// The sync of Listen in progress
+		indexer.mu.Lock()
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			report, err := indexer.Verify(context.Background(), 4, 4, true)
+			Expect(err).Should(Succeed())
+			Expect(report.RepairedHeights).Should(Equal([]int64{4}))
+		}()
+		Consistently(done).ShouldNot(BeClosed())
+
+		indexer.mu.Unlock()
+		Eventually(done).Should(BeClosed())
+		Expect(indexer.currentBlock).Should(Equal(modelBlocks[4]))
+	})
+
+	It("Empty DB", func() {
+		mockManager.On("GetLatestBlock", mock.Anything, mock.Anything).Return(nil, commonIndexer.ErrNotFound).Once()
+
+		report, err := indexer.Verify(context.Background(), 0, 10, false)
+		Expect(err).Should(Succeed())
+		Expect(report.CheckedBlocks).Should(Equal(int64(0)))
+	})
+
+	It("Invalid range", func() {
+		_, err := indexer.Verify(context.Background(), 5, 4, false)
+		Expect(err).Should(Equal(commonIndexer.ErrInvalidRange))
+	})
+})
@@ -0,0 +1,44 @@
+package leader
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	defaultLockKey        = 0x6274632d696478 // "btc-idx"
+	defaultRetryTimeInSec = 10
+	defaultCheckTimeInSec = 5
+)
+
+type Config struct {
+	// Disabled makes the instance index without leader election, e.g. when it runs alone
+	Disabled bool
+	// LockKey identifies the lock shared by the instances indexing the same DB
+	LockKey int64
+	// RetryTimeInSec is how often a follower tries to become the leader
+	RetryTimeInSec int
+	// CheckTimeInSec is how often the leader checks it still holds the lock
+	CheckTimeInSec int
+}
+
+func (c Config) Validate() error {
+	var errContents []string
+	if c.RetryTimeInSec < 0 {
+		errContents = append(errContents, "RetryTimeInSec config for Leader Election must not be negative")
+	}
+	if c.CheckTimeInSec < 0 {
+		errContents = append(errContents, "CheckTimeInSec config for Leader Election must not be negative")
+	}
+	if len(errContents) > 0 {
+		return errors.New(strings.Join(errContents, ", "))
+	}
+	return nil
+}
+
+func (c Config) Key() int64 {
+	if c.LockKey == 0 {
+		return defaultLockKey
+	}
+	return c.LockKey
+}
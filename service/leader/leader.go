@@ -0,0 +1,115 @@
+package leader
+
+import (
+	"context"
+	"expvar"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/store"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// isLeader is published at /debug/vars, 1 while the instance is the leader.
+var isLeader = expvar.NewInt("indexer_leader")
+
+// Elector makes sure only one of the instances sharing a lock runs the leader task at a time.
+// The others wait as followers and take over once the leader dies.
+type Elector struct {
+	lock      store.LeaderLock
+	retryTime time.Duration
+	checkTime time.Duration
+	// leading is 1 while the leader task runs
+	leading int32
+}
+
+func NewElector(lock store.LeaderLock, cfg Config) *Elector {
+	e := &Elector{
+		lock:      lock,
+		retryTime: time.Second * defaultRetryTimeInSec,
+		checkTime: time.Second * defaultCheckTimeInSec,
+	}
+	if cfg.RetryTimeInSec > 0 {
+		e.retryTime = time.Second * time.Duration(cfg.RetryTimeInSec)
+	}
+	if cfg.CheckTimeInSec > 0 {
+		e.checkTime = time.Second * time.Duration(cfg.CheckTimeInSec)
+	}
+	return e
+}
+
+// Run campaigns for the leadership until ctx is done, and runs task whenever the instance becomes the leader.
+// The context of task is canceled once the leadership is lost, then the instance campaigns again.
+func (e *Elector) Run(ctx context.Context, task func(ctx context.Context) error) error {
+	for {
+		locked, err := e.lock.TryLock(ctx)
+		if err != nil {
+			log.L().Warn("Failed to Try Leader Lock", zap.Error(err))
+		}
+		if locked {
+			e.lead(ctx, task)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(e.retryTime):
+		}
+	}
+}
+
+// IsLeader tells if the instance is the leader, running the leader task.
+func (e *Elector) IsLeader() bool {
+	return atomic.LoadInt32(&e.leading) == 1
+}
+
+func (e *Elector) lead(ctx context.Context, task func(ctx context.Context) error) {
+	log.L().Info("Became the leader")
+	isLeader.Set(1)
+	atomic.StoreInt32(&e.leading, 1)
+	defer func() {
+		atomic.StoreInt32(&e.leading, 0)
+		isLeader.Set(0)
+	}()
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		e.watch(leaderCtx, cancel)
+	}()
+
+	err := task(leaderCtx)
+	cancel()
+	wg.Wait()
+	if err != nil && err != context.Canceled {
+		log.L().Warn("Leader task stopped", zap.Error(err))
+	}
+
+	err = e.lock.Unlock()
+	if err != nil {
+		log.L().Warn("Failed to Unlock Leader Lock", zap.Error(err))
+	}
+	log.L().Info("Stepped down from the leader")
+}
+
+// watch cancels the leader task once the lock may not be held anymore.
+func (e *Elector) watch(ctx context.Context, cancel context.CancelFunc) {
+	ticker := time.NewTicker(e.checkTime)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := e.lock.Check(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.L().Error("Lost the leadership", zap.Error(err))
+				cancel()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	. "github.com/onsi/gomega"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLock is shared by the electors of a test like a lock in DB.
+type fakeLock struct {
+	mu     sync.Mutex
+	holder *fakeSession
+}
+
+type fakeSession struct {
+	lock   *fakeLock
+	broken bool
+}
+
+func (s *fakeSession) TryLock(ctx context.Context) (bool, error) {
+	s.lock.mu.Lock()
+	defer s.lock.mu.Unlock()
+	if s.lock.holder == nil {
+		s.lock.holder = s
+	}
+	return s.lock.holder == s, nil
+}
+
+func (s *fakeSession) Check(ctx context.Context) error {
+	s.lock.mu.Lock()
+	defer s.lock.mu.Unlock()
+	if s.broken {
+		// The lock is released with the session
+		if s.lock.holder == s {
+			s.lock.holder = nil
+		}
+		return errors.New("connection broken")
+	}
+	return nil
+}
+
+func (s *fakeSession) Unlock() error {
+	s.lock.mu.Lock()
+	defer s.lock.mu.Unlock()
+	if s.lock.holder == s {
+		s.lock.holder = nil
+	}
+	return nil
+}
+
+func (s *fakeSession) setBroken(broken bool) {
+	s.lock.mu.Lock()
+	defer s.lock.mu.Unlock()
+	s.broken = broken
+}
+
+func newTestElector(session *fakeSession) *Elector {
+	e := NewElector(session, Config{})
+	e.retryTime = time.Millisecond * 10
+	e.checkTime = time.Millisecond * 10
+	return e
+}
+
+func TestElector_Failover(t *testing.T) {
+	RegisterTestingT(t)
+	log.Init(false)
+
+	lock := &fakeLock{}
+	sessions := []*fakeSession{{lock: lock}, {lock: lock}}
+	var mu sync.Mutex
+	running := make(map[int]bool)
+	var overlapped bool
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i, s := range sessions {
+		i, e := i, newTestElector(s)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = e.Run(ctx, func(ctx context.Context) error {
+				mu.Lock()
+				overlapped = overlapped || len(running) > 0
+				running[i] = true
+				mu.Unlock()
+
+				<-ctx.Done()
+
+				mu.Lock()
+				delete(running, i)
+				mu.Unlock()
+				return ctx.Err()
+			})
+		}()
+	}
+
+	leader := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		for i := range running {
+			return i
+		}
+		return -1
+	}
+	Eventually(leader).ShouldNot(Equal(-1))
+	first := leader()
+	Consistently(leader, time.Millisecond*50).Should(Equal(first))
+	mu.Lock()
+	Expect(overlapped).Should(BeFalse())
+	mu.Unlock()
+
+	// The leader dies, the follower takes over
+	sessions[first].setBroken(true)
+	Eventually(leader).Should(Equal(1 - first))
+
+	cancel()
+	wg.Wait()
+}
+
+func TestElector_TaskFailed(t *testing.T) {
+	RegisterTestingT(t)
+	log.Init(false)
+
+	lock := &fakeLock{}
+	session := &fakeSession{lock: lock}
+	e := newTestElector(session)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var runs int
+	err := e.Run(ctx, func(ctx context.Context) error {
+		runs++
+		Expect(e.IsLeader()).Should(BeTrue())
+		if runs == 3 {
+			cancel()
+		}
+		return errors.New("failed")
+	})
+	Expect(err).Should(Equal(context.Canceled))
+	Expect(runs).Should(Equal(3))
+	Expect(e.IsLeader()).Should(BeFalse())
+	// The lock is given back for another instance after the task failed
+	Expect(lock.holder).Should(BeNil())
+}
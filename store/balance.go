@@ -0,0 +1,278 @@
+package store
+
+import (
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"sort"
+	"strings"
+)
+
+// balanceChunkSize is the number of addresses or tx hashes per query, far below the limit of params.
+const balanceChunkSize = 10000
+
+// balanceEntry is the value an address receives or sends in a tx.
+type balanceEntry struct {
+	address  string
+	height   int64
+	txHash   string
+	received int64
+	sent     int64
+}
+
+// balanceEntries returns the entries of the ins & outs, the last ones of the duplicated keys only.
+// prevOut gives the tx out spent by a tx in, nil if not indexed.
+func balanceEntries(txIns []*model.TxIn, txOuts []*model.TxOut, prevOut func(p txDataId) *model.TxOut) []balanceEntry {
+	entries := make([]balanceEntry, 0, len(txIns)+len(txOuts))
+	for _, i := range lastOfKeys(len(txOuts), func(i int) string {
+		return fmt.Sprint(txOuts[i].Height, txOuts[i].TxHash, txOuts[i].TxIndex)
+	}) {
+		out := txOuts[i]
+		entries = append(entries, balanceEntry{address: out.Address, height: out.Height, txHash: out.TxHash, received: out.Value})
+	}
+	for _, i := range lastOfKeys(len(txIns), func(i int) string {
+		return fmt.Sprint(txIns[i].Height, txIns[i].TxHash, txIns[i].TxIndex)
+	}) {
+		in := txIns[i]
+		out := prevOut(txDataId{txHash: in.PreviousTxHash, txIndex: in.PreviousTxIndex})
+		if out == nil {
+			continue
+		}
+		entries = append(entries, balanceEntry{address: out.Address, height: in.Height, txHash: in.TxHash, sent: out.Value})
+	}
+	return entries
+}
+
+type balanceKey struct {
+	address string
+	height  int64
+}
+
+// groupChanges sums the entries per address & height, counting the distinct txs, in order of address & height.
+func groupChanges(entries []balanceEntry) []*model.AddressBalanceChange {
+	changes := make(map[balanceKey]*model.AddressBalanceChange)
+	txs := make(map[balanceKey]map[string]bool)
+	for _, e := range entries {
+		k := balanceKey{address: e.address, height: e.height}
+		c, ok := changes[k]
+		if !ok {
+			c = &model.AddressBalanceChange{Address: e.address, Height: e.height}
+			changes[k] = c
+			txs[k] = make(map[string]bool)
+		}
+		c.Received += e.received
+		c.Sent += e.sent
+		if !txs[k][e.txHash] {
+			txs[k][e.txHash] = true
+			c.TxNo++
+		}
+	}
+
+	result := make([]*model.AddressBalanceChange, 0, len(changes))
+	for _, c := range changes {
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Address != result[j].Address {
+			return result[i].Address < result[j].Address
+		}
+		return result[i].Height < result[j].Height
+	})
+	return result
+}
+
+// sumChanges returns the balance of the address from its changes up to the height, common.ErrNotFound if none.
+func sumChanges(address string, changes []*model.AddressBalanceChange, height int64) (*model.AddressBalance, error) {
+	var balance *model.AddressBalance
+	for _, c := range changes {
+		if c.Address != address || c.Height > height {
+			continue
+		}
+		if balance == nil {
+			balance = &model.AddressBalance{Address: address, FirstSeenHeight: c.Height, LastSeenHeight: c.Height}
+		}
+		balance.Received += c.Received
+		balance.Sent += c.Sent
+		balance.TxNo += c.TxNo
+		if c.Height < balance.FirstSeenHeight {
+			balance.FirstSeenHeight = c.Height
+		}
+		if c.Height > balance.LastSeenHeight {
+			balance.LastSeenHeight = c.Height
+		}
+	}
+	if balance == nil {
+		return nil, common.ErrNotFound
+	}
+	balance.Balance = balance.Received - balance.Sent
+	return balance, nil
+}
+
+// addBalanceChanges stores the balance changes of the ins & outs, then adds their differences with the changes
+// stored before at the same heights, by a retried batch, to the balances.
+func (txm *txManager) addBalanceChanges(txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	outs := make(map[txDataId]*model.TxOut, len(txOuts))
+	for _, out := range txOuts {
+		outs[txDataId{txHash: out.TxHash, txIndex: out.TxIndex}] = out
+	}
+	var hashes []string
+	seen := make(map[string]bool)
+	for _, in := range txIns {
+		p := txDataId{txHash: in.PreviousTxHash, txIndex: in.PreviousTxIndex}
+		if _, ok := outs[p]; !ok && !seen[p.txHash] {
+			seen[p.txHash] = true
+			hashes = append(hashes, p.txHash)
+		}
+	}
+	err := txm.getTxOutsOfTxs(hashes, outs)
+	if err != nil {
+		return err
+	}
+
+	changes := groupChanges(balanceEntries(txIns, txOuts, func(p txDataId) *model.TxOut {
+		return outs[p]
+	}))
+	if len(changes) == 0 {
+		return nil
+	}
+	stored, err := txm.getBalanceChanges(changes)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, 0, len(changes)*len(model.AddressBalanceChange{}.ColumnNames()))
+	diffs := make(map[string]*model.AddressBalance)
+	var addresses []string
+	for _, c := range changes {
+		values = append(values, c.Address, c.Height, c.Received, c.Sent, c.TxNo)
+
+		d, ok := diffs[c.Address]
+		if !ok {
+			d = &model.AddressBalance{Address: c.Address, FirstSeenHeight: c.Height, LastSeenHeight: c.Height}
+			diffs[c.Address] = d
+			addresses = append(addresses, c.Address)
+		}
+		d.Received += c.Received
+		d.Sent += c.Sent
+		d.TxNo += c.TxNo
+		if s, ok := stored[balanceKey{address: c.Address, height: c.Height}]; ok {
+			d.Received -= s.Received
+			d.Sent -= s.Sent
+			d.TxNo -= s.TxNo
+		}
+		// Changes are in order of height per address
+		d.LastSeenHeight = c.Height
+	}
+	err = txm.execSql(fmt.Sprintf("INSERT INTO %s (%s)", model.AddressBalanceChange{}.TableName(), strings.Join(model.AddressBalanceChange{}.ColumnNames(), ",")),
+		onConflictUpdate(model.AddressBalanceChange{}.KeyColumnNames(), model.AddressBalanceChange{}.ColumnNames()),
+		values, len(model.AddressBalanceChange{}.ColumnNames()))
+	if err != nil {
+		return fmt.Errorf("failed to Create Address Balance Changes: %v", err)
+	}
+
+	values = make([]interface{}, 0, len(diffs)*7)
+	for _, a := range addresses {
+		d := diffs[a]
+		values = append(values, d.Address, d.Received, d.Sent, d.Received-d.Sent, d.TxNo, d.FirstSeenHeight, d.LastSeenHeight)
+	}
+	err = txm.execSql("INSERT INTO address_balances (address, received, sent, balance, tx_no, first_seen_height, last_seen_height)",
+		`ON CONFLICT (address) DO UPDATE SET
+			received = address_balances.received + EXCLUDED.received,
+			sent = address_balances.sent + EXCLUDED.sent,
+			balance = address_balances.balance + EXCLUDED.balance,
+			tx_no = address_balances.tx_no + EXCLUDED.tx_no,
+			first_seen_height = CASE WHEN EXCLUDED.first_seen_height < address_balances.first_seen_height
+				THEN EXCLUDED.first_seen_height ELSE address_balances.first_seen_height END,
+			last_seen_height = CASE WHEN EXCLUDED.last_seen_height > address_balances.last_seen_height
+				THEN EXCLUDED.last_seen_height ELSE address_balances.last_seen_height END`,
+		values, 7)
+	if err != nil {
+		return fmt.Errorf("failed to Update Address Balances: %v", err)
+	}
+	return nil
+}
+
+// removeBalanceChanges deletes the balance changes matching the condition, then computes again the balances of their addresses.
+func (txm *txManager) removeBalanceChanges(where string, args ...interface{}) error {
+	var addresses []string
+	err := txm.db.Model(model.AddressBalanceChange{}).Where(where, args...).Pluck("DISTINCT address", &addresses).Error
+	if err != nil {
+		return fmt.Errorf("failed to Get Addresses of Balance Changes: %v", err)
+	}
+	err = txm.db.Delete(model.AddressBalanceChange{}, append([]interface{}{where}, args...)...).Error
+	if err != nil {
+		return fmt.Errorf("failed to Delete Address Balance Changes: %v", err)
+	}
+
+	for start := 0; start < len(addresses); start += balanceChunkSize {
+		end := start + balanceChunkSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		chunk := addresses[start:end]
+		err = txm.db.Delete(model.AddressBalance{}, "address IN (?)", chunk).Error
+		if err != nil {
+			return fmt.Errorf("failed to Delete Address Balances: %v", err)
+		}
+		err = txm.db.Exec(`INSERT INTO address_balances (address, received, sent, balance, tx_no, first_seen_height, last_seen_height)
+			SELECT address, SUM(received), SUM(sent), SUM(received) - SUM(sent), SUM(tx_no), MIN(height), MAX(height)
+			FROM address_balance_changes WHERE address IN (?) GROUP BY address`, chunk).Error
+		if err != nil {
+			return fmt.Errorf("failed to Compute Address Balances: %v", err)
+		}
+	}
+	return nil
+}
+
+// getTxOutsOfTxs adds the stored tx outs of the txs to outs.
+func (txm *txManager) getTxOutsOfTxs(hashes []string, outs map[txDataId]*model.TxOut) error {
+	for start := 0; start < len(hashes); start += balanceChunkSize {
+		end := start + balanceChunkSize
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		txOuts, err := findTxOuts(txm.db.Where("tx_hash IN (?)", hashesBytes(hashes[start:end])))
+		if err != nil {
+			return fmt.Errorf("failed to Get TxOuts of Txs: %v", err)
+		}
+		for _, out := range txOuts {
+			outs[txDataId{txHash: out.TxHash, txIndex: out.TxIndex}] = out
+		}
+	}
+	return nil
+}
+
+// getBalanceChanges returns the stored changes having the keys of the given ones.
+func (txm *txManager) getBalanceChanges(changes []*model.AddressBalanceChange) (map[balanceKey]*model.AddressBalanceChange, error) {
+	var addresses []string
+	var heights []int64
+	seenAddresses := make(map[string]bool)
+	seenHeights := make(map[int64]bool)
+	for _, c := range changes {
+		if !seenAddresses[c.Address] {
+			seenAddresses[c.Address] = true
+			addresses = append(addresses, c.Address)
+		}
+		if !seenHeights[c.Height] {
+			seenHeights[c.Height] = true
+			heights = append(heights, c.Height)
+		}
+	}
+
+	stored := make(map[balanceKey]*model.AddressBalanceChange)
+	for start := 0; start < len(addresses); start += balanceChunkSize {
+		end := start + balanceChunkSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		var rows []*model.AddressBalanceChange
+		err := txm.db.Where("address IN (?) AND height IN (?)", addresses[start:end], heights).Find(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to Get Address Balance Changes: %v", err)
+		}
+		for _, c := range rows {
+			stored[balanceKey{address: c.Address, height: c.Height}] = c
+		}
+	}
+	return stored, nil
+}
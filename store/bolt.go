@@ -0,0 +1,1037 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/model"
+	bolt "go.etcd.io/bbolt"
+	"math"
+	"time"
+)
+
+// Buckets of the embedded DB. The keys of the data buckets start with the big endian height,
+// so a cursor scans them in order of height:
+//
+//	blocks:    height
+//	txes:      height | hash
+//	tx_ins:    height | tx hash | 0x00 | tx index
+//	tx_outs:   height | tx hash | 0x00 | tx index
+//
+// The index buckets map a key of the data buckets to the one of their value:
+//
+//	tx_hashes: hash -> heights of the tx in ascending order, 8 bytes each; 2 for the duplicated coinbases before BIP30
+//	addr_ins:  address | 0x00 | height | tx position | tx hash | 0x00 | tx index -> empty, the key of tx_ins with the position
+//	addr_outs: address | 0x00 | height | tx position | tx hash | 0x00 | tx index -> empty, the key of tx_outs with the position
+//	spends:    previous tx hash | 0x00 | previous tx index -> key of tx_ins spending the tx out
+//	reorgs:    sequence id
+//
+// The watch lists of the address book are kept apart from the indexed data:
+//
+//	watched_addresses: list | 0x00 | address -> empty
+var (
+	blocksBucket   = []byte(model.Block{}.TableName())
+	txsBucket      = []byte(model.Tx{}.TableName())
+	txInsBucket    = []byte(model.TxIn{}.TableName())
+	txOutsBucket   = []byte(model.TxOut{}.TableName())
+	txHashesBucket = []byte("tx_hashes")
+	addrInsBucket  = []byte("addr_ins")
+	addrOutsBucket = []byte("addr_outs")
+	spendsBucket   = []byte("spends")
+	reorgsBucket   = []byte("reorgs")
+	watchedBucket  = []byte(model.WatchedAddress{}.TableName())
+
+	boltBuckets = [][]byte{blocksBucket, txsBucket, txInsBucket, txOutsBucket, txHashesBucket, addrInsBucket, addrOutsBucket, spendsBucket, reorgsBucket, watchedBucket}
+)
+
+// boltOpenTimeout bounds the wait for the file lock, held by another process using the DB.
+const boltOpenTimeout = 5 * time.Second
+
+// errPageFull stops a scan once a page has enough values
+var errPageFull = errors.New("page full")
+
+// boltManager stores the data in an embedded bbolt DB, for deployments without Postgres.
+// Every write is done in a single bbolt transaction, so it's atomic.
+type boltManager struct {
+	db *bolt.DB
+}
+
+func NewBoltManager(path string) (Manager, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to Open bbolt DB, Path '%s': %v", path, err)
+	}
+
+	m := &boltManager{db: db}
+	err = m.createBuckets()
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// view runs fn in a read-only bbolt transaction, unless the context is done. A bbolt transaction can't be interrupted.
+func (m *boltManager) view(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.db.View(fn)
+}
+
+// update runs fn in a read-write bbolt transaction, unless the context is done.
+func (m *boltManager) update(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.db.Update(fn)
+}
+
+func (m *boltManager) createBuckets() error {
+	return m.db.Update(func(tx *bolt.Tx) error {
+		indexSpends := tx.Bucket(spendsBucket) == nil
+		for _, name := range boltBuckets {
+			_, err := tx.CreateBucketIfNotExists(name)
+			if err != nil {
+				return fmt.Errorf("failed to Create Bucket '%s': %v", name, err)
+			}
+		}
+		if !indexSpends {
+			return nil
+		}
+
+		// The spends of a DB created before the index existed
+		spends := tx.Bucket(spendsBucket)
+		return tx.Bucket(txInsBucket).ForEach(func(k, v []byte) error {
+			in := new(model.TxIn)
+			err := json.Unmarshal(v, in)
+			if err != nil {
+				return fmt.Errorf("failed to Decode TxIn: %v", err)
+			}
+			return spends.Put(outPointKey(in.PreviousTxHash, in.PreviousTxIndex), k)
+		})
+	})
+}
+
+func (m *boltManager) GetLatestBlock(ctx context.Context) (*model.Block, error) {
+	b := new(model.Block)
+	err := m.view(ctx, func(tx *bolt.Tx) error {
+		_, v := tx.Bucket(blocksBucket).Cursor().Last()
+		if v == nil {
+			return common.ErrNotFound
+		}
+		return json.Unmarshal(v, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (m *boltManager) GetBlock(ctx context.Context, height int64) (*model.Block, error) {
+	b := new(model.Block)
+	err := m.view(ctx, func(tx *bolt.Tx) error {
+		v := tx.Bucket(blocksBucket).Get(heightKey(height))
+		if v == nil {
+			return common.ErrNotFound
+		}
+		return json.Unmarshal(v, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (m *boltManager) GetBlocks(ctx context.Context, heights []int64) (map[int64]*model.Block, error) {
+	result := make(map[int64]*model.Block, len(heights))
+	err := m.view(ctx, func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(blocksBucket)
+		for _, height := range heights {
+			v := bucket.Get(heightKey(height))
+			if v == nil {
+				continue
+			}
+			b := new(model.Block)
+			err := json.Unmarshal(v, b)
+			if err != nil {
+				return fmt.Errorf("failed to Decode Block at height '%d': %v", height, err)
+			}
+			result[b.Height] = b
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (m *boltManager) Reorg(ctx context.Context, event *model.Reorg) error {
+	stampReorg(event)
+	return m.update(ctx, func(tx *bolt.Tx) error {
+		event.TxNo = 0
+		err := scanHeights(tx.Bucket(txsBucket), event.FromHeight, math.MaxInt64, func(k, v []byte) error {
+			event.TxNo++
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to Count Txs from height '%d': %v", event.FromHeight, err)
+		}
+
+		err = deleteHeights(tx, event.FromHeight, -1)
+		if err != nil {
+			return fmt.Errorf("failed to Delete data from height '%d': %v", event.FromHeight, err)
+		}
+
+		bucket := tx.Bucket(reorgsBucket)
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return fmt.Errorf("failed to Get Reorg id: %v", err)
+		}
+		event.Id = int64(id)
+		err = putJSON(bucket, heightKey(event.Id), event)
+		if err != nil {
+			return fmt.Errorf("failed to Create Reorg event '%v': %v", event, err)
+		}
+		return nil
+	})
+}
+
+func (m *boltManager) AddBlocksData(ctx context.Context, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	err := validateBlocksData(blocks, txs, txIns, txOuts)
+	if err != nil {
+		return err
+	}
+	return m.update(ctx, func(tx *bolt.Tx) error {
+		return putBlocksData(tx, blocks, txs, txIns, txOuts)
+	})
+}
+
+func (m *boltManager) GetBlocksData(ctx context.Context, fromHeight, toHeight int64, interestedAddresses []string) (map[int64]*model.Block, map[int64][]*model.TxIn, map[int64][]*model.TxOut, error) {
+	blocks := make(map[int64]*model.Block)
+	txInsResult := make(map[int64][]*model.TxIn)
+	txOutsResult := make(map[int64][]*model.TxOut)
+	err := m.view(ctx, func(tx *bolt.Tx) error {
+		err := scanHeights(tx.Bucket(blocksBucket), fromHeight, toHeight, func(k, v []byte) error {
+			b := new(model.Block)
+			err := json.Unmarshal(v, b)
+			if err != nil {
+				return fmt.Errorf("failed to Decode Block: %v", err)
+			}
+			blocks[b.Height] = b
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool, len(interestedAddresses))
+		for _, address := range interestedAddresses {
+			if seen[address] {
+				continue
+			}
+			seen[address] = true
+
+			err = scanAddress(tx.Bucket(addrInsBucket), tx.Bucket(txInsBucket), address, fromHeight, toHeight, func(v []byte) error {
+				txIn := new(model.TxIn)
+				err := json.Unmarshal(v, txIn)
+				if err != nil {
+					return fmt.Errorf("failed to Decode TxIn: %v", err)
+				}
+				txInsResult[txIn.Height] = append(txInsResult[txIn.Height], txIn)
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to Get TxIns of address '%s': %v", address, err)
+			}
+
+			err = scanAddress(tx.Bucket(addrOutsBucket), tx.Bucket(txOutsBucket), address, fromHeight, toHeight, func(v []byte) error {
+				txOut := new(model.TxOut)
+				err := json.Unmarshal(v, txOut)
+				if err != nil {
+					return fmt.Errorf("failed to Decode TxOut: %v", err)
+				}
+				txOutsResult[txOut.Height] = append(txOutsResult[txOut.Height], txOut)
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to Get TxOuts of address '%s': %v", address, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to Get Blocks Data from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+	return blocks, txInsResult, txOutsResult, nil
+}
+
+func (m *boltManager) GetBlocksInRange(ctx context.Context, fromHeight, toHeight int64) ([]*model.Block, error) {
+	var blocks []*model.Block
+	err := m.view(ctx, func(tx *bolt.Tx) error {
+		return scanHeights(tx.Bucket(blocksBucket), fromHeight, toHeight, func(k, v []byte) error {
+			b := new(model.Block)
+			err := json.Unmarshal(v, b)
+			if err != nil {
+				return fmt.Errorf("failed to Decode Block: %v", err)
+			}
+			blocks = append(blocks, b)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Blocks from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+	return blocks, nil
+}
+
+func (m *boltManager) GetBlockStats(ctx context.Context, fromHeight, toHeight int64) (map[int64]*model.BlockStats, error) {
+	stats := make(map[int64]*model.BlockStats)
+	getStats := func(height int64) *model.BlockStats {
+		s, ok := stats[height]
+		if !ok {
+			s = &model.BlockStats{Height: height}
+			stats[height] = s
+		}
+		return s
+	}
+
+	err := m.view(ctx, func(tx *bolt.Tx) error {
+		err := scanHeights(tx.Bucket(txsBucket), fromHeight, toHeight, func(k, v []byte) error {
+			getStats(keyHeight(k)).TxNo++
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to Count Txs: %v", err)
+		}
+
+		return scanHeights(tx.Bucket(txOutsBucket), fromHeight, toHeight, func(k, v []byte) error {
+			txOut := new(model.TxOut)
+			err := json.Unmarshal(v, txOut)
+			if err != nil {
+				return fmt.Errorf("failed to Decode TxOut: %v", err)
+			}
+			s := getStats(txOut.Height)
+			s.TxOutNo++
+			s.TxOutValue += txOut.Value
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Block Stats from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+	return stats, nil
+}
+
+// GetBlocksDataInRange scans the data buckets, in order of their keys, then filters the ins & outs of the addresses.
+func (m *boltManager) GetBlocksDataInRange(ctx context.Context, fromHeight, toHeight int64, addresses []string) ([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut, error) {
+	var blocks []*model.Block
+	var txs []*model.Tx
+	var txIns []*model.TxIn
+	var txOuts []*model.TxOut
+	err := m.view(ctx, func(tx *bolt.Tx) error {
+		err := scanHeights(tx.Bucket(blocksBucket), fromHeight, toHeight, func(k, v []byte) error {
+			b := new(model.Block)
+			blocks = append(blocks, b)
+			return json.Unmarshal(v, b)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to Decode Block: %v", err)
+		}
+		err = scanHeights(tx.Bucket(txsBucket), fromHeight, toHeight, func(k, v []byte) error {
+			t := new(model.Tx)
+			txs = append(txs, t)
+			return json.Unmarshal(v, t)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to Decode Tx: %v", err)
+		}
+		err = scanHeights(tx.Bucket(txInsBucket), fromHeight, toHeight, func(k, v []byte) error {
+			txIn := new(model.TxIn)
+			txIns = append(txIns, txIn)
+			return json.Unmarshal(v, txIn)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to Decode TxIn: %v", err)
+		}
+		err = scanHeights(tx.Bucket(txOutsBucket), fromHeight, toHeight, func(k, v []byte) error {
+			txOut := new(model.TxOut)
+			txOuts = append(txOuts, txOut)
+			return json.Unmarshal(v, txOut)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to Decode TxOut: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to Get Blocks Data from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+	txs, txIns, txOuts = filterAddresses(addresses, txs, txIns, txOuts)
+	return blocks, txs, txIns, txOuts, nil
+}
+
+func (m *boltManager) ReplaceBlocksData(ctx context.Context, heights []int64, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	err := validateBlocksData(blocks, txs, txIns, txOuts)
+	if err != nil {
+		return err
+	}
+	return m.update(ctx, func(tx *bolt.Tx) error {
+		for _, height := range heights {
+			err := deleteHeights(tx, height, height)
+			if err != nil {
+				return fmt.Errorf("failed to Delete data at height '%d': %v", height, err)
+			}
+		}
+		return putBlocksData(tx, blocks, txs, txIns, txOuts)
+	})
+}
+
+func (m *boltManager) GetTx(ctx context.Context, hash string) (*model.TxDetail, error) {
+	if !validHash(hash) {
+		return nil, common.ErrInvalidHash
+	}
+	detail := new(model.TxDetail)
+	err := m.view(ctx, func(tx *bolt.Tx) error {
+		heights := tx.Bucket(txHashesBucket).Get([]byte(hash))
+		if heights == nil {
+			return common.ErrNotFound
+		}
+		// The later of duplicated txs overwrote the earlier one, as in Full Node
+		height := heights[len(heights)-8:]
+		txHeight := keyHeight(height)
+
+		detail.Tx = new(model.Tx)
+		err := json.Unmarshal(tx.Bucket(txsBucket).Get(txKey(txHeight, hash)), detail.Tx)
+		if err != nil {
+			return fmt.Errorf("failed to Decode Tx: %v", err)
+		}
+
+		v := tx.Bucket(blocksBucket).Get(height)
+		if v == nil {
+			return fmt.Errorf("not found Block at height '%d'", txHeight)
+		}
+		detail.Block = new(model.Block)
+		err = json.Unmarshal(v, detail.Block)
+		if err != nil {
+			return fmt.Errorf("failed to Decode Block: %v", err)
+		}
+		latestHeight, _ := tx.Bucket(blocksBucket).Cursor().Last()
+		detail.Confirmations = keyHeight(latestHeight) - txHeight + 1
+
+		// The keys of the ins & outs of a tx share a prefix, in order of index
+		prefix := append(txKey(txHeight, hash), 0)
+		c := tx.Bucket(txInsBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			txIn := new(model.TxIn)
+			err = json.Unmarshal(v, txIn)
+			if err != nil {
+				return fmt.Errorf("failed to Decode TxIn: %v", err)
+			}
+			detail.TxIns = append(detail.TxIns, txIn)
+		}
+		c = tx.Bucket(txOutsBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			txOut := new(model.TxOut)
+			err = json.Unmarshal(v, txOut)
+			if err != nil {
+				return fmt.Errorf("failed to Decode TxOut: %v", err)
+			}
+			detail.TxOuts = append(detail.TxOuts, txOut)
+		}
+		return nil
+	})
+	if err == common.ErrNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Tx '%s': %v", hash, err)
+	}
+	return detail, nil
+}
+
+func (m *boltManager) GetAddressHistory(ctx context.Context, query *model.AddressHistoryQuery) (*model.AddressHistory, error) {
+	err := validateHistoryQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var txIns []*model.TxIn
+	var txOuts []*model.TxOut
+	err = m.view(ctx, func(tx *bolt.Tx) error {
+		err := scanAddressPage(tx.Bucket(addrInsBucket), tx.Bucket(txInsBucket), query, cursor, false, func(v []byte) error {
+			txIn := new(model.TxIn)
+			err := json.Unmarshal(v, txIn)
+			if err != nil {
+				return fmt.Errorf("failed to Decode TxIn: %v", err)
+			}
+			txIns = append(txIns, txIn)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return scanAddressPage(tx.Bucket(addrOutsBucket), tx.Bucket(txOutsBucket), query, cursor, true, func(v []byte) error {
+			txOut := new(model.TxOut)
+			err := json.Unmarshal(v, txOut)
+			if err != nil {
+				return fmt.Errorf("failed to Decode TxOut: %v", err)
+			}
+			txOuts = append(txOuts, txOut)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get History of address '%s': %v", query.Address, err)
+	}
+	return mergeHistory(txIns, txOuts, query.Limit, query.Descending), nil
+}
+
+func (m *boltManager) GetUTXOs(ctx context.Context, query *model.UTXOsQuery, coinbaseMaturity int64) (*model.UTXOsPage, error) {
+	cursor, err := validateUTXOsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var latestHeight int64
+	var txOuts []*model.TxOut
+	err = m.view(ctx, func(tx *bolt.Tx) error {
+		k, _ := tx.Bucket(blocksBucket).Cursor().Last()
+		if k == nil {
+			return nil
+		}
+		latestHeight = keyHeight(k)
+
+		// Up to 'Limit' + 1 unspent tx outs of each address from the cursor, then the first ones of all are kept
+		spends := tx.Bucket(spendsBucket)
+		seen := make(map[string]bool, len(query.Addresses))
+		for _, address := range query.Addresses {
+			if seen[address] {
+				continue
+			}
+			seen[address] = true
+
+			n := 0
+			page := &model.AddressHistoryQuery{Address: address, FromHeight: 0, ToHeight: latestHeight, Limit: math.MaxInt32}
+			err := scanAddressPage(tx.Bucket(addrOutsBucket), tx.Bucket(txOutsBucket), page, cursor, true, func(v []byte) error {
+				txOut := new(model.TxOut)
+				err := json.Unmarshal(v, txOut)
+				if err != nil {
+					return fmt.Errorf("failed to Decode TxOut: %v", err)
+				}
+				if spends.Get(outPointKey(txOut.TxHash, txOut.TxIndex)) != nil {
+					return nil
+				}
+				txOuts = append(txOuts, txOut)
+				if n++; n > query.Limit {
+					return errPageFull
+				}
+				return nil
+			})
+			if err != nil && err != errPageFull {
+				return fmt.Errorf("failed to Get TxOuts of address '%s': %v", address, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Unspent TxOuts of number of addresses '%d': %v", len(query.Addresses), err)
+	}
+	sortByPosition(txOuts)
+	return pageUTXOs(txOuts, query.Limit, latestHeight, coinbaseMaturity), nil
+}
+
+func (m *boltManager) GetAddressBalance(ctx context.Context, address string) (*model.AddressBalance, error) {
+	return m.GetAddressBalanceAtHeight(ctx, address, math.MaxInt64)
+}
+
+// GetAddressBalanceAtHeight sums up the outs of the address & their spends by the index, no balance is stored.
+func (m *boltManager) GetAddressBalanceAtHeight(ctx context.Context, address string, height int64) (*model.AddressBalance, error) {
+	var entries []balanceEntry
+	err := m.view(ctx, func(tx *bolt.Tx) error {
+		spends, txIns := tx.Bucket(spendsBucket), tx.Bucket(txInsBucket)
+		return scanAddress(tx.Bucket(addrOutsBucket), tx.Bucket(txOutsBucket), address, 0, height, func(v []byte) error {
+			txOut := new(model.TxOut)
+			err := json.Unmarshal(v, txOut)
+			if err != nil {
+				return fmt.Errorf("failed to Decode TxOut: %v", err)
+			}
+			entries = append(entries, balanceEntry{address: address, height: txOut.Height, txHash: txOut.TxHash, received: txOut.Value})
+
+			k := spends.Get(outPointKey(txOut.TxHash, txOut.TxIndex))
+			if k == nil {
+				return nil
+			}
+			txIn := new(model.TxIn)
+			err = json.Unmarshal(txIns.Get(k), txIn)
+			if err != nil {
+				return fmt.Errorf("failed to Decode TxIn spending '%s:%d': %v", txOut.TxHash, txOut.TxIndex, err)
+			}
+			entries = append(entries, balanceEntry{address: address, height: txIn.Height, txHash: txIn.TxHash, sent: txOut.Value})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Balance of address '%s' until height '%d': %v", address, height, err)
+	}
+	return sumChanges(address, groupChanges(entries), height)
+}
+
+func (m *boltManager) GetReorgs(ctx context.Context, query *model.ReorgQuery) ([]*model.Reorg, error) {
+	err := validateReorgQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var reorgs []*model.Reorg
+	err = m.view(ctx, func(tx *bolt.Tx) error {
+		// Keyed by sequence id, in the order they happened
+		return tx.Bucket(reorgsBucket).ForEach(func(k, v []byte) error {
+			reorg := new(model.Reorg)
+			err := json.Unmarshal(v, reorg)
+			if err != nil {
+				return fmt.Errorf("failed to Decode Reorg: %v", err)
+			}
+			if matchReorg(reorg, query) {
+				reorgs = append(reorgs, reorg)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Reorgs from '%d' to '%d' height: %v", query.FromHeight, query.ToHeight, err)
+	}
+	return reorgs, nil
+}
+
+func (m *boltManager) AddWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error) {
+	var added int64
+	err := m.update(ctx, func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(watchedBucket)
+		for _, a := range addresses {
+			k := watchedKey(list, a)
+			if bucket.Get(k) != nil {
+				continue
+			}
+			err := bucket.Put(k, []byte{})
+			if err != nil {
+				return err
+			}
+			added++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to Add addresses to watch list '%s': %v", list, err)
+	}
+	return added, nil
+}
+
+func (m *boltManager) RemoveWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error) {
+	var removed int64
+	err := m.update(ctx, func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(watchedBucket)
+		for _, a := range addresses {
+			k := watchedKey(list, a)
+			if bucket.Get(k) == nil {
+				continue
+			}
+			err := bucket.Delete(k)
+			if err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to Remove addresses from watch list '%s': %v", list, err)
+	}
+	return removed, nil
+}
+
+func (m *boltManager) GetWatchLists(ctx context.Context) (map[string][]string, error) {
+	lists := make(map[string][]string)
+	err := m.view(ctx, func(tx *bolt.Tx) error {
+		// Keyed by list then address, so in ascending order of both
+		return tx.Bucket(watchedBucket).ForEach(func(k, v []byte) error {
+			i := bytes.IndexByte(k, 0)
+			if i < 0 {
+				return fmt.Errorf("invalid watched address key '%x'", k)
+			}
+			list := string(k[:i])
+			lists[list] = append(lists[list], string(k[i+1:]))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Watch Lists: %v", err)
+	}
+	return lists, nil
+}
+
+// putBlocksData overwrites the data having the same keys, like the upserts of the SQL manager.
+func putBlocksData(tx *bolt.Tx, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	bucket := tx.Bucket(blocksBucket)
+	for _, b := range blocks {
+		err := putJSON(bucket, heightKey(b.Height), b)
+		if err != nil {
+			return fmt.Errorf("failed to Create Block at height '%d': %v", b.Height, err)
+		}
+	}
+
+	bucket = tx.Bucket(txsBucket)
+	hashes := tx.Bucket(txHashesBucket)
+	for _, t := range txs {
+		err := putJSON(bucket, txKey(t.Height, t.Hash), t)
+		if err != nil {
+			return fmt.Errorf("failed to Create Tx '%s': %v", t.Hash, err)
+		}
+		err = hashes.Put([]byte(t.Hash), insertHeightKey(hashes.Get([]byte(t.Hash)), heightKey(t.Height)))
+		if err != nil {
+			return fmt.Errorf("failed to Index Tx '%s': %v", t.Hash, err)
+		}
+	}
+
+	for _, in := range txIns {
+		err := putTxData(insBuckets(tx), txDataKey(in.Height, in.TxHash, in.TxIndex), in.Address, in.TxPosition, in)
+		if err != nil {
+			return fmt.Errorf("failed to Create TxIn '%s:%d': %v", in.TxHash, in.TxIndex, err)
+		}
+		err = tx.Bucket(spendsBucket).Put(outPointKey(in.PreviousTxHash, in.PreviousTxIndex), txDataKey(in.Height, in.TxHash, in.TxIndex))
+		if err != nil {
+			return fmt.Errorf("failed to Index Spend of TxIn '%s:%d': %v", in.TxHash, in.TxIndex, err)
+		}
+	}
+
+	for _, out := range txOuts {
+		err := putTxData(outsBuckets(tx), txDataKey(out.Height, out.TxHash, out.TxIndex), out.Address, out.TxPosition, out)
+		if err != nil {
+			return fmt.Errorf("failed to Create TxOut '%s:%d': %v", out.TxHash, out.TxIndex, err)
+		}
+	}
+	return nil
+}
+
+// txDataBuckets are the buckets of tx ins or outs with their indexes.
+type txDataBuckets struct {
+	data *bolt.Bucket
+	addr *bolt.Bucket
+	// spends indexes the tx ins by the outs they spend, nil for tx outs
+	spends *bolt.Bucket
+}
+
+func insBuckets(tx *bolt.Tx) txDataBuckets {
+	return txDataBuckets{data: tx.Bucket(txInsBucket), addr: tx.Bucket(addrInsBucket), spends: tx.Bucket(spendsBucket)}
+}
+
+func outsBuckets(tx *bolt.Tx) txDataBuckets {
+	return txDataBuckets{data: tx.Bucket(txOutsBucket), addr: tx.Bucket(addrOutsBucket)}
+}
+
+// putTxData puts a tx in or out, replacing the indexes of the overwritten one.
+func putTxData(b txDataBuckets, key []byte, address string, txPosition int32, value interface{}) error {
+	err := deleteTxData(b, key)
+	if err != nil {
+		return err
+	}
+	err = putJSON(b.data, key, value)
+	if err != nil {
+		return err
+	}
+	return b.addr.Put(addressDataKey(address, key, txPosition), []byte{})
+}
+
+func deleteTxData(b txDataBuckets, key []byte) error {
+	v := b.data.Get(key)
+	if v == nil {
+		return nil
+	}
+	var data struct {
+		Address         string
+		PreviousTxHash  string
+		PreviousTxIndex int32
+		TxPosition      int32
+	}
+	err := json.Unmarshal(v, &data)
+	if err != nil {
+		return fmt.Errorf("failed to Decode data: %v", err)
+	}
+	err = b.addr.Delete(addressDataKey(data.Address, key, data.TxPosition))
+	if err != nil {
+		return err
+	}
+	if b.spends != nil {
+		outPoint := outPointKey(data.PreviousTxHash, data.PreviousTxIndex)
+		if bytes.Equal(b.spends.Get(outPoint), key) {
+			err = b.spends.Delete(outPoint)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return b.data.Delete(key)
+}
+
+// deleteHeights deletes all data from height 'from' to 'to', up to the last one if 'to' is negative.
+func deleteHeights(tx *bolt.Tx, from, to int64) error {
+	if to < 0 {
+		to = int64(^uint64(0) >> 1)
+	}
+
+	// Keys are collected before deleting, a bbolt cursor may skip the next key after a delete
+	collect := func(bucket *bolt.Bucket) ([][]byte, error) {
+		var keys [][]byte
+		err := scanHeights(bucket, from, to, func(k, v []byte) error {
+			keys = append(keys, append([]byte(nil), k...))
+			return nil
+		})
+		return keys, err
+	}
+
+	bucket := tx.Bucket(blocksBucket)
+	keys, err := collect(bucket)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		err = bucket.Delete(k)
+		if err != nil {
+			return fmt.Errorf("failed to Delete Block at height '%d': %v", keyHeight(k), err)
+		}
+	}
+
+	bucket = tx.Bucket(txsBucket)
+	hashes := tx.Bucket(txHashesBucket)
+	keys, err = collect(bucket)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		hash := k[8:]
+		heights := removeHeightKey(hashes.Get(hash), k[:8])
+		if len(heights) == 0 {
+			err = hashes.Delete(hash)
+		} else {
+			err = hashes.Put(hash, heights)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to Delete Tx index '%s': %v", hash, err)
+		}
+		err = bucket.Delete(k)
+		if err != nil {
+			return fmt.Errorf("failed to Delete Tx '%s': %v", hash, err)
+		}
+	}
+
+	for _, b := range []txDataBuckets{insBuckets(tx), outsBuckets(tx)} {
+		keys, err = collect(b.data)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			err = deleteTxData(b, k)
+			if err != nil {
+				return fmt.Errorf("failed to Delete Tx Data at height '%d': %v", keyHeight(k), err)
+			}
+		}
+	}
+	return nil
+}
+
+// scanHeights calls fn for the keys of a bucket having height from 'from' to 'to', in ascending order.
+func scanHeights(bucket *bolt.Bucket, from, to int64, fn func(k, v []byte) error) error {
+	c := bucket.Cursor()
+	for k, v := c.Seek(heightKey(from)); k != nil && keyHeight(k) <= to; k, v = c.Next() {
+		err := fn(k, v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanAddress calls fn for the values of the data bucket indexed by the address from height 'from' to 'to'.
+func scanAddress(addrBucket, bucket *bolt.Bucket, address string, from, to int64, fn func(v []byte) error) error {
+	prefix := addressKey(address, nil)
+	c := addrBucket.Cursor()
+	for k, _ := c.Seek(addressKey(address, heightKey(from))); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		key := indexedDataKey(k[len(prefix):])
+		if keyHeight(key) > to {
+			break
+		}
+		v := bucket.Get(key)
+		if v == nil {
+			return fmt.Errorf("not found data of index '%x'", k)
+		}
+		err := fn(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanAddressPage calls fn for up to 'Limit' + 1 values of the data bucket indexed by the address of the query,
+// in the direction of the query from the cursor.
+func scanAddressPage(addrBucket, bucket *bolt.Bucket, query *model.AddressHistoryQuery, cursor *historyPosition, credit bool, fn func(v []byte) error) error {
+	prefix := addressKey(query.Address, nil)
+	c := addrBucket.Cursor()
+	var k []byte
+	next := c.Next
+	if query.Descending {
+		// Just after the keys of the address, as the address is followed by 0x00, unless the heights are bounded
+		end := append([]byte(query.Address), 1)
+		if query.ToHeight < math.MaxInt64 {
+			end = addressKey(query.Address, heightKey(query.ToHeight+1))
+		}
+		if cursor != nil {
+			// Just after the keys of the tx of the cursor, as the tx hash is followed by 0x00
+			end = addressKey(query.Address, append(historyKey(cursor.height, cursor.txPosition, cursor.txHash), 1))
+		}
+		if k, _ = c.Seek(end); k == nil {
+			k, _ = c.Last()
+		} else {
+			k, _ = c.Prev()
+		}
+		next = c.Prev
+	} else {
+		start := heightKey(query.FromHeight)
+		if cursor != nil {
+			start = historyKey(cursor.height, cursor.txPosition, cursor.txHash)
+		}
+		k, _ = c.Seek(addressKey(query.Address, start))
+	}
+
+	for n := 0; k != nil && bytes.HasPrefix(k, prefix) && n <= query.Limit; k, _ = next() {
+		key := k[len(prefix):]
+		p := historyPosition{
+			height:     keyHeight(key),
+			txPosition: int32(binary.BigEndian.Uint32(key[8:12])),
+			txHash:     string(key[12 : len(key)-5]),
+			credit:     credit,
+			txIndex:    int32(binary.BigEndian.Uint32(key[len(key)-4:])),
+		}
+		if (query.Descending && p.height < query.FromHeight) || (!query.Descending && p.height > query.ToHeight) {
+			break
+		}
+		if p.height < query.FromHeight || p.height > query.ToHeight || (cursor != nil && !p.after(*cursor, query.Descending)) {
+			continue
+		}
+
+		v := bucket.Get(indexedDataKey(key))
+		if v == nil {
+			return fmt.Errorf("not found data of index '%x'", k)
+		}
+		err := fn(v)
+		if err != nil {
+			return err
+		}
+		n++
+	}
+	return nil
+}
+
+func putJSON(bucket *bolt.Bucket, key []byte, value interface{}) error {
+	v, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to Encode '%v': %v", value, err)
+	}
+	return bucket.Put(key, v)
+}
+
+func heightKey(height int64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(height))
+	return k
+}
+
+func keyHeight(k []byte) int64 {
+	return int64(binary.BigEndian.Uint64(k[:8]))
+}
+
+// insertHeightKey returns a copy of the height keys in ascending order with one more, as the values of bbolt must not be modified.
+func insertHeightKey(keys, key []byte) []byte {
+	i := 0
+	for ; i < len(keys); i += 8 {
+		switch bytes.Compare(keys[i:i+8], key) {
+		case 0:
+			return append([]byte(nil), keys...)
+		case 1:
+			return append(append(append([]byte(nil), keys[:i]...), key...), keys[i:]...)
+		}
+	}
+	return append(append([]byte(nil), keys...), key...)
+}
+
+// removeHeightKey returns a copy of the height keys in ascending order without one.
+func removeHeightKey(keys, key []byte) []byte {
+	removed := make([]byte, 0, len(keys))
+	for i := 0; i < len(keys); i += 8 {
+		if !bytes.Equal(keys[i:i+8], key) {
+			removed = append(removed, keys[i:i+8]...)
+		}
+	}
+	return removed
+}
+
+func txKey(height int64, hash string) []byte {
+	return append(heightKey(height), hash...)
+}
+
+func txDataKey(height int64, txHash string, txIndex int32) []byte {
+	k := append(txKey(height, txHash), 0)
+	index := make([]byte, 4)
+	binary.BigEndian.PutUint32(index, uint32(txIndex))
+	return append(k, index...)
+}
+
+func outPointKey(txHash string, txIndex int32) []byte {
+	k := make([]byte, 0, len(txHash)+5)
+	k = append(k, txHash...)
+	k = append(k, 0)
+	index := make([]byte, 4)
+	binary.BigEndian.PutUint32(index, uint32(txIndex))
+	return append(k, index...)
+}
+
+func addressKey(address string, key []byte) []byte {
+	k := make([]byte, 0, len(address)+1+len(key))
+	k = append(k, address...)
+	k = append(k, 0)
+	return append(k, key...)
+}
+
+// historyKey is the start of the keys of the address indexes of a tx, after the address.
+func historyKey(height int64, txPosition int32, txHash string) []byte {
+	position := make([]byte, 4)
+	binary.BigEndian.PutUint32(position, uint32(txPosition))
+	return append(append(heightKey(height), position...), txHash...)
+}
+
+// addressDataKey returns the key of the address index of the data key of a tx in or out, the position of the tx
+// following the height so the history of an address is scanned in order of the txs in their blocks.
+func addressDataKey(address string, key []byte, txPosition int32) []byte {
+	position := make([]byte, 4)
+	binary.BigEndian.PutUint32(position, uint32(txPosition))
+	return append(append(addressKey(address, key[:8]), position...), key[8:]...)
+}
+
+// indexedDataKey returns the data key of the key of an address index without the address.
+func indexedDataKey(key []byte) []byte {
+	return append(append(make([]byte, 0, len(key)-4), key[:8]...), key[12:]...)
+}
+
+func watchedKey(list, address string) []byte {
+	return addressKey(list, []byte(address))
+}
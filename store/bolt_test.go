@@ -0,0 +1,131 @@
+package store
+
+import (
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/model"
+	. "github.com/onsi/gomega"
+	bolt "go.etcd.io/bbolt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type boltBackend struct {
+	m *boltManager
+}
+
+func (b *boltBackend) clear() error {
+	err := b.m.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range boltBuckets {
+			err := tx.DeleteBucket(name)
+			if err != nil && err != bolt.ErrBucketNotFound {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return b.m.createBuckets()
+}
+
+func (b *boltBackend) create(value interface{}) error {
+	switch v := value.(type) {
+	case model.Block:
+		return b.m.AddBlocksData(ctx, []*model.Block{&v}, nil, nil, nil)
+	case model.Tx:
+		return b.m.AddBlocksData(ctx, nil, []*model.Tx{&v}, nil, nil)
+	case model.TxIn:
+		return b.m.AddBlocksData(ctx, nil, nil, []*model.TxIn{&v}, nil)
+	case model.TxOut:
+		return b.m.AddBlocksData(ctx, nil, nil, nil, []*model.TxOut{&v})
+	}
+	return fmt.Errorf("unsupported type %T", value)
+}
+
+func (b *boltBackend) count(table string) (int, error) {
+	var count int
+	err := b.m.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(table))
+		if bucket == nil {
+			return fmt.Errorf("not found Bucket '%s'", table)
+		}
+		count = bucket.Stats().KeyN
+		return nil
+	})
+	return count, err
+}
+
+// TestBoltManager runs the tests of Manager against the embedded DB.
+func TestBoltManager(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir, err := ioutil.TempDir("", "bolt")
+	Expect(err).Should(Succeed())
+	defer os.RemoveAll(dir)
+
+	m, err := NewBoltManager(filepath.Join(dir, "indexer.db"))
+	Expect(err).Should(Succeed())
+	defer m.(*boltManager).db.Close()
+
+	runManagerTests(t, m, &boltBackend{m: m.(*boltManager)})
+}
+
+func TestBoltManager_Reorg_Index(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir, err := ioutil.TempDir("", "bolt")
+	Expect(err).Should(Succeed())
+	defer os.RemoveAll(dir)
+
+	m, err := NewBoltManager(filepath.Join(dir, "indexer.db"))
+	Expect(err).Should(Succeed())
+	defer m.(*boltManager).db.Close()
+
+	err = m.AddBlocksData(ctx,
+		[]*model.Block{{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}, {Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("tx13"), CoinBase: &falseValue}, {Height: 14, Hash: hashOf("tx14"), CoinBase: &falseValue}},
+		[]*model.TxIn{{Height: 14, TxHash: hashOf("tx14"), Address: "bob", PreviousTxHash: hashOf("tx13")}},
+		[]*model.TxOut{
+			{Height: 13, TxHash: hashOf("tx13"), Value: 100, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 14, TxHash: hashOf("tx14"), Value: 90, Address: "alice", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+		},
+	)
+	Expect(err).Should(Succeed())
+
+	err = m.Reorg(ctx, &model.Reorg{FromHeight: 14, FromHash: hashOf("14"), ToHeight: 14, ToHash: hashOf("14")})
+	Expect(err).Should(Succeed())
+
+	// The address indexes of the reorganized heights are deleted too
+	for bucket, no := range map[string]int{
+		string(txHashesBucket): 1,
+		string(addrInsBucket):  0,
+		string(addrOutsBucket): 1,
+		string(reorgsBucket):   1,
+	} {
+		count, err := (&boltBackend{m: m.(*boltManager)}).count(bucket)
+		Expect(err).Should(Succeed())
+		Expect(count).Should(Equal(no), bucket)
+	}
+
+	// A tx at another height keeps a row per height, both in the index of its hash
+	err = m.AddBlocksData(ctx, []*model.Block{{Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")}}, []*model.Tx{{Height: 14, Hash: hashOf("tx13"), CoinBase: &falseValue}}, nil, nil)
+	Expect(err).Should(Succeed())
+	stats, err := m.GetBlockStats(ctx, 13, 14)
+	Expect(err).Should(Succeed())
+	Expect(stats[13].TxNo).Should(Equal(int64(1)))
+	Expect(stats[14].TxNo).Should(Equal(int64(1)))
+	err = m.(*boltManager).db.View(func(tx *bolt.Tx) error {
+		Expect(tx.Bucket(txHashesBucket).Get([]byte(hashOf("tx13")))).Should(Equal(append(heightKey(13), heightKey(14)...)))
+		return nil
+	})
+	Expect(err).Should(Succeed())
+
+	err = m.Reorg(ctx, &model.Reorg{FromHeight: 14, FromHash: hashOf("14"), ToHeight: 14, ToHash: hashOf("14")})
+	Expect(err).Should(Succeed())
+	detail, err := m.GetTx(ctx, hashOf("tx13"))
+	Expect(err).Should(Succeed())
+	Expect(detail.Tx.Height).Should(Equal(int64(13)))
+}
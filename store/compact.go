@@ -0,0 +1,404 @@
+package store
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"github.com/jinzhu/gorm"
+	"strings"
+)
+
+// The SQL tables store the hashes as 32 bytes instead of 64 hex chars, and the addresses of the ins & outs as ids of
+// the dictionary table 'addresses', so the rows of 'tx_ins' & 'tx_outs' are less than half as big.
+// The rows below are converted from & to the models, the callers of Manager are unaware of the layout.
+
+const (
+	addressesTable = "addresses"
+	hashSize       = 32
+)
+
+var (
+	txInColumns  = []string{"height", "tx_hash", "tx_index", "address_id", "previous_tx_hash", "previous_tx_index", "tx_position"}
+	txOutColumns = []string{"height", "tx_hash", "tx_index", "value", "address_id", "script_pub_key", "coin_base", "tx_position"}
+)
+
+// hashColumns are the columns of hashes per table
+var hashColumns = []struct {
+	table   string
+	columns []string
+}{
+	{table: "blocks", columns: []string{"hash", "previous_hash"}},
+	{table: "txes", columns: []string{"hash"}},
+	{table: "tx_ins", columns: []string{"tx_hash", "previous_tx_hash"}},
+	{table: "tx_outs", columns: []string{"tx_hash"}},
+}
+
+// validHash tells if the hash is 64 lower case hex chars, the only form the SQL tables store as bytes & read back.
+// The hashes are validated before the writes & lookups, so the columns always hold exactly 32 bytes.
+func validHash(hash string) bool {
+	if len(hash) != 2*hashSize {
+		return false
+	}
+	b, err := hex.DecodeString(hash)
+	return err == nil && hex.EncodeToString(b) == hash
+}
+
+// validateBlocksData returns common.ErrInvalidHash if any hash of the data isn't valid. The genesis block has no
+// previous hash.
+func validateBlocksData(blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	var hashes []string
+	for _, b := range blocks {
+		hashes = append(hashes, b.Hash)
+		if b.Height != 0 || b.PreviousHash != "" {
+			hashes = append(hashes, b.PreviousHash)
+		}
+	}
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash)
+	}
+	for _, in := range txIns {
+		hashes = append(hashes, in.TxHash, in.PreviousTxHash)
+	}
+	for _, out := range txOuts {
+		hashes = append(hashes, out.TxHash)
+	}
+	for _, h := range hashes {
+		if !validHash(h) {
+			return common.ErrInvalidHash
+		}
+	}
+	return nil
+}
+
+// hashBytes returns the 32 bytes of a valid hash, in the same order so the rows sort the same way, no bytes for the
+// missing previous hash of the genesis block, nil otherwise.
+func hashBytes(hash string) []byte {
+	if hash == "" {
+		return []byte{}
+	}
+	if !validHash(hash) {
+		return nil
+	}
+	b, _ := hex.DecodeString(hash)
+	return b
+}
+
+func hashString(b []byte) string {
+	return hex.EncodeToString(b)
+}
+
+func hashesBytes(hashes []string) [][]byte {
+	result := make([][]byte, 0, len(hashes))
+	for _, h := range hashes {
+		result = append(result, hashBytes(h))
+	}
+	return result
+}
+
+type blockRow struct {
+	Height       int64
+	Hash         []byte
+	PreviousHash []byte
+}
+
+type txRow struct {
+	Height   int64
+	Hash     []byte
+	CoinBase *bool
+}
+
+// txInRow & txOutRow have the address joined from the dictionary
+type txInRow struct {
+	Height          int64
+	TxHash          []byte
+	TxIndex         int32
+	Address         string
+	PreviousTxHash  []byte
+	PreviousTxIndex int32
+	TxPosition      int32
+}
+
+type txOutRow struct {
+	Height       int64
+	TxHash       []byte
+	TxIndex      int32
+	Value        int64
+	Address      string
+	ScriptPubKey []byte
+	CoinBase     *bool
+	TxPosition   int32
+}
+
+// findBlocks returns the blocks matching the conditions of db
+func findBlocks(db *gorm.DB) ([]*model.Block, error) {
+	var rows []*blockRow
+	err := db.Table(model.Block{}.TableName()).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]*model.Block, 0, len(rows))
+	for _, r := range rows {
+		blocks = append(blocks, &model.Block{Height: r.Height, Hash: hashString(r.Hash), PreviousHash: hashString(r.PreviousHash)})
+	}
+	return blocks, nil
+}
+
+// firstBlock returns the first block matching the conditions of db, common.ErrNotFound if none.
+func firstBlock(db *gorm.DB) (*model.Block, error) {
+	blocks, err := findBlocks(db.Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, common.ErrNotFound
+	}
+	return blocks[0], nil
+}
+
+func findTxs(db *gorm.DB) ([]*model.Tx, error) {
+	var rows []*txRow
+	err := db.Table(model.Tx{}.TableName()).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*model.Tx, 0, len(rows))
+	for _, r := range rows {
+		txs = append(txs, &model.Tx{Height: r.Height, Hash: hashString(r.Hash), CoinBase: r.CoinBase})
+	}
+	return txs, nil
+}
+
+// findTxIns returns the ins matching the conditions of db, which may be on column 'address' of the dictionary.
+func findTxIns(db *gorm.DB) ([]*model.TxIn, error) {
+	var rows []*txInRow
+	err := db.Table(model.TxIn{}.TableName()).
+		Select("tx_ins.height, tx_ins.tx_hash, tx_ins.tx_index, addresses.address, tx_ins.previous_tx_hash, tx_ins.previous_tx_index, tx_ins.tx_position").
+		Joins("JOIN addresses ON addresses.id = tx_ins.address_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	txIns := make([]*model.TxIn, 0, len(rows))
+	for _, r := range rows {
+		txIns = append(txIns, &model.TxIn{
+			Height:          r.Height,
+			TxHash:          hashString(r.TxHash),
+			TxIndex:         r.TxIndex,
+			Address:         r.Address,
+			PreviousTxHash:  hashString(r.PreviousTxHash),
+			PreviousTxIndex: r.PreviousTxIndex,
+			TxPosition:      r.TxPosition,
+		})
+	}
+	return txIns, nil
+}
+
+// findTxOuts returns the outs matching the conditions of db, which may be on column 'address' of the dictionary.
+func findTxOuts(db *gorm.DB) ([]*model.TxOut, error) {
+	var rows []*txOutRow
+	err := db.Table(model.TxOut{}.TableName()).
+		Select("tx_outs.height, tx_outs.tx_hash, tx_outs.tx_index, tx_outs.value, addresses.address, tx_outs.script_pub_key, tx_outs.coin_base, tx_outs.tx_position").
+		Joins("JOIN addresses ON addresses.id = tx_outs.address_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	txOuts := make([]*model.TxOut, 0, len(rows))
+	for _, r := range rows {
+		txOuts = append(txOuts, &model.TxOut{
+			Height:       r.Height,
+			TxHash:       hashString(r.TxHash),
+			TxIndex:      r.TxIndex,
+			Value:        r.Value,
+			Address:      r.Address,
+			ScriptPubKey: r.ScriptPubKey,
+			CoinBase:     r.CoinBase,
+			TxPosition:   r.TxPosition,
+		})
+	}
+	return txOuts, nil
+}
+
+// addressIDs returns the ids of the addresses, adding the new ones to the dictionary. The addresses of deleted
+// ins & outs are kept, their ids are reused if they are seen again.
+func (txm *txManager) addressIDs(addresses []string) (map[string]int64, error) {
+	distinct := distinctAddresses(addresses)
+	ids := make(map[string]int64, len(distinct))
+	for start := 0; start < len(distinct); start += balanceChunkSize {
+		end := start + balanceChunkSize
+		if end > len(distinct) {
+			end = len(distinct)
+		}
+		chunk := distinct[start:end]
+
+		values := make([]interface{}, 0, len(chunk))
+		for _, a := range chunk {
+			values = append(values, a)
+		}
+		err := txm.execSql(fmt.Sprintf("INSERT INTO %s (address)", addressesTable), "ON CONFLICT (address) DO NOTHING", values, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to Create Addresses: %v", err)
+		}
+
+		var rows []struct {
+			ID      int64
+			Address string
+		}
+		err = txm.db.Table(addressesTable).Select("id, address").Where("address IN (?)", chunk).Find(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to Get Address ids: %v", err)
+		}
+		for _, r := range rows {
+			ids[r.Address] = r.ID
+		}
+	}
+	return ids, nil
+}
+
+// compactUp moves the addresses of the ins & outs to the dictionary, then converts the hashes to bytes.
+// All the rows of the big tables are rewritten in the transaction of the migration, holding exclusive locks on them
+// until it commits: the services must be stopped for the whole run, hours on a fully indexed chain, and the DB needs
+// free space for a copy of the tables. The migration is thus only applied to an indexed DB when allowed, see
+// Migrator.AllowRewrites. A DB may be indexed again from scratch instead, at the latest schema version.
+func compactUp(db *gorm.DB) error {
+	err := execAll(db,
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id %s, address varchar(62) NOT NULL)", addressesTable, serialPrimaryKeyType(db)),
+		fmt.Sprintf("INSERT INTO %s (address) SELECT address FROM tx_outs UNION SELECT address FROM tx_ins", addressesTable),
+		fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS idx_addresses_address ON %s (address)", addressesTable),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range partitionedTables {
+		err = execAll(db,
+			fmt.Sprintf("DROP INDEX IF EXISTS idx_%s_address_height", t.name),
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN address_id bigint NOT NULL DEFAULT 0", t.name),
+			fmt.Sprintf("UPDATE %s SET address_id = (SELECT id FROM %s WHERE %s.address = %s.address)", t.name, addressesTable, addressesTable, t.name),
+			fmt.Sprintf("ALTER TABLE %s DROP COLUMN address", t.name),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The tables are rewritten by the conversion, after the update of the addresses to leave no dead rows
+	// A hash not valid fails the migration, the columns hold exactly 32 bytes after it but the previous hash of genesis
+	err = convertHashes(db, "bytea", "decode(%s, 'hex')", func(v []byte) (interface{}, error) {
+		if len(v) > 0 && !validHash(string(v)) {
+			return nil, fmt.Errorf("invalid hash '%s'", v)
+		}
+		return hashBytes(string(v)), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, t := range partitionedTables {
+		err = execAll(db, fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_address_id_height ON %s (address_id, height, tx_hash, tx_index)", t.name, t.name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compactDown(db *gorm.DB) error {
+	for _, t := range partitionedTables {
+		err := execAll(db,
+			fmt.Sprintf("DROP INDEX IF EXISTS idx_%s_address_id_height", t.name),
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN address varchar(62) NOT NULL DEFAULT ''", t.name),
+			fmt.Sprintf("UPDATE %s SET address = (SELECT address FROM %s WHERE %s.id = %s.address_id)", t.name, addressesTable, addressesTable, t.name),
+			fmt.Sprintf("ALTER TABLE %s DROP COLUMN address_id", t.name),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := convertHashes(db, "varchar(64)", "encode(%s, 'hex')", func(v []byte) (interface{}, error) {
+		return hashString(v), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, t := range partitionedTables {
+		err = execAll(db, fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_address_height ON %s (address, height, tx_hash, tx_index)", t.name, t.name))
+		if err != nil {
+			return err
+		}
+	}
+	return execAll(db, fmt.Sprintf("DROP TABLE IF EXISTS %s", addressesTable))
+}
+
+// convertHashes changes the type of the hash columns on Postgres, 'using' being the conversion of a column with
+// its name for every '%s'. SQLite keeps the declared types, as its columns take values of any type, so the rows
+// are converted one by one by 'convert' instead.
+func convertHashes(db *gorm.DB, typ, using string, convert func(v []byte) (interface{}, error)) error {
+	for _, h := range hashColumns {
+		if db.Dialect().GetName() == "postgres" {
+			alters := make([]string, 0, len(h.columns))
+			for _, c := range h.columns {
+				alters = append(alters, fmt.Sprintf("ALTER COLUMN %s TYPE %s USING %s", c, typ, strings.Replace(using, "%s", c, -1)))
+			}
+			err := execAll(db, fmt.Sprintf("ALTER TABLE %s %s", h.table, strings.Join(alters, ", ")))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := convertRows(db, h.table, h.columns, convert)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertRows(db *gorm.DB, table string, columns []string, convert func(v []byte) (interface{}, error)) error {
+	rows, err := db.Raw(fmt.Sprintf("SELECT rowid, %s FROM %s", strings.Join(columns, ","), table)).Rows()
+	if err != nil {
+		return fmt.Errorf("failed to Get rows of '%s': %v", table, err)
+	}
+	var updates [][]interface{}
+	for rows.Next() {
+		var rowID int64
+		values := make([][]byte, len(columns))
+		dest := []interface{}{&rowID}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		err = rows.Scan(dest...)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to Scan row of '%s': %v", table, err)
+		}
+		update := make([]interface{}, 0, len(columns)+1)
+		for _, v := range values {
+			converted, err := convert(v)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to Convert row of '%s': %v", table, err)
+			}
+			update = append(update, converted)
+		}
+		updates = append(updates, append(update, rowID))
+	}
+	rows.Close()
+
+	sets := make([]string, 0, len(columns))
+	for _, c := range columns {
+		sets = append(sets, c+" = (?)")
+	}
+	sql := fmt.Sprintf("UPDATE %s SET %s WHERE rowid = (?)", table, strings.Join(sets, ", "))
+	for _, update := range updates {
+		err = db.Exec(sql, update...).Error
+		if err != nil {
+			return fmt.Errorf("failed to Convert row of '%s': %v", table, err)
+		}
+	}
+	return nil
+}
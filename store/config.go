@@ -1,26 +1,79 @@
 package store
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
+const (
+	defaultReplicaCheckTimeInSec = 1
+	replicaHostSeparator         = ","
+	defaultSSLMode               = "disable"
+)
+
+// sslModes are the TLS modes supported by the Postgres driver.
+var sslModes = map[string]bool{
+	"disable":     true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
 type Config struct {
 	User     string // Username
 	Password string // Password
-	Host     string // Network address
-	Port     int
-	DBName   string // Database name
+	// PasswordFile is the path of a file holding the password, read instead of giving Password by env
+	PasswordFile string
+	Host         string // Network address
+	Port         int
+	DBName       string // Database name
+	// SSLMode is the TLS mode of the connections: disable, require, verify-ca or verify-full, disable by default
+	SSLMode string
+	// SSLRootCert is the path of the CA bundle verifying the certificate of the server
+	SSLRootCert string
+	// SSLCert & SSLKey are the paths of the client certificate & its key, if the server asks for one
+	SSLCert string
+	SSLKey  string
+	// ApplicationName identifies the connections of the indexer on the server
+	ApplicationName string
+	// StatementTimeoutInMs aborts the statements running longer, zero means no timeout
+	StatementTimeoutInMs int
+	// Pool of connections of each DB, zero means the default one of database/sql
+	MaxOpenConns         int
+	MaxIdleConns         int
+	ConnMaxLifetimeInSec int
+	// BoltFile is the path of an embedded bbolt DB used instead of Postgres, for small deployments
+	BoltFile string
+	// ReplicaHost is the address of a read-only Postgres replica, or a comma separated list of them, as 'host' or 'host:port'
+	// sharing the credentials & DB name of the primary. The queries & sync streams read from them.
+	ReplicaHost string
+	// ReplicaMaxLag is the number of blocks a replica may be behind the primary to serve reads, zero means none.
+	ReplicaMaxLag int64
+	// ReplicaCheckTimeInSec is how often the latest blocks of the replicas & primary are checked, zero means the default one.
+	ReplicaCheckTimeInSec int
 }
 
 func (c *Config) Validate() error {
+	if len(c.BoltFile) > 0 {
+		if len(c.ReplicaHost) > 0 {
+			return errors.New("DB ReplicaHost not supported with BoltFile")
+		}
+		return nil
+	}
+
 	var errContents []string
 	if len(c.User) == 0 {
 		errContents = append(errContents, "DB User required")
 	}
 	if len(c.Password) == 0 {
-		errContents = append(errContents, "DB Password required")
+		errContents = append(errContents, "DB Password or PasswordFile required")
 	}
 	if len(c.Host) == 0 {
 		errContents = append(errContents, "DB Host required")
@@ -31,6 +84,29 @@ func (c *Config) Validate() error {
 	if len(c.DBName) == 0 {
 		errContents = append(errContents, "DB Name required")
 	}
+	if len(c.SSLMode) > 0 && !sslModes[c.SSLMode] {
+		errContents = append(errContents, fmt.Sprintf("DB SSLMode '%s' not supported", c.SSLMode))
+	}
+	if (len(c.SSLCert) > 0) != (len(c.SSLKey) > 0) {
+		errContents = append(errContents, "DB SSLCert & SSLKey required together")
+	}
+	if c.StatementTimeoutInMs < 0 {
+		errContents = append(errContents, "DB StatementTimeoutInMs must not be negative")
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 || c.ConnMaxLifetimeInSec < 0 {
+		errContents = append(errContents, "DB MaxOpenConns, MaxIdleConns & ConnMaxLifetimeInSec must not be negative")
+	}
+	for _, h := range c.replicaHosts() {
+		if _, _, err := c.replicaAddress(h); err != nil {
+			errContents = append(errContents, fmt.Sprintf("invalid DB ReplicaHost '%s': %v", h, err))
+		}
+	}
+	if c.ReplicaMaxLag < 0 {
+		errContents = append(errContents, "DB ReplicaMaxLag must not be negative")
+	}
+	if c.ReplicaCheckTimeInSec < 0 {
+		errContents = append(errContents, "DB ReplicaCheckTimeInSec must not be negative")
+	}
 	if len(errContents) > 0 {
 		return errors.New(strings.Join(errContents, ", "))
 	}
@@ -38,8 +114,163 @@ func (c *Config) Validate() error {
 }
 
 // DSN returns a connection string compatible with POSTGRES server
-// A DSN in its fullest form: host=localhost port=5432 user=postgres dbname=postgres password=123@123a sslmode=disable
+// A DSN in its fullest form: host=localhost port=5432 user=postgres dbname=postgres password=123@123a sslmode=verify-full
+// sslrootcert=ca.pem sslcert=client.pem sslkey=client.key application_name=btc-indexer statement_timeout=30000
 // See: https://github.com/go-sql-driver/mysql#dsn-data-source-name
 func (c *Config) DSN() string {
-	return fmt.Sprintf("host=%s port=%d user=%s dbname=%s password=%s sslmode=disable", c.Host, c.Port, c.User, c.DBName, c.Password)
+	return c.dsn(c.Host, c.Port)
+}
+
+// ReplicaDSNs returns the connection strings of the replicas, none if not configured.
+func (c *Config) ReplicaDSNs() []string {
+	var dsns []string
+	for _, h := range c.replicaHosts() {
+		host, port, err := c.replicaAddress(h)
+		if err != nil {
+			continue
+		}
+		dsns = append(dsns, c.dsn(host, port))
+	}
+	return dsns
+}
+
+func (c *Config) ReplicaCheckTime() time.Duration {
+	if c.ReplicaCheckTimeInSec == 0 {
+		return time.Second * defaultReplicaCheckTimeInSec
+	}
+	return time.Second * time.Duration(c.ReplicaCheckTimeInSec)
+}
+
+// LoadPasswordFile reads the password from the password file, if any.
+func (c *Config) LoadPasswordFile() error {
+	if len(c.PasswordFile) == 0 {
+		return nil
+	}
+	b, err := ioutil.ReadFile(c.PasswordFile)
+	if err != nil {
+		return fmt.Errorf("failed to Read DB PasswordFile '%s': %v", c.PasswordFile, err)
+	}
+	c.Password = strings.TrimRight(string(b), "\r\n")
+	return nil
+}
+
+// ConfigurePool applies the settings of the pool to the connections of a DB.
+func (c *Config) ConfigurePool(db *sql.DB) {
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetimeInSec > 0 {
+		db.SetConnMaxLifetime(time.Second * time.Duration(c.ConnMaxLifetimeInSec))
+	}
+}
+
+func (c *Config) dsn(host string, port int) string {
+	sslMode := c.SSLMode
+	if len(sslMode) == 0 {
+		sslMode = defaultSSLMode
+	}
+	parts := []string{
+		"host=" + dsnValue(host),
+		fmt.Sprintf("port=%d", port),
+		"user=" + dsnValue(c.User),
+		"dbname=" + dsnValue(c.DBName),
+		"password=" + dsnValue(c.Password),
+		"sslmode=" + sslMode,
+	}
+	for _, p := range []struct {
+		key, value string
+	}{
+		{"sslrootcert", c.SSLRootCert},
+		{"sslcert", c.SSLCert},
+		{"sslkey", c.SSLKey},
+		{"application_name", c.ApplicationName},
+	} {
+		if len(p.value) > 0 {
+			parts = append(parts, p.key+"="+dsnValue(p.value))
+		}
+	}
+	if c.StatementTimeoutInMs > 0 {
+		// Unknown keys are sent by the driver as run-time parameters of the connections
+		parts = append(parts, fmt.Sprintf("statement_timeout=%d", c.StatementTimeoutInMs))
+	}
+	return strings.Join(parts, " ")
+}
+
+// dsnValue quotes a value of a connection string if it's empty or has spaces or quotes.
+func dsnValue(value string) string {
+	if len(value) > 0 && !strings.ContainsAny(value, " \t\n'\\") {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// dsnTarget returns the host, port & dbname of a connection string, so that errors & logs don't show its password.
+func dsnTarget(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && len(u.Scheme) > 0 {
+		return fmt.Sprintf("host=%s dbname=%s", u.Host, strings.TrimPrefix(u.Path, "/"))
+	}
+
+	var parts []string
+	for len(dsn) > 0 {
+		dsn = strings.TrimLeft(dsn, " \t\n")
+		i := strings.IndexByte(dsn, '=')
+		if i < 0 {
+			break
+		}
+		key := strings.TrimSpace(dsn[:i])
+		dsn = strings.TrimLeft(dsn[i+1:], " \t\n")
+
+		// A quoted value ends at the first quote not escaped
+		end := strings.IndexAny(dsn, " \t\n")
+		if strings.HasPrefix(dsn, "'") {
+			end = -1
+			for j := 1; j < len(dsn); j++ {
+				if dsn[j] == '\\' {
+					j++
+				} else if dsn[j] == '\'' {
+					end = j + 1
+					break
+				}
+			}
+		}
+		if end < 0 {
+			end = len(dsn)
+		}
+		switch key {
+		case "host", "port", "dbname":
+			parts = append(parts, key+"="+dsn[:end])
+		}
+		dsn = dsn[end:]
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *Config) replicaHosts() []string {
+	var hosts []string
+	for _, h := range strings.Split(c.ReplicaHost, replicaHostSeparator) {
+		h = strings.TrimSpace(h)
+		if len(h) > 0 {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// replicaAddress splits the address of a replica, the port of the primary is the default one.
+func (c *Config) replicaAddress(address string) (string, int, error) {
+	if !strings.Contains(address, ":") {
+		return address, c.Port, nil
+	}
+	host, p, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port '%s'", p)
+	}
+	return host, port, nil
 }
@@ -0,0 +1,71 @@
+package store
+
+import (
+	. "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_DSN(t *testing.T) {
+	RegisterTestingT(t)
+
+	cfg := Config{
+		User:                 "user",
+		Password:             "pass word's",
+		Host:                 "primary",
+		Port:                 5432,
+		DBName:               "db",
+		SSLMode:              "verify-full",
+		SSLRootCert:          "/certs/ca.pem",
+		SSLCert:              "/certs/client.pem",
+		SSLKey:               "/certs/client.key",
+		ApplicationName:      "btc-indexer",
+		StatementTimeoutInMs: 30000,
+	}
+	Expect(cfg.Validate()).Should(Succeed())
+	Expect(cfg.DSN()).Should(Equal(`host=primary port=5432 user=user dbname=db password='pass word\'s' sslmode=verify-full ` +
+		`sslrootcert=/certs/ca.pem sslcert=/certs/client.pem sslkey=/certs/client.key application_name=btc-indexer statement_timeout=30000`))
+	Expect(dsnTarget(cfg.DSN())).Should(Equal("host=primary port=5432 dbname=db"))
+	Expect(dsnTarget(`password='x\' host=y' host=h dbname='my db'`)).Should(Equal("host=h dbname='my db'"))
+	Expect(dsnTarget("postgres://user:pass@h:5432/db?sslmode=disable")).Should(Equal("host=h:5432 dbname=db"))
+
+	cfg.SSLMode = "prefer"
+	cfg.SSLKey = ""
+	Expect(cfg.Validate()).ShouldNot(Succeed())
+}
+
+func TestConfig_LoadPasswordFile(t *testing.T) {
+	RegisterTestingT(t)
+
+	dir, err := ioutil.TempDir("", "config")
+	Expect(err).Should(Succeed())
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "password")
+	err = ioutil.WriteFile(file, []byte("secret\n"), 0600)
+	Expect(err).Should(Succeed())
+
+	cfg := Config{User: "user", Host: "primary", Port: 5432, DBName: "db", PasswordFile: file}
+	Expect(cfg.Validate()).ShouldNot(Succeed())
+	Expect(cfg.LoadPasswordFile()).Should(Succeed())
+	Expect(cfg.Password).Should(Equal("secret"))
+	Expect(cfg.Validate()).Should(Succeed())
+
+	cfg.PasswordFile = filepath.Join(dir, "missing")
+	Expect(cfg.LoadPasswordFile()).ShouldNot(Succeed())
+}
+
+func TestConfig_ReplicaDSNs(t *testing.T) {
+	RegisterTestingT(t)
+
+	cfg := Config{User: "user", Password: "password", Host: "primary", Port: 5432, DBName: "db", ReplicaHost: "replica1, replica2:5433"}
+	Expect(cfg.Validate()).Should(Succeed())
+	Expect(cfg.ReplicaDSNs()).Should(Equal([]string{
+		"host=replica1 port=5432 user=user dbname=db password=password sslmode=disable",
+		"host=replica2 port=5433 user=user dbname=db password=password sslmode=disable",
+	}))
+
+	cfg.ReplicaHost = "replica:port"
+	Expect(cfg.Validate()).ShouldNot(Succeed())
+}
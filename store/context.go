@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jinzhu/gorm"
+)
+
+// ctxDB runs the statements of gorm with a context, which gorm doesn't take, so that they are canceled with it.
+type ctxDB struct {
+	ctx context.Context
+	db  *sql.DB
+}
+
+func (d *ctxDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return d.db.ExecContext(d.ctx, query, args...)
+}
+
+func (d *ctxDB) Prepare(query string) (*sql.Stmt, error) {
+	return d.db.PrepareContext(d.ctx, query)
+}
+
+func (d *ctxDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.db.QueryContext(d.ctx, query, args...)
+}
+
+func (d *ctxDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.db.QueryRowContext(d.ctx, query, args...)
+}
+
+// ctxTx is a DB transaction begun with a context, which rolls it back once done.
+type ctxTx struct {
+	ctx context.Context
+	tx  *sql.Tx
+}
+
+func (t *ctxTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return t.tx.ExecContext(t.ctx, query, args...)
+}
+
+func (t *ctxTx) Prepare(query string) (*sql.Stmt, error) {
+	return t.tx.PrepareContext(t.ctx, query)
+}
+
+func (t *ctxTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return t.tx.QueryContext(t.ctx, query, args...)
+}
+
+func (t *ctxTx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return t.tx.QueryRowContext(t.ctx, query, args...)
+}
+
+func (t *ctxTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *ctxTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// withContext returns the DB running its statements with the context. The handle of gorm is opened over the pool
+// of connections of the manager, which neither connects nor pings, as gorm has no other way to set a context.
+func (m *manager) withContext(ctx context.Context) (*gorm.DB, error) {
+	db, err := gorm.Open(m.db.Dialect().GetName(), &ctxDB{ctx: ctx, db: m.db.DB()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to Open DB with context: %v", err)
+	}
+	return db, nil
+}
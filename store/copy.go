@@ -0,0 +1,53 @@
+package store
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// copyIn writes the rows with COPY FROM STDIN, much cheaper than INSERT statements for big batches.
+// COPY can't handle conflicts, so the rows are copied into a temporary table first,
+// then moved to the table with the conflict clause in the same DB transaction.
+func (txm *txManager) copyIn(table string, columns []string, onConflict string, values []interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	stagingTable := "staging_" + table
+	err := txm.db.Exec(fmt.Sprintf("CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP", stagingTable, table)).Error
+	if err != nil {
+		return fmt.Errorf("failed to Create Staging Table '%s': %v", stagingTable, err)
+	}
+
+	stmt, err := txm.db.CommonDB().Prepare(pq.CopyIn(stagingTable, columns...))
+	if err != nil {
+		return fmt.Errorf("failed to Prepare Copy In '%s': %v", stagingTable, err)
+	}
+	defer stmt.Close()
+
+	columnNo := len(columns)
+	for i := 0; i < len(values); i += columnNo {
+		_, err = stmt.Exec(values[i : i+columnNo]...)
+		if err != nil {
+			return fmt.Errorf("failed to Copy In '%s', row '%d': %v", stagingTable, i/columnNo, err)
+		}
+	}
+	// Flush the buffered rows
+	_, err = stmt.Exec()
+	if err != nil {
+		return fmt.Errorf("failed to Flush Copy In '%s': %v", stagingTable, err)
+	}
+
+	err = txm.db.Exec(fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s %s",
+		table, strings.Join(columns, ","), strings.Join(columns, ","), stagingTable, onConflict)).Error
+	if err != nil {
+		return fmt.Errorf("failed to Move rows from '%s' to '%s': %v", stagingTable, table, err)
+	}
+
+	err = txm.db.Exec(fmt.Sprintf("DROP TABLE %s", stagingTable)).Error
+	if err != nil {
+		return fmt.Errorf("failed to Drop Staging Table '%s': %v", stagingTable, err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+	"strings"
+)
+
+type uniqueKey struct {
+	table   string
+	index   string
+	columns []string
+}
+
+// uniqueKeys are the unique indexes added by migration 'add_unique_keys', which can't be created while duplicates exist.
+// The txs are keyed by height too, so the duplicated coinbases of different blocks before BIP30 are all kept.
+var uniqueKeys = []uniqueKey{
+	{table: "blocks", index: "idx_blocks_height", columns: []string{"height"}},
+	{table: "txes", index: "idx_txes_height_hash", columns: []string{"height", "hash"}},
+	{table: "tx_ins", index: "idx_tx_ins_height_tx_hash_tx_index", columns: []string{"height", "tx_hash", "tx_index"}},
+	{table: "tx_outs", index: "idx_tx_outs_height_tx_hash_tx_index", columns: []string{"height", "tx_hash", "tx_index"}},
+}
+
+// dedupe deletes the duplicated rows written before the unique indexes existed, keeping a single row per key.
+// Duplicates come from retried batches, so they mostly have the same values.
+// Tables already having their unique index are skipped.
+func dedupe(db *gorm.DB, keys ...uniqueKey) error {
+	// Physical row identifiers to tell apart the rows having the same key
+	rowID := "rowid"
+	if db.Dialect().GetName() == "postgres" {
+		rowID = "ctid"
+	}
+
+	for _, k := range keys {
+		if !db.HasTable(k.table) || db.Dialect().HasIndex(k.table, k.index) {
+			continue
+		}
+
+		conditions := make([]string, 0, len(k.columns))
+		for _, c := range k.columns {
+			conditions = append(conditions, fmt.Sprintf("a.%s = b.%s", c, c))
+		}
+		sql := fmt.Sprintf("DELETE FROM %s WHERE %s IN (SELECT a.%s FROM %s a JOIN %s b ON %s AND a.%s < b.%s)",
+			k.table, rowID, rowID, k.table, k.table, strings.Join(conditions, " AND "), rowID, rowID)
+		result := db.Exec(sql)
+		if result.Error != nil {
+			return fmt.Errorf("failed to Delete duplicated rows of '%s': %v", k.table, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.L().Warn("Deleted duplicated rows", zap.String("Table", k.table), zap.Int64("Rows", result.RowsAffected))
+		}
+	}
+	return nil
+}
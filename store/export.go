@@ -0,0 +1,66 @@
+package store
+
+import (
+	"github.com/darkknightbk52/btc-indexer/model"
+	"sort"
+)
+
+// filterAddresses keeps the ins & outs of the addresses with their txs, all of them if no address is given.
+func filterAddresses(addresses []string, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) ([]*model.Tx, []*model.TxIn, []*model.TxOut) {
+	if len(addresses) == 0 {
+		return txs, txIns, txOuts
+	}
+	interested := make(map[string]bool, len(addresses))
+	for _, a := range addresses {
+		interested[a] = true
+	}
+
+	hashes := make(map[string]bool)
+	var ins []*model.TxIn
+	for _, in := range txIns {
+		if interested[in.Address] {
+			ins = append(ins, in)
+			hashes[in.TxHash] = true
+		}
+	}
+	var outs []*model.TxOut
+	for _, out := range txOuts {
+		if interested[out.Address] {
+			outs = append(outs, out)
+			hashes[out.TxHash] = true
+		}
+	}
+	var result []*model.Tx
+	for _, tx := range txs {
+		if hashes[tx.Hash] {
+			result = append(result, tx)
+		}
+	}
+	return result, ins, outs
+}
+
+// sortBlocksData sorts the data in order of height, then of hash, or tx hash & tx index.
+func sortBlocksData(blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) {
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Height != blocks[j].Height {
+			return blocks[i].Height < blocks[j].Height
+		}
+		return blocks[i].Hash < blocks[j].Hash
+	})
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].Height != txs[j].Height {
+			return txs[i].Height < txs[j].Height
+		}
+		return txs[i].Hash < txs[j].Hash
+	})
+	sort.Slice(txIns, func(i, j int) bool {
+		if txIns[i].Height != txIns[j].Height {
+			return txIns[i].Height < txIns[j].Height
+		}
+		if txIns[i].TxHash != txIns[j].TxHash {
+			return txIns[i].TxHash < txIns[j].TxHash
+		}
+		return txIns[i].TxIndex < txIns[j].TxIndex
+	})
+	sortTxOuts(txOuts)
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// historyPosition is the position of a tx in the history of an address: height, position of the tx in the block,
+// ins before outs, then tx index. The tx hash tells apart the txs indexed before their positions were.
+type historyPosition struct {
+	height     int64
+	txPosition int32
+	txHash     string
+	credit     bool
+	txIndex    int32
+}
+
+func positionOf(tx *model.AddressTx) historyPosition {
+	if tx.TxIn != nil {
+		return historyPosition{height: tx.TxIn.Height, txPosition: tx.TxIn.TxPosition, txHash: tx.TxIn.TxHash, txIndex: tx.TxIn.TxIndex}
+	}
+	return historyPosition{height: tx.TxOut.Height, txPosition: tx.TxOut.TxPosition, txHash: tx.TxOut.TxHash, credit: true, txIndex: tx.TxOut.TxIndex}
+}
+
+func (p historyPosition) less(o historyPosition) bool {
+	if p.height != o.height {
+		return p.height < o.height
+	}
+	if p.txPosition != o.txPosition {
+		return p.txPosition < o.txPosition
+	}
+	if p.txHash != o.txHash {
+		return p.txHash < o.txHash
+	}
+	if p.credit != o.credit {
+		return !p.credit
+	}
+	return p.txIndex < o.txIndex
+}
+
+// boundIndex returns the tx index to compare (height, tx position, tx hash, tx index) of the ins, or outs if credit,
+// strictly with the ones of the cursor, in both directions. The ins of the tx of a cursor on an out are before it,
+// the outs of the tx of a cursor on an in are after it.
+func (p historyPosition) boundIndex(credit bool) int32 {
+	switch {
+	case credit == p.credit:
+		return p.txIndex
+	case p.credit:
+		// Above any tx index, a tx can't have that many ins
+		return math.MaxInt32
+	default:
+		return -1
+	}
+}
+
+// after tells if the position is after the cursor in the direction of the pages.
+func (p historyPosition) after(cursor historyPosition, descending bool) bool {
+	if descending {
+		return p.less(cursor)
+	}
+	return cursor.less(p)
+}
+
+func encodeCursor(p historyPosition) string {
+	credit := 0
+	if p.credit {
+		credit = 1
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d:%s:%d:%d", p.height, p.txPosition, p.txHash, credit, p.txIndex)))
+}
+
+func decodeCursor(cursor string) (*historyPosition, error) {
+	if len(cursor) == 0 {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, common.ErrInvalidCursor
+	}
+	parts := strings.Split(string(b), ":")
+	if len(parts) != 5 {
+		return nil, common.ErrInvalidCursor
+	}
+	height, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, common.ErrInvalidCursor
+	}
+	txPosition, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return nil, common.ErrInvalidCursor
+	}
+	txIndex, err := strconv.ParseInt(parts[4], 10, 32)
+	if err != nil || !validHash(parts[2]) {
+		return nil, common.ErrInvalidCursor
+	}
+	return &historyPosition{height: height, txPosition: int32(txPosition), txHash: parts[2], credit: parts[3] == "1", txIndex: int32(txIndex)}, nil
+}
+
+func validateHistoryQuery(query *model.AddressHistoryQuery) error {
+	if query.FromHeight < 0 || query.FromHeight > query.ToHeight {
+		return common.ErrInvalidRange
+	}
+	if query.Limit <= 0 {
+		return fmt.Errorf("invalid limit '%d' of Address History", query.Limit)
+	}
+	return nil
+}
+
+// mergeHistory returns the first page of 'limit' txs of the ins & outs, each of them sorted in the direction of the pages.
+// Up to 'limit' + 1 of each are given to know if there is a next page.
+func mergeHistory(txIns []*model.TxIn, txOuts []*model.TxOut, limit int, descending bool) *model.AddressHistory {
+	txs := make([]*model.AddressTx, 0, len(txIns)+len(txOuts))
+	for _, in := range txIns {
+		txs = append(txs, &model.AddressTx{TxIn: in})
+	}
+	for _, out := range txOuts {
+		txs = append(txs, &model.AddressTx{TxOut: out})
+	}
+	sort.SliceStable(txs, func(i, j int) bool {
+		if descending {
+			return positionOf(txs[j]).less(positionOf(txs[i]))
+		}
+		return positionOf(txs[i]).less(positionOf(txs[j]))
+	})
+
+	history := &model.AddressHistory{Txs: txs}
+	if len(txs) > limit {
+		history.Txs = txs[:limit]
+		history.NextCursor = encodeCursor(positionOf(txs[limit-1]))
+	}
+	return history
+}
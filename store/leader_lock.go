@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// LeaderLock is held by a single instance at a time, and released automatically once its holder dies.
+type LeaderLock interface {
+	// TryLock acquires the lock without waiting, it returns false if another instance holds it.
+	TryLock(ctx context.Context) (bool, error)
+	// Check returns an error if the lock may not be held anymore, e.g. its connection is broken.
+	Check(ctx context.Context) error
+	Unlock() error
+}
+
+// advisoryLock is a Postgres session level advisory lock held on a dedicated connection,
+// so Postgres releases it as soon as the session of a dead holder ends.
+type advisoryLock struct {
+	db   *sql.DB
+	key  int64
+	conn *sql.Conn
+}
+
+func NewPostgresLeaderLock(connectionString string, key int64) (LeaderLock, error) {
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Open connection with postgres DB for Leader Lock: %v", err)
+	}
+	// Without idle connections, a connection given back is closed together with its session
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(0)
+	return &advisoryLock{db: db, key: key}, nil
+}
+
+func (l *advisoryLock) TryLock(ctx context.Context) (bool, error) {
+	if l.conn == nil {
+		conn, err := l.db.Conn(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to Get DB connection: %v", err)
+		}
+		l.conn = conn
+	}
+
+	var locked bool
+	err := l.conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&locked)
+	if err != nil {
+		l.close()
+		return false, fmt.Errorf("failed to Try Advisory Lock '%d': %v", l.key, err)
+	}
+	return locked, nil
+}
+
+func (l *advisoryLock) Check(ctx context.Context) error {
+	if l.conn == nil {
+		return fmt.Errorf("advisory Lock '%d' not held", l.key)
+	}
+	// The lock is kept as long as the session is alive
+	err := l.conn.PingContext(ctx)
+	if err != nil {
+		l.close()
+		return fmt.Errorf("failed to Ping DB connection holding Advisory Lock '%d': %v", l.key, err)
+	}
+	return nil
+}
+
+func (l *advisoryLock) Unlock() error {
+	if l.conn == nil {
+		return nil
+	}
+	defer l.close()
+
+	var unlocked bool
+	err := l.conn.QueryRowContext(context.Background(), "SELECT pg_advisory_unlock($1)", l.key).Scan(&unlocked)
+	if err != nil {
+		return fmt.Errorf("failed to Unlock Advisory Lock '%d': %v", l.key, err)
+	}
+	if !unlocked {
+		return fmt.Errorf("advisory Lock '%d' not held by the session", l.key)
+	}
+	return nil
+}
+
+// close ends the session, so the lock is released if it's still held.
+func (l *advisoryLock) close() {
+	if l.conn == nil {
+		return
+	}
+	_ = l.conn.Close()
+	l.conn = nil
+}
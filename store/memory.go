@@ -0,0 +1,555 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"math"
+	"sort"
+	"sync"
+)
+
+// txDataId is the unique key of a tx in or out at a height.
+type txDataId struct {
+	txHash  string
+	txIndex int32
+}
+
+// memoryHeight holds the data at a height.
+type memoryHeight struct {
+	block  *model.Block
+	txs    map[string]*model.Tx
+	txIns  map[txDataId]*model.TxIn
+	txOuts map[txDataId]*model.TxOut
+}
+
+// memoryManager keeps the data in memory, for development and tests. It's safe for concurrent use,
+// the data are copied in and out so callers never share them. Its calls never block, so the contexts are ignored.
+type memoryManager struct {
+	mu      sync.RWMutex
+	heights map[int64]*memoryHeight
+	// txHeights maps a tx hash to its heights in ascending order, more than one for the duplicated
+	// coinbases before BIP30, as (height, hash) is the unique key of the txs
+	txHeights map[string][]int64
+	reorgs    []*model.Reorg
+	// watchLists maps a watch list name to its addresses
+	watchLists map[string]map[string]bool
+}
+
+func NewMemoryManager() Manager {
+	return newMemoryManager()
+}
+
+func newMemoryManager() *memoryManager {
+	return &memoryManager{
+		heights:    make(map[int64]*memoryHeight),
+		txHeights:  make(map[string][]int64),
+		watchLists: make(map[string]map[string]bool),
+	}
+}
+
+func (m *memoryManager) GetLatestBlock(ctx context.Context) (*model.Block, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	height := m.latestHeight()
+	if height < 0 {
+		return nil, common.ErrNotFound
+	}
+	b := *m.heights[height].block
+	return &b, nil
+}
+
+// latestHeight returns the height of the latest block, -1 if none.
+func (m *memoryManager) latestHeight() int64 {
+	latest := int64(-1)
+	for height, h := range m.heights {
+		if h.block != nil && height > latest {
+			latest = height
+		}
+	}
+	return latest
+}
+
+func (m *memoryManager) GetBlock(ctx context.Context, height int64) (*model.Block, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	h, ok := m.heights[height]
+	if !ok || h.block == nil {
+		return nil, common.ErrNotFound
+	}
+	b := *h.block
+	return &b, nil
+}
+
+func (m *memoryManager) GetBlocks(ctx context.Context, heights []int64) (map[int64]*model.Block, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[int64]*model.Block, len(heights))
+	for _, height := range heights {
+		h, ok := m.heights[height]
+		if !ok || h.block == nil {
+			continue
+		}
+		b := *h.block
+		result[height] = &b
+	}
+	return result, nil
+}
+
+func (m *memoryManager) Reorg(ctx context.Context, event *model.Reorg) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stampReorg(event)
+	event.TxNo = 0
+	for height, h := range m.heights {
+		if height >= event.FromHeight {
+			event.TxNo += int64(len(h.txs))
+			m.deleteHeight(height)
+		}
+	}
+
+	event.Id = int64(len(m.reorgs) + 1)
+	e := *event
+	m.reorgs = append(m.reorgs, &e)
+	return nil
+}
+
+func (m *memoryManager) AddBlocksData(ctx context.Context, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	err := validateBlocksData(blocks, txs, txIns, txOuts)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.putBlocksData(blocks, txs, txIns, txOuts)
+	return nil
+}
+
+func (m *memoryManager) GetBlocksData(ctx context.Context, fromHeight, toHeight int64, interestedAddresses []string) (map[int64]*model.Block, map[int64][]*model.TxIn, map[int64][]*model.TxOut, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	addresses := make(map[string]bool, len(interestedAddresses))
+	for _, a := range interestedAddresses {
+		addresses[a] = true
+	}
+
+	blocks := make(map[int64]*model.Block)
+	txInsResult := make(map[int64][]*model.TxIn)
+	txOutsResult := make(map[int64][]*model.TxOut)
+	for height, h := range m.heights {
+		if height < fromHeight || height > toHeight {
+			continue
+		}
+		if h.block != nil {
+			b := *h.block
+			blocks[height] = &b
+		}
+		for _, in := range h.txIns {
+			if addresses[in.Address] {
+				txIn := *in
+				txInsResult[height] = append(txInsResult[height], &txIn)
+			}
+		}
+		for _, out := range h.txOuts {
+			if addresses[out.Address] {
+				txOutsResult[height] = append(txOutsResult[height], copyTxOut(out))
+			}
+		}
+	}
+	return blocks, txInsResult, txOutsResult, nil
+}
+
+func (m *memoryManager) GetBlocksInRange(ctx context.Context, fromHeight, toHeight int64) ([]*model.Block, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var blocks []*model.Block
+	for height, h := range m.heights {
+		if height < fromHeight || height > toHeight || h.block == nil {
+			continue
+		}
+		b := *h.block
+		blocks = append(blocks, &b)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].Height < blocks[j].Height
+	})
+	return blocks, nil
+}
+
+func (m *memoryManager) GetBlockStats(ctx context.Context, fromHeight, toHeight int64) (map[int64]*model.BlockStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := make(map[int64]*model.BlockStats)
+	for height, h := range m.heights {
+		if height < fromHeight || height > toHeight || len(h.txs)+len(h.txOuts) == 0 {
+			continue
+		}
+		s := &model.BlockStats{
+			Height:  height,
+			TxNo:    int64(len(h.txs)),
+			TxOutNo: int64(len(h.txOuts)),
+		}
+		for _, out := range h.txOuts {
+			s.TxOutValue += out.Value
+		}
+		stats[height] = s
+	}
+	return stats, nil
+}
+
+func (m *memoryManager) GetBlocksDataInRange(ctx context.Context, fromHeight, toHeight int64, addresses []string) ([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var blocks []*model.Block
+	var txs []*model.Tx
+	var txIns []*model.TxIn
+	var txOuts []*model.TxOut
+	for height, h := range m.heights {
+		if height < fromHeight || height > toHeight {
+			continue
+		}
+		if h.block != nil {
+			b := *h.block
+			blocks = append(blocks, &b)
+		}
+		for _, tx := range h.txs {
+			t := *tx
+			txs = append(txs, &t)
+		}
+		for _, in := range h.txIns {
+			txIn := *in
+			txIns = append(txIns, &txIn)
+		}
+		for _, out := range h.txOuts {
+			txOuts = append(txOuts, copyTxOut(out))
+		}
+	}
+	txs, txIns, txOuts = filterAddresses(addresses, txs, txIns, txOuts)
+	sortBlocksData(blocks, txs, txIns, txOuts)
+	return blocks, txs, txIns, txOuts, nil
+}
+
+func (m *memoryManager) ReplaceBlocksData(ctx context.Context, heights []int64, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	err := validateBlocksData(blocks, txs, txIns, txOuts)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, height := range heights {
+		m.deleteHeight(height)
+	}
+	m.putBlocksData(blocks, txs, txIns, txOuts)
+	return nil
+}
+
+func (m *memoryManager) GetTx(ctx context.Context, hash string) (*model.TxDetail, error) {
+	if !validHash(hash) {
+		return nil, common.ErrInvalidHash
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	heights, ok := m.txHeights[hash]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	// The later of duplicated txs overwrote the earlier one, as in Full Node
+	height := heights[len(heights)-1]
+	h := m.heights[height]
+	if h.block == nil {
+		return nil, fmt.Errorf("not found Block at height '%d' of Tx '%s'", height, hash)
+	}
+
+	tx := *h.txs[hash]
+	block := *h.block
+	detail := &model.TxDetail{
+		Tx:            &tx,
+		Block:         &block,
+		Confirmations: m.latestHeight() - height + 1,
+	}
+	for id, in := range h.txIns {
+		if id.txHash == hash {
+			txIn := *in
+			detail.TxIns = append(detail.TxIns, &txIn)
+		}
+	}
+	for id, out := range h.txOuts {
+		if id.txHash == hash {
+			detail.TxOuts = append(detail.TxOuts, copyTxOut(out))
+		}
+	}
+	sort.Slice(detail.TxIns, func(i, j int) bool {
+		return detail.TxIns[i].TxIndex < detail.TxIns[j].TxIndex
+	})
+	sort.Slice(detail.TxOuts, func(i, j int) bool {
+		return detail.TxOuts[i].TxIndex < detail.TxOuts[j].TxIndex
+	})
+	return detail, nil
+}
+
+func (m *memoryManager) GetAddressHistory(ctx context.Context, query *model.AddressHistoryQuery) (*model.AddressHistory, error) {
+	err := validateHistoryQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	notAfter := func(tx *model.AddressTx) bool {
+		return cursor != nil && !positionOf(tx).after(*cursor, query.Descending)
+	}
+	var txIns []*model.TxIn
+	var txOuts []*model.TxOut
+	for height, h := range m.heights {
+		if height < query.FromHeight || height > query.ToHeight {
+			continue
+		}
+		for _, in := range h.txIns {
+			if in.Address == query.Address && !notAfter(&model.AddressTx{TxIn: in}) {
+				txIn := *in
+				txIns = append(txIns, &txIn)
+			}
+		}
+		for _, out := range h.txOuts {
+			if out.Address == query.Address && !notAfter(&model.AddressTx{TxOut: out}) {
+				txOuts = append(txOuts, copyTxOut(out))
+			}
+		}
+	}
+	return mergeHistory(txIns, txOuts, query.Limit, query.Descending), nil
+}
+
+func (m *memoryManager) GetUTXOs(ctx context.Context, query *model.UTXOsQuery, coinbaseMaturity int64) (*model.UTXOsPage, error) {
+	cursor, err := validateUTXOsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	latestHeight := m.latestHeight()
+	if latestHeight < 0 {
+		return &model.UTXOsPage{UTXOs: []*model.UTXO{}}, nil
+	}
+
+	interested := make(map[string]bool, len(query.Addresses))
+	for _, a := range query.Addresses {
+		interested[a] = true
+	}
+	spent := make(map[txDataId]bool)
+	for _, h := range m.heights {
+		for _, in := range h.txIns {
+			spent[txDataId{txHash: in.PreviousTxHash, txIndex: in.PreviousTxIndex}] = true
+		}
+	}
+
+	var txOuts []*model.TxOut
+	for _, h := range m.heights {
+		for id, out := range h.txOuts {
+			if interested[out.Address] && !spent[id] && (cursor == nil || outPosition(out).after(*cursor, false)) {
+				txOuts = append(txOuts, copyTxOut(out))
+			}
+		}
+	}
+	sortByPosition(txOuts)
+	return pageUTXOs(txOuts, query.Limit, latestHeight, coinbaseMaturity), nil
+}
+
+func (m *memoryManager) GetAddressBalance(ctx context.Context, address string) (*model.AddressBalance, error) {
+	return m.GetAddressBalanceAtHeight(ctx, address, math.MaxInt64)
+}
+
+func (m *memoryManager) GetAddressBalanceAtHeight(ctx context.Context, address string, height int64) (*model.AddressBalance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	outs := make(map[txDataId]*model.TxOut)
+	for _, h := range m.heights {
+		for id, out := range h.txOuts {
+			outs[id] = out
+		}
+	}
+	var entries []balanceEntry
+	for _, h := range m.heights {
+		for _, out := range h.txOuts {
+			if out.Address == address {
+				entries = append(entries, balanceEntry{address: address, height: out.Height, txHash: out.TxHash, received: out.Value})
+			}
+		}
+		for _, in := range h.txIns {
+			out, ok := outs[txDataId{txHash: in.PreviousTxHash, txIndex: in.PreviousTxIndex}]
+			if ok && out.Address == address {
+				entries = append(entries, balanceEntry{address: address, height: in.Height, txHash: in.TxHash, sent: out.Value})
+			}
+		}
+	}
+	return sumChanges(address, groupChanges(entries), height)
+}
+
+func (m *memoryManager) GetReorgs(ctx context.Context, query *model.ReorgQuery) ([]*model.Reorg, error) {
+	err := validateReorgQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var reorgs []*model.Reorg
+	for _, r := range m.reorgs {
+		if matchReorg(r, query) {
+			reorg := *r
+			reorgs = append(reorgs, &reorg)
+		}
+	}
+	return reorgs, nil
+}
+
+func (m *memoryManager) AddWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	watched, ok := m.watchLists[list]
+	if !ok {
+		watched = make(map[string]bool)
+		m.watchLists[list] = watched
+	}
+	var added int64
+	for _, a := range addresses {
+		if !watched[a] {
+			watched[a] = true
+			added++
+		}
+	}
+	return added, nil
+}
+
+func (m *memoryManager) RemoveWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	watched := m.watchLists[list]
+	var removed int64
+	for _, a := range addresses {
+		if watched[a] {
+			delete(watched, a)
+			removed++
+		}
+	}
+	if len(watched) == 0 {
+		delete(m.watchLists, list)
+	}
+	return removed, nil
+}
+
+func (m *memoryManager) GetWatchLists(ctx context.Context) (map[string][]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return sortedWatchLists(m.watchLists), nil
+}
+
+// putBlocksData overwrites the data having the same keys, like the upserts of the SQL manager.
+func (m *memoryManager) putBlocksData(blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) {
+	for _, b := range blocks {
+		block := *b
+		m.height(b.Height).block = &block
+	}
+
+	for _, t := range txs {
+		tx := *t
+		h := m.height(t.Height)
+		if _, ok := h.txs[t.Hash]; !ok {
+			m.txHeights[t.Hash] = insertHeight(m.txHeights[t.Hash], t.Height)
+		}
+		h.txs[t.Hash] = &tx
+	}
+
+	for _, in := range txIns {
+		txIn := *in
+		m.height(in.Height).txIns[txDataId{txHash: in.TxHash, txIndex: in.TxIndex}] = &txIn
+	}
+
+	for _, out := range txOuts {
+		m.height(out.Height).txOuts[txDataId{txHash: out.TxHash, txIndex: out.TxIndex}] = copyTxOut(out)
+	}
+}
+
+func (m *memoryManager) height(height int64) *memoryHeight {
+	h, ok := m.heights[height]
+	if !ok {
+		h = &memoryHeight{
+			txs:    make(map[string]*model.Tx),
+			txIns:  make(map[txDataId]*model.TxIn),
+			txOuts: make(map[txDataId]*model.TxOut),
+		}
+		m.heights[height] = h
+	}
+	return h
+}
+
+func (m *memoryManager) deleteHeight(height int64) {
+	h, ok := m.heights[height]
+	if !ok {
+		return
+	}
+	for hash := range h.txs {
+		heights := removeHeight(m.txHeights[hash], height)
+		if len(heights) == 0 {
+			delete(m.txHeights, hash)
+			continue
+		}
+		m.txHeights[hash] = heights
+	}
+	delete(m.heights, height)
+}
+
+// insertHeight adds a height to ones in ascending order, which it may modify.
+func insertHeight(heights []int64, height int64) []int64 {
+	i := sort.Search(len(heights), func(i int) bool { return heights[i] >= height })
+	if i < len(heights) && heights[i] == height {
+		return heights
+	}
+	heights = append(heights, 0)
+	copy(heights[i+1:], heights[i:])
+	heights[i] = height
+	return heights
+}
+
+// removeHeight removes a height from ones in ascending order, which it may modify.
+func removeHeight(heights []int64, height int64) []int64 {
+	i := sort.Search(len(heights), func(i int) bool { return heights[i] >= height })
+	if i == len(heights) || heights[i] != height {
+		return heights
+	}
+	return append(heights[:i], heights[i+1:]...)
+}
+
+func copyTxOut(out *model.TxOut) *model.TxOut {
+	txOut := *out
+	txOut.ScriptPubKey = append([]byte(nil), out.ScriptPubKey...)
+	if out.CoinBase != nil {
+		coinBase := *out.CoinBase
+		txOut.CoinBase = &coinBase
+	}
+	return &txOut
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/model"
+	. "github.com/onsi/gomega"
+	"sync"
+	"testing"
+)
+
+type memoryBackend struct {
+	m *memoryManager
+}
+
+func (b *memoryBackend) clear() error {
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+
+	b.m.heights = make(map[int64]*memoryHeight)
+	b.m.txHeights = make(map[string][]int64)
+	b.m.reorgs = nil
+	b.m.watchLists = make(map[string]map[string]bool)
+	return nil
+}
+
+func (b *memoryBackend) create(value interface{}) error {
+	switch v := value.(type) {
+	case model.Block:
+		return b.m.AddBlocksData(ctx, []*model.Block{&v}, nil, nil, nil)
+	case model.Tx:
+		return b.m.AddBlocksData(ctx, nil, []*model.Tx{&v}, nil, nil)
+	case model.TxIn:
+		return b.m.AddBlocksData(ctx, nil, nil, []*model.TxIn{&v}, nil)
+	case model.TxOut:
+		return b.m.AddBlocksData(ctx, nil, nil, nil, []*model.TxOut{&v})
+	}
+	return fmt.Errorf("unsupported type %T", value)
+}
+
+func (b *memoryBackend) count(table string) (int, error) {
+	b.m.mu.RLock()
+	defer b.m.mu.RUnlock()
+
+	count := 0
+	for _, h := range b.m.heights {
+		switch table {
+		case model.Block{}.TableName():
+			if h.block != nil {
+				count++
+			}
+		case model.Tx{}.TableName():
+			count += len(h.txs)
+		case model.TxIn{}.TableName():
+			count += len(h.txIns)
+		case model.TxOut{}.TableName():
+			count += len(h.txOuts)
+		default:
+			return 0, fmt.Errorf("unknown table '%s'", table)
+		}
+	}
+	return count, nil
+}
+
+// TestMemoryManager runs the tests of Manager against the in-memory one.
+func TestMemoryManager(t *testing.T) {
+	m := newMemoryManager()
+	runManagerTests(t, m, &memoryBackend{m: m})
+}
+
+func TestMemoryManager_NotFound(t *testing.T) {
+	RegisterTestingT(t)
+
+	m := NewMemoryManager()
+	_, err := m.GetLatestBlock(ctx)
+	Expect(err).Should(Equal(common.ErrNotFound))
+	_, err = m.GetBlock(ctx, 13)
+	Expect(err).Should(Equal(common.ErrNotFound))
+
+	// The stored data are not shared with the callers
+	b := &model.Block{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}
+	err = m.AddBlocksData(ctx, []*model.Block{b}, nil, nil, nil)
+	Expect(err).Should(Succeed())
+	b.Hash = "changed"
+	block, err := m.GetBlock(ctx, 13)
+	Expect(err).Should(Succeed())
+	Expect(block.Hash).Should(Equal(hashOf("13")))
+}
+
+func TestMemoryManager_Concurrent(t *testing.T) {
+	RegisterTestingT(t)
+
+	m := NewMemoryManager()
+	var wg sync.WaitGroup
+	for i := int64(0); i < 10; i++ {
+		wg.Add(2)
+		go func(height int64) {
+			defer wg.Done()
+			err := m.AddBlocksData(ctx,
+				[]*model.Block{{Height: height, Hash: hashOf(fmt.Sprint(height)), PreviousHash: hashOf(fmt.Sprint(height - 1))}},
+				[]*model.Tx{{Height: height, Hash: hashOf(fmt.Sprintf("tx%d", height)), CoinBase: &falseValue}},
+				nil,
+				[]*model.TxOut{{Height: height, TxHash: hashOf(fmt.Sprintf("tx%d", height)), Value: 10, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue}},
+			)
+			Expect(err).Should(Succeed())
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, _, _, err := m.GetBlocksData(ctx, 0, 9, []string{"bob"})
+			Expect(err).Should(Succeed())
+		}()
+	}
+	wg.Wait()
+
+	stats, err := m.GetBlockStats(ctx, 0, 9)
+	Expect(err).Should(Succeed())
+	Expect(len(stats)).Should(Equal(10))
+}
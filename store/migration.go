@@ -0,0 +1,483 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+	"time"
+)
+
+var (
+	// ErrSchemaTooNew is returned when the DB schema was migrated by a newer binary.
+	ErrSchemaTooNew = errors.New("DB schema newer than the binary")
+	// ErrSchemaTooOld is returned when the DB schema has pending migrations.
+	ErrSchemaTooOld = errors.New("DB schema older than the binary, run command 'migrate up'")
+	// ErrRewriteNotAllowed is returned for a migration rewriting the indexed tables, unless allowed.
+	ErrRewriteNotAllowed = errors.New("migration rewrites the indexed tables with the services stopped, allow it with option '-allow-rewrite'")
+)
+
+const schemaMigrationsTable = "schema_migrations"
+
+type migration struct {
+	version int64
+	name    string
+	up      func(db *gorm.DB) error
+	down    func(db *gorm.DB) error
+	// rewrites tells if the migration rewrites all the rows of the indexed tables, locking them for its whole run
+	rewrites bool
+}
+
+// migrations are applied in order of version, a released one must never be changed.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create_tables",
+		up: func(db *gorm.DB) error {
+			// Tables may exist already, created by AutoMigrate before the migrations were versioned
+			return execAll(db,
+				`CREATE TABLE IF NOT EXISTS blocks (
+					height bigint NOT NULL,
+					hash varchar(64) NOT NULL,
+					previous_hash varchar(64) NOT NULL)`,
+				`CREATE TABLE IF NOT EXISTS txes (
+					height bigint NOT NULL,
+					hash varchar(64) NOT NULL,
+					coin_base boolean NOT NULL DEFAULT false)`,
+				fmt.Sprintf("CREATE TABLE IF NOT EXISTS tx_ins (%s)", txInsColumns),
+				fmt.Sprintf("CREATE TABLE IF NOT EXISTS tx_outs (%s)", txOutsColumns(db)),
+				fmt.Sprintf(`CREATE TABLE IF NOT EXISTS reorgs (
+					id %s,
+					from_height bigint NOT NULL,
+					from_hash text NOT NULL,
+					to_height bigint NOT NULL,
+					to_hash text NOT NULL)`, serialPrimaryKeyType(db)),
+			)
+		},
+		down: func(db *gorm.DB) error {
+			return execAll(db,
+				"DROP TABLE IF EXISTS reorgs",
+				"DROP TABLE IF EXISTS tx_outs",
+				"DROP TABLE IF EXISTS tx_ins",
+				"DROP TABLE IF EXISTS txes",
+				"DROP TABLE IF EXISTS blocks",
+			)
+		},
+	},
+	{
+		version: 2,
+		name:    "add_unique_keys",
+		up: func(db *gorm.DB) error {
+			err := dedupe(db, uniqueKeys...)
+			if err != nil {
+				return err
+			}
+			// The keys have the height, as the coinbases of blocks 91812 & 91842, 91722 & 91880 of mainnet have
+			// the same hashes, allowed before BIP30. The keys cover the lookups by height.
+			return execAll(db,
+				"CREATE UNIQUE INDEX IF NOT EXISTS idx_blocks_height ON blocks (height)",
+				"CREATE UNIQUE INDEX IF NOT EXISTS idx_txes_height_hash ON txes (height, hash)",
+				"CREATE INDEX IF NOT EXISTS idx_txes_hash ON txes (hash)",
+				"CREATE UNIQUE INDEX IF NOT EXISTS idx_tx_ins_height_tx_hash_tx_index ON tx_ins (height, tx_hash, tx_index)",
+				"CREATE UNIQUE INDEX IF NOT EXISTS idx_tx_outs_height_tx_hash_tx_index ON tx_outs (height, tx_hash, tx_index)",
+			)
+		},
+		down: func(db *gorm.DB) error {
+			return execAll(db,
+				"DROP INDEX IF EXISTS idx_tx_outs_height_tx_hash_tx_index",
+				"DROP INDEX IF EXISTS idx_tx_ins_height_tx_hash_tx_index",
+				"DROP INDEX IF EXISTS idx_txes_hash",
+				"DROP INDEX IF EXISTS idx_txes_height_hash",
+				"DROP INDEX IF EXISTS idx_blocks_height",
+			)
+		},
+	},
+	{
+		version: 3,
+		name:    "partition_tx_ins_tx_outs",
+		up:      partitionUp,
+		down:    partitionDown,
+	},
+	{
+		version: 4,
+		name:    "add_address_indexes",
+		up: func(db *gorm.DB) error {
+			// Pages of the history of an address are read in order of these keys
+			return execAll(db,
+				"CREATE INDEX IF NOT EXISTS idx_tx_ins_address_height ON tx_ins (address, height, tx_hash, tx_index)",
+				"CREATE INDEX IF NOT EXISTS idx_tx_outs_address_height ON tx_outs (address, height, tx_hash, tx_index)",
+			)
+		},
+		down: func(db *gorm.DB) error {
+			return execAll(db,
+				"DROP INDEX IF EXISTS idx_tx_outs_address_height",
+				"DROP INDEX IF EXISTS idx_tx_ins_address_height",
+			)
+		},
+	},
+	{
+		version: 5,
+		name:    "add_spends_index",
+		up: func(db *gorm.DB) error {
+			// The tx outs spent by tx ins, to find the unspent ones
+			return execAll(db, "CREATE INDEX IF NOT EXISTS idx_tx_ins_previous_tx_hash_previous_tx_index ON tx_ins (previous_tx_hash, previous_tx_index)")
+		},
+		down: func(db *gorm.DB) error {
+			return execAll(db, "DROP INDEX IF EXISTS idx_tx_ins_previous_tx_hash_previous_tx_index")
+		},
+	},
+	{
+		version: 6,
+		name:    "add_address_balances",
+		up: func(db *gorm.DB) error {
+			// The tx outs spent by the tx ins of a batch are looked up by tx hash, then the balances are filled
+			// from the indexed data
+			return execAll(db,
+				"CREATE INDEX IF NOT EXISTS idx_tx_outs_tx_hash ON tx_outs (tx_hash, tx_index)",
+				`CREATE TABLE IF NOT EXISTS address_balance_changes (
+					address varchar(62) NOT NULL,
+					height bigint NOT NULL,
+					received bigint NOT NULL,
+					sent bigint NOT NULL,
+					tx_no bigint NOT NULL
+				)`,
+				"CREATE UNIQUE INDEX IF NOT EXISTS idx_address_balance_changes_address_height ON address_balance_changes (address, height)",
+				"CREATE INDEX IF NOT EXISTS idx_address_balance_changes_height ON address_balance_changes (height)",
+				`CREATE TABLE IF NOT EXISTS address_balances (
+					address varchar(62) NOT NULL PRIMARY KEY,
+					received bigint NOT NULL,
+					sent bigint NOT NULL,
+					balance bigint NOT NULL,
+					tx_no bigint NOT NULL,
+					first_seen_height bigint NOT NULL,
+					last_seen_height bigint NOT NULL
+				)`,
+				`INSERT INTO address_balance_changes (address, height, received, sent, tx_no)
+					SELECT address, height, SUM(received), SUM(sent), COUNT(DISTINCT tx_hash) FROM (
+						SELECT address, height, tx_hash, value AS received, 0 AS sent FROM tx_outs
+						UNION ALL
+						SELECT tx_outs.address, tx_ins.height, tx_ins.tx_hash, 0 AS received, tx_outs.value AS sent FROM tx_ins
+						JOIN tx_outs ON tx_outs.tx_hash = tx_ins.previous_tx_hash AND tx_outs.tx_index = tx_ins.previous_tx_index
+					) AS entries GROUP BY address, height`,
+				`INSERT INTO address_balances (address, received, sent, balance, tx_no, first_seen_height, last_seen_height)
+					SELECT address, SUM(received), SUM(sent), SUM(received) - SUM(sent), SUM(tx_no), MIN(height), MAX(height)
+					FROM address_balance_changes GROUP BY address`,
+			)
+		},
+		down: func(db *gorm.DB) error {
+			return execAll(db,
+				"DROP TABLE IF EXISTS address_balances",
+				"DROP TABLE IF EXISTS address_balance_changes",
+				"DROP INDEX IF EXISTS idx_tx_outs_tx_hash",
+			)
+		},
+	},
+	{
+		version: 7,
+		name:    "add_reorg_details",
+		up: func(db *gorm.DB) error {
+			// The reorgs are listed by height or time, the details of the old ones are unknown
+			return execAll(db,
+				"ALTER TABLE reorgs ADD COLUMN new_height bigint NOT NULL DEFAULT 0",
+				"ALTER TABLE reorgs ADD COLUMN new_hash text NOT NULL DEFAULT ''",
+				"ALTER TABLE reorgs ADD COLUMN tx_no bigint NOT NULL DEFAULT 0",
+				fmt.Sprintf("ALTER TABLE reorgs ADD COLUMN created_at %s", timestampType(db)),
+				"CREATE INDEX IF NOT EXISTS idx_reorgs_to_height ON reorgs (to_height)",
+				"CREATE INDEX IF NOT EXISTS idx_reorgs_created_at ON reorgs (created_at)",
+			)
+		},
+		down: func(db *gorm.DB) error {
+			return execAll(db,
+				"DROP INDEX IF EXISTS idx_reorgs_created_at",
+				"DROP INDEX IF EXISTS idx_reorgs_to_height",
+				"ALTER TABLE reorgs DROP COLUMN created_at",
+				"ALTER TABLE reorgs DROP COLUMN tx_no",
+				"ALTER TABLE reorgs DROP COLUMN new_hash",
+				"ALTER TABLE reorgs DROP COLUMN new_height",
+			)
+		},
+	},
+	{
+		version:  8,
+		name:     "compact_hashes_addresses",
+		up:       compactUp,
+		down:     compactDown,
+		rewrites: true,
+	},
+	{
+		version: 9,
+		name:    "add_watched_addresses",
+		up: func(db *gorm.DB) error {
+			return execAll(db,
+				fmt.Sprintf(`CREATE TABLE IF NOT EXISTS watched_addresses (
+					list varchar(64) NOT NULL,
+					address varchar(62) NOT NULL,
+					created_at %s)`, timestampType(db)),
+				"CREATE UNIQUE INDEX IF NOT EXISTS idx_watched_addresses_list_address ON watched_addresses (list, address)",
+			)
+		},
+		down: func(db *gorm.DB) error {
+			return execAll(db, "DROP TABLE IF EXISTS watched_addresses")
+		},
+	},
+	{
+		version: 10,
+		name:    "add_tx_positions",
+		up: func(db *gorm.DB) error {
+			// The history of an address is ordered by the positions of the txs in their blocks. The rows indexed before
+			// have position 0, so their txs are ordered by hash in a block until the block is indexed again.
+			return execAll(db,
+				"ALTER TABLE tx_ins ADD COLUMN tx_position integer NOT NULL DEFAULT 0",
+				"ALTER TABLE tx_outs ADD COLUMN tx_position integer NOT NULL DEFAULT 0",
+				"DROP INDEX IF EXISTS idx_tx_ins_address_id_height",
+				"DROP INDEX IF EXISTS idx_tx_outs_address_id_height",
+				"CREATE INDEX IF NOT EXISTS idx_tx_ins_address_id_height ON tx_ins (address_id, height, tx_position, tx_hash, tx_index)",
+				"CREATE INDEX IF NOT EXISTS idx_tx_outs_address_id_height ON tx_outs (address_id, height, tx_position, tx_hash, tx_index)",
+			)
+		},
+		down: func(db *gorm.DB) error {
+			return execAll(db,
+				"DROP INDEX IF EXISTS idx_tx_outs_address_id_height",
+				"DROP INDEX IF EXISTS idx_tx_ins_address_id_height",
+				"ALTER TABLE tx_outs DROP COLUMN tx_position",
+				"ALTER TABLE tx_ins DROP COLUMN tx_position",
+				"CREATE INDEX IF NOT EXISTS idx_tx_ins_address_id_height ON tx_ins (address_id, height, tx_hash, tx_index)",
+				"CREATE INDEX IF NOT EXISTS idx_tx_outs_address_id_height ON tx_outs (address_id, height, tx_hash, tx_index)",
+			)
+		},
+	},
+}
+
+// LatestSchemaVersion is the version of the DB schema the binary works with.
+func LatestSchemaVersion() int64 {
+	return migrations[len(migrations)-1].version
+}
+
+// Migrator applies the versioned migrations, recording the applied ones in table 'schema_migrations'.
+type Migrator struct {
+	db            *gorm.DB
+	allowRewrites bool
+}
+
+func NewPostgresMigrator(connectionString string) (*Migrator, error) {
+	db, err := gorm.Open("postgres", connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Open connection with postgres DB '%s': %v", dsnTarget(connectionString), err)
+	}
+	return newMigrator(db)
+}
+
+func newMigrator(db *gorm.DB) (*Migrator, error) {
+	err := db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		version bigint NOT NULL PRIMARY KEY,
+		name varchar(255) NOT NULL,
+		applied_at timestamp NOT NULL)`, schemaMigrationsTable)).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to Create table '%s': %v", schemaMigrationsTable, err)
+	}
+	return &Migrator{db: db}, nil
+}
+
+// AllowRewrites allows the migrations rewriting the indexed tables, which need the services stopped for their runs.
+// They are always allowed on a DB without blocks.
+func (m *Migrator) AllowRewrites() {
+	m.allowRewrites = true
+}
+
+// checkRewrite returns ErrRewriteNotAllowed for a migration rewriting the tables of an indexed DB, unless allowed.
+func (m *Migrator) checkRewrite(mg migration) error {
+	if !mg.rewrites || m.allowRewrites {
+		return nil
+	}
+	var blocks int
+	err := m.db.Table("blocks").Count(&blocks).Error
+	if err != nil {
+		return fmt.Errorf("failed to Count Blocks: %v", err)
+	}
+	if blocks > 0 {
+		return ErrRewriteNotAllowed
+	}
+	return nil
+}
+
+// Version returns the latest applied version, 0 if none.
+func (m *Migrator) Version() (int64, error) {
+	var version struct {
+		Version *int64
+	}
+	err := m.db.Raw(fmt.Sprintf("SELECT MAX(version) AS version FROM %s", schemaMigrationsTable)).Scan(&version).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to Get Schema Version: %v", err)
+	}
+	if version.Version == nil {
+		return 0, nil
+	}
+	return *version.Version, nil
+}
+
+// Up applies the migrations after the current version up to version 'to', the latest one if 'to' is not positive.
+func (m *Migrator) Up(to int64) error {
+	if to <= 0 {
+		to = LatestSchemaVersion()
+	}
+	current, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if current > LatestSchemaVersion() {
+		return ErrSchemaTooNew
+	}
+
+	for _, mg := range migrations {
+		if mg.version <= current || mg.version > to {
+			continue
+		}
+		err = m.checkRewrite(mg)
+		if err == nil {
+			err = m.apply(mg, mg.up, func(tx *gorm.DB) error {
+				return tx.Exec(fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (?, ?, ?)", schemaMigrationsTable),
+					mg.version, mg.name, time.Now().UTC()).Error
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to Migrate Up to version '%d' '%s': %v", mg.version, mg.name, err)
+		}
+		log.L().Info("Migrated Up", zap.Int64("Version", mg.version), zap.String("Name", mg.name))
+	}
+	return nil
+}
+
+// Down reverts the applied migrations after version 'to', in reverse order.
+func (m *Migrator) Down(to int64) error {
+	if to < 0 {
+		return fmt.Errorf("invalid version '%d' to Migrate Down", to)
+	}
+	current, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if current > LatestSchemaVersion() {
+		return ErrSchemaTooNew
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		mg := migrations[i]
+		if mg.version > current || mg.version <= to {
+			continue
+		}
+		err = m.checkRewrite(mg)
+		if err == nil {
+			err = m.apply(mg, mg.down, func(tx *gorm.DB) error {
+				return tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE version = ?", schemaMigrationsTable), mg.version).Error
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to Migrate Down from version '%d' '%s': %v", mg.version, mg.name, err)
+		}
+		log.L().Info("Migrated Down", zap.Int64("Version", mg.version), zap.String("Name", mg.name))
+	}
+	return nil
+}
+
+// checkSchema returns ErrSchemaTooOld or ErrSchemaTooNew unless the DB schema is at the version of the binary.
+// It only reads, so a service doesn't change the schema while its older version may still run.
+func checkSchema(db *gorm.DB) error {
+	current := int64(0)
+	if db.HasTable(schemaMigrationsTable) {
+		var err error
+		current, err = (&Migrator{db: db}).Version()
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case current < LatestSchemaVersion():
+		return ErrSchemaTooOld
+	case current > LatestSchemaVersion():
+		return ErrSchemaTooNew
+	}
+	return nil
+}
+
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
+
+// apply runs a step of migration and records it in a DB transaction.
+func (m *Migrator) apply(mg migration, step func(db *gorm.DB) error, record func(tx *gorm.DB) error) error {
+	txm := &txManager{db: m.db.Begin()}
+	if err := txm.db.Error; err != nil {
+		return fmt.Errorf("failed to Begin DB transaction: %v", err)
+	}
+	defer txm.maybeRollback()
+
+	if m.db.Dialect().GetName() == "postgres" {
+		// Migrations may rewrite big tables, longer than the statement timeout of the connections
+		err := txm.db.Exec("SET LOCAL statement_timeout = 0").Error
+		if err != nil {
+			return fmt.Errorf("failed to Disable Statement Timeout: %v", err)
+		}
+	}
+
+	err := step(txm.db)
+	if err != nil {
+		return err
+	}
+	err = record(txm.db)
+	if err != nil {
+		return fmt.Errorf("failed to Record version '%d': %v", mg.version, err)
+	}
+	return txm.commit()
+}
+
+func execAll(db *gorm.DB, sqls ...string) error {
+	for _, sql := range sqls {
+		err := db.Exec(sql).Error
+		if err != nil {
+			return fmt.Errorf("failed to Exec '%s': %v", sql, err)
+		}
+	}
+	return nil
+}
+
+const txInsColumns = `
+		height bigint NOT NULL,
+		tx_hash varchar(64) NOT NULL,
+		tx_index integer NOT NULL,
+		address varchar(62) NOT NULL,
+		previous_tx_hash varchar(64) NOT NULL,
+		previous_tx_index integer NOT NULL`
+
+func txOutsColumns(db *gorm.DB) string {
+	return fmt.Sprintf(`
+		height bigint NOT NULL,
+		tx_hash varchar(64) NOT NULL,
+		tx_index integer NOT NULL,
+		value bigint NOT NULL,
+		address varchar(62) NOT NULL,
+		script_pub_key %s NOT NULL,
+		coin_base boolean NOT NULL DEFAULT false`, blobType(db))
+}
+
+func blobType(db *gorm.DB) string {
+	if db.Dialect().GetName() == "postgres" {
+		return "bytea"
+	}
+	return "blob"
+}
+
+func timestampType(db *gorm.DB) string {
+	if db.Dialect().GetName() == "postgres" {
+		return "timestamp with time zone"
+	}
+	return "datetime"
+}
+
+func serialPrimaryKeyType(db *gorm.DB) string {
+	if db.Dialect().GetName() == "postgres" {
+		return "bigserial PRIMARY KEY"
+	}
+	return "integer PRIMARY KEY AUTOINCREMENT"
+}
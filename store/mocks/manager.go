@@ -2,6 +2,7 @@
 
 package mocks
 
+import context "context"
 import mock "github.com/stretchr/testify/mock"
 import model "github.com/darkknightbk52/btc-indexer/model"
 
@@ -10,13 +11,13 @@ type Manager struct {
 	mock.Mock
 }
 
-// AddBlocksData provides a mock function with given fields: _a0, _a1, _a2, _a3
-func (_m *Manager) AddBlocksData(_a0 []*model.Block, _a1 []*model.Tx, _a2 []*model.TxIn, _a3 []*model.TxOut) error {
-	ret := _m.Called(_a0, _a1, _a2, _a3)
+// AddBlocksData provides a mock function with given fields: ctx, blocks, txs, txIns, txOuts
+func (_m *Manager) AddBlocksData(ctx context.Context, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	ret := _m.Called(ctx, blocks, txs, txIns, txOuts)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut) error); ok {
-		r0 = rf(_a0, _a1, _a2, _a3)
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut) error); ok {
+		r0 = rf(ctx, blocks, txs, txIns, txOuts)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -24,13 +25,103 @@ func (_m *Manager) AddBlocksData(_a0 []*model.Block, _a1 []*model.Tx, _a2 []*mod
 	return r0
 }
 
-// GetBlock provides a mock function with given fields: height
-func (_m *Manager) GetBlock(height int64) (*model.Block, error) {
-	ret := _m.Called(height)
+// AddWatchedAddresses provides a mock function with given fields: ctx, list, addresses
+func (_m *Manager) AddWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error) {
+	ret := _m.Called(ctx, list, addresses)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) int64); ok {
+		r0 = rf(ctx, list, addresses)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, list, addresses)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAddressBalance provides a mock function with given fields: ctx, address
+func (_m *Manager) GetAddressBalance(ctx context.Context, address string) (*model.AddressBalance, error) {
+	ret := _m.Called(ctx, address)
+
+	var r0 *model.AddressBalance
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.AddressBalance); ok {
+		r0 = rf(ctx, address)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AddressBalance)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, address)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAddressBalanceAtHeight provides a mock function with given fields: ctx, address, height
+func (_m *Manager) GetAddressBalanceAtHeight(ctx context.Context, address string, height int64) (*model.AddressBalance, error) {
+	ret := _m.Called(ctx, address, height)
+
+	var r0 *model.AddressBalance
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) *model.AddressBalance); ok {
+		r0 = rf(ctx, address, height)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AddressBalance)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, address, height)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAddressHistory provides a mock function with given fields: ctx, query
+func (_m *Manager) GetAddressHistory(ctx context.Context, query *model.AddressHistoryQuery) (*model.AddressHistory, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 *model.AddressHistory
+	if rf, ok := ret.Get(0).(func(context.Context, *model.AddressHistoryQuery) *model.AddressHistory); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AddressHistory)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *model.AddressHistoryQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBlock provides a mock function with given fields: ctx, height
+func (_m *Manager) GetBlock(ctx context.Context, height int64) (*model.Block, error) {
+	ret := _m.Called(ctx, height)
 
 	var r0 *model.Block
-	if rf, ok := ret.Get(0).(func(int64) *model.Block); ok {
-		r0 = rf(height)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.Block); ok {
+		r0 = rf(ctx, height)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Block)
@@ -38,8 +129,8 @@ func (_m *Manager) GetBlock(height int64) (*model.Block, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int64) error); ok {
-		r1 = rf(height)
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, height)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -47,13 +138,36 @@ func (_m *Manager) GetBlock(height int64) (*model.Block, error) {
 	return r0, r1
 }
 
-// GetBlocks provides a mock function with given fields: heights
-func (_m *Manager) GetBlocks(heights []int64) (map[int64]*model.Block, error) {
-	ret := _m.Called(heights)
+// GetBlockStats provides a mock function with given fields: ctx, fromHeight, toHeight
+func (_m *Manager) GetBlockStats(ctx context.Context, fromHeight int64, toHeight int64) (map[int64]*model.BlockStats, error) {
+	ret := _m.Called(ctx, fromHeight, toHeight)
+
+	var r0 map[int64]*model.BlockStats
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) map[int64]*model.BlockStats); ok {
+		r0 = rf(ctx, fromHeight, toHeight)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int64]*model.BlockStats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, fromHeight, toHeight)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBlocks provides a mock function with given fields: ctx, heights
+func (_m *Manager) GetBlocks(ctx context.Context, heights []int64) (map[int64]*model.Block, error) {
+	ret := _m.Called(ctx, heights)
 
 	var r0 map[int64]*model.Block
-	if rf, ok := ret.Get(0).(func([]int64) map[int64]*model.Block); ok {
-		r0 = rf(heights)
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) map[int64]*model.Block); ok {
+		r0 = rf(ctx, heights)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(map[int64]*model.Block)
@@ -61,8 +175,8 @@ func (_m *Manager) GetBlocks(heights []int64) (map[int64]*model.Block, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func([]int64) error); ok {
-		r1 = rf(heights)
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, heights)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -70,13 +184,13 @@ func (_m *Manager) GetBlocks(heights []int64) (map[int64]*model.Block, error) {
 	return r0, r1
 }
 
-// GetBlocksData provides a mock function with given fields: fromHeight, toHeight, interestedAddresses
-func (_m *Manager) GetBlocksData(fromHeight int64, toHeight int64, interestedAddresses []string) (map[int64]*model.Block, map[int64][]*model.TxIn, map[int64][]*model.TxOut, error) {
-	ret := _m.Called(fromHeight, toHeight, interestedAddresses)
+// GetBlocksData provides a mock function with given fields: ctx, fromHeight, toHeight, interestedAddresses
+func (_m *Manager) GetBlocksData(ctx context.Context, fromHeight int64, toHeight int64, interestedAddresses []string) (map[int64]*model.Block, map[int64][]*model.TxIn, map[int64][]*model.TxOut, error) {
+	ret := _m.Called(ctx, fromHeight, toHeight, interestedAddresses)
 
 	var r0 map[int64]*model.Block
-	if rf, ok := ret.Get(0).(func(int64, int64, []string) map[int64]*model.Block); ok {
-		r0 = rf(fromHeight, toHeight, interestedAddresses)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, []string) map[int64]*model.Block); ok {
+		r0 = rf(ctx, fromHeight, toHeight, interestedAddresses)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(map[int64]*model.Block)
@@ -84,8 +198,8 @@ func (_m *Manager) GetBlocksData(fromHeight int64, toHeight int64, interestedAdd
 	}
 
 	var r1 map[int64][]*model.TxIn
-	if rf, ok := ret.Get(1).(func(int64, int64, []string) map[int64][]*model.TxIn); ok {
-		r1 = rf(fromHeight, toHeight, interestedAddresses)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, []string) map[int64][]*model.TxIn); ok {
+		r1 = rf(ctx, fromHeight, toHeight, interestedAddresses)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(map[int64][]*model.TxIn)
@@ -93,8 +207,8 @@ func (_m *Manager) GetBlocksData(fromHeight int64, toHeight int64, interestedAdd
 	}
 
 	var r2 map[int64][]*model.TxOut
-	if rf, ok := ret.Get(2).(func(int64, int64, []string) map[int64][]*model.TxOut); ok {
-		r2 = rf(fromHeight, toHeight, interestedAddresses)
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64, []string) map[int64][]*model.TxOut); ok {
+		r2 = rf(ctx, fromHeight, toHeight, interestedAddresses)
 	} else {
 		if ret.Get(2) != nil {
 			r2 = ret.Get(2).(map[int64][]*model.TxOut)
@@ -102,8 +216,8 @@ func (_m *Manager) GetBlocksData(fromHeight int64, toHeight int64, interestedAdd
 	}
 
 	var r3 error
-	if rf, ok := ret.Get(3).(func(int64, int64, []string) error); ok {
-		r3 = rf(fromHeight, toHeight, interestedAddresses)
+	if rf, ok := ret.Get(3).(func(context.Context, int64, int64, []string) error); ok {
+		r3 = rf(ctx, fromHeight, toHeight, interestedAddresses)
 	} else {
 		r3 = ret.Error(3)
 	}
@@ -111,13 +225,86 @@ func (_m *Manager) GetBlocksData(fromHeight int64, toHeight int64, interestedAdd
 	return r0, r1, r2, r3
 }
 
-// GetLatestBlock provides a mock function with given fields:
-func (_m *Manager) GetLatestBlock() (*model.Block, error) {
-	ret := _m.Called()
+// GetBlocksDataInRange provides a mock function with given fields: ctx, fromHeight, toHeight, addresses
+func (_m *Manager) GetBlocksDataInRange(ctx context.Context, fromHeight int64, toHeight int64, addresses []string) ([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut, error) {
+	ret := _m.Called(ctx, fromHeight, toHeight, addresses)
+
+	var r0 []*model.Block
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, []string) []*model.Block); ok {
+		r0 = rf(ctx, fromHeight, toHeight, addresses)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Block)
+		}
+	}
+
+	var r1 []*model.Tx
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, []string) []*model.Tx); ok {
+		r1 = rf(ctx, fromHeight, toHeight, addresses)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]*model.Tx)
+		}
+	}
+
+	var r2 []*model.TxIn
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64, []string) []*model.TxIn); ok {
+		r2 = rf(ctx, fromHeight, toHeight, addresses)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).([]*model.TxIn)
+		}
+	}
+
+	var r3 []*model.TxOut
+	if rf, ok := ret.Get(3).(func(context.Context, int64, int64, []string) []*model.TxOut); ok {
+		r3 = rf(ctx, fromHeight, toHeight, addresses)
+	} else {
+		if ret.Get(3) != nil {
+			r3 = ret.Get(3).([]*model.TxOut)
+		}
+	}
+
+	var r4 error
+	if rf, ok := ret.Get(4).(func(context.Context, int64, int64, []string) error); ok {
+		r4 = rf(ctx, fromHeight, toHeight, addresses)
+	} else {
+		r4 = ret.Error(4)
+	}
+
+	return r0, r1, r2, r3, r4
+}
+
+// GetBlocksInRange provides a mock function with given fields: ctx, fromHeight, toHeight
+func (_m *Manager) GetBlocksInRange(ctx context.Context, fromHeight int64, toHeight int64) ([]*model.Block, error) {
+	ret := _m.Called(ctx, fromHeight, toHeight)
+
+	var r0 []*model.Block
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*model.Block); ok {
+		r0 = rf(ctx, fromHeight, toHeight)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Block)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, fromHeight, toHeight)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLatestBlock provides a mock function with given fields: ctx
+func (_m *Manager) GetLatestBlock(ctx context.Context) (*model.Block, error) {
+	ret := _m.Called(ctx)
 
 	var r0 *model.Block
-	if rf, ok := ret.Get(0).(func() *model.Block); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) *model.Block); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.Block)
@@ -125,8 +312,100 @@ func (_m *Manager) GetLatestBlock() (*model.Block, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReorgs provides a mock function with given fields: ctx, query
+func (_m *Manager) GetReorgs(ctx context.Context, query *model.ReorgQuery) ([]*model.Reorg, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 []*model.Reorg
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ReorgQuery) []*model.Reorg); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Reorg)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ReorgQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTx provides a mock function with given fields: ctx, hash
+func (_m *Manager) GetTx(ctx context.Context, hash string) (*model.TxDetail, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *model.TxDetail
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.TxDetail); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TxDetail)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUTXOs provides a mock function with given fields: ctx, query, coinbaseMaturity
+func (_m *Manager) GetUTXOs(ctx context.Context, query *model.UTXOsQuery, coinbaseMaturity int64) (*model.UTXOsPage, error) {
+	ret := _m.Called(ctx, query, coinbaseMaturity)
+
+	var r0 *model.UTXOsPage
+	if rf, ok := ret.Get(0).(func(context.Context, *model.UTXOsQuery, int64) *model.UTXOsPage); ok {
+		r0 = rf(ctx, query, coinbaseMaturity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UTXOsPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *model.UTXOsQuery, int64) error); ok {
+		r1 = rf(ctx, query, coinbaseMaturity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWatchLists provides a mock function with given fields: ctx
+func (_m *Manager) GetWatchLists(ctx context.Context) (map[string][]string, error) {
+	ret := _m.Called(ctx)
+
+	var r0 map[string][]string
+	if rf, ok := ret.Get(0).(func(context.Context) map[string][]string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -134,13 +413,48 @@ func (_m *Manager) GetLatestBlock() (*model.Block, error) {
 	return r0, r1
 }
 
-// Reorg provides a mock function with given fields: event
-func (_m *Manager) Reorg(event *model.Reorg) error {
-	ret := _m.Called(event)
+// RemoveWatchedAddresses provides a mock function with given fields: ctx, list, addresses
+func (_m *Manager) RemoveWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error) {
+	ret := _m.Called(ctx, list, addresses)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) int64); ok {
+		r0 = rf(ctx, list, addresses)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, list, addresses)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reorg provides a mock function with given fields: ctx, event
+func (_m *Manager) Reorg(ctx context.Context, event *model.Reorg) error {
+	ret := _m.Called(ctx, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Reorg) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceBlocksData provides a mock function with given fields: ctx, heights, blocks, txs, txIns, txOuts
+func (_m *Manager) ReplaceBlocksData(ctx context.Context, heights []int64, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	ret := _m.Called(ctx, heights, blocks, txs, txIns, txOuts)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*model.Reorg) error); ok {
-		r0 = rf(event)
+	if rf, ok := ret.Get(0).(func(context.Context, []int64, []*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut) error); ok {
+		r0 = rf(ctx, heights, blocks, txs, txIns, txOuts)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+)
+
+// heightsPerPartition is the range of heights of a partition of 'tx_ins' & 'tx_outs' in Postgres.
+const heightsPerPartition = 10000
+
+// minPartitioningVersion is the first Postgres version supporting unique indexes on partitioned tables.
+const minPartitioningVersion = 110000
+
+type partitionedTable struct {
+	name    string
+	columns func(db *gorm.DB) string
+	// columnNames are in the order of columns, the ones of the table may differ after columns were added & dropped
+	columnNames []string
+}
+
+var partitionedTables = []partitionedTable{
+	{name: model.TxIn{}.TableName(), columns: func(db *gorm.DB) string { return txInsColumns }, columnNames: model.TxIn{}.ColumnNames()},
+	{name: model.TxOut{}.TableName(), columns: txOutsColumns, columnNames: model.TxOut{}.ColumnNames()},
+}
+
+// partitionUp partitions the tables by range of height on Postgres. The existing rows are kept in
+// a legacy partition covering the heights up to a partition bound, later partitions are created while indexing.
+// The unique keys have the partition key 'height' already, as required by Postgres. Other DBs are left as they are.
+func partitionUp(db *gorm.DB) error {
+	partitioning, err := supportPartitioning(db)
+	if err != nil {
+		return err
+	}
+	if !partitioning {
+		return nil
+	}
+
+	for _, t := range partitionedTables {
+		legacy := t.name + "_legacy"
+		err = execAll(db,
+			fmt.Sprintf("ALTER TABLE %s RENAME TO %s", t.name, legacy),
+			// Attached to the index of the partitioned table with the partition
+			fmt.Sprintf("ALTER INDEX IF EXISTS idx_%s_height_tx_hash_tx_index RENAME TO idx_%s_height_tx_hash_tx_index", t.name, legacy),
+			fmt.Sprintf("CREATE TABLE %s (%s) PARTITION BY RANGE (height)", t.name, t.columns(db)),
+			fmt.Sprintf("CREATE UNIQUE INDEX idx_%s_height_tx_hash_tx_index ON %s (height, tx_hash, tx_index)", t.name, t.name),
+		)
+		if err != nil {
+			return err
+		}
+
+		var maxHeight struct {
+			Height *int64
+		}
+		err = db.Raw(fmt.Sprintf("SELECT MAX(height) AS height FROM %s", legacy)).Scan(&maxHeight).Error
+		if err != nil {
+			return fmt.Errorf("failed to Get Max Height of '%s': %v", legacy, err)
+		}
+		if maxHeight.Height == nil {
+			err = execAll(db, fmt.Sprintf("DROP TABLE %s", legacy))
+		} else {
+			bound := (*maxHeight.Height/heightsPerPartition + 1) * heightsPerPartition
+			err = execAll(db, fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (MINVALUE) TO (%d)", t.name, legacy, bound))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func partitionDown(db *gorm.DB) error {
+	for _, t := range partitionedTables {
+		partitioned, err := isPartitioned(db, t.name)
+		if err != nil {
+			return err
+		}
+		if partitioned {
+			tmp := t.name + "_partitioned"
+			names := strings.Join(t.columnNames, ",")
+			err = execAll(db,
+				fmt.Sprintf("ALTER TABLE %s RENAME TO %s", t.name, tmp),
+				fmt.Sprintf("ALTER INDEX idx_%s_height_tx_hash_tx_index RENAME TO idx_%s_height_tx_hash_tx_index", t.name, tmp),
+				fmt.Sprintf("CREATE TABLE %s (%s)", t.name, t.columns(db)),
+				fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", t.name, names, names, tmp),
+				fmt.Sprintf("DROP TABLE %s", tmp),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		// The key of migration 'add_unique_keys', the rows of a partitioned table having it already
+		err = execAll(db, fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS idx_%s_height_tx_hash_tx_index ON %s (height, tx_hash, tx_index)", t.name, t.name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func supportPartitioning(db *gorm.DB) (bool, error) {
+	if db.Dialect().GetName() != "postgres" {
+		return false, nil
+	}
+
+	var version struct {
+		Version int
+	}
+	err := db.Raw("SELECT current_setting('server_version_num')::int AS version").Scan(&version).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to Get Postgres version: %v", err)
+	}
+	if version.Version < minPartitioningVersion {
+		log.L().Warn("Postgres version not supporting partitioning, tables are not partitioned", zap.Int("Version", version.Version))
+		return false, nil
+	}
+	return true, nil
+}
+
+func isPartitioned(db *gorm.DB, table string) (bool, error) {
+	if db.Dialect().GetName() != "postgres" {
+		return false, nil
+	}
+
+	var count struct {
+		Count int
+	}
+	err := db.Raw("SELECT COUNT(*) AS count FROM pg_class WHERE relname = ? AND relkind = 'p'", table).Scan(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to Check partitioning of '%s': %v", table, err)
+	}
+	return count.Count > 0, nil
+}
+
+// partitioner creates the partitions of next heights as the tip advances.
+type partitioner struct {
+	mu sync.Mutex
+	db *gorm.DB
+	// upTo is the bound of the existing partitions, heights from it have no partition yet
+	upTo int64
+}
+
+// newPartitioner returns nil if the tables are not partitioned.
+func newPartitioner(db *gorm.DB) (*partitioner, error) {
+	partitioned, err := isPartitioned(db, partitionedTables[0].name)
+	if err != nil || !partitioned {
+		return nil, err
+	}
+
+	// The bounds are like "FOR VALUES FROM (MINVALUE) TO (10000)"
+	var upTo struct {
+		UpTo int64
+	}
+	err = db.Raw(`SELECT COALESCE(MAX(substring(pg_get_expr(c.relpartbound, c.oid) FROM 'TO \((\d+)\)')::bigint), 0) AS up_to
+		FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = ?::regclass`, partitionedTables[0].name).Scan(&upTo).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Partition Bounds of '%s': %v", partitionedTables[0].name, err)
+	}
+	return &partitioner{db: db, upTo: upTo.UpTo}, nil
+}
+
+// ensure creates the missing partitions up to height, outside of any DB transaction to not lose them on rollback.
+func (p *partitioner) ensure(height int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, from := range partitionBounds(p.upTo, height) {
+		to := from + heightsPerPartition
+		for _, t := range partitionedTables {
+			partition := fmt.Sprintf("%s_p%d", t.name, from/heightsPerPartition)
+			err := p.db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d)", partition, t.name, from, to)).Error
+			if err != nil {
+				return fmt.Errorf("failed to Create Partition '%s': %v", partition, err)
+			}
+		}
+		p.upTo = to
+		log.L().Info("Created Partitions", zap.Int64("From Height", from), zap.Int64("To Height", to))
+	}
+	return nil
+}
+
+// partitionBounds returns the lower bounds of the partitions needed for height, given the bound of the existing ones.
+func partitionBounds(upTo, height int64) []int64 {
+	var bounds []int64
+	for from := upTo; from <= height; from += heightsPerPartition {
+		bounds = append(bounds, from)
+	}
+	return bounds
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"time"
+)
+
+func validateReorgQuery(query *model.ReorgQuery) error {
+	if query.FromHeight < 0 || query.FromHeight > query.ToHeight {
+		return common.ErrInvalidRange
+	}
+	if !query.FromTime.IsZero() && !query.ToTime.IsZero() && query.ToTime.Before(query.FromTime) {
+		return common.ErrInvalidRange
+	}
+	return nil
+}
+
+// matchReorg tells if the reorg orphaned a block in the height range & happened in the time range of the query.
+func matchReorg(reorg *model.Reorg, query *model.ReorgQuery) bool {
+	if reorg.ToHeight < query.FromHeight || reorg.FromHeight > query.ToHeight {
+		return false
+	}
+	if !query.FromTime.IsZero() && reorg.CreatedAt.Before(query.FromTime) {
+		return false
+	}
+	if !query.ToTime.IsZero() && reorg.CreatedAt.After(query.ToTime) {
+		return false
+	}
+	return true
+}
+
+// stampReorg sets the time of the reorg, if not given.
+func stampReorg(event *model.Reorg) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+}
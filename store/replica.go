@@ -0,0 +1,287 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common"
+	"github.com/darkknightbk52/btc-indexer/common/log"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// trackedManager is a Manager with the height of its latest block, checked at most once per check time.
+type trackedManager struct {
+	Manager
+	name      string
+	mu        sync.Mutex
+	height    int64
+	checkedAt time.Time
+}
+
+// latestHeight returns the height of the latest block, -1 if none or the DB is not reachable.
+func (t *trackedManager) latestHeight(ctx context.Context, checkTime time.Duration) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.checkedAt.IsZero() && time.Since(t.checkedAt) < checkTime {
+		return t.height
+	}
+	block, err := t.Manager.GetLatestBlock(ctx)
+	if err != nil && ctx.Err() != nil {
+		// Canceled by the caller, which tells nothing about the DB
+		return t.height
+	}
+	t.checkedAt = time.Now()
+	switch {
+	case err == nil:
+		t.height = block.Height
+	case err == common.ErrNotFound:
+		t.height = -1
+	default:
+		log.L().Warn("Failed to Get Latest Block", zap.String("DB", t.name), zap.Error(err))
+		t.height = -1
+	}
+	return t.height
+}
+
+// replicaManager writes to the primary, and reads from a replica not lagging behind the primary by more than
+// 'maxLag' blocks, the primary otherwise. The data of blocks are written with their txs in a single DB transaction,
+// which a replica applies at once, so a replica having a block has its txs too. Reads of the data at a height
+// are routed to a replica having it, and every method is routed explicitly so a new read can't go to the primary
+// unnoticed.
+type replicaManager struct {
+	primary   *trackedManager
+	replicas  []*trackedManager
+	maxLag    int64
+	checkTime time.Duration
+	next      *uint32
+	// pinned is the DB of the reads of a manager returned by Pin, nil otherwise
+	pinned *pinnedReader
+}
+
+// pinnedReader is the DB chosen by the first read of a pinned manager.
+type pinnedReader struct {
+	mu sync.Mutex
+	db *trackedManager
+}
+
+// NewReadManager returns a Manager reading from the replicas of the config, writing to & falling back on the primary.
+// It's the primary itself if no replica is configured.
+func NewReadManager(cfg Config, primary Manager) (Manager, error) {
+	dsns := cfg.ReplicaDSNs()
+	if len(dsns) == 0 {
+		return primary, nil
+	}
+
+	replicas := make([]Manager, 0, len(dsns))
+	for _, dsn := range dsns {
+		r, err := newReplica("postgres", dsn, cfg.ConfigurePool)
+		if err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, r)
+	}
+	return newReplicaManager(primary, replicas, cfg.ReplicaMaxLag, cfg.ReplicaCheckTime()), nil
+}
+
+// newReplica opens a read-only DB, its schema is migrated through the primary.
+func newReplica(dialect, dsn string, pool func(db *sql.DB)) (Manager, error) {
+	db, err := gorm.Open(dialect, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Open connection with replica DB '%s': %v", dsnTarget(dsn), err)
+	}
+	pool(db.DB())
+	return &manager{db: db}, nil
+}
+
+func newReplicaManager(primary Manager, replicas []Manager, maxLag int64, checkTime time.Duration) *replicaManager {
+	m := &replicaManager{
+		primary:   &trackedManager{Manager: primary, name: "primary"},
+		maxLag:    maxLag,
+		checkTime: checkTime,
+		next:      new(uint32),
+	}
+	for i, r := range replicas {
+		m.replicas = append(m.replicas, &trackedManager{Manager: r, name: fmt.Sprintf("replica %d", i)})
+	}
+	return m
+}
+
+// Pin returns a Manager reading from a single DB for the life of a stream, so that a read never sees the data
+// of a replica behind the one of a previous read. The DB is the replica chosen by the first read, then the primary
+// for good once the replica lags too much, misses a requested height, or has another block at the height.
+// It's the manager itself if it doesn't read from replicas.
+func Pin(m Manager) Manager {
+	r, ok := m.(*replicaManager)
+	if !ok {
+		return m
+	}
+	pinned := *r
+	pinned.pinned = new(pinnedReader)
+	return &pinned
+}
+
+// reader returns the DB to read the data up to 'minHeight' from, the pinned one of a pinned manager.
+func (m *replicaManager) reader(ctx context.Context, minHeight int64) *trackedManager {
+	if m.pinned == nil {
+		return m.pick(ctx, minHeight)
+	}
+
+	m.pinned.mu.Lock()
+	defer m.pinned.mu.Unlock()
+	switch {
+	case m.pinned.db == nil:
+		m.pinned.db = m.pick(ctx, minHeight)
+	case m.pinned.db != m.primary && !m.usable(ctx, m.pinned.db, minHeight):
+		log.L().Info("Pin reads to primary", zap.String("From", m.pinned.db.name), zap.Int64("Height", minHeight))
+		m.pinned.db = m.primary
+	}
+	return m.pinned.db
+}
+
+// fallback makes the reads of a pinned manager go to the primary, as the replica had another block than the primary.
+func (m *replicaManager) fallback() {
+	if m.pinned == nil {
+		return
+	}
+	m.pinned.mu.Lock()
+	m.pinned.db = m.primary
+	m.pinned.mu.Unlock()
+}
+
+// pick returns a replica having the block at 'minHeight' & not lagging too much, in turns, the primary if none.
+func (m *replicaManager) pick(ctx context.Context, minHeight int64) *trackedManager {
+	start := int(atomic.AddUint32(m.next, 1))
+	for i := range m.replicas {
+		r := m.replicas[(start+i)%len(m.replicas)]
+		if m.usable(ctx, r, minHeight) {
+			return r
+		}
+	}
+	return m.primary
+}
+
+// usable tells whether the replica has the block at 'minHeight' & doesn't lag too much.
+func (m *replicaManager) usable(ctx context.Context, r *trackedManager, minHeight int64) bool {
+	primaryHeight := m.primary.latestHeight(ctx, m.checkTime)
+	height := r.latestHeight(ctx, m.checkTime)
+	return height >= 0 && height >= minHeight && height >= primaryHeight-m.maxLag
+}
+
+// maxHeight returns the highest of the heights, 0 if none.
+func maxHeight(heights []int64) int64 {
+	var max int64
+	for _, h := range heights {
+		if h > max {
+			max = h
+		}
+	}
+	return max
+}
+
+func (m *replicaManager) Reorg(ctx context.Context, event *model.Reorg) error {
+	return m.primary.Reorg(ctx, event)
+}
+
+func (m *replicaManager) AddBlocksData(ctx context.Context, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	return m.primary.AddBlocksData(ctx, blocks, txs, txIns, txOuts)
+}
+
+func (m *replicaManager) ReplaceBlocksData(ctx context.Context, heights []int64, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	return m.primary.ReplaceBlocksData(ctx, heights, blocks, txs, txIns, txOuts)
+}
+
+func (m *replicaManager) AddWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error) {
+	return m.primary.AddWatchedAddresses(ctx, list, addresses)
+}
+
+func (m *replicaManager) RemoveWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error) {
+	return m.primary.RemoveWatchedAddresses(ctx, list, addresses)
+}
+
+// GetWatchLists reads from the primary, the watch lists being edited through it.
+func (m *replicaManager) GetWatchLists(ctx context.Context) (map[string][]string, error) {
+	return m.primary.GetWatchLists(ctx)
+}
+
+func (m *replicaManager) GetLatestBlock(ctx context.Context) (*model.Block, error) {
+	return m.reader(ctx, 0).GetLatestBlock(ctx)
+}
+
+func (m *replicaManager) GetBlock(ctx context.Context, height int64) (*model.Block, error) {
+	return m.reader(ctx, height).GetBlock(ctx, height)
+}
+
+func (m *replicaManager) GetBlocks(ctx context.Context, heights []int64) (map[int64]*model.Block, error) {
+	return m.reader(ctx, maxHeight(heights)).GetBlocks(ctx, heights)
+}
+
+// GetBlocksData checks the block at 'toHeight' read from a replica against the one of the primary, as the replica
+// may not have replicated a reorg of the heights yet, then reads from the primary on mismatch. The blocks below are
+// the ancestors of the checked one in a DB, so they match too.
+func (m *replicaManager) GetBlocksData(ctx context.Context, fromHeight, toHeight int64, interestedAddresses []string) (map[int64]*model.Block, map[int64][]*model.TxIn, map[int64][]*model.TxOut, error) {
+	r := m.reader(ctx, toHeight)
+	blocks, txIns, txOuts, err := r.GetBlocksData(ctx, fromHeight, toHeight, interestedAddresses)
+	if err != nil || r == m.primary {
+		return blocks, txIns, txOuts, err
+	}
+
+	block, err := m.primary.GetBlock(ctx, toHeight)
+	if err != nil && err != common.ErrNotFound {
+		return nil, nil, nil, fmt.Errorf("failed to Get Block of primary, height '%d': %v", toHeight, err)
+	}
+	var primaryHash, replicaHash string
+	if block != nil {
+		primaryHash = block.Hash
+	}
+	if blocks[toHeight] != nil {
+		replicaHash = blocks[toHeight].Hash
+	}
+	if primaryHash != replicaHash {
+		log.L().Info("Read Blocks Data from primary, replica has another block", zap.String("DB", r.name), zap.Int64("Height", toHeight))
+		m.fallback()
+		return m.primary.GetBlocksData(ctx, fromHeight, toHeight, interestedAddresses)
+	}
+	return blocks, txIns, txOuts, nil
+}
+
+func (m *replicaManager) GetBlocksInRange(ctx context.Context, fromHeight, toHeight int64) ([]*model.Block, error) {
+	return m.reader(ctx, toHeight).GetBlocksInRange(ctx, fromHeight, toHeight)
+}
+
+func (m *replicaManager) GetBlockStats(ctx context.Context, fromHeight, toHeight int64) (map[int64]*model.BlockStats, error) {
+	return m.reader(ctx, toHeight).GetBlockStats(ctx, fromHeight, toHeight)
+}
+
+func (m *replicaManager) GetBlocksDataInRange(ctx context.Context, fromHeight, toHeight int64, addresses []string) ([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut, error) {
+	return m.reader(ctx, toHeight).GetBlocksDataInRange(ctx, fromHeight, toHeight, addresses)
+}
+
+func (m *replicaManager) GetTx(ctx context.Context, hash string) (*model.TxDetail, error) {
+	return m.reader(ctx, 0).GetTx(ctx, hash)
+}
+
+func (m *replicaManager) GetAddressHistory(ctx context.Context, query *model.AddressHistoryQuery) (*model.AddressHistory, error) {
+	return m.reader(ctx, 0).GetAddressHistory(ctx, query)
+}
+
+func (m *replicaManager) GetUTXOs(ctx context.Context, query *model.UTXOsQuery, coinbaseMaturity int64) (*model.UTXOsPage, error) {
+	return m.reader(ctx, 0).GetUTXOs(ctx, query, coinbaseMaturity)
+}
+
+func (m *replicaManager) GetAddressBalance(ctx context.Context, address string) (*model.AddressBalance, error) {
+	return m.reader(ctx, 0).GetAddressBalance(ctx, address)
+}
+
+func (m *replicaManager) GetAddressBalanceAtHeight(ctx context.Context, address string, height int64) (*model.AddressBalance, error) {
+	return m.reader(ctx, height).GetAddressBalanceAtHeight(ctx, address, height)
+}
+
+func (m *replicaManager) GetReorgs(ctx context.Context, query *model.ReorgQuery) ([]*model.Reorg, error) {
+	return m.reader(ctx, 0).GetReorgs(ctx, query)
+}
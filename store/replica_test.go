@@ -0,0 +1,98 @@
+package store
+
+import (
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/model"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestReplicaManager(t *testing.T) {
+	RegisterTestingT(t)
+
+	primary, lagging := newMemoryManager(), newMemoryManager()
+	for height := int64(1); height <= 3; height++ {
+		block := &model.Block{Height: height, Hash: hashOf("primary"), PreviousHash: hashOf("primary")}
+		err := primary.AddBlocksData(ctx, []*model.Block{block}, nil, nil, nil)
+		Expect(err).Should(Succeed())
+		if height < 3 {
+			block = &model.Block{Height: height, Hash: hashOf("replica"), PreviousHash: hashOf("replica")}
+			err = lagging.AddBlocksData(ctx, []*model.Block{block}, nil, nil, nil)
+			Expect(err).Should(Succeed())
+		}
+	}
+	m := newReplicaManager(primary, []Manager{lagging}, 1, 0)
+
+	// Reads go to a replica lagging by up to 'maxLag' blocks
+	block, err := m.GetLatestBlock(ctx)
+	Expect(err).Should(Succeed())
+	Expect(block.Hash).Should(Equal(hashOf("replica")))
+
+	// The data of a height not replicated yet are read from the primary
+	block, err = m.GetBlock(ctx, 3)
+	Expect(err).Should(Succeed())
+	Expect(block.Hash).Should(Equal(hashOf("primary")))
+	blocksByHeight, err := m.GetBlocks(ctx, []int64{1, 3})
+	Expect(err).Should(Succeed())
+	Expect(blocksByHeight[1].Hash).Should(Equal(hashOf("primary")))
+	blocks, _, _, err := m.GetBlocksData(ctx, 3, 3, nil)
+	Expect(err).Should(Succeed())
+	Expect(blocks[3].Hash).Should(Equal(hashOf("primary")))
+	// The replica has other blocks than the primary at the heights
+	blocks, _, _, err = m.GetBlocksData(ctx, 1, 2, nil)
+	Expect(err).Should(Succeed())
+	Expect(blocks[2].Hash).Should(Equal(hashOf("primary")))
+
+	// Writes go to the primary, then the replica lags too much
+	for height := int64(4); height <= 5; height++ {
+		err = m.AddBlocksData(ctx, []*model.Block{{Height: height, Hash: hashOf("primary"), PreviousHash: hashOf("primary")}}, nil, nil, nil)
+		Expect(err).Should(Succeed())
+	}
+	block, err = m.GetLatestBlock(ctx)
+	Expect(err).Should(Succeed())
+	Expect(block.Height).Should(Equal(int64(5)))
+	Expect(block.Hash).Should(Equal(hashOf("primary")))
+}
+
+func TestReplicaManager_Pin(t *testing.T) {
+	RegisterTestingT(t)
+
+	primary, replica := newMemoryManager(), newMemoryManager()
+	for height := int64(1); height <= 3; height++ {
+		block := &model.Block{Height: height, Hash: hashOf(fmt.Sprint(height)), PreviousHash: hashOf(fmt.Sprint(height - 1))}
+		err := primary.AddBlocksData(ctx, []*model.Block{block}, nil, nil, nil)
+		Expect(err).Should(Succeed())
+		if height < 3 {
+			err = replica.AddBlocksData(ctx, []*model.Block{block}, nil, nil, nil)
+			Expect(err).Should(Succeed())
+		}
+	}
+	m := newReplicaManager(primary, []Manager{replica}, 1, 0)
+	Expect(Pin(primary)).Should(BeIdenticalTo(primary))
+
+	// The reads stay on the replica chosen by the first one
+	pinned := Pin(m)
+	block, err := pinned.GetLatestBlock(ctx)
+	Expect(err).Should(Succeed())
+	Expect(block.Height).Should(Equal(int64(2)))
+	blocks, _, _, err := pinned.GetBlocksData(ctx, 1, 2, nil)
+	Expect(err).Should(Succeed())
+	Expect(blocks).Should(HaveLen(2))
+
+	// Then go to the primary for good once the replica misses a height
+	block, err = pinned.GetBlock(ctx, 3)
+	Expect(err).Should(Succeed())
+	Expect(block.Height).Should(Equal(int64(3)))
+	err = replica.AddBlocksData(ctx, []*model.Block{{Height: 3, Hash: hashOf("3"), PreviousHash: hashOf("2")}}, nil, nil, nil)
+	Expect(err).Should(Succeed())
+	err = primary.AddBlocksData(ctx, []*model.Block{{Height: 4, Hash: hashOf("4"), PreviousHash: hashOf("3")}}, nil, nil, nil)
+	Expect(err).Should(Succeed())
+	block, err = pinned.GetLatestBlock(ctx)
+	Expect(err).Should(Succeed())
+	Expect(block.Height).Should(Equal(int64(4)))
+
+	// Another pinned manager chooses its own
+	block, err = Pin(m).GetLatestBlock(ctx)
+	Expect(err).Should(Succeed())
+	Expect(block.Height).Should(Equal(int64(3)))
+}
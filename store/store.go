@@ -1,6 +1,8 @@
 package store
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"github.com/darkknightbk52/btc-indexer/common"
 	"github.com/darkknightbk52/btc-indexer/model"
@@ -9,67 +11,103 @@ import (
 )
 
 type Manager interface {
-	GetLatestBlock() (*model.Block, error)
-	GetBlock(height int64) (*model.Block, error)
-	GetBlocks(heights []int64) (map[int64]*model.Block, error)
-	Reorg(event *model.Reorg) error
-	AddBlocksData([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut) error
-	GetBlocksData(fromHeight, toHeight int64, interestedAddresses []string) (map[int64]*model.Block, map[int64][]*model.TxIn, map[int64][]*model.TxOut, error)
+	GetLatestBlock(ctx context.Context) (*model.Block, error)
+	GetBlock(ctx context.Context, height int64) (*model.Block, error)
+	GetBlocks(ctx context.Context, heights []int64) (map[int64]*model.Block, error)
+	Reorg(ctx context.Context, event *model.Reorg) error
+	AddBlocksData(ctx context.Context, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error
+	GetBlocksData(ctx context.Context, fromHeight, toHeight int64, interestedAddresses []string) (map[int64]*model.Block, map[int64][]*model.TxIn, map[int64][]*model.TxOut, error)
+	// GetBlocksInRange returns all stored blocks from height 'fromHeight' to 'toHeight' in ascending order, duplicated heights included.
+	GetBlocksInRange(ctx context.Context, fromHeight, toHeight int64) ([]*model.Block, error)
+	GetBlockStats(ctx context.Context, fromHeight, toHeight int64) (map[int64]*model.BlockStats, error)
+	// GetBlocksDataInRange returns all data from height 'fromHeight' to 'toHeight' in order of height, then of hash,
+	// or tx hash & tx index. If addresses are given, only their ins & outs are returned, with the txs having them.
+	GetBlocksDataInRange(ctx context.Context, fromHeight, toHeight int64, addresses []string) ([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut, error)
+	// ReplaceBlocksData deletes all data at the heights, then adds the given data instead.
+	ReplaceBlocksData(ctx context.Context, heights []int64, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error
+	// GetTx returns the tx of the hash with its block, ins & outs, common.ErrNotFound if not indexed.
+	GetTx(ctx context.Context, hash string) (*model.TxDetail, error)
+	// GetAddressHistory returns a page of the txs debiting or crediting an address, common.ErrInvalidCursor if the cursor is not valid.
+	GetAddressHistory(ctx context.Context, query *model.AddressHistoryQuery) (*model.AddressHistory, error)
+	// GetUTXOs returns a page of the tx outs of the addresses not spent by any tx in, common.ErrInvalidCursor if the cursor is not valid.
+	// A coinbase tx out needs 'coinbaseMaturity' confirmations to be mature.
+	GetUTXOs(ctx context.Context, query *model.UTXOsQuery, coinbaseMaturity int64) (*model.UTXOsPage, error)
+	// GetAddressBalance returns the balance of an address, common.ErrNotFound if it has no tx.
+	GetAddressBalance(ctx context.Context, address string) (*model.AddressBalance, error)
+	// GetAddressBalanceAtHeight returns the balance of an address after the block at the height, common.ErrNotFound if it has no tx until then.
+	GetAddressBalanceAtHeight(ctx context.Context, address string, height int64) (*model.AddressBalance, error)
+	// GetReorgs returns the reorgs matching the query in the order they happened.
+	GetReorgs(ctx context.Context, query *model.ReorgQuery) ([]*model.Reorg, error)
+	// AddWatchedAddresses adds the addresses to the named watch list, returning the number of ones not in it before.
+	AddWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error)
+	// RemoveWatchedAddresses removes the addresses from the named watch list, returning the number of ones in it before.
+	RemoveWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error)
+	// GetWatchLists returns the addresses of all watch lists by name, in ascending order.
+	GetWatchLists(ctx context.Context) (map[string][]string, error)
 }
 
 type manager struct {
-	db *gorm.DB
+	db          *gorm.DB
+	partitioner *partitioner
+}
+
+// NewManager returns the embedded DB manager if a bbolt file is configured, the Postgres one otherwise.
+func NewManager(cfg Config) (Manager, error) {
+	if len(cfg.BoltFile) > 0 {
+		return NewBoltManager(cfg.BoltFile)
+	}
+	return newManager("postgres", cfg.DSN(), cfg.ConfigurePool)
 }
 
 func NewPostgresManager(connectionString string) (Manager, error) {
-	return newManager("postgres", connectionString)
+	return newManager("postgres", connectionString, nil)
 }
 
-func newManager(dialect, dsn string) (Manager, error) {
+// newManager opens the DB, configuring its pool of connections if 'pool' is given, then checks that it's migrated
+// to the schema version of the binary.
+func newManager(dialect, dsn string, pool func(db *sql.DB)) (Manager, error) {
 	db, err := gorm.Open(dialect, dsn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to Open connection with postgres DB, DSN '%s': %v", dsn, err)
+		return nil, fmt.Errorf("failed to Open connection with postgres DB '%s': %v", dsnTarget(dsn), err)
+	}
+	if pool != nil {
+		pool(db.DB())
 	}
 
-	tables := []interface{}{
-		model.Block{},
-		model.Tx{},
-		model.TxIn{},
-		model.TxOut{},
-		model.Reorg{},
+	err = checkSchema(db)
+	if err != nil {
+		return nil, err
 	}
-	err = db.AutoMigrate(tables...).Error
+
+	p, err := newPartitioner(db)
 	if err != nil {
-		var tableNames string
-		for _, t := range tables {
-			tableNames = fmt.Sprintf("%s %T", tableNames, t)
-		}
-		return nil, fmt.Errorf("failed to Auto Migrate tables '%s': %v", tableNames, err)
+		return nil, err
 	}
-	return &manager{db: db}, nil
+	return &manager{db: db, partitioner: p}, nil
 }
 
-func (m *manager) GetLatestBlock() (*model.Block, error) {
-	b := new(model.Block)
-	err := m.db.Order("height DESC").Limit(1).First(b).Error
-	if err == gorm.ErrRecordNotFound {
-		return nil, common.ErrNotFound
+func (m *manager) GetLatestBlock(ctx context.Context) (*model.Block, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return b, err
+	return firstBlock(db.Order("height DESC"))
 }
 
-func (m *manager) GetBlock(height int64) (*model.Block, error) {
-	b := new(model.Block)
-	err := m.db.Where(model.Block{Height: height}).First(b).Error
-	if err == gorm.ErrRecordNotFound {
-		return nil, common.ErrNotFound
+func (m *manager) GetBlock(ctx context.Context, height int64) (*model.Block, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return b, err
+	return firstBlock(db.Where("height = (?)", height))
 }
 
-func (m *manager) GetBlocks(heights []int64) (map[int64]*model.Block, error) {
-	blocks := make([]*model.Block, 0, len(heights))
-	err := m.db.Where("height IN (?)", heights).Find(&blocks).Error
+func (m *manager) GetBlocks(ctx context.Context, heights []int64) (map[int64]*model.Block, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := findBlocks(db.Where("height IN (?)", heights))
 	if err != nil {
 		return nil, err
 	}
@@ -81,24 +119,51 @@ func (m *manager) GetBlocks(heights []int64) (map[int64]*model.Block, error) {
 	return result, nil
 }
 
-func (m *manager) newTxManager() (*txManager, error) {
-	tx := m.db.Begin()
-	if err := tx.Error; err != nil {
+func (m *manager) newTxManager(ctx context.Context) (*txManager, error) {
+	tx, err := m.db.DB().BeginTx(ctx, nil)
+	if err != nil {
 		return nil, fmt.Errorf("failed to Begin DB transaction: %v", err)
 	}
+	db, err := gorm.Open(m.db.Dialect().GetName(), &ctxTx{ctx: ctx, tx: tx})
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("failed to Open DB transaction with context: %v", err)
+	}
 	return &txManager{
-		db:        tx,
+		db:        db,
 		committed: false,
+		bulkLoad:  m.db.Dialect().GetName() == "postgres",
 	}, nil
 }
 
-func (m *manager) Reorg(event *model.Reorg) error {
-	txm, err := m.newTxManager()
+// ensurePartitions creates the partitions for the heights of the blocks if needed.
+func (m *manager) ensurePartitions(blocks []*model.Block) error {
+	if m.partitioner == nil || len(blocks) == 0 {
+		return nil
+	}
+	maxHeight := blocks[0].Height
+	for _, b := range blocks {
+		if b.Height > maxHeight {
+			maxHeight = b.Height
+		}
+	}
+	return m.partitioner.ensure(maxHeight)
+}
+
+func (m *manager) Reorg(ctx context.Context, event *model.Reorg) error {
+	txm, err := m.newTxManager(ctx)
 	if err != nil {
 		return err
 	}
 	defer txm.maybeRollback()
 
+	stampReorg(event)
+	err = txm.db.Model(model.Tx{}).Where("height >= (?)", event.FromHeight).Count(&event.TxNo).Error
+	if err != nil {
+		return fmt.Errorf("failed to Count Txs from height '%d': %v", event.FromHeight, err)
+	}
+
+	// Partitions of 'tx_ins' & 'tx_outs' below the height are pruned, so only the tail ones are touched
 	for _, table := range []interface{}{
 		model.Block{},
 		model.Tx{},
@@ -111,6 +176,11 @@ func (m *manager) Reorg(event *model.Reorg) error {
 		}
 	}
 
+	err = txm.removeBalanceChanges("height >= (?)", event.FromHeight)
+	if err != nil {
+		return err
+	}
+
 	err = txm.db.Create(event).Error
 	if err != nil {
 		return fmt.Errorf("failed to Create Reorg event '%v': %v", event, err)
@@ -119,8 +189,18 @@ func (m *manager) Reorg(event *model.Reorg) error {
 	return txm.commit()
 }
 
-func (m *manager) AddBlocksData(blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
-	txm, err := m.newTxManager()
+func (m *manager) AddBlocksData(ctx context.Context, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	err := validateBlocksData(blocks, txs, txIns, txOuts)
+	if err != nil {
+		return err
+	}
+
+	err = m.ensurePartitions(blocks)
+	if err != nil {
+		return err
+	}
+
+	txm, err := m.newTxManager(ctx)
 	if err != nil {
 		return err
 	}
@@ -146,21 +226,29 @@ func (m *manager) AddBlocksData(blocks []*model.Block, txs []*model.Tx, txIns []
 		return fmt.Errorf("failed to Create TxOuts, TxOuts No '%d': %v", len(txOuts), err)
 	}
 
+	err = txm.addBalanceChanges(txIns, txOuts)
+	if err != nil {
+		return err
+	}
+
 	return txm.commit()
 }
 
-func (m *manager) GetBlocksData(fromHeight, toHeight int64, interestedAddresses []string) (map[int64]*model.Block, map[int64][]*model.TxIn, map[int64][]*model.TxOut, error) {
+func (m *manager) GetBlocksData(ctx context.Context, fromHeight, toHeight int64, interestedAddresses []string) (map[int64]*model.Block, map[int64][]*model.TxIn, map[int64][]*model.TxOut, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	heights := make([]int64, 0, toHeight-fromHeight+1)
 	for i := fromHeight; i <= toHeight; i++ {
 		heights = append(heights, i)
 	}
-	blocks, err := m.GetBlocks(heights)
+	blocks, err := m.GetBlocks(ctx, heights)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to Get Blocks for Heights '%v': %v", heights, err)
 	}
 
-	var txIns []*model.TxIn
-	err = m.db.Where("height >= (?) AND height <= (?) AND address in (?)", fromHeight, toHeight, interestedAddresses).Find(&txIns).Error
+	txIns, err := findTxIns(db.Where("height >= (?) AND height <= (?) AND address in (?)", fromHeight, toHeight, interestedAddresses))
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to Get TxIns from '%d' to '%d' height and number of addresses '%d': %v", fromHeight, toHeight, len(interestedAddresses), err)
 	}
@@ -169,8 +257,7 @@ func (m *manager) GetBlocksData(fromHeight, toHeight int64, interestedAddresses
 		txInsResult[txIn.Height] = append(txInsResult[txIn.Height], txIn)
 	}
 
-	var txOuts []*model.TxOut
-	err = m.db.Where("height >= (?) AND height <= (?) AND address in (?)", fromHeight, toHeight, interestedAddresses).Find(&txOuts).Error
+	txOuts, err := findTxOuts(db.Where("height >= (?) AND height <= (?) AND address in (?)", fromHeight, toHeight, interestedAddresses))
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to Get TxOuts from '%d' to '%d' height and number of addresses '%d': %v", fromHeight, toHeight, len(interestedAddresses), err)
 	}
@@ -181,3 +268,359 @@ func (m *manager) GetBlocksData(fromHeight, toHeight int64, interestedAddresses
 
 	return blocks, txInsResult, txOutsResult, nil
 }
+
+func (m *manager) GetBlocksInRange(ctx context.Context, fromHeight, toHeight int64) ([]*model.Block, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := findBlocks(db.Where("height >= (?) AND height <= (?)", fromHeight, toHeight).Order("height ASC"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Blocks from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+	return blocks, nil
+}
+
+func (m *manager) GetBlockStats(ctx context.Context, fromHeight, toHeight int64) (map[int64]*model.BlockStats, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[int64]*model.BlockStats)
+	getStats := func(height int64) *model.BlockStats {
+		s, ok := stats[height]
+		if !ok {
+			s = &model.BlockStats{Height: height}
+			stats[height] = s
+		}
+		return s
+	}
+
+	rows, err := db.Table(model.Tx{}.TableName()).
+		Select("height, COUNT(*)").
+		Where("height >= (?) AND height <= (?)", fromHeight, toHeight).
+		Group("height").Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to Count Txs from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var height, txNo int64
+		err = rows.Scan(&height, &txNo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to Scan Tx count: %v", err)
+		}
+		getStats(height).TxNo = txNo
+	}
+
+	rows, err = db.Table(model.TxOut{}.TableName()).
+		Select("height, COUNT(*), SUM(value)").
+		Where("height >= (?) AND height <= (?)", fromHeight, toHeight).
+		Group("height").Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to Sum TxOuts from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var height, txOutNo, txOutValue int64
+		err = rows.Scan(&height, &txOutNo, &txOutValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to Scan TxOut sum: %v", err)
+		}
+		s := getStats(height)
+		s.TxOutNo = txOutNo
+		s.TxOutValue = txOutValue
+	}
+
+	return stats, nil
+}
+
+func (m *manager) GetBlocksDataInRange(ctx context.Context, fromHeight, toHeight int64, addresses []string) ([]*model.Block, []*model.Tx, []*model.TxIn, []*model.TxOut, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	blocks, err := findBlocks(db.Where("height >= (?) AND height <= (?)", fromHeight, toHeight).Order("height ASC, hash ASC"))
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to Get Blocks from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+
+	inRange := db.Where("height >= (?) AND height <= (?)", fromHeight, toHeight)
+	txsDB, txInsDB, txOutsDB := inRange, inRange, inRange
+	if len(addresses) > 0 {
+		txInsDB = inRange.Where("address IN (?)", addresses)
+		txOutsDB = inRange.Where("address IN (?)", addresses)
+		txsDB = inRange.Where(`hash IN (
+			SELECT tx_hash FROM tx_ins WHERE height >= (?) AND height <= (?) AND address_id IN (SELECT id FROM addresses WHERE address IN (?))
+			UNION SELECT tx_hash FROM tx_outs WHERE height >= (?) AND height <= (?) AND address_id IN (SELECT id FROM addresses WHERE address IN (?)))`,
+			fromHeight, toHeight, addresses, fromHeight, toHeight, addresses)
+	}
+
+	txs, err := findTxs(txsDB.Order("height ASC, hash ASC"))
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to Get Txs from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+	txIns, err := findTxIns(txInsDB.Order("height ASC, tx_hash ASC, tx_index ASC"))
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to Get TxIns from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+	txOuts, err := findTxOuts(txOutsDB.Order("height ASC, tx_hash ASC, tx_index ASC"))
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to Get TxOuts from '%d' to '%d' height: %v", fromHeight, toHeight, err)
+	}
+	return blocks, txs, txIns, txOuts, nil
+}
+
+func (m *manager) ReplaceBlocksData(ctx context.Context, heights []int64, blocks []*model.Block, txs []*model.Tx, txIns []*model.TxIn, txOuts []*model.TxOut) error {
+	err := validateBlocksData(blocks, txs, txIns, txOuts)
+	if err != nil {
+		return err
+	}
+
+	err = m.ensurePartitions(blocks)
+	if err != nil {
+		return err
+	}
+
+	txm, err := m.newTxManager(ctx)
+	if err != nil {
+		return err
+	}
+	defer txm.maybeRollback()
+
+	for _, table := range []interface{}{
+		model.Block{},
+		model.Tx{},
+		model.TxIn{},
+		model.TxOut{},
+	} {
+		err = txm.db.Delete(table, "height IN (?)", heights).Error
+		if err != nil {
+			return fmt.Errorf("failed to Delete %T at heights '%v': %v", table, heights, err)
+		}
+	}
+
+	err = txm.removeBalanceChanges("height IN (?)", heights)
+	if err != nil {
+		return err
+	}
+
+	err = txm.createBlocks(blocks)
+	if err != nil {
+		return fmt.Errorf("failed to Create Blocks, Blocks No '%d': %v", len(blocks), err)
+	}
+
+	err = txm.createTxs(txs)
+	if err != nil {
+		return fmt.Errorf("failed to Create Txs, Txs No '%d': %v", len(txs), err)
+	}
+
+	err = txm.createTxIns(txIns)
+	if err != nil {
+		return fmt.Errorf("failed to Create TxIns, TxIns No '%d': %v", len(txIns), err)
+	}
+
+	err = txm.createTxOuts(txOuts)
+	if err != nil {
+		return fmt.Errorf("failed to Create TxOuts, TxOuts No '%d': %v", len(txOuts), err)
+	}
+
+	err = txm.addBalanceChanges(txIns, txOuts)
+	if err != nil {
+		return err
+	}
+
+	return txm.commit()
+}
+
+func (m *manager) GetTx(ctx context.Context, hash string) (*model.TxDetail, error) {
+	if !validHash(hash) {
+		return nil, common.ErrInvalidHash
+	}
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Looked up by the index on 'txes.hash'. The later of duplicated txs overwrote the earlier one, as in Full Node
+	txs, err := findTxs(db.Where("hash = (?)", hashBytes(hash)).Order("height DESC").Limit(1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Tx '%s': %v", hash, err)
+	}
+	if len(txs) == 0 {
+		return nil, common.ErrNotFound
+	}
+	tx := txs[0]
+
+	block, err := m.GetBlock(ctx, tx.Height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Block at height '%d' of Tx '%s': %v", tx.Height, hash, err)
+	}
+	latestBlock, err := m.GetLatestBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Latest Block: %v", err)
+	}
+
+	// The height leads the keys of 'tx_ins' & 'tx_outs', so a single partition is scanned
+	txIns, err := findTxIns(db.Where("height = (?) AND tx_hash = (?)", tx.Height, hashBytes(hash)).Order("tx_index ASC"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get TxIns of Tx '%s': %v", hash, err)
+	}
+	txOuts, err := findTxOuts(db.Where("height = (?) AND tx_hash = (?)", tx.Height, hashBytes(hash)).Order("tx_index ASC"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get TxOuts of Tx '%s': %v", hash, err)
+	}
+
+	return &model.TxDetail{
+		Tx:            tx,
+		Block:         block,
+		TxIns:         txIns,
+		TxOuts:        txOuts,
+		Confirmations: latestBlock.Height - tx.Height + 1,
+	}, nil
+}
+
+func (m *manager) GetAddressHistory(ctx context.Context, query *model.AddressHistoryQuery) (*model.AddressHistory, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = validateHistoryQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	order, compare := "ASC", ">"
+	if query.Descending {
+		order, compare = "DESC", "<"
+	}
+	// A page of each of ins & outs is read by the indexes on address, then they are merged
+	page := func(credit bool) *gorm.DB {
+		q := db.Where("address = (?) AND height >= (?) AND height <= (?)", query.Address, query.FromHeight, query.ToHeight)
+		if cursor != nil {
+			q = q.Where(fmt.Sprintf("(height, tx_position, tx_hash, tx_index) %s (?, ?, ?, ?)", compare),
+				cursor.height, cursor.txPosition, hashBytes(cursor.txHash), cursor.boundIndex(credit))
+		}
+		return q.Order(fmt.Sprintf("height %s, tx_position %s, tx_hash %s, tx_index %s", order, order, order, order)).Limit(query.Limit + 1)
+	}
+
+	txIns, err := findTxIns(page(false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get TxIns of address '%s': %v", query.Address, err)
+	}
+	txOuts, err := findTxOuts(page(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get TxOuts of address '%s': %v", query.Address, err)
+	}
+	return mergeHistory(txIns, txOuts, query.Limit, query.Descending), nil
+}
+
+func (m *manager) GetUTXOs(ctx context.Context, query *model.UTXOsQuery, coinbaseMaturity int64) (*model.UTXOsPage, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := validateUTXOsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	latestBlock, err := m.GetLatestBlock(ctx)
+	if err == common.ErrNotFound {
+		return &model.UTXOsPage{UTXOs: []*model.UTXO{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Latest Block: %v", err)
+	}
+
+	// Spends are looked up by the index on the previous tx outs of 'tx_ins'. The data of reorganized heights are deleted,
+	// so a tx out spent by a tx in of an orphaned block is unspent again.
+	q := db.Where(`address IN (?) AND NOT EXISTS (
+		SELECT 1 FROM tx_ins WHERE tx_ins.previous_tx_hash = tx_outs.tx_hash AND tx_ins.previous_tx_index = tx_outs.tx_index)`, query.Addresses)
+	if cursor != nil {
+		q = q.Where("(tx_outs.height, tx_outs.tx_position, tx_outs.tx_hash, tx_outs.tx_index) > (?, ?, ?, ?)",
+			cursor.height, cursor.txPosition, hashBytes(cursor.txHash), cursor.boundIndex(true))
+	}
+	txOuts, err := findTxOuts(q.Order("tx_outs.height ASC, tx_outs.tx_position ASC, tx_outs.tx_hash ASC, tx_outs.tx_index ASC").Limit(query.Limit + 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Unspent TxOuts of number of addresses '%d': %v", len(query.Addresses), err)
+	}
+	return pageUTXOs(txOuts, query.Limit, latestBlock.Height, coinbaseMaturity), nil
+}
+
+func (m *manager) GetAddressBalance(ctx context.Context, address string) (*model.AddressBalance, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balance := new(model.AddressBalance)
+	err = db.Where("address = (?)", address).First(balance).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Balance of address '%s': %v", address, err)
+	}
+	return balance, nil
+}
+
+func (m *manager) GetAddressBalanceAtHeight(ctx context.Context, address string, height int64) (*model.AddressBalance, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The changes per height of an address are summed up in DB by the unique index on (address, height)
+	var sums struct {
+		Count           int64
+		Received        int64
+		Sent            int64
+		TxNo            int64
+		FirstSeenHeight int64
+		LastSeenHeight  int64
+	}
+	err = db.Raw(fmt.Sprintf(`SELECT COUNT(*) AS count, COALESCE(SUM(received), 0) AS received, COALESCE(SUM(sent), 0) AS sent,
+		COALESCE(SUM(tx_no), 0) AS tx_no, COALESCE(MIN(height), 0) AS first_seen_height, COALESCE(MAX(height), 0) AS last_seen_height
+		FROM %s WHERE address = ? AND height <= ?`, model.AddressBalanceChange{}.TableName()), address, height).Scan(&sums).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to Sum Balance Changes of address '%s' until height '%d': %v", address, height, err)
+	}
+	if sums.Count == 0 {
+		return nil, common.ErrNotFound
+	}
+	return &model.AddressBalance{
+		Address:         address,
+		Received:        sums.Received,
+		Sent:            sums.Sent,
+		Balance:         sums.Received - sums.Sent,
+		TxNo:            sums.TxNo,
+		FirstSeenHeight: sums.FirstSeenHeight,
+		LastSeenHeight:  sums.LastSeenHeight,
+	}, nil
+}
+
+func (m *manager) GetReorgs(ctx context.Context, query *model.ReorgQuery) ([]*model.Reorg, error) {
+	err := validateReorgQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db = db.Where("to_height >= (?) AND from_height <= (?)", query.FromHeight, query.ToHeight)
+	if !query.FromTime.IsZero() {
+		db = db.Where("created_at >= (?)", query.FromTime.UTC())
+	}
+	if !query.ToTime.IsZero() {
+		db = db.Where("created_at <= (?)", query.ToTime.UTC())
+	}
+	var reorgs []*model.Reorg
+	err = db.Order("id ASC").Find(&reorgs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Reorgs from '%d' to '%d' height: %v", query.FromHeight, query.ToHeight, err)
+	}
+	return reorgs, nil
+}
@@ -1,7 +1,10 @@
 package store
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
+	"github.com/darkknightbk52/btc-indexer/common"
 	"github.com/darkknightbk52/btc-indexer/common/log"
 	"github.com/darkknightbk52/btc-indexer/model"
 	"github.com/jinzhu/gorm"
@@ -10,17 +13,25 @@ import (
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"math"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 var (
 	store Manager
 	db    *gorm.DB
+	// backend arranges & inspects the data of the Manager under test
+	backend testBackend
 )
 
 var (
 	falseValue = false
+	ctx        = context.Background()
 )
 
 func TestMain(m *testing.M) {
@@ -54,33 +65,161 @@ func TestMain(m *testing.M) {
 		Port:     cfg.Port,
 		DBName:   cfg.DbName,
 	}
-	store, err = NewPostgresManager(dbCfg.DSN())
+	// A SQLite file out of the working tree is used if there is no Postgres DB
+	dir, err := ioutil.TempDir("", "store")
 	if err != nil {
-		log.L().Info("Failed to connect to external Postgres DB", zap.String("DSN", dbCfg.DSN()), zap.Error(err))
-		store, err = newManager("sqlite3", "./gorm.db")
+		log.S().Fatal(err)
+	}
+	store, err = newMigratedManager("postgres", dbCfg.DSN())
+	if err != nil {
+		log.L().Info("Failed to connect to external Postgres DB", zap.String("DB", dsnTarget(dbCfg.DSN())), zap.Error(err))
+		store, err = newMigratedManager("sqlite3", filepath.Join(dir, "gorm.db"))
 		if err != nil {
 			log.S().Fatal(err)
 		}
-		log.S().Info("Use Memory DB instead")
+		log.S().Info("Use SQLite DB instead")
 	}
 	db = store.(*manager).db
+	backend = &gormBackend{m: store.(*manager)}
 
 	out := m.Run()
-	_ = os.Remove("./gorm.db")
+	_ = os.RemoveAll(dir)
 	os.Exit(out)
 }
 
-func clearDB(t *testing.T) {
-	tables := []interface{}{
+// newMigratedManager migrates the DB up first, as the manager only checks the schema version.
+func newMigratedManager(dialect, dsn string) (Manager, error) {
+	db, err := gorm.Open(dialect, dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+	err = migrator.Up(0)
+	if err != nil {
+		return nil, err
+	}
+	return newManager(dialect, dsn, nil)
+}
+
+// runManagerTests runs the tests of the Manager behaviours against another implementation.
+func runManagerTests(t *testing.T, m Manager, b testBackend) {
+	sqlStore, sqlBackend := store, backend
+	store, backend = m, b
+	defer func() {
+		store, backend = sqlStore, sqlBackend
+	}()
+
+	for name, test := range map[string]func(t *testing.T){
+		"GetLatestBlock":       TestManager_GetLatestBlock,
+		"GetBlock":             TestManager_GetBlock,
+		"GetBlocks":            TestManager_GetBlocks,
+		"GetBlocksData":        TestManager_GetBlocksData,
+		"AddBlocksData":        TestManager_AddBlocksData,
+		"Reorg":                TestManager_Reorg,
+		"GetBlocksInRange":     TestManager_GetBlocksInRange,
+		"GetBlockStats":        TestManager_GetBlockStats,
+		"GetBlocksDataInRange": TestManager_GetBlocksDataInRange,
+		"ReplaceBlocksData":    TestManager_ReplaceBlocksData,
+		"GetTx":                TestManager_GetTx,
+		"DuplicatedTxs":        TestManager_DuplicatedTxs,
+		"GetAddressHistory":    TestManager_GetAddressHistory,
+		"GetUTXOs":             TestManager_GetUTXOs,
+		"GetAddressBalance":    TestManager_GetAddressBalance,
+		"GetReorgs":            TestManager_GetReorgs,
+		"WatchedAddresses":     TestManager_WatchedAddresses,
+	} {
+		t.Run(name, test)
+	}
+}
+
+// hashOf returns a valid hash made of the name, the hashes sorting as their names do.
+func hashOf(name string) string {
+	h := hex.EncodeToString([]byte(name))
+	return h + strings.Repeat("0", 2*hashSize-len(h))
+}
+
+// testBackend gives the tests a way to arrange & inspect the data, independent of the Manager implementation.
+type testBackend interface {
+	clear() error
+	create(value interface{}) error
+	count(table string) (int, error)
+}
+
+type gormBackend struct {
+	m *manager
+}
+
+// clear drops all tables, then creates them again by the migrations, as the layout differs from the models.
+func (b *gormBackend) clear() error {
+	err := b.m.db.DropTableIfExists(
 		model.Block{},
 		model.Tx{},
 		model.TxIn{},
 		model.TxOut{},
 		model.Reorg{},
+		model.AddressBalance{},
+		model.AddressBalanceChange{},
+		model.WatchedAddress{},
+		addressesTable,
+		schemaMigrationsTable,
+	).Error
+	if err != nil {
+		return err
 	}
-	err := db.DropTable(tables...).Error
-	Expect(err).Should(Succeed())
-	err = db.CreateTable(tables...).Error
+	migrator, err := newMigrator(b.m.db)
+	if err != nil {
+		return err
+	}
+	err = migrator.Up(0)
+	if err != nil {
+		return err
+	}
+	b.m.partitioner, err = newPartitioner(b.m.db)
+	return err
+}
+
+// create writes the blocks, ins & outs like the manager does, other values as they are.
+func (b *gormBackend) create(value interface{}) error {
+	txm, err := b.m.newTxManager(ctx)
+	if err != nil {
+		return err
+	}
+	defer txm.maybeRollback()
+
+	switch v := value.(type) {
+	case model.Block:
+		err = txm.createBlocks([]*model.Block{&v})
+	case model.TxIn:
+		err = b.m.ensurePartitions([]*model.Block{{Height: v.Height}})
+		if err == nil {
+			err = txm.createTxIns([]*model.TxIn{&v})
+		}
+	case model.TxOut:
+		err = b.m.ensurePartitions([]*model.Block{{Height: v.Height}})
+		if err == nil {
+			err = txm.createTxOuts([]*model.TxOut{&v})
+		}
+	default:
+		err = txm.db.Create(value).Error
+	}
+	if err != nil {
+		return err
+	}
+	return txm.commit()
+}
+
+func (b *gormBackend) count(table string) (int, error) {
+	var count int
+	err := b.m.db.Table(table).Count(&count).Error
+	return count, err
+}
+
+func clearDB(t *testing.T) {
+	err := backend.clear()
 	Expect(err).Should(Succeed())
 	log.S().Info("Done clearing DB")
 }
@@ -96,7 +235,7 @@ func TestNewPostgresManager_Fail(t *testing.T) {
 		DBName:   "DbName",
 	}
 	_, e := gorm.Open("postgres", dbCfg.DSN())
-	errContent := fmt.Sprintf("failed to Open connection with postgres DB, DSN '%s': %s", dbCfg.DSN(), e)
+	errContent := fmt.Sprintf("failed to Open connection with postgres DB 'host=Host port=1313 dbname=DbName': %s", e)
 
 	_, err := NewPostgresManager(dbCfg.DSN())
 	Expect(err).ShouldNot(Succeed())
@@ -109,21 +248,21 @@ func TestManager_GetLatestBlock(t *testing.T) {
 	RegisterTestingT(t)
 	clearDB(t)
 
-	err := db.Create(model.Block{
+	err := backend.create(model.Block{
 		Height:       12,
-		Hash:         "12",
-		PreviousHash: "11",
-	}).Error
+		Hash:         hashOf("12"),
+		PreviousHash: hashOf("11"),
+	})
 	Expect(err).Should(Succeed())
 
-	err = db.Create(model.Block{
+	err = backend.create(model.Block{
 		Height:       13,
-		Hash:         "13",
-		PreviousHash: "12",
-	}).Error
+		Hash:         hashOf("13"),
+		PreviousHash: hashOf("12"),
+	})
 	Expect(err).Should(Succeed())
 
-	block, err := store.GetLatestBlock()
+	block, err := store.GetLatestBlock(ctx)
 	Expect(err).Should(Succeed())
 	Expect(block.Height).Should(Equal(int64(13)))
 	log.S().Info("latest block:", block)
@@ -133,13 +272,13 @@ func TestManager_GetBlock(t *testing.T) {
 	RegisterTestingT(t)
 	clearDB(t)
 
-	err := db.Create(model.Block{
+	err := backend.create(model.Block{
 		Height:       13,
-		Hash:         "13",
-		PreviousHash: "12",
-	}).Error
+		Hash:         hashOf("13"),
+		PreviousHash: hashOf("12"),
+	})
 	Expect(err).Should(Succeed())
-	block, err := store.GetBlock(13)
+	block, err := store.GetBlock(ctx, 13)
 	Expect(err).Should(Succeed())
 	Expect(block.Height).Should(Equal(int64(13)))
 	log.S().Info("block:", block)
@@ -149,21 +288,21 @@ func TestManager_GetBlocks(t *testing.T) {
 	RegisterTestingT(t)
 	clearDB(t)
 
-	err := db.Create(model.Block{
+	err := backend.create(model.Block{
 		Height:       12,
-		Hash:         "12",
-		PreviousHash: "11",
-	}).Error
+		Hash:         hashOf("12"),
+		PreviousHash: hashOf("11"),
+	})
 	Expect(err).Should(Succeed())
 
-	err = db.Create(model.Block{
+	err = backend.create(model.Block{
 		Height:       13,
-		Hash:         "13",
-		PreviousHash: "12",
-	}).Error
+		Hash:         hashOf("13"),
+		PreviousHash: hashOf("12"),
+	})
 	Expect(err).Should(Succeed())
 
-	blocks, err := store.GetBlocks([]int64{12, 13})
+	blocks, err := store.GetBlocks(ctx, []int64{12, 13})
 	Expect(err).Should(Succeed())
 	Expect(len(blocks)).Should(Equal(2))
 }
@@ -173,24 +312,24 @@ func TestManager_GetBlocksData(t *testing.T) {
 	clearDB(t)
 
 	// ===
-	err := db.Create(model.Block{
+	err := backend.create(model.Block{
 		Height:       13,
-		Hash:         "13",
-		PreviousHash: "12",
-	}).Error
+		Hash:         hashOf("13"),
+		PreviousHash: hashOf("12"),
+	})
 	Expect(err).Should(Succeed())
-	db.Create(model.TxIn{
+	backend.create(model.TxIn{
 		Height:          13,
-		TxHash:          "tx13",
+		TxHash:          hashOf("tx13"),
 		TxIndex:         0,
 		Address:         "bob",
-		PreviousTxHash:  "ptx13",
+		PreviousTxHash:  hashOf("ptx13"),
 		PreviousTxIndex: 0,
 	})
 	Expect(err).Should(Succeed())
-	db.Create(model.TxOut{
+	backend.create(model.TxOut{
 		Height:       13,
-		TxHash:       "tx13",
+		TxHash:       hashOf("tx13"),
 		TxIndex:      0,
 		Address:      "alice",
 		Value:        13,
@@ -198,18 +337,18 @@ func TestManager_GetBlocksData(t *testing.T) {
 		CoinBase:     &falseValue,
 	})
 	Expect(err).Should(Succeed())
-	db.Create(model.TxIn{
+	backend.create(model.TxIn{
 		Height:          13,
-		TxHash:          "tx13",
+		TxHash:          hashOf("tx13"),
 		TxIndex:         1,
 		Address:         "bob",
-		PreviousTxHash:  "ptx13",
+		PreviousTxHash:  hashOf("ptx13"),
 		PreviousTxIndex: 1,
 	})
 	Expect(err).Should(Succeed())
-	db.Create(model.TxOut{
+	backend.create(model.TxOut{
 		Height:       13,
-		TxHash:       "tx13",
+		TxHash:       hashOf("tx13"),
 		TxIndex:      1,
 		Address:      "alice",
 		Value:        13,
@@ -219,24 +358,24 @@ func TestManager_GetBlocksData(t *testing.T) {
 	Expect(err).Should(Succeed())
 
 	// ===
-	err = db.Create(model.Block{
+	err = backend.create(model.Block{
 		Height:       14,
-		Hash:         "14",
-		PreviousHash: "13",
-	}).Error
+		Hash:         hashOf("14"),
+		PreviousHash: hashOf("13"),
+	})
 	Expect(err).Should(Succeed())
-	db.Create(model.TxIn{
+	backend.create(model.TxIn{
 		Height:          14,
-		TxHash:          "tx14",
+		TxHash:          hashOf("tx14"),
 		TxIndex:         0,
 		Address:         "mike",
-		PreviousTxHash:  "ptx14",
+		PreviousTxHash:  hashOf("ptx14"),
 		PreviousTxIndex: 0,
 	})
 	Expect(err).Should(Succeed())
-	db.Create(model.TxOut{
+	backend.create(model.TxOut{
 		Height:       14,
-		TxHash:       "tx14",
+		TxHash:       hashOf("tx14"),
 		TxIndex:      0,
 		Address:      "john",
 		Value:        13,
@@ -244,18 +383,18 @@ func TestManager_GetBlocksData(t *testing.T) {
 		CoinBase:     &falseValue,
 	})
 	Expect(err).Should(Succeed())
-	db.Create(model.TxIn{
+	backend.create(model.TxIn{
 		Height:          14,
-		TxHash:          "tx14",
+		TxHash:          hashOf("tx14"),
 		TxIndex:         1,
 		Address:         "mike",
-		PreviousTxHash:  "ptx14",
+		PreviousTxHash:  hashOf("ptx14"),
 		PreviousTxIndex: 1,
 	})
 	Expect(err).Should(Succeed())
-	db.Create(model.TxOut{
+	backend.create(model.TxOut{
 		Height:       14,
-		TxHash:       "tx14",
+		TxHash:       hashOf("tx14"),
 		TxIndex:      1,
 		Address:      "john",
 		Value:        13,
@@ -264,7 +403,7 @@ func TestManager_GetBlocksData(t *testing.T) {
 	})
 	Expect(err).Should(Succeed())
 
-	blocks, txIns, txOuts, err := store.GetBlocksData(13, 14, []string{"bob", "alice", "mike", "john"})
+	blocks, txIns, txOuts, err := store.GetBlocksData(ctx, 13, 14, []string{"bob", "alice", "mike", "john"})
 	Expect(err).Should(Succeed())
 	Expect(len(blocks)).Should(Equal(2))
 	Expect(txIns[13]).ShouldNot(BeNil())
@@ -289,7 +428,7 @@ func TestManager_GetBlocksData(t *testing.T) {
 		}
 	}
 
-	blocks, txIns, txOuts, err = store.GetBlocksData(13, 13, []string{"bob", "alice", "mike", "john"})
+	blocks, txIns, txOuts, err = store.GetBlocksData(ctx, 13, 13, []string{"bob", "alice", "mike", "john"})
 	Expect(err).Should(Succeed())
 	Expect(len(blocks)).Should(Equal(1))
 	Expect(txIns[13]).ShouldNot(BeNil())
@@ -297,7 +436,7 @@ func TestManager_GetBlocksData(t *testing.T) {
 	Expect(txOuts[13]).ShouldNot(BeNil())
 	Expect(len(txOuts[13])).Should(Equal(2))
 
-	blocks, txIns, txOuts, err = store.GetBlocksData(13, 14, []string{"bob", "alice"})
+	blocks, txIns, txOuts, err = store.GetBlocksData(ctx, 13, 14, []string{"bob", "alice"})
 	Expect(err).Should(Succeed())
 	Expect(len(blocks)).Should(Equal(2))
 	Expect(txIns[13]).ShouldNot(BeNil())
@@ -307,7 +446,7 @@ func TestManager_GetBlocksData(t *testing.T) {
 	Expect(len(txOuts[13])).Should(Equal(2))
 	Expect(txOuts[14]).Should(BeNil())
 
-	blocks, txIns, txOuts, err = store.GetBlocksData(13, 14, []string{"bob", "mike"})
+	blocks, txIns, txOuts, err = store.GetBlocksData(ctx, 13, 14, []string{"bob", "mike"})
 	Expect(err).Should(Succeed())
 	Expect(len(blocks)).Should(Equal(2))
 	Expect(txIns[13]).ShouldNot(BeNil())
@@ -317,7 +456,7 @@ func TestManager_GetBlocksData(t *testing.T) {
 	Expect(txOuts[13]).Should(BeNil())
 	Expect(txOuts[14]).Should(BeNil())
 
-	blocks, txIns, txOuts, err = store.GetBlocksData(13, 14, []string{"alice", "john"})
+	blocks, txIns, txOuts, err = store.GetBlocksData(ctx, 13, 14, []string{"alice", "john"})
 	Expect(err).Should(Succeed())
 	Expect(len(blocks)).Should(Equal(2))
 	Expect(txIns[13]).Should(BeNil())
@@ -327,7 +466,7 @@ func TestManager_GetBlocksData(t *testing.T) {
 	Expect(txOuts[14]).ShouldNot(BeNil())
 	Expect(len(txOuts[14])).Should(Equal(2))
 
-	blocks, txIns, txOuts, err = store.GetBlocksData(113, 114, []string{"bob", "alice", "mike", "john"})
+	blocks, txIns, txOuts, err = store.GetBlocksData(ctx, 113, 114, []string{"bob", "alice", "mike", "john"})
 	Expect(err).Should(Succeed())
 	Expect(len(blocks)).Should(Equal(0))
 	Expect(txIns[113]).Should(BeNil())
@@ -335,7 +474,7 @@ func TestManager_GetBlocksData(t *testing.T) {
 	Expect(txIns[114]).Should(BeNil())
 	Expect(txOuts[114]).Should(BeNil())
 
-	blocks, txIns, txOuts, err = store.GetBlocksData(13, 14, []string{"bobCat", "aliceCat", "mikeCat", "johnCat"})
+	blocks, txIns, txOuts, err = store.GetBlocksData(ctx, 13, 14, []string{"bobCat", "aliceCat", "mikeCat", "johnCat"})
 	Expect(err).Should(Succeed())
 	Expect(len(blocks)).Should(Equal(2))
 	Expect(txIns[13]).Should(BeNil())
@@ -351,25 +490,25 @@ func TestManager_AddBlocksData(t *testing.T) {
 	blocks := []*model.Block{
 		{
 			Height:       13,
-			Hash:         "13",
-			PreviousHash: "12",
+			Hash:         hashOf("13"),
+			PreviousHash: hashOf("12"),
 		},
 		{
 			Height:       14,
-			Hash:         "14",
-			PreviousHash: "13",
+			Hash:         hashOf("14"),
+			PreviousHash: hashOf("13"),
 		},
 	}
 
 	txes := []*model.Tx{
 		{
 			Height:   13,
-			Hash:     "tx13",
+			Hash:     hashOf("tx13"),
 			CoinBase: &falseValue,
 		},
 		{
 			Height:   14,
-			Hash:     "tx14",
+			Hash:     hashOf("tx14"),
 			CoinBase: &falseValue,
 		},
 	}
@@ -377,34 +516,34 @@ func TestManager_AddBlocksData(t *testing.T) {
 	txIns := []*model.TxIn{
 		{
 			Height:          13,
-			TxHash:          "tx13",
+			TxHash:          hashOf("tx13"),
 			TxIndex:         0,
 			Address:         "bob",
-			PreviousTxHash:  "ptx13",
+			PreviousTxHash:  hashOf("ptx13"),
 			PreviousTxIndex: 0,
 		},
 		{
 			Height:          13,
-			TxHash:          "tx13",
+			TxHash:          hashOf("tx13"),
 			TxIndex:         1,
 			Address:         "bob",
-			PreviousTxHash:  "ptx13",
+			PreviousTxHash:  hashOf("ptx13"),
 			PreviousTxIndex: 1,
 		},
 		{
 			Height:          14,
-			TxHash:          "tx14",
+			TxHash:          hashOf("tx14"),
 			TxIndex:         0,
 			Address:         "mike",
-			PreviousTxHash:  "ptx14",
+			PreviousTxHash:  hashOf("ptx14"),
 			PreviousTxIndex: 0,
 		},
 		{
 			Height:          14,
-			TxHash:          "tx14",
+			TxHash:          hashOf("tx14"),
 			TxIndex:         1,
 			Address:         "mike",
-			PreviousTxHash:  "ptx14",
+			PreviousTxHash:  hashOf("ptx14"),
 			PreviousTxIndex: 1,
 		},
 	}
@@ -412,7 +551,7 @@ func TestManager_AddBlocksData(t *testing.T) {
 	txOuts := []*model.TxOut{
 		{
 			Height:       13,
-			TxHash:       "tx13",
+			TxHash:       hashOf("tx13"),
 			TxIndex:      0,
 			Address:      "alice",
 			Value:        13,
@@ -421,7 +560,7 @@ func TestManager_AddBlocksData(t *testing.T) {
 		},
 		{
 			Height:       13,
-			TxHash:       "tx13",
+			TxHash:       hashOf("tx13"),
 			TxIndex:      1,
 			Address:      "alice",
 			Value:        13,
@@ -430,7 +569,7 @@ func TestManager_AddBlocksData(t *testing.T) {
 		},
 		{
 			Height:       14,
-			TxHash:       "tx14",
+			TxHash:       hashOf("tx14"),
 			TxIndex:      0,
 			Address:      "john",
 			Value:        13,
@@ -439,7 +578,7 @@ func TestManager_AddBlocksData(t *testing.T) {
 		},
 		{
 			Height:       14,
-			TxHash:       "tx14",
+			TxHash:       hashOf("tx14"),
 			TxIndex:      1,
 			Address:      "john",
 			Value:        13,
@@ -448,9 +587,23 @@ func TestManager_AddBlocksData(t *testing.T) {
 		},
 	}
 
-	err := store.AddBlocksData(blocks, txes, txIns, txOuts)
+	err := store.AddBlocksData(ctx, blocks, txes, txIns, txOuts)
 	Expect(err).Should(Succeed())
 
+	// A retried batch doesn't duplicate rows
+	err = store.AddBlocksData(ctx, blocks, txes, txIns, txOuts)
+	Expect(err).Should(Succeed())
+	for table, no := range map[string]int{
+		model.Block{}.TableName(): len(blocks),
+		model.Tx{}.TableName():    len(txes),
+		model.TxIn{}.TableName():  len(txIns),
+		model.TxOut{}.TableName(): len(txOuts),
+	} {
+		count, err := backend.count(table)
+		Expect(err).Should(Succeed())
+		Expect(count).Should(Equal(no))
+	}
+
 	for _, b := range blocks {
 		log.S().Info("block:", b)
 	}
@@ -469,48 +622,878 @@ func TestManager_Reorg(t *testing.T) {
 	RegisterTestingT(t)
 	clearDB(t)
 
-	err := db.Create(model.Block{
+	err := backend.create(model.Block{
 		Height:       12,
-		Hash:         "12",
-		PreviousHash: "11",
-	}).Error
+		Hash:         hashOf("12"),
+		PreviousHash: hashOf("11"),
+	})
 	Expect(err).Should(Succeed())
 
-	err = db.Create(model.Block{
+	err = backend.create(model.Block{
 		Height:       13,
-		Hash:         "13",
-		PreviousHash: "12",
-	}).Error
+		Hash:         hashOf("13"),
+		PreviousHash: hashOf("12"),
+	})
 	Expect(err).Should(Succeed())
 
-	err = db.Create(model.Block{
+	err = backend.create(model.Block{
 		Height:       14,
-		Hash:         "14",
-		PreviousHash: "13",
-	}).Error
+		Hash:         hashOf("14"),
+		PreviousHash: hashOf("13"),
+	})
 	Expect(err).Should(Succeed())
 
-	err = store.Reorg(&model.Reorg{
+	err = store.Reorg(ctx, &model.Reorg{
 		FromHeight: 14,
-		FromHash:   "14",
+		FromHash:   hashOf("14"),
 		ToHeight:   14,
-		ToHash:     "14",
+		ToHash:     hashOf("14"),
 	})
 	Expect(err).Should(Succeed())
 
-	blocks, err := store.GetBlocks([]int64{12, 13, 14})
+	blocks, err := store.GetBlocks(ctx, []int64{12, 13, 14})
 	Expect(err).Should(Succeed())
 	Expect(len(blocks)).Should(Equal(2))
 
-	err = store.Reorg(&model.Reorg{
+	err = store.Reorg(ctx, &model.Reorg{
 		FromHeight: 12,
-		FromHash:   "12",
+		FromHash:   hashOf("12"),
 		ToHeight:   13,
-		ToHash:     "13",
+		ToHash:     hashOf("13"),
 	})
 	Expect(err).Should(Succeed())
 
-	blocks, err = store.GetBlocks([]int64{12, 13, 14})
+	blocks, err = store.GetBlocks(ctx, []int64{12, 13, 14})
 	Expect(err).Should(Succeed())
 	Expect(len(blocks)).Should(Equal(0))
 }
+
+func TestManager_GetBlocksInRange(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	for _, b := range []model.Block{
+		{Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")},
+		{Height: 12, Hash: hashOf("12"), PreviousHash: hashOf("11")},
+		{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")},
+		{Height: 15, Hash: hashOf("15"), PreviousHash: hashOf("14")},
+	} {
+		err := backend.create(b)
+		Expect(err).Should(Succeed())
+	}
+
+	blocks, err := store.GetBlocksInRange(ctx, 12, 14)
+	Expect(err).Should(Succeed())
+	Expect(len(blocks)).Should(Equal(3))
+	Expect(blocks[0].Height).Should(Equal(int64(12)))
+	Expect(blocks[1].Height).Should(Equal(int64(13)))
+	Expect(blocks[2].Height).Should(Equal(int64(14)))
+}
+
+func TestManager_GetBlockStats(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	err := store.AddBlocksData(ctx,
+		[]*model.Block{{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}, {Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("tx13"), CoinBase: &falseValue}, {Height: 13, Hash: hashOf("tx13b"), CoinBase: &falseValue}, {Height: 14, Hash: hashOf("tx14"), CoinBase: &falseValue}},
+		[]*model.TxIn{{Height: 13, TxHash: hashOf("tx13"), Address: "bob", PreviousTxHash: hashOf("ptx13")}},
+		[]*model.TxOut{
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 0, Value: 100, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 13, TxHash: hashOf("tx13b"), TxIndex: 0, Value: 200, Address: "mike", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+		},
+	)
+	Expect(err).Should(Succeed())
+
+	stats, err := store.GetBlockStats(ctx, 13, 14)
+	Expect(err).Should(Succeed())
+	Expect(stats).Should(Equal(map[int64]*model.BlockStats{
+		13: {Height: 13, TxNo: 2, TxOutNo: 2, TxOutValue: 300},
+		14: {Height: 14, TxNo: 1},
+	}))
+}
+
+func TestManager_GetBlocksDataInRange(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	trueValue := true
+	err := store.AddBlocksData(ctx,
+		[]*model.Block{{Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")}, {Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}, {Height: 15, Hash: hashOf("15"), PreviousHash: hashOf("14")}},
+		[]*model.Tx{{Height: 14, Hash: hashOf("tx14"), CoinBase: &falseValue}, {Height: 13, Hash: hashOf("tx13b"), CoinBase: &falseValue}, {Height: 13, Hash: hashOf("tx13"), CoinBase: &trueValue}},
+		[]*model.TxIn{
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 1, Address: "bob", PreviousTxHash: hashOf("tx13"), PreviousTxIndex: 1},
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 0, Address: "alice", PreviousTxHash: hashOf("tx13"), PreviousTxIndex: 0},
+		},
+		[]*model.TxOut{
+			{Height: 13, TxHash: hashOf("tx13b"), TxIndex: 0, Value: 30, Address: "mike", ScriptPubKey: []byte{3}, CoinBase: &falseValue},
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 1, Value: 20, Address: "bob", ScriptPubKey: []byte{2}, CoinBase: &trueValue},
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 0, Value: 10, Address: "alice", ScriptPubKey: []byte{1}, CoinBase: &trueValue},
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 0, Value: 25, Address: "john", ScriptPubKey: []byte{4}, CoinBase: &falseValue},
+		},
+	)
+	Expect(err).Should(Succeed())
+
+	blocks, txs, txIns, txOuts, err := store.GetBlocksDataInRange(ctx, 13, 14, nil)
+	Expect(err).Should(Succeed())
+	Expect(blocks).Should(HaveLen(2))
+	Expect(blocks[0].Hash).Should(Equal(hashOf("13")))
+	Expect(blocks[1].Hash).Should(Equal(hashOf("14")))
+	Expect(txs).Should(HaveLen(3))
+	Expect([]string{txs[0].Hash, txs[1].Hash, txs[2].Hash}).Should(Equal([]string{hashOf("tx13"), hashOf("tx13b"), hashOf("tx14")}))
+	Expect(*txs[0].CoinBase).Should(BeTrue())
+	Expect(txIns).Should(HaveLen(2))
+	Expect(txIns[0].Address).Should(Equal("alice"))
+	Expect(txIns[1].Address).Should(Equal("bob"))
+	Expect(txOuts).Should(HaveLen(4))
+	Expect([]int64{txOuts[0].Value, txOuts[1].Value, txOuts[2].Value, txOuts[3].Value}).Should(Equal([]int64{10, 20, 30, 25}))
+	Expect(txOuts[0].ScriptPubKey).Should(Equal([]byte{1}))
+
+	// The blocks are kept, the txs without any in or out of the addresses are not
+	blocks, txs, txIns, txOuts, err = store.GetBlocksDataInRange(ctx, 13, 15, []string{"mike", "john"})
+	Expect(err).Should(Succeed())
+	Expect(blocks).Should(HaveLen(3))
+	Expect(txs).Should(HaveLen(2))
+	Expect([]string{txs[0].Hash, txs[1].Hash}).Should(Equal([]string{hashOf("tx13b"), hashOf("tx14")}))
+	Expect(txIns).Should(BeEmpty())
+	Expect(txOuts).Should(HaveLen(2))
+	Expect([]string{txOuts[0].Address, txOuts[1].Address}).Should(Equal([]string{"mike", "john"}))
+}
+
+func TestManager_ReplaceBlocksData(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	// The last one of the duplicated heights in a batch is kept
+	err := store.AddBlocksData(ctx,
+		[]*model.Block{{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}, {Height: 13, Hash: hashOf("13b"), PreviousHash: hashOf("12")}, {Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("tx13"), CoinBase: &falseValue}, {Height: 14, Hash: hashOf("tx14"), CoinBase: &falseValue}},
+		[]*model.TxIn{{Height: 13, TxHash: hashOf("tx13"), Address: "bob", PreviousTxHash: hashOf("ptx13")}},
+		[]*model.TxOut{{Height: 13, TxHash: hashOf("tx13"), Value: 100, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue}},
+	)
+	Expect(err).Should(Succeed())
+
+	err = store.ReplaceBlocksData(ctx, []int64{13},
+		[]*model.Block{{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("tx13"), CoinBase: &falseValue}},
+		[]*model.TxIn{{Height: 13, TxHash: hashOf("tx13"), Address: "bob", PreviousTxHash: hashOf("ptx13")}},
+		[]*model.TxOut{{Height: 13, TxHash: hashOf("tx13"), Value: 150, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue}},
+	)
+	Expect(err).Should(Succeed())
+
+	blocks, err := store.GetBlocksInRange(ctx, 13, 14)
+	Expect(err).Should(Succeed())
+	Expect(len(blocks)).Should(Equal(2))
+	Expect(blocks[0].Hash).Should(Equal(hashOf("13")))
+
+	stats, err := store.GetBlockStats(ctx, 13, 13)
+	Expect(err).Should(Succeed())
+	Expect(stats[13].TxOutValue).Should(Equal(int64(150)))
+}
+
+func TestManager_GetTx(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	err := store.AddBlocksData(ctx,
+		[]*model.Block{{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}, {Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")}, {Height: 15, Hash: hashOf("15"), PreviousHash: hashOf("14")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("tx13"), CoinBase: &falseValue}, {Height: 14, Hash: hashOf("tx14"), CoinBase: &falseValue}},
+		[]*model.TxIn{
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 1, Address: "bob", PreviousTxHash: hashOf("tx13"), PreviousTxIndex: 1},
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 0, Address: "bob", PreviousTxHash: hashOf("tx13"), PreviousTxIndex: 0},
+		},
+		[]*model.TxOut{
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 0, Value: 100, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 1, Value: 200, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 0, Value: 290, Address: "alice", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+		},
+	)
+	Expect(err).Should(Succeed())
+
+	detail, err := store.GetTx(ctx, hashOf("tx14"))
+	Expect(err).Should(Succeed())
+	Expect(detail.Tx.Hash).Should(Equal(hashOf("tx14")))
+	Expect(detail.Block.Hash).Should(Equal(hashOf("14")))
+	Expect(detail.Confirmations).Should(Equal(int64(2)))
+	Expect(len(detail.TxIns)).Should(Equal(2))
+	Expect(detail.TxIns[0].TxIndex).Should(Equal(int32(0)))
+	Expect(detail.TxIns[1].TxIndex).Should(Equal(int32(1)))
+	Expect(len(detail.TxOuts)).Should(Equal(1))
+	Expect(detail.TxOuts[0].Value).Should(Equal(int64(290)))
+
+	detail, err = store.GetTx(ctx, hashOf("tx13"))
+	Expect(err).Should(Succeed())
+	Expect(detail.Confirmations).Should(Equal(int64(3)))
+	Expect(len(detail.TxIns)).Should(Equal(0))
+	Expect(len(detail.TxOuts)).Should(Equal(2))
+
+	_, err = store.GetTx(ctx, hashOf("tx15"))
+	Expect(err).Should(Equal(common.ErrNotFound))
+	_, err = store.GetTx(ctx, "tx15")
+	Expect(err).Should(Equal(common.ErrInvalidHash))
+	_, err = store.GetTx(ctx, "D5D27987D2A3DFC724E359870C6644B40E497BDC0589A033220FE15429D88599")
+	Expect(err).Should(Equal(common.ErrInvalidHash))
+
+	// The data with a hash not valid is refused as a whole
+	err = store.AddBlocksData(ctx,
+		[]*model.Block{{Height: 16, Hash: hashOf("16"), PreviousHash: hashOf("15")}},
+		[]*model.Tx{{Height: 16, Hash: "tx16", CoinBase: &falseValue}},
+		nil, nil,
+	)
+	Expect(err).Should(Equal(common.ErrInvalidHash))
+	_, err = store.GetBlock(ctx, 16)
+	Expect(err).Should(Equal(common.ErrNotFound))
+}
+
+func TestManager_DuplicatedTxs(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	// The coinbase of blocks 91812 & 91842 of mainnet, allowed before BIP30
+	const coinBase = "d5d27987d2a3dfc724e359870c6644b40e497bdc0589a033220fe15429d88599"
+	trueValue := true
+	for _, height := range []int64{91812, 91842} {
+		err := store.AddBlocksData(ctx,
+			[]*model.Block{{Height: height, Hash: hashOf(fmt.Sprint(height)), PreviousHash: hashOf(fmt.Sprint(height - 1))}},
+			[]*model.Tx{{Height: height, Hash: coinBase, CoinBase: &trueValue}},
+			nil,
+			[]*model.TxOut{{Height: height, TxHash: coinBase, Value: 5000000000, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &trueValue}},
+		)
+		Expect(err).Should(Succeed())
+	}
+
+	stats, err := store.GetBlockStats(ctx, 91812, 91842)
+	Expect(err).Should(Succeed())
+	Expect(stats[91812].TxNo).Should(Equal(int64(1)))
+	Expect(stats[91842].TxNo).Should(Equal(int64(1)))
+
+	// The later tx overwrote the earlier one
+	detail, err := store.GetTx(ctx, coinBase)
+	Expect(err).Should(Succeed())
+	Expect(detail.Tx.Height).Should(Equal(int64(91842)))
+	Expect(detail.Block.Hash).Should(Equal(hashOf("91842")))
+	Expect(len(detail.TxOuts)).Should(Equal(1))
+
+	err = store.ReplaceBlocksData(ctx, []int64{91842}, []*model.Block{{Height: 91842, Hash: hashOf("91842"), PreviousHash: hashOf("91841")}}, nil, nil, nil)
+	Expect(err).Should(Succeed())
+
+	detail, err = store.GetTx(ctx, coinBase)
+	Expect(err).Should(Succeed())
+	Expect(detail.Tx.Height).Should(Equal(int64(91812)))
+	Expect(detail.Confirmations).Should(Equal(int64(31)))
+}
+
+func TestManager_GetAddressHistory(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	err := store.AddBlocksData(ctx,
+		[]*model.Block{{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}, {Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")}, {Height: 15, Hash: hashOf("15"), PreviousHash: hashOf("14")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("tx13"), CoinBase: &falseValue}, {Height: 14, Hash: hashOf("tx14"), CoinBase: &falseValue}, {Height: 15, Hash: hashOf("tx15"), CoinBase: &falseValue}, {Height: 15, Hash: hashOf("tx00"), CoinBase: &falseValue}},
+		[]*model.TxIn{
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 0, Address: "bob", PreviousTxHash: hashOf("tx13"), PreviousTxIndex: 0},
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 1, Address: "bob", PreviousTxHash: hashOf("tx13"), PreviousTxIndex: 1},
+			{Height: 15, TxHash: hashOf("tx15"), TxIndex: 0, Address: "alice", PreviousTxHash: hashOf("tx14"), PreviousTxIndex: 0, TxPosition: 1},
+		},
+		[]*model.TxOut{
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 0, Value: 100, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 1, Value: 200, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 0, Value: 250, Address: "alice", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 1, Value: 40, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 15, TxHash: hashOf("tx15"), TxIndex: 0, Value: 240, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue, TxPosition: 1},
+			// Spending tx15 later in the block, ordered after it despite the smaller hash
+			{Height: 15, TxHash: hashOf("tx00"), TxIndex: 1, Value: 230, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue, TxPosition: 2},
+		},
+	)
+	Expect(err).Should(Succeed())
+
+	// Pages of 'limit' txs, described as 'kind:height:index'
+	readAll := func(query model.AddressHistoryQuery) []string {
+		var txs []string
+		for {
+			history, err := store.GetAddressHistory(ctx, &query)
+			Expect(err).Should(Succeed())
+			Expect(len(history.Txs)).Should(BeNumerically("<=", query.Limit))
+			for _, tx := range history.Txs {
+				if tx.TxIn != nil {
+					txs = append(txs, fmt.Sprintf("in:%d:%d", tx.TxIn.Height, tx.TxIn.TxIndex))
+				} else {
+					txs = append(txs, fmt.Sprintf("out:%d:%d", tx.TxOut.Height, tx.TxOut.TxIndex))
+				}
+			}
+			if len(history.NextCursor) == 0 {
+				return txs
+			}
+			query.Cursor = history.NextCursor
+		}
+	}
+
+	ascending := []string{"out:13:0", "out:13:1", "in:14:0", "in:14:1", "out:14:1", "out:15:0", "out:15:1"}
+	for _, limit := range []int{1, 2, 4, 7, 10} {
+		Expect(readAll(model.AddressHistoryQuery{Address: "bob", FromHeight: 0, ToHeight: 100, Limit: limit})).Should(Equal(ascending), "limit %d", limit)
+
+		var descending []string
+		for i := len(ascending) - 1; i >= 0; i-- {
+			descending = append(descending, ascending[i])
+		}
+		Expect(readAll(model.AddressHistoryQuery{Address: "bob", FromHeight: 0, ToHeight: 100, Limit: limit, Descending: true})).Should(Equal(descending), "limit %d", limit)
+	}
+
+	Expect(readAll(model.AddressHistoryQuery{Address: "bob", FromHeight: 14, ToHeight: 14, Limit: 2})).Should(Equal([]string{"in:14:0", "in:14:1", "out:14:1"}))
+	Expect(readAll(model.AddressHistoryQuery{Address: "bob", FromHeight: 14, ToHeight: 14, Limit: 2, Descending: true})).Should(Equal([]string{"out:14:1", "in:14:1", "in:14:0"}))
+	Expect(readAll(model.AddressHistoryQuery{Address: "bob", FromHeight: 15, ToHeight: math.MaxInt64, Limit: 1, Descending: true})).Should(Equal([]string{"out:15:1", "out:15:0"}))
+	Expect(readAll(model.AddressHistoryQuery{Address: "mike", FromHeight: 0, ToHeight: 100, Limit: 2})).Should(BeEmpty())
+
+	_, err = store.GetAddressHistory(ctx, &model.AddressHistoryQuery{Address: "bob", FromHeight: 0, ToHeight: 100, Limit: 2, Cursor: "not a cursor"})
+	Expect(err).Should(Equal(common.ErrInvalidCursor))
+	_, err = store.GetAddressHistory(ctx, &model.AddressHistoryQuery{Address: "bob", FromHeight: 15, ToHeight: 14, Limit: 2})
+	Expect(err).Should(Equal(common.ErrInvalidRange))
+}
+
+func TestManager_GetUTXOs(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	getUTXOs := func(addresses []string, coinbaseMaturity int64) []*model.UTXO {
+		page, err := store.GetUTXOs(ctx, &model.UTXOsQuery{Addresses: addresses, Limit: 10}, coinbaseMaturity)
+		Expect(err).Should(Succeed())
+		Expect(page.NextCursor).Should(BeEmpty())
+		return page.UTXOs
+	}
+
+	Expect(getUTXOs([]string{"bob"}, 100)).Should(BeEmpty())
+
+	trueValue := true
+	err := store.AddBlocksData(ctx,
+		[]*model.Block{{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}, {Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")}, {Height: 15, Hash: hashOf("15"), PreviousHash: hashOf("14")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("cb13"), CoinBase: &trueValue}, {Height: 13, Hash: hashOf("tx13"), CoinBase: &falseValue}, {Height: 14, Hash: hashOf("tx14"), CoinBase: &falseValue}},
+		[]*model.TxIn{
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 0, Address: "bob", PreviousTxHash: hashOf("tx13"), PreviousTxIndex: 1},
+		},
+		[]*model.TxOut{
+			{Height: 13, TxHash: hashOf("cb13"), TxIndex: 0, Value: 5000, Address: "bob", ScriptPubKey: []byte("cb"), CoinBase: &trueValue},
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 0, Value: 100, Address: "alice", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 1, Value: 200, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 14, TxHash: hashOf("tx14"), TxIndex: 0, Value: 190, Address: "mike", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+		},
+	)
+	Expect(err).Should(Succeed())
+
+	utxos := getUTXOs([]string{"bob", "alice"}, 3)
+	Expect(len(utxos)).Should(Equal(2))
+	Expect(utxos[0].TxOut.TxHash).Should(Equal(hashOf("cb13")))
+	Expect(utxos[0].TxOut.ScriptPubKey).Should(Equal([]byte("cb")))
+	Expect(utxos[0].Confirmations).Should(Equal(int64(3)))
+	Expect(utxos[0].Mature).Should(BeTrue())
+	Expect(utxos[1].TxOut.TxHash).Should(Equal(hashOf("tx13")))
+	Expect(utxos[1].TxOut.TxIndex).Should(Equal(int32(0)))
+	Expect(utxos[1].Mature).Should(BeTrue())
+
+	utxos = getUTXOs([]string{"bob"}, 4)
+	Expect(len(utxos)).Should(Equal(1))
+	Expect(utxos[0].Mature).Should(BeFalse())
+
+	// Paginated
+	var hashes []string
+	query := &model.UTXOsQuery{Addresses: []string{"bob", "alice"}, Limit: 1}
+	for {
+		page, err := store.GetUTXOs(ctx, query, 3)
+		Expect(err).Should(Succeed())
+		Expect(len(page.UTXOs)).Should(BeNumerically("<=", 1))
+		for _, utxo := range page.UTXOs {
+			hashes = append(hashes, utxo.TxOut.TxHash)
+		}
+		if len(page.NextCursor) == 0 {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	Expect(hashes).Should(Equal([]string{hashOf("cb13"), hashOf("tx13")}))
+	_, err = store.GetUTXOs(ctx, &model.UTXOsQuery{Addresses: []string{"bob"}, Limit: 1, Cursor: "not a cursor"}, 3)
+	Expect(err).Should(Equal(common.ErrInvalidCursor))
+
+	// The spending tx is orphaned
+	err = store.Reorg(ctx, &model.Reorg{FromHeight: 14, FromHash: hashOf("14"), ToHeight: 15, ToHash: hashOf("15")})
+	Expect(err).Should(Succeed())
+	utxos = getUTXOs([]string{"bob", "mike"}, 1)
+	Expect(len(utxos)).Should(Equal(2))
+	Expect(utxos[1].TxOut.TxHash).Should(Equal(hashOf("tx13")))
+	Expect(utxos[1].TxOut.TxIndex).Should(Equal(int32(1)))
+	Expect(utxos[1].Confirmations).Should(Equal(int64(1)))
+}
+
+func TestManager_GetAddressBalance(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	_, err := store.GetAddressBalance(ctx, "bob")
+	Expect(err).Should(Equal(common.ErrNotFound))
+
+	err = store.AddBlocksData(ctx,
+		[]*model.Block{{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("tx13"), CoinBase: &falseValue}},
+		nil,
+		[]*model.TxOut{
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 0, Value: 100, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 1, Value: 200, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+		},
+	)
+	Expect(err).Should(Succeed())
+
+	// Bob spends an out of the previous batch & gets the change back
+	addBlock14 := func() {
+		err := store.AddBlocksData(ctx,
+			[]*model.Block{{Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")}},
+			[]*model.Tx{{Height: 14, Hash: hashOf("tx14"), CoinBase: &falseValue}},
+			[]*model.TxIn{{Height: 14, TxHash: hashOf("tx14"), TxIndex: 0, Address: "bob", PreviousTxHash: hashOf("tx13"), PreviousTxIndex: 1}},
+			[]*model.TxOut{
+				{Height: 14, TxHash: hashOf("tx14"), TxIndex: 0, Value: 150, Address: "alice", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+				{Height: 14, TxHash: hashOf("tx14"), TxIndex: 1, Value: 40, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			},
+		)
+		Expect(err).Should(Succeed())
+	}
+	addBlock14()
+	// A retried batch is counted once
+	addBlock14()
+
+	balance, err := store.GetAddressBalance(ctx, "bob")
+	Expect(err).Should(Succeed())
+	Expect(*balance).Should(Equal(model.AddressBalance{
+		Address: "bob", Received: 340, Sent: 200, Balance: 140, TxNo: 2, FirstSeenHeight: 13, LastSeenHeight: 14,
+	}))
+
+	balance, err = store.GetAddressBalanceAtHeight(ctx, "bob", 13)
+	Expect(err).Should(Succeed())
+	Expect(*balance).Should(Equal(model.AddressBalance{
+		Address: "bob", Received: 300, Sent: 0, Balance: 300, TxNo: 1, FirstSeenHeight: 13, LastSeenHeight: 13,
+	}))
+	_, err = store.GetAddressBalanceAtHeight(ctx, "alice", 13)
+	Expect(err).Should(Equal(common.ErrNotFound))
+
+	// The balances are reversed by a reorg
+	err = store.Reorg(ctx, &model.Reorg{FromHeight: 14, FromHash: hashOf("14"), ToHeight: 14, ToHash: hashOf("14")})
+	Expect(err).Should(Succeed())
+	balance, err = store.GetAddressBalance(ctx, "bob")
+	Expect(err).Should(Succeed())
+	Expect(*balance).Should(Equal(model.AddressBalance{
+		Address: "bob", Received: 300, Sent: 0, Balance: 300, TxNo: 1, FirstSeenHeight: 13, LastSeenHeight: 13,
+	}))
+	_, err = store.GetAddressBalance(ctx, "alice")
+	Expect(err).Should(Equal(common.ErrNotFound))
+
+	// And by a replacement of the data at a height
+	addBlock14()
+	err = store.ReplaceBlocksData(ctx, []int64{14},
+		[]*model.Block{{Height: 14, Hash: hashOf("14b"), PreviousHash: hashOf("13")}},
+		[]*model.Tx{{Height: 14, Hash: hashOf("tx14b"), CoinBase: &falseValue}},
+		[]*model.TxIn{{Height: 14, TxHash: hashOf("tx14b"), TxIndex: 0, Address: "bob", PreviousTxHash: hashOf("tx13"), PreviousTxIndex: 0}},
+		[]*model.TxOut{{Height: 14, TxHash: hashOf("tx14b"), TxIndex: 0, Value: 90, Address: "alice", ScriptPubKey: []byte{}, CoinBase: &falseValue}},
+	)
+	Expect(err).Should(Succeed())
+	balance, err = store.GetAddressBalance(ctx, "bob")
+	Expect(err).Should(Succeed())
+	Expect(balance.Balance).Should(Equal(int64(200)))
+	Expect(balance.TxNo).Should(Equal(int64(2)))
+	balance, err = store.GetAddressBalanceAtHeight(ctx, "alice", 14)
+	Expect(err).Should(Succeed())
+	Expect(balance.Received).Should(Equal(int64(90)))
+}
+
+func TestManager_GetReorgs(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	err := store.AddBlocksData(ctx,
+		[]*model.Block{{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}, {Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("tx13"), CoinBase: &falseValue}, {Height: 14, Hash: hashOf("tx14a"), CoinBase: &falseValue}, {Height: 14, Hash: hashOf("tx14b"), CoinBase: &falseValue}},
+		nil, nil,
+	)
+	Expect(err).Should(Succeed())
+
+	before := time.Now().Add(-time.Minute)
+	err = store.Reorg(ctx, &model.Reorg{FromHeight: 14, FromHash: hashOf("14"), ToHeight: 14, ToHash: hashOf("14"), NewHeight: 15, NewHash: hashOf("15b")})
+	Expect(err).Should(Succeed())
+	err = store.Reorg(ctx, &model.Reorg{
+		FromHeight: 12, FromHash: hashOf("12"), ToHeight: 13, ToHash: hashOf("13"), NewHeight: 14, NewHash: hashOf("14c"),
+		CreatedAt: before.Add(-time.Hour),
+	})
+	Expect(err).Should(Succeed())
+
+	reorgs, err := store.GetReorgs(ctx, &model.ReorgQuery{FromHeight: 0, ToHeight: math.MaxInt64})
+	Expect(err).Should(Succeed())
+	Expect(len(reorgs)).Should(Equal(2))
+	Expect(reorgs[0].Depth()).Should(Equal(int64(1)))
+	Expect(reorgs[0].ToHash).Should(Equal(hashOf("14")))
+	Expect(reorgs[0].NewHash).Should(Equal(hashOf("15b")))
+	Expect(reorgs[0].TxNo).Should(Equal(int64(2)))
+	Expect(reorgs[0].CreatedAt.After(before)).Should(BeTrue())
+	Expect(reorgs[1].Depth()).Should(Equal(int64(2)))
+	Expect(reorgs[1].TxNo).Should(Equal(int64(1)))
+
+	// Since a height, on reconnect
+	reorgs, err = store.GetReorgs(ctx, &model.ReorgQuery{FromHeight: 14, ToHeight: math.MaxInt64})
+	Expect(err).Should(Succeed())
+	Expect(len(reorgs)).Should(Equal(1))
+	Expect(reorgs[0].FromHeight).Should(Equal(int64(14)))
+
+	reorgs, err = store.GetReorgs(ctx, &model.ReorgQuery{FromHeight: 0, ToHeight: math.MaxInt64, ToTime: before})
+	Expect(err).Should(Succeed())
+	Expect(len(reorgs)).Should(Equal(1))
+	Expect(reorgs[0].FromHeight).Should(Equal(int64(12)))
+
+	_, err = store.GetReorgs(ctx, &model.ReorgQuery{FromHeight: 14, ToHeight: 13})
+	Expect(err).Should(Equal(common.ErrInvalidRange))
+}
+
+func TestManager_WatchedAddresses(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	lists, err := store.GetWatchLists(ctx)
+	Expect(err).Should(Succeed())
+	Expect(lists).Should(BeEmpty())
+
+	added, err := store.AddWatchedAddresses(ctx, "hot", []string{"b", "a", "b"})
+	Expect(err).Should(Succeed())
+	Expect(added).Should(Equal(int64(2)))
+	added, err = store.AddWatchedAddresses(ctx, "hot", []string{"a", "c"})
+	Expect(err).Should(Succeed())
+	Expect(added).Should(Equal(int64(1)))
+	added, err = store.AddWatchedAddresses(ctx, "cold", []string{"a"})
+	Expect(err).Should(Succeed())
+	Expect(added).Should(Equal(int64(1)))
+
+	lists, err = store.GetWatchLists(ctx)
+	Expect(err).Should(Succeed())
+	Expect(lists).Should(Equal(map[string][]string{"hot": {"a", "b", "c"}, "cold": {"a"}}))
+
+	removed, err := store.RemoveWatchedAddresses(ctx, "hot", []string{"a", "d"})
+	Expect(err).Should(Succeed())
+	Expect(removed).Should(Equal(int64(1)))
+	removed, err = store.RemoveWatchedAddresses(ctx, "cold", []string{"a"})
+	Expect(err).Should(Succeed())
+	Expect(removed).Should(Equal(int64(1)))
+
+	lists, err = store.GetWatchLists(ctx)
+	Expect(err).Should(Succeed())
+	Expect(lists).Should(Equal(map[string][]string{"hot": {"b", "c"}}))
+}
+
+func TestCopyIn(t *testing.T) {
+	if db.Dialect().GetName() != "postgres" {
+		t.Skip("COPY is only supported by Postgres")
+	}
+	RegisterTestingT(t)
+	clearDB(t)
+
+	m := store.(*manager)
+	write := func(blocks []*model.Block, txs []*model.Tx, txOuts []*model.TxOut) {
+		txm, err := m.newTxManager(ctx)
+		Expect(err).Should(Succeed())
+		defer txm.maybeRollback()
+		Expect(txm.bulkLoad).Should(BeTrue())
+
+		Expect(m.ensurePartitions(blocks)).Should(Succeed())
+		Expect(txm.createBlocks(blocks)).Should(Succeed())
+		Expect(txm.createTxs(txs)).Should(Succeed())
+		// The staging table is dropped after each copy, so a table is copied to twice in a DB transaction
+		Expect(txm.createTxOuts(txOuts[:1])).Should(Succeed())
+		Expect(txm.createTxOuts(txOuts[1:])).Should(Succeed())
+		Expect(txm.commit()).Should(Succeed())
+	}
+
+	// The last one of the duplicated keys in a batch is kept
+	write(
+		[]*model.Block{{Height: 13, Hash: hashOf("13a"), PreviousHash: hashOf("12")}, {Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("tx13"), CoinBase: &falseValue}},
+		[]*model.TxOut{
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 0, Value: 100, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 1, Value: 200, Address: "alice", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+		},
+	)
+	// The rows in conflict with the stored ones update them
+	trueValue := true
+	write(
+		[]*model.Block{{Height: 13, Hash: hashOf("13b"), PreviousHash: hashOf("12")}},
+		[]*model.Tx{{Height: 13, Hash: hashOf("tx13"), CoinBase: &trueValue}},
+		[]*model.TxOut{
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 0, Value: 150, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &trueValue},
+			{Height: 13, TxHash: hashOf("tx13"), TxIndex: 1, Value: 250, Address: "mike", ScriptPubKey: []byte{}, CoinBase: &trueValue},
+		},
+	)
+
+	for table, no := range map[string]int{"blocks": 1, "txes": 1, "tx_outs": 2} {
+		count, err := backend.count(table)
+		Expect(err).Should(Succeed())
+		Expect(count).Should(Equal(no), table)
+	}
+	detail, err := store.GetTx(ctx, hashOf("tx13"))
+	Expect(err).Should(Succeed())
+	Expect(detail.Block.Hash).Should(Equal(hashOf("13b")))
+	Expect(*detail.Tx.CoinBase).Should(BeTrue())
+	Expect(len(detail.TxOuts)).Should(Equal(2))
+	Expect(detail.TxOuts[0].Value).Should(Equal(int64(150)))
+	Expect(detail.TxOuts[1].Value).Should(Equal(int64(250)))
+	Expect(detail.TxOuts[1].Address).Should(Equal("mike"))
+}
+
+func TestDedupe(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	trueValue := true
+	// Duplicates were written to the layout before migration 'compact_hashes_addresses'
+	migrator, err := newMigrator(db)
+	Expect(err).Should(Succeed())
+	err = migrator.Down(7)
+	Expect(err).Should(Succeed())
+
+	// Data written before the unique indexes existed
+	err = db.Model(model.Block{}).RemoveIndex("idx_blocks_height").Error
+	Expect(err).Should(Succeed())
+	err = db.Model(model.TxOut{}).RemoveIndex("idx_tx_outs_height_tx_hash_tx_index").Error
+	Expect(err).Should(Succeed())
+	err = db.Model(model.Tx{}).RemoveIndex("idx_txes_height_hash").Error
+	Expect(err).Should(Succeed())
+	for _, b := range []model.Block{
+		{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")},
+		{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")},
+		{Height: 14, Hash: hashOf("14"), PreviousHash: hashOf("13")},
+	} {
+		err = db.Create(b).Error
+		Expect(err).Should(Succeed())
+	}
+	for _, out := range []model.TxOut{
+		{Height: 13, TxHash: hashOf("tx13"), TxIndex: 0, Value: 100, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+		{Height: 13, TxHash: hashOf("tx13"), TxIndex: 0, Value: 100, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+		{Height: 13, TxHash: hashOf("tx13"), TxIndex: 1, Value: 200, Address: "mike", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+		// The same coinbase in another block, allowed before BIP30
+		{Height: 14, TxHash: hashOf("tx13"), TxIndex: 0, Value: 100, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &trueValue},
+	} {
+		// The positions of the txs were added after
+		err = db.Omit("tx_position").Create(out).Error
+		Expect(err).Should(Succeed())
+	}
+
+	for _, tx := range []model.Tx{
+		{Height: 13, Hash: hashOf("tx13"), CoinBase: &trueValue},
+		{Height: 13, Hash: hashOf("tx13"), CoinBase: &trueValue},
+		{Height: 14, Hash: hashOf("tx13"), CoinBase: &trueValue},
+	} {
+		err = db.Create(tx).Error
+		Expect(err).Should(Succeed())
+	}
+
+	err = dedupe(db, uniqueKeys...)
+	Expect(err).Should(Succeed())
+	err = db.AutoMigrate(model.Block{}, model.Tx{}, model.TxOut{}).Error
+	Expect(err).Should(Succeed())
+
+	var count int
+	err = db.Model(model.Block{}).Count(&count).Error
+	Expect(err).Should(Succeed())
+	Expect(count).Should(Equal(2))
+	err = db.Model(model.TxOut{}).Count(&count).Error
+	Expect(err).Should(Succeed())
+	Expect(count).Should(Equal(3))
+	err = db.Model(model.Tx{}).Count(&count).Error
+	Expect(err).Should(Succeed())
+	Expect(count).Should(Equal(2))
+	Expect(db.Dialect().HasIndex(model.Block{}.TableName(), "idx_blocks_height")).Should(BeTrue())
+	Expect(db.Dialect().HasIndex(model.TxOut{}.TableName(), "idx_tx_outs_height_tx_hash_tx_index")).Should(BeTrue())
+}
+
+func TestMigrator(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	migrator, err := newMigrator(db)
+	Expect(err).Should(Succeed())
+	version, err := migrator.Version()
+	Expect(err).Should(Succeed())
+	Expect(version).Should(Equal(LatestSchemaVersion()))
+
+	err = migrator.Down(2)
+	Expect(err).Should(Succeed())
+	Expect(db.Dialect().HasIndex(model.TxOut{}.TableName(), "idx_tx_outs_height_tx_hash_tx_index")).Should(BeTrue())
+	Expect(db.Dialect().HasIndex(model.Tx{}.TableName(), "idx_txes_height_hash")).Should(BeTrue())
+
+	err = migrator.Down(1)
+	Expect(err).Should(Succeed())
+	version, err = migrator.Version()
+	Expect(err).Should(Succeed())
+	Expect(version).Should(Equal(int64(1)))
+	Expect(db.HasTable(model.Block{}.TableName())).Should(BeTrue())
+	Expect(db.Dialect().HasIndex(model.Block{}.TableName(), "idx_blocks_height")).Should(BeFalse())
+	Expect(db.Dialect().HasIndex(model.TxOut{}.TableName(), "idx_tx_outs_height_tx_hash_tx_index")).Should(BeFalse())
+
+	err = migrator.Down(0)
+	Expect(err).Should(Succeed())
+	version, err = migrator.Version()
+	Expect(err).Should(Succeed())
+	Expect(version).Should(Equal(int64(0)))
+	Expect(db.HasTable(model.Block{}.TableName())).Should(BeFalse())
+
+	err = migrator.Up(0)
+	Expect(err).Should(Succeed())
+	version, err = migrator.Version()
+	Expect(err).Should(Succeed())
+	Expect(version).Should(Equal(LatestSchemaVersion()))
+	Expect(db.Dialect().HasIndex(model.Block{}.TableName(), "idx_blocks_height")).Should(BeTrue())
+	Expect(db.Dialect().HasIndex(model.TxOut{}.TableName(), "idx_tx_outs_height_tx_hash_tx_index")).Should(BeTrue())
+
+	// A DB migrated by a newer binary is refused
+	newerVersion := LatestSchemaVersion() + 1
+	err = db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)", newerVersion, "newer", time.Now()).Error
+	Expect(err).Should(Succeed())
+	defer func() {
+		err = db.Exec("DELETE FROM schema_migrations WHERE version = ?", newerVersion).Error
+		Expect(err).Should(Succeed())
+	}()
+	err = migrator.Up(0)
+	Expect(err).Should(Equal(ErrSchemaTooNew))
+}
+
+func TestCheckSchema(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+	Expect(checkSchema(db)).Should(Succeed())
+
+	migrator, err := newMigrator(db)
+	Expect(err).Should(Succeed())
+	err = migrator.Down(LatestSchemaVersion() - 1)
+	Expect(err).Should(Succeed())
+	Expect(checkSchema(db)).Should(Equal(ErrSchemaTooOld))
+
+	err = db.DropTable(schemaMigrationsTable).Error
+	Expect(err).Should(Succeed())
+	Expect(checkSchema(db)).Should(Equal(ErrSchemaTooOld))
+
+	newerVersion := LatestSchemaVersion() + 1
+	_, err = newMigrator(db)
+	Expect(err).Should(Succeed())
+	err = db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)", newerVersion, "newer", time.Now()).Error
+	Expect(err).Should(Succeed())
+	Expect(checkSchema(db)).Should(Equal(ErrSchemaTooNew))
+	clearDB(t)
+}
+
+func TestHashBytes(t *testing.T) {
+	RegisterTestingT(t)
+
+	hash := "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	Expect(hashBytes(hash)).Should(HaveLen(hashSize))
+	Expect(hashString(hashBytes(hash))).Should(Equal(hash))
+	// Only the hashes in lower case are valid, as they are read back that way
+	for _, invalid := range []string{strings.ToUpper(hash), hash[2:], hash + "00", "tx13"} {
+		Expect(validHash(invalid)).Should(BeFalse())
+		Expect(hashBytes(invalid)).Should(BeNil())
+	}
+	// The genesis block has no previous hash
+	Expect(validHash("")).Should(BeFalse())
+	Expect(hashString(hashBytes(""))).Should(Equal(""))
+	// The hashes of the tests sort as their names
+	Expect(validHash(hashOf("tx13"))).Should(BeTrue())
+	Expect(hashOf("tx13") < hashOf("tx13b")).Should(BeTrue())
+}
+
+func TestCompactMigration(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	migrator, err := newMigrator(db)
+	Expect(err).Should(Succeed())
+	err = migrator.Down(7)
+	Expect(err).Should(Succeed())
+
+	// Data indexed before the migration, without the positions of the txs added after
+	blockHash := "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048"
+	txHash := "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+	err = db.Create(model.Block{Height: 1, Hash: blockHash, PreviousHash: "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"}).Error
+	Expect(err).Should(Succeed())
+	err = db.Create(model.Tx{Height: 1, Hash: txHash, CoinBase: &falseValue}).Error
+	Expect(err).Should(Succeed())
+	for i, address := range []string{"bob", "alice", "bob"} {
+		err = db.Omit("tx_position").Create(model.TxOut{Height: 1, TxHash: txHash, TxIndex: int32(i), Value: 10, Address: address, ScriptPubKey: []byte{1}, CoinBase: &falseValue}).Error
+		Expect(err).Should(Succeed())
+	}
+	err = db.Omit("tx_position").Create(model.TxIn{Height: 1, TxHash: txHash, TxIndex: 0, Address: "mike", PreviousTxHash: hashOf("tx0"), PreviousTxIndex: 1}).Error
+	Expect(err).Should(Succeed())
+
+	// The tables of an indexed DB are only rewritten when allowed
+	err = migrator.Up(0)
+	Expect(err).Should(MatchError(ContainSubstring(ErrRewriteNotAllowed.Error())))
+	version, err := migrator.Version()
+	Expect(err).Should(Succeed())
+	Expect(version).Should(Equal(int64(7)))
+
+	migrator.AllowRewrites()
+	err = migrator.Up(0)
+	Expect(err).Should(Succeed())
+	count, err := backend.count(addressesTable)
+	Expect(err).Should(Succeed())
+	Expect(count).Should(Equal(3))
+	var hashes []struct {
+		Hash []byte
+	}
+	err = db.Table(model.Tx{}.TableName()).Select("hash").Find(&hashes).Error
+	Expect(err).Should(Succeed())
+	Expect(hashes).Should(HaveLen(1))
+	Expect(hashes[0].Hash).Should(HaveLen(hashSize))
+
+	detail, err := store.GetTx(ctx, txHash)
+	Expect(err).Should(Succeed())
+	Expect(detail.Block.Hash).Should(Equal(blockHash))
+	Expect(detail.TxIns).Should(HaveLen(1))
+	Expect(detail.TxIns[0].Address).Should(Equal("mike"))
+	Expect(detail.TxIns[0].PreviousTxHash).Should(Equal(hashOf("tx0")))
+	Expect(detail.TxOuts).Should(HaveLen(3))
+	Expect([]string{detail.TxOuts[0].Address, detail.TxOuts[1].Address, detail.TxOuts[2].Address}).Should(Equal([]string{"bob", "alice", "bob"}))
+
+	// New addresses are added to the dictionary, the known ones are reused
+	err = store.AddBlocksData(ctx, []*model.Block{{Height: 2, Hash: hashOf("2"), PreviousHash: blockHash}}, nil, nil,
+		[]*model.TxOut{{Height: 2, TxHash: hashOf("tx2"), TxIndex: 0, Value: 5, Address: "bob", ScriptPubKey: []byte{}, CoinBase: &falseValue},
+			{Height: 2, TxHash: hashOf("tx2"), TxIndex: 1, Value: 5, Address: "john", ScriptPubKey: []byte{}, CoinBase: &falseValue}})
+	Expect(err).Should(Succeed())
+	count, err = backend.count(addressesTable)
+	Expect(err).Should(Succeed())
+	Expect(count).Should(Equal(4))
+
+	err = migrator.Down(7)
+	Expect(err).Should(Succeed())
+	var txOuts []*model.TxOut
+	err = db.Where("height = (?)", 2).Order("tx_index ASC").Find(&txOuts).Error
+	Expect(err).Should(Succeed())
+	Expect(txOuts).Should(HaveLen(2))
+	Expect(txOuts[1].Address).Should(Equal("john"))
+	tx := new(model.Tx)
+	err = db.Where("hash = (?)", txHash).First(tx).Error
+	Expect(err).Should(Succeed())
+	Expect(db.HasTable(addressesTable)).Should(BeFalse())
+}
+
+func TestPartitionBounds(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(partitionBounds(0, 0)).Should(Equal([]int64{0}))
+	Expect(partitionBounds(heightsPerPartition, heightsPerPartition-1)).Should(BeEmpty())
+	Expect(partitionBounds(heightsPerPartition, 3*heightsPerPartition)).Should(Equal([]int64{heightsPerPartition, 2 * heightsPerPartition, 3 * heightsPerPartition}))
+}
+
+func TestManager_Canceled(t *testing.T) {
+	RegisterTestingT(t)
+	clearDB(t)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err := store.GetLatestBlock(canceled)
+	Expect(err).ShouldNot(Succeed())
+
+	err = store.AddBlocksData(canceled, []*model.Block{{Height: 13, Hash: hashOf("13"), PreviousHash: hashOf("12")}}, nil, nil, nil)
+	Expect(err).ShouldNot(Succeed())
+	count, err := backend.count(model.Block{}.TableName())
+	Expect(err).Should(Succeed())
+	Expect(count).Should(Equal(0))
+}
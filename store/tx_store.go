@@ -15,6 +15,8 @@ const postgresParamsLimit = 65535
 type txManager struct {
 	db        *gorm.DB
 	committed bool
+	// bulkLoad writes with COPY instead of INSERT statements, only supported by Postgres
+	bulkLoad bool
 }
 
 func (txm *txManager) commit() error {
@@ -39,82 +41,136 @@ func (txm *txManager) createBlocks(blocks []*model.Block) error {
 	sql := fmt.Sprintf("INSERT INTO %s (%s)",
 		model.Block{}.TableName(),
 		strings.Join(model.Block{}.ColumnNames(), ","))
+	onConflict := onConflictUpdate(model.Block{}.KeyColumnNames(), model.Block{}.ColumnNames())
 
 	values := make([]interface{}, 0, len(blocks)*len(model.Block{}.ColumnNames()))
-	for _, b := range blocks {
+	for _, i := range lastOfKeys(len(blocks), func(i int) string { return fmt.Sprint(blocks[i].Height) }) {
+		b := blocks[i]
 		values = append(values, b.Height)
-		values = append(values, b.Hash)
-		values = append(values, b.PreviousHash)
+		values = append(values, hashBytes(b.Hash))
+		values = append(values, hashBytes(b.PreviousHash))
 	}
 
-	return txm.execSql(sql, values, len(model.Block{}.ColumnNames()))
+	if txm.bulkLoad {
+		return txm.copyIn(model.Block{}.TableName(), model.Block{}.ColumnNames(), onConflict, values)
+	}
+	return txm.execSql(sql, onConflict, values, len(model.Block{}.ColumnNames()))
 }
 
 func (txm *txManager) createTxs(txs []*model.Tx) error {
 	sql := fmt.Sprintf("INSERT INTO %s (%s)",
 		model.Tx{}.TableName(),
 		strings.Join(model.Tx{}.ColumnNames(), ","))
+	onConflict := onConflictUpdate(model.Tx{}.KeyColumnNames(), model.Tx{}.ColumnNames())
 
 	values := make([]interface{}, 0, len(txs)*len(model.Tx{}.ColumnNames()))
-	for _, b := range txs {
+	for _, i := range lastOfKeys(len(txs), func(i int) string { return fmt.Sprintf("%d:%s", txs[i].Height, txs[i].Hash) }) {
+		b := txs[i]
 		values = append(values, b.Height)
-		values = append(values, b.Hash)
+		values = append(values, hashBytes(b.Hash))
 		values = append(values, b.CoinBase)
 	}
 
-	return txm.execSql(sql, values, len(model.Tx{}.ColumnNames()))
+	if txm.bulkLoad {
+		return txm.copyIn(model.Tx{}.TableName(), model.Tx{}.ColumnNames(), onConflict, values)
+	}
+	return txm.execSql(sql, onConflict, values, len(model.Tx{}.ColumnNames()))
 }
 
 func (txm *txManager) createTxIns(txIns []*model.TxIn) error {
+	if len(txIns) == 0 {
+		return nil
+	}
+	addresses := make([]string, 0, len(txIns))
+	for _, in := range txIns {
+		addresses = append(addresses, in.Address)
+	}
+	ids, err := txm.addressIDs(addresses)
+	if err != nil {
+		return err
+	}
+
 	sql := fmt.Sprintf("INSERT INTO %s (%s)",
 		model.TxIn{}.TableName(),
-		strings.Join(model.TxIn{}.ColumnNames(), ","))
+		strings.Join(txInColumns, ","))
+	onConflict := onConflictUpdate(model.TxIn{}.KeyColumnNames(), txInColumns)
 
-	values := make([]interface{}, 0, len(txIns)*len(model.TxIn{}.ColumnNames()))
-	for _, b := range txIns {
+	values := make([]interface{}, 0, len(txIns)*len(txInColumns))
+	for _, i := range lastOfKeys(len(txIns), func(i int) string { return fmt.Sprintf("%d:%s:%d", txIns[i].Height, txIns[i].TxHash, txIns[i].TxIndex) }) {
+		b := txIns[i]
 		values = append(values, b.Height)
-		values = append(values, b.TxHash)
+		values = append(values, hashBytes(b.TxHash))
 		values = append(values, b.TxIndex)
-		values = append(values, b.Address)
-		values = append(values, b.PreviousTxHash)
+		values = append(values, ids[b.Address])
+		values = append(values, hashBytes(b.PreviousTxHash))
 		values = append(values, b.PreviousTxIndex)
+		values = append(values, b.TxPosition)
 	}
 
-	return txm.execSql(sql, values, len(model.TxIn{}.ColumnNames()))
+	if txm.bulkLoad {
+		return txm.copyIn(model.TxIn{}.TableName(), txInColumns, onConflict, values)
+	}
+	return txm.execSql(sql, onConflict, values, len(txInColumns))
 }
 
 func (txm *txManager) createTxOuts(txOuts []*model.TxOut) error {
+	if len(txOuts) == 0 {
+		return nil
+	}
+	addresses := make([]string, 0, len(txOuts))
+	for _, out := range txOuts {
+		addresses = append(addresses, out.Address)
+	}
+	ids, err := txm.addressIDs(addresses)
+	if err != nil {
+		return err
+	}
+
 	sql := fmt.Sprintf("INSERT INTO %s (%s)",
 		model.TxOut{}.TableName(),
-		strings.Join(model.TxOut{}.ColumnNames(), ","))
-
-	values := make([]interface{}, 0, len(txOuts)*len(model.TxOut{}.ColumnNames()))
-	for _, b := range txOuts {
+		strings.Join(txOutColumns, ","))
+	onConflict := onConflictUpdate(model.TxOut{}.KeyColumnNames(), txOutColumns)
+
+	values := make([]interface{}, 0, len(txOuts)*len(txOutColumns))
+	for _, i := range lastOfKeys(len(txOuts), func(i int) string {
+		return fmt.Sprintf("%d:%s:%d", txOuts[i].Height, txOuts[i].TxHash, txOuts[i].TxIndex)
+	}) {
+		b := txOuts[i]
 		values = append(values, b.Height)
-		values = append(values, b.TxHash)
+		values = append(values, hashBytes(b.TxHash))
 		values = append(values, b.TxIndex)
 		values = append(values, b.Value)
-		values = append(values, b.Address)
+		values = append(values, ids[b.Address])
 		values = append(values, b.ScriptPubKey)
 		values = append(values, b.CoinBase)
+		values = append(values, b.TxPosition)
 	}
 
-	return txm.execSql(sql, values, len(model.TxOut{}.ColumnNames()))
+	if txm.bulkLoad {
+		return txm.copyIn(model.TxOut{}.TableName(), txOutColumns, onConflict, values)
+	}
+	return txm.execSql(sql, onConflict, values, len(txOutColumns))
 }
 
-func (txm *txManager) execSql(sql string, values []interface{}, columnNo int) error {
+func (txm *txManager) execSql(sql, onConflict string, values []interface{}, columnNo int) error {
+	if len(values) == 0 {
+		return nil
+	}
+
 	var sqlParts []string
 	var paramsParts [][]interface{}
 	for len(values) >= postgresParamsLimit {
 		// calculate affordable number of rows be used to build each sql command
 		rowNo := postgresParamsLimit / columnNo
 		limitIndex := rowNo * columnNo
-		sqlParts = append(sqlParts, fmt.Sprintf("%s VALUES %s", sql, common.GenerateSqlValuesPart(columnNo, rowNo)))
+		sqlParts = append(sqlParts, fmt.Sprintf("%s VALUES %s %s", sql, common.GenerateSqlValuesPart(columnNo, rowNo), onConflict))
 		paramsParts = append(paramsParts, values[0:limitIndex])
 		values = values[limitIndex:]
 	}
-	sqlParts = append(sqlParts, fmt.Sprintf("%s VALUES %s", sql, common.GenerateSqlValuesPart(columnNo, len(values)/columnNo)))
-	paramsParts = append(paramsParts, values)
+	if len(values) > 0 {
+		sqlParts = append(sqlParts, fmt.Sprintf("%s VALUES %s %s", sql, common.GenerateSqlValuesPart(columnNo, len(values)/columnNo), onConflict))
+		paramsParts = append(paramsParts, values)
+	}
 
 	for i, paramsPart := range paramsParts {
 		sqlPart := sqlParts[i]
@@ -126,3 +182,27 @@ func (txm *txManager) execSql(sql string, values []interface{}, columnNo int) er
 
 	return nil
 }
+
+// onConflictUpdate makes an INSERT overwrite the row having the same keys, so writing the same data again is harmless.
+func onConflictUpdate(keyColumns, columns []string) string {
+	updates := make([]string, 0, len(columns))
+	for _, c := range columns {
+		updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", c, c))
+	}
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keyColumns, ","), strings.Join(updates, ","))
+}
+
+// lastOfKeys returns the indexes of the last rows per key in order, as an INSERT can't update a row twice on conflict.
+func lastOfKeys(rowNo int, key func(i int) string) []int {
+	last := make(map[string]int, rowNo)
+	for i := 0; i < rowNo; i++ {
+		last[key(i)] = i
+	}
+	indexes := make([]int, 0, len(last))
+	for i := 0; i < rowNo; i++ {
+		if last[key(i)] == i {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"sort"
+)
+
+// validateUTXOsQuery checks the query, then returns its cursor, nil for the first page.
+func validateUTXOsQuery(query *model.UTXOsQuery) (*historyPosition, error) {
+	if query.Limit <= 0 {
+		return nil, fmt.Errorf("invalid limit '%d' of UTXOs", query.Limit)
+	}
+	return decodeCursor(query.Cursor)
+}
+
+// outPosition is the position of a tx out in the pages of UTXOs, as in the history of its address.
+func outPosition(out *model.TxOut) historyPosition {
+	return positionOf(&model.AddressTx{TxOut: out})
+}
+
+// sortByPosition sorts the tx outs in the order of the pages of UTXOs.
+func sortByPosition(txOuts []*model.TxOut) {
+	sort.Slice(txOuts, func(i, j int) bool {
+		return outPosition(txOuts[i]).less(outPosition(txOuts[j]))
+	})
+}
+
+// pageUTXOs returns the first page of 'limit' UTXOs of the tx outs sorted by position.
+// Up to 'limit' + 1 tx outs are given to know if there is a next page.
+func pageUTXOs(txOuts []*model.TxOut, limit int, latestHeight, coinbaseMaturity int64) *model.UTXOsPage {
+	page := new(model.UTXOsPage)
+	if len(txOuts) > limit {
+		txOuts = txOuts[:limit]
+		page.NextCursor = encodeCursor(outPosition(txOuts[limit-1]))
+	}
+	page.UTXOs = toUTXOs(txOuts, latestHeight, coinbaseMaturity)
+	return page
+}
+
+func toUTXOs(txOuts []*model.TxOut, latestHeight, coinbaseMaturity int64) []*model.UTXO {
+	utxos := make([]*model.UTXO, 0, len(txOuts))
+	for _, out := range txOuts {
+		confirmations := latestHeight - out.Height + 1
+		utxos = append(utxos, &model.UTXO{
+			TxOut:         out,
+			Confirmations: confirmations,
+			Mature:        out.CoinBase == nil || !*out.CoinBase || confirmations >= coinbaseMaturity,
+		})
+	}
+	return utxos
+}
+
+func sortTxOuts(txOuts []*model.TxOut) {
+	sort.Slice(txOuts, func(i, j int) bool {
+		if txOuts[i].Height != txOuts[j].Height {
+			return txOuts[i].Height < txOuts[j].Height
+		}
+		if txOuts[i].TxHash != txOuts[j].TxHash {
+			return txOuts[i].TxHash < txOuts[j].TxHash
+		}
+		return txOuts[i].TxIndex < txOuts[j].TxIndex
+	})
+}
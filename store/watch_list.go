@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"github.com/darkknightbk52/btc-indexer/model"
+	"sort"
+	"time"
+)
+
+func (m *manager) AddWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error) {
+	txm, err := m.newTxManager(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer txm.maybeRollback()
+
+	// An INSERT per address, as the rows affected by a multi rows one do not tell which addresses were new
+	var added int64
+	now := time.Now().UTC()
+	for _, address := range distinctAddresses(addresses) {
+		res := txm.db.Exec("INSERT INTO watched_addresses (list, address, created_at) VALUES (?, ?, ?) ON CONFLICT (list, address) DO NOTHING",
+			list, address, now)
+		if res.Error != nil {
+			return 0, fmt.Errorf("failed to Add address '%s' to watch list '%s': %v", address, list, res.Error)
+		}
+		added += res.RowsAffected
+	}
+
+	err = txm.commit()
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+func (m *manager) RemoveWatchedAddresses(ctx context.Context, list string, addresses []string) (int64, error) {
+	if len(addresses) == 0 {
+		return 0, nil
+	}
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	res := db.Where("list = (?) AND address IN (?)", list, distinctAddresses(addresses)).Delete(model.WatchedAddress{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to Remove addresses from watch list '%s': %v", list, res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+func (m *manager) GetWatchLists(ctx context.Context) (map[string][]string, error) {
+	db, err := m.withContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var watched []*model.WatchedAddress
+	err = db.Order("list ASC, address ASC").Find(&watched).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to Get Watch Lists: %v", err)
+	}
+	lists := make(map[string][]string)
+	for _, w := range watched {
+		lists[w.List] = append(lists[w.List], w.Address)
+	}
+	return lists, nil
+}
+
+func distinctAddresses(addresses []string) []string {
+	seen := make(map[string]bool, len(addresses))
+	distinct := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		if !seen[a] {
+			seen[a] = true
+			distinct = append(distinct, a)
+		}
+	}
+	return distinct
+}
+
+// sortedWatchLists copies the watch lists, sorting the addresses of each one.
+func sortedWatchLists(lists map[string]map[string]bool) map[string][]string {
+	sorted := make(map[string][]string, len(lists))
+	for name, addresses := range lists {
+		if len(addresses) == 0 {
+			continue
+		}
+		list := make([]string, 0, len(addresses))
+		for a := range addresses {
+			list = append(list, a)
+		}
+		sort.Strings(list)
+		sorted[name] = list
+	}
+	return sorted
+}